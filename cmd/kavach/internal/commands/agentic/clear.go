@@ -0,0 +1,47 @@
+// Package agentic provides dynamic loader cache subcommands.
+// clear.go: Reset the loader cache and delete the persisted trigger index.
+package agentic
+
+import (
+	"fmt"
+	"os"
+
+	shared "github.com/claude/shared/pkg/agentic"
+	"github.com/spf13/cobra"
+)
+
+var clearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Reset the dynamic loader cache",
+	Long: `[AGENTIC_CLEAR]
+desc: Delete the persisted trigger index and drop cached agents and skills
+note: The index is saved by status; clear removes ~/.claude/agentic/triggers.json
+purpose: Verify a clean reload after editing SKILL.md triggers
+
+usage:
+  kavach agentic clear`,
+	Run: runClear,
+}
+
+func runClear(cmd *cobra.Command, args []string) {
+	path := shared.IndexPath()
+	loader := shared.NewAgenticSystem(shared.DefaultSystemConfig()).Loader()
+	if err := loader.LoadIndex(path); err != nil {
+		fmt.Fprintf(os.Stderr, "[AGENTIC_CLEAR] read %s: %v\n", path, err)
+	}
+	before := loader.Stats()
+
+	agents, skills := loader.Clear()
+	removed, err := shared.RemoveIndex(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[AGENTIC_CLEAR] remove %s: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("[AGENTIC_CLEAR]")
+	fmt.Printf("index: %s\n", path)
+	fmt.Printf("removed: %v\n", removed)
+	fmt.Printf("dropped_agents: %d\n", agents)
+	fmt.Printf("dropped_skills: %d\n", skills)
+	fmt.Printf("dropped_triggers: %d\n", before.TriggerIndexSize)
+}
@@ -0,0 +1,10 @@
+// Package agentic provides dynamic loader cache subcommands.
+package agentic
+
+import "github.com/spf13/cobra"
+
+// Register adds all agentic commands to the parent agentic command.
+func Register(agenticCmd *cobra.Command) {
+	agenticCmd.AddCommand(statusCmd)
+	agenticCmd.AddCommand(clearCmd)
+}
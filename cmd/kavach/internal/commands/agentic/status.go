@@ -0,0 +1,134 @@
+// Package agentic provides dynamic loader cache subcommands.
+// status.go: Loaded agents/skills, memory estimate, trigger index.
+package agentic
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	shared "github.com/claude/shared/pkg/agentic"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusCold     bool
+	statusTrigger  string
+	statusTriggers bool
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show dynamic loader cache state",
+	Long: `[AGENTIC_STATUS]
+desc: Loaded agents/skills, memory footprint estimate, trigger index size, conflicts
+note: A warm run saves the trigger index to ~/.claude/agentic/triggers.json; --cold reads it back
+purpose: Diagnose why a skill isn't firing (trigger not indexed) or memory growth
+
+usage:
+  kavach agentic status                  # Warm skills, show cache
+  kavach agentic status --triggers       # List trigger -> skill index
+  kavach agentic status --trigger cargo  # Which skill owns a trigger
+  kavach agentic status --cold           # Saved index without warming`,
+	Run: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusCold, "cold", false, "Report the saved trigger index instead of warming skills")
+	statusCmd.Flags().StringVar(&statusTrigger, "trigger", "", "Look up the skill indexed for a trigger")
+	statusCmd.Flags().BoolVar(&statusTriggers, "triggers", false, "List the full trigger index")
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	cfg := shared.DefaultSystemConfig()
+	loader := shared.NewAgenticSystem(cfg).Loader()
+
+	var failed []string
+	if statusCold {
+		if err := loader.LoadIndex(shared.IndexPath()); err != nil {
+			fmt.Fprintf(os.Stderr, "[AGENTIC_STATUS] read index: %v\n", err)
+		}
+	} else {
+		failed = loader.WarmSkills()
+		if err := loader.SaveIndex(shared.IndexPath()); err != nil {
+			fmt.Fprintf(os.Stderr, "[AGENTIC_STATUS] save index: %v\n", err)
+		}
+	}
+	stats := loader.Stats()
+
+	fmt.Println("[AGENTIC_STATUS]")
+	fmt.Printf("agent_dir: %s\n", cfg.AgentDir)
+	fmt.Printf("skill_dir: %s\n", cfg.SkillDir)
+	fmt.Printf("warmed: %v\n", !statusCold)
+	fmt.Printf("loaded_agents: %d\n", len(stats.LoadedAgents))
+	fmt.Printf("loaded_skills: %d\n", len(stats.LoadedSkills))
+	fmt.Printf("trigger_index: %d\n", stats.TriggerIndexSize)
+	fmt.Printf("memory_estimate: %s\n", formatBytes(stats.MemoryBytes))
+
+	if len(stats.LoadedAgents) > 0 {
+		fmt.Printf("agents: %s\n", strings.Join(stats.LoadedAgents, ", "))
+	}
+	if len(stats.LoadedSkills) > 0 {
+		fmt.Printf("skills: %s\n", strings.Join(stats.LoadedSkills, ", "))
+	}
+	if len(failed) > 0 {
+		fmt.Printf("failed: %s\n", strings.Join(failed, ", "))
+	}
+
+	if conflicts := loader.DetectTriggerConflicts(); len(conflicts) > 0 {
+		keys := sortedKeys(conflicts)
+		fmt.Println()
+		fmt.Printf("[TRIGGER_CONFLICTS:%d]\n", len(keys))
+		for _, k := range keys {
+			fmt.Printf("%s: %s -> %s\n", k, strings.Join(conflicts[k], ", "), loader.FindSkillByTrigger(k))
+		}
+	}
+
+	if statusTrigger != "" {
+		fmt.Println()
+		fmt.Println("[TRIGGER]")
+		fmt.Printf("keyword: %s\n", statusTrigger)
+		if name := loader.FindSkillByTrigger(statusTrigger); name != "" {
+			fmt.Printf("skill: %s\n", name)
+			if claimants := loader.Triggers()[statusTrigger]; len(claimants) > 1 {
+				fmt.Printf("claimed_by: %s\n", strings.Join(claimants, ", "))
+			}
+		} else {
+			fmt.Println("skill: none (not indexed - check the skill's triggers: line)")
+		}
+	}
+
+	if statusTriggers {
+		index := loader.Triggers()
+		keys := sortedKeys(index)
+
+		fmt.Println()
+		fmt.Printf("[TRIGGERS:%d]\n", len(keys))
+		for _, k := range keys {
+			fmt.Printf("%s: %s\n", k, strings.Join(index[k], ", "))
+		}
+	}
+}
+
+// sortedKeys returns the map's keys in order.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatBytes renders a byte count as B/KB/MB.
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,142 @@
+// Package chain provides verification chain state subcommands.
+// classify.go: Batch intent classification of historical prompts, for
+// tuning intent keywords before they cause false blocks.
+package chain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/claude/cmd/kavach/internal/commands/gates"
+	chainpkg "github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var classifyFile string
+
+var classifyCmd = &cobra.Command{
+	Use:   "classify",
+	Short: "Classify a file of prompts and show the intent distribution",
+	Long: `[CHAIN_CLASSIFY]
+desc: Runs the configured intent classifier (intent.keywords in gates config)
+      on each line of a file and prints one row per prompt plus histograms
+input: One prompt per line; blank lines and lines starting with # are skipped
+fields: line, prompt snippet, type, risk, confidence, matched signals
+
+usage:
+  kavach chain classify --file prompts.txt
+  grep -h '"prompt"' logs/*.jsonl | jq -r .prompt | kavach chain classify --file -`,
+	Run: runClassify,
+}
+
+func init() {
+	classifyCmd.Flags().StringVar(&classifyFile, "file", "", "File with one prompt per line (- for stdin)")
+	classifyCmd.MarkFlagRequired("file")
+}
+
+// classifiedPrompt is one input line with its analysis.
+type classifiedPrompt struct {
+	Line   int
+	Prompt string
+	Intent *chainpkg.IntentAnalysis
+}
+
+// riskOrder lists risk levels low to high for the histogram.
+var riskOrder = []string{"low", "medium", "high", "critical"}
+
+func runClassify(cmd *cobra.Command, args []string) {
+	var in io.Reader = os.Stdin
+	if classifyFile != "-" {
+		f, err := os.Open(classifyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[CHAIN] Classify error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	classifier := gates.IntentClassifier(config.LoadGatesConfig())
+	var results []classifiedPrompt
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		prompt := strings.TrimSpace(scanner.Text())
+		if prompt == "" || strings.HasPrefix(prompt, "#") {
+			continue
+		}
+		results = append(results, classifiedPrompt{Line: line, Prompt: prompt, Intent: classifier.Classify(prompt)})
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "[CHAIN] Classify read error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("[CHAIN_CLASSIFY]")
+	fmt.Printf("file: %s\n", classifyFile)
+	fmt.Printf("config_hash: %s\n", config.GatesConfigHash())
+	fmt.Printf("prompts: %d\n", len(results))
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("[PROMPTS]")
+	fmt.Printf("%-5s %-40s %-10s %-8s %-5s %s\n", "line", "prompt", "type", "risk", "conf", "signals")
+	types := make(map[string]int)
+	risks := make(map[string]int)
+	for _, r := range results {
+		fmt.Printf("%-5d %-40s %-10s %-8s %.2f  %s\n", r.Line, snippet(r.Prompt, 40),
+			r.Intent.Type, r.Intent.RiskLevel, r.Intent.Confidence, strings.Join(r.Intent.MatchedSignals, ","))
+		types[r.Intent.Type]++
+		risks[r.Intent.RiskLevel]++
+	}
+
+	typeNames := make([]string, 0, len(types))
+	for t := range types {
+		typeNames = append(typeNames, t)
+	}
+	sort.Slice(typeNames, func(i, j int) bool {
+		if types[typeNames[i]] != types[typeNames[j]] {
+			return types[typeNames[i]] > types[typeNames[j]]
+		}
+		return typeNames[i] < typeNames[j]
+	})
+	fmt.Println()
+	fmt.Println("[BY_TYPE]")
+	for _, t := range typeNames {
+		printBar(t, types[t], len(results))
+	}
+
+	fmt.Println()
+	fmt.Println("[BY_RISK]")
+	for _, level := range riskOrder {
+		if risks[level] > 0 {
+			printBar(level, risks[level], len(results))
+		}
+	}
+}
+
+// printBar prints one histogram row scaled to 40 columns.
+func printBar(label string, count, total int) {
+	width := count * 40 / total
+	if width == 0 {
+		width = 1
+	}
+	fmt.Printf("%-10s %4d %5.1f%% %s\n", label, count, float64(count)*100/float64(total), strings.Repeat("#", width))
+}
+
+// snippet shortens a prompt to max runes on one line.
+func snippet(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-3]) + "..."
+}
@@ -0,0 +1,55 @@
+// Package chain provides verification chain state subcommands.
+// list.go: Persisted chain runs with a --since filter.
+package chain
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	chainpkg "github.com/claude/shared/pkg/chain"
+	"github.com/spf13/cobra"
+)
+
+var listSince time.Duration
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List persisted chain runs",
+	Long: `[CHAIN_LIST]
+desc: One line per persisted chain run (~/.claude/chain), newest first
+fields: time, session, final status, gate count
+
+usage:
+  kavach chain list              # All runs
+  kavach chain list --since 24h  # Runs from the last day`,
+	Run: runList,
+}
+
+func init() {
+	listCmd.Flags().DurationVar(&listSince, "since", 0, "Only runs modified within this duration (e.g. 24h)")
+}
+
+func runList(cmd *cobra.Command, args []string) {
+	dir := chainpkg.StateDir()
+	states, err := chainpkg.ListStates(dir, listSince)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[CHAIN] List error: %v\n", err)
+		return
+	}
+
+	fmt.Println("[CHAIN_LIST]")
+	fmt.Printf("dir: %s\n", dir)
+	if listSince > 0 {
+		fmt.Printf("since: %s\n", listSince)
+	}
+	fmt.Printf("count: %d\n", len(states))
+	if len(states) == 0 {
+		return
+	}
+	fmt.Println()
+	for _, s := range states {
+		fmt.Printf("%s session=%s status=%s gates=%d\n",
+			s.Time.Format(time.RFC3339), s.SessionID, s.FinalStatus, s.Gates)
+	}
+}
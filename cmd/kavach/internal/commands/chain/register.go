@@ -0,0 +1,12 @@
+// Package chain provides verification chain state subcommands.
+package chain
+
+import "github.com/spf13/cobra"
+
+// Register adds all chain commands to the parent chain command.
+func Register(chainCmd *cobra.Command) {
+	chainCmd.AddCommand(listCmd)
+	chainCmd.AddCommand(replayCmd)
+	chainCmd.AddCommand(classifyCmd)
+	chainCmd.AddCommand(topologyCmd)
+}
@@ -0,0 +1,109 @@
+// Package chain provides verification chain state subcommands.
+// replay.go: Re-run a saved chain state against the current gates config.
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/claude/cmd/kavach/internal/commands/gates"
+	chainpkg "github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <state-file>",
+	Short: "Re-evaluate a saved chain run against the current config",
+	Long: `[CHAIN_REPLAY]
+desc: Re-runs the recorded prompt/tool/input of a chain_*.json file with the
+      current gates config and prints the before/after decision diff
+note: The replay is not persisted; runs saved before input capture cannot replay
+
+usage:
+  kavach chain list
+  kavach chain replay ~/.claude/chain/chain_<session>_<unix>.json`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReplay,
+}
+
+func runReplay(cmd *cobra.Command, args []string) {
+	saved, err := chainpkg.LoadState(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[CHAIN] Replay error: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := config.LoadGatesConfig()
+	opts := append(gates.ChainOptions(cfg, saved.PriorRiskScore(), nil),
+		chainpkg.WithProvenance(config.GatesConfigHash(), nil),
+	)
+	if saved.Input != nil && saved.Input.TranscriptPath != "" {
+		opts = append(opts, chainpkg.WithTranscript(saved.Input.TranscriptPath, cfg.Research.ResearchTools, cfg.Research.ResearchWindow))
+	}
+
+	replayed, err := chainpkg.Replay(saved, opts...)
+	if errors.Is(err, chainpkg.ErrNoInput) {
+		fmt.Fprintf(os.Stderr, "[CHAIN] %s was saved without inputs and cannot be replayed\n", args[0])
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "[CHAIN] Replay error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("[CHAIN_REPLAY]")
+	fmt.Printf("file: %s\n", args[0])
+	fmt.Printf("session: %s\n", saved.SessionID)
+	fmt.Printf("tool: %s\n", saved.Input.ToolName)
+	if saved.Input.Redacted {
+		fmt.Println("redacted: true (scrubbed values replay as placeholders)")
+	}
+	fmt.Printf("config_hash: %s\n", config.GatesConfigHash())
+	fmt.Printf("before: %s\n", saved.FinalStatus)
+	fmt.Printf("after: %s\n", replayed.FinalStatus)
+	fmt.Printf("changed: %t\n", saved.FinalStatus != replayed.FinalStatus)
+
+	fmt.Println()
+	fmt.Println("[GATES]")
+	for _, d := range chainpkg.DiffStates(saved, replayed) {
+		marker := " "
+		if d.Changed() {
+			marker = "*"
+		}
+		fmt.Printf("%s %s: %s -> %s\n", marker, d.Gate, gateStatus(d.Before), gateStatus(d.After))
+	}
+
+	if reasons := changedReasons(saved, replayed); len(reasons) > 0 {
+		fmt.Println()
+		fmt.Println("[NOW]")
+		for _, r := range reasons {
+			fmt.Println(r)
+		}
+	}
+}
+
+// gateStatus renders a missing gate result (fail-fast skipped it).
+func gateStatus(status string) string {
+	if status == "" {
+		return "not_run"
+	}
+	return status
+}
+
+// changedReasons lists the replayed reasons for gates whose status moved.
+func changedReasons(before, after *chainpkg.ChainState) []string {
+	changed := make(map[string]bool)
+	for _, d := range chainpkg.DiffStates(before, after) {
+		if d.Changed() {
+			changed[d.Gate] = true
+		}
+	}
+	var out []string
+	for _, r := range after.Results {
+		if changed[r.Gate] && r.Reason != "" {
+			out = append(out, fmt.Sprintf("%s: %s", r.Gate, r.Reason))
+		}
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+// Package chain provides verification chain state subcommands.
+// topology.go: Diagram of the configured chain pipeline for docs.
+package chain
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/claude/cmd/kavach/internal/commands/gates"
+	chainpkg "github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var topologyFormat string
+
+var topologyCmd = &cobra.Command{
+	Use:   "topology",
+	Short: "Render the configured chain pipeline as Mermaid or dot",
+	Long: `[CHAIN_TOPOLOGY]
+desc: Builds a runner from the gates config (as the hooks do) and draws its
+      pipeline: INTENT → CEO → AEGIS → RESEARCH → POLICY
+shows: enabled Aegis checks, parallel Aegis/Research (enforcer.parallel),
+       the tools it covers (enforcer.chain), block exits per stage with
+       enforcer.fail_fast or one at the end without, disabled stages greyed
+       (all of them when enforcer.enabled is off)
+
+usage:
+  kavach chain topology                 # Mermaid flowchart
+  kavach chain topology --format dot | dot -Tsvg > chain.svg`,
+	Run: runTopology,
+}
+
+func init() {
+	topologyCmd.Flags().StringVar(&topologyFormat, "format", "mermaid", "Output format: mermaid or dot")
+}
+
+func runTopology(cmd *cobra.Command, args []string) {
+	cfg := config.LoadGatesConfig()
+	topo := chainpkg.NewRunner("topology", gates.ChainOptions(cfg, 0, nil)...).Topology()
+	topo.Enabled = cfg.Enforcer.Enabled
+	topo.Chain = cfg.Enforcer.Chain
+	topo.FailFast = cfg.Enforcer.FailFast
+	switch topologyFormat {
+	case "mermaid":
+		fmt.Print(topo.Mermaid())
+	case "dot":
+		fmt.Print(topo.Dot())
+	default:
+		fmt.Fprintf(os.Stderr, "[CHAIN] Unknown format %q (mermaid, dot)\n", topologyFormat)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+// Package config provides gates configuration subcommands.
+// diff.go: Effective gates config vs defaults, with value sources.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	gatescfg "github.com/claude/shared/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffAll    bool
+	diffFormat string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show effective gates config vs defaults",
+	Long: `[CONFIG_DIFF]
+desc: Fully-merged effective GatesConfig compared to built-in defaults
+sources: default < global (~/.claude/gates/config.json) < project (.kavach/gates.json); unset = omitted, zero value
+purpose: Debug "why is this blocked"
+
+usage:
+  kavach config diff               # Changed fields only
+  kavach config diff --all         # Every effective field
+  kavach config diff --format json # Machine-readable`,
+	Run: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffAll, "all", false, "Show unchanged fields too")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "toon", "Output format (toon, json)")
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	fields := gatescfg.DiffGatesConfig()
+
+	var shown []gatescfg.ConfigField
+	changed := 0
+	for _, f := range fields {
+		if f.Changed {
+			changed++
+		}
+		if f.Changed || diffAll {
+			shown = append(shown, f)
+		}
+	}
+
+	if diffFormat == "json" {
+		data, _ := json.MarshalIndent(shown, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Println("[CONFIG_DIFF]")
+	layers := gatescfg.GatesConfigLayers()
+	fmt.Printf("global: %s\n", layers[0])
+	for _, project := range layers[1:] {
+		fmt.Printf("project: %s\n", project)
+	}
+	if _, err := gatescfg.ReadGatesConfigLayers(layers[0], layers[1:]...); errors.Is(err, gatescfg.ErrInvalidConfig) {
+		fmt.Printf("warning: %v (defaults in effect)\n", err)
+	}
+	fmt.Printf("fields: %d\n", len(fields))
+	fmt.Printf("changed: %d\n", changed)
+	fmt.Println()
+
+	for _, f := range shown {
+		marker := " "
+		if f.Changed {
+			marker = "*"
+		}
+		fmt.Printf("%s %s: %s (%s)\n", marker, f.Path, compactJSON(f.Value), f.Source)
+		if f.Changed {
+			fmt.Printf("    default: %s\n", compactJSON(f.Default))
+		}
+	}
+}
+
+// compactJSON renders a config value on one line.
+func compactJSON(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,11 @@
+// Package config provides gates configuration subcommands.
+package config
+
+import "github.com/spf13/cobra"
+
+// Register adds all config commands to the parent config command.
+func Register(configCmd *cobra.Command) {
+	configCmd.AddCommand(diffCmd)
+	configCmd.AddCommand(suggestCmd)
+	configCmd.AddCommand(templateCmd)
+}
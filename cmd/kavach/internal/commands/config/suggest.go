@@ -0,0 +1,70 @@
+// Package config provides gates configuration subcommands.
+// suggest.go: Recommend config changes from the audit log.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/claude/shared/pkg/audit"
+	gatescfg "github.com/claude/shared/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestMinSessions  int
+	suggestMinOverrides int
+	suggestExplain      bool
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest gates config changes from audit history",
+	Long: `[CONFIG_SUGGEST]
+desc: Data-driven tuning from ~/.claude/audit/decisions.jsonl
+promote: warn recurring across sessions → block list
+demote:  block or ask frequently overridden → warn list
+output: RFC 6902 JSON Patch for ~/.claude/gates/config.json as written; lists it does not set are added whole
+
+usage:
+  kavach config suggest                    # JSON Patch on stdout
+  kavach config suggest --explain          # Per-rule reasoning
+  kavach config suggest --min-sessions 5   # Stricter promotion`,
+	Run: runSuggest,
+}
+
+func init() {
+	suggestCmd.Flags().IntVar(&suggestMinSessions, "min-sessions", 3, "Distinct sessions a warn must recur in to promote")
+	suggestCmd.Flags().IntVar(&suggestMinOverrides, "min-overrides", 3, "Overrides of a block or ask needed to demote")
+	suggestCmd.Flags().BoolVar(&suggestExplain, "explain", false, "Print suggestions with counts instead of a bare patch")
+}
+
+func runSuggest(cmd *cobra.Command, args []string) {
+	records, err := audit.ReadAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[CONFIG_SUGGEST] read audit log: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The patch applies to config.json as written, not the merged config
+	path := gatescfg.GatesConfigPath()
+	file, cfg, err := gatescfg.ReadUserGatesConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[CONFIG_SUGGEST] read %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	target := audit.PatchTarget{File: file, Config: cfg}
+
+	suggestions := audit.Suggest(records, target, audit.SuggestOptions{
+		MinWarnSessions: suggestMinSessions,
+		MinOverrides:    suggestMinOverrides,
+	})
+
+	var out interface{} = audit.Patch(suggestions, target)
+	if suggestExplain {
+		out = suggestions
+	}
+	data, _ := json.MarshalIndent(out, "", "  ")
+	fmt.Println(string(data))
+}
@@ -0,0 +1,91 @@
+// Package config provides gates configuration subcommands.
+// template.go: Annotated reference of every gates config field with its
+// default, as JSONC or a Markdown table.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	gatescfg "github.com/claude/shared/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var templateFormat string
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Print the full gates config with defaults and field docs",
+	Long: `[CONFIG_TEMPLATE]
+desc: Every GatesConfig field with its built-in default and a description,
+      generated from the config struct; a reference for writing your own
+formats: jsonc (// comments above each field), md (table for docs)
+note: Strip the comments before using the jsonc output as config.json
+
+usage:
+  kavach config template                 # JSONC
+  kavach config template --format md > GATES_CONFIG.md`,
+	Run: runTemplate,
+}
+
+func init() {
+	templateCmd.Flags().StringVar(&templateFormat, "format", "jsonc", "Output format (jsonc, md)")
+}
+
+func runTemplate(cmd *cobra.Command, args []string) {
+	switch templateFormat {
+	case "jsonc":
+		fmt.Print(gatescfg.GatesConfigTemplate())
+	case "md":
+		printTemplateMarkdown()
+	default:
+		fmt.Fprintf(os.Stderr, "[CONFIG] Unknown format: %s (jsonc, md)\n", templateFormat)
+		os.Exit(1)
+	}
+}
+
+// printTemplateMarkdown renders the config reference as one table per section.
+func printTemplateMarkdown() {
+	fmt.Println("# kavach gates config reference")
+	fmt.Println()
+	fmt.Printf("File: `%s`. Fields left out fall back to the defaults below.\n", gatescfg.GatesConfigPath())
+
+	header := func(title, doc string) {
+		fmt.Println()
+		fmt.Printf("## %s\n\n", title)
+		if doc != "" {
+			fmt.Printf("%s\n\n", doc)
+		}
+		fmt.Println("| Field | Type | Default | Description |")
+		fmt.Println("|---|---|---|---|")
+	}
+
+	row := func(f gatescfg.TemplateField) {
+		fmt.Printf("| `%s` | %s | `%s` | %s |\n", f.Path, f.Type, mdCell(compactJSON(f.Default)), mdCell(f.Doc))
+	}
+	fields := gatescfg.GatesConfigFields()
+
+	// Top-level scalars first, then one table per section
+	header("top level", "")
+	for _, f := range fields {
+		if !f.Section && !strings.Contains(f.Path, ".") {
+			row(f)
+		}
+	}
+	for _, f := range fields {
+		switch {
+		case f.Section && !strings.Contains(f.Path, "."):
+			header(f.Path, f.Doc)
+		case f.Section:
+			fmt.Printf("| `%s` | object | | %s |\n", f.Path, mdCell(f.Doc))
+		case strings.Contains(f.Path, "."):
+			row(f)
+		}
+	}
+}
+
+// mdCell escapes a value for a Markdown table cell.
+func mdCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,77 @@
+// Package gates provides hook gates for Claude Code.
+// advisory.go: Warn-once advisories. A warning whose category is listed in
+// advisories.once is emitted the first time it fires in a session and
+// silent afterwards; every other warning is emitted each time.
+package gates
+
+import (
+	"strings"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+)
+
+// advisoryCategory is the part of a warn value before its details
+// ("protected_branch:push:main" → "protected_branch").
+func advisoryCategory(warn string) string {
+	if idx := strings.Index(warn, ":"); idx >= 0 {
+		return warn[:idx]
+	}
+	return warn
+}
+
+// exitAdvisory emits kvs as a gate warning, or exits silently when its
+// category was already emitted this session and may be suppressed. A
+// suppressed warning still exits ExitCodeWarn under --strict-exit.
+func exitAdvisory(gate string, kvs map[string]string) {
+	if suppressAdvisory(kvs["warn"]) {
+		hook.ExitSilent()
+	}
+	hook.ExitModifyTOON(gate, kvs)
+}
+
+// mergeAdvisory adds warning to the kvs of a gate's normal output, for
+// gates whose warning must not end their own work early. Suppressed like
+// exitAdvisory.
+func mergeAdvisory(kvs, warning map[string]string) {
+	if warning == nil || suppressAdvisory(warning["warn"]) {
+		return
+	}
+	for k, v := range warning {
+		kvs[k] = v
+	}
+}
+
+// exitAdvisories emits several warnings of gate as one output, joining the
+// values of a repeated key with "; ". Each is suppressed like exitAdvisory;
+// returns when none is left.
+func exitAdvisories(gate string, warnings []map[string]string) {
+	merged := map[string]string{}
+	for _, warning := range warnings {
+		if suppressAdvisory(warning["warn"]) {
+			continue
+		}
+		for k, v := range warning {
+			if merged[k] != "" {
+				v = merged[k] + "; " + v
+			}
+			merged[k] = v
+		}
+	}
+	if len(merged) > 0 {
+		hook.ExitModifyTOON(gate, merged)
+	}
+}
+
+// suppressAdvisory reports whether warn was already emitted this session
+// and is warn-once, noting ExitCodeWarn when it is.
+func suppressAdvisory(warn string) bool {
+	category := advisoryCategory(warn)
+	if config.LoadGatesConfig().Advisories.WarnOnce(category) &&
+		!enforce.GetOrCreateSession().MarkAdvisory(category) {
+		hook.Note(hook.ExitCodeWarn)
+		return true
+	}
+	return false
+}
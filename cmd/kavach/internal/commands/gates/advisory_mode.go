@@ -0,0 +1,29 @@
+// Package gates provides hook gates for Claude Code.
+// advisory_mode.go: Global advisory mode (KAVACH_ADVISORY=1 or
+// onboarding.advisory). Every gate runs, but blocks and asks are shown as
+// allow with their reasoning, for teams evaluating kavach before enforcing.
+package gates
+
+import (
+	"os"
+	"slices"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/logger"
+)
+
+const advisoryBanner = "kavach advisory mode: gates report what they would block or ask, but nothing is enforced (KAVACH_ADVISORY / onboarding.advisory)"
+
+// setupAdvisoryMode turns advisory mode on from the environment or config,
+// announcing it once per session: the banner counts as shown only when a
+// response carried it.
+func setupAdvisoryMode() {
+	on := os.Getenv("KAVACH_ADVISORY") == "1" || config.LoadGatesConfig().Onboarding.Advisory
+	hook.SetAdvisoryMode(on)
+	if session := enforce.GetOrCreateSession(); on && !slices.Contains(session.Advisories, "advisory_mode") {
+		logger.Warn("gates", advisoryBanner)
+		hook.AnnounceAdvisoryMode(advisoryBanner, func() { session.MarkAdvisory("advisory_mode") })
+	}
+}
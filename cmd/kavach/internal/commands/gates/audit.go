@@ -0,0 +1,85 @@
+// Package gates provides hook gates for Claude Code.
+// audit.go: Gate decision recording for config tuning (kavach config suggest).
+package gates
+
+import (
+	"strings"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/util"
+)
+
+// recordDecision appends a gate decision to the audit log (best-effort).
+// In advisory mode a block or ask is recorded as advisory, so the call
+// running anyway is not taken for an override.
+func recordDecision(input *hook.Input, gate, decision, rule string) {
+	rec := decisionRecord(input, gate, decision, rule)
+	if hook.AdvisoryMode() && (decision == audit.DecisionBlock || decision == audit.DecisionAsk) {
+		rec.Decision, rec.Would = audit.DecisionAdvisory, decision
+	}
+	audit.Append(rec)
+}
+
+// decisionRecord is the audit record of a gate decision on input.
+func decisionRecord(input *hook.Input, gate, decision, rule string) audit.Record {
+	return audit.Record{
+		SessionID: input.SessionID,
+		Gate:      gate,
+		Decision:  decision,
+		Rule:      rule,
+		ToolName:  input.ToolName,
+		ToolUseID: input.ToolUseID,
+		Subject:   auditSubject(input),
+		Diff:      auditDiff(input),
+	}
+}
+
+// auditDiff summarizes an Edit/MultiEdit as line counts; the old and new
+// strings themselves are not logged.
+func auditDiff(input *hook.Input) string {
+	if s := util.SummarizeEdit(input.ToolName, input.ToolInput); s != nil {
+		return s.String()
+	}
+	return ""
+}
+
+// recordOverride logs an override when a tool runs after a prior block/ask
+// for the same action. Called from PostToolUse.
+func recordOverride(input *hook.Input) {
+	subject := auditSubject(input)
+	if subject == "" && input.ToolUseID == "" {
+		return
+	}
+	records, err := audit.ReadRecent(500)
+	if err != nil || len(records) == 0 {
+		return
+	}
+	if prior := audit.FindPriorDenial(records, input.SessionID, input.ToolUseID, input.ToolName, subject); prior != nil {
+		recordDecision(input, prior.Gate, audit.DecisionOverride, prior.Rule)
+	}
+}
+
+// auditSubject returns the command or path a rule would match against.
+// Paths are hashed here when redaction.hash_paths is set; secrets are
+// scrubbed by audit.Append.
+func auditSubject(input *hook.Input) string {
+	if v := input.GetString("command"); v != "" {
+		return v
+	}
+	for _, key := range []string{"file_path", "path"} {
+		if v := input.GetString(key); v != "" {
+			return config.RedactPath(v)
+		}
+	}
+	return ""
+}
+
+// warnName strips the config list prefix from a rule ("bash.warn_commands:sudo" → "sudo").
+func warnName(rule string) string {
+	if idx := strings.Index(rule, ":"); idx >= 0 {
+		return rule[idx+1:]
+	}
+	return rule
+}
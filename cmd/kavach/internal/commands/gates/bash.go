@@ -6,9 +6,13 @@ package gates
 import (
 	"strings"
 
+	"github.com/claude/shared/pkg/audit"
 	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/gitctx"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/claude/shared/pkg/patterns"
+	"github.com/claude/shared/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -41,15 +45,17 @@ func runBashGate(cmd *cobra.Command, args []string) {
 		hook.ExitBlockTOON("BASH", "empty_command")
 	}
 
-	// Check blocked commands from gates/config.json (priority)
-	if config.IsBlockedCommand(command) {
+	// Blocked commands from gates/config.json (priority), then patterns.toon
+	if rule, blocked := blockedCommandRule(command); blocked {
+		if rule != "" {
+			checkLearning(input, "BASH", audit.DecisionBlock, rule)
+			recordDecision(input, "BASH", audit.DecisionBlock, rule)
+		}
 		hook.ExitBlockTOON("BASH", "blocked_command")
 	}
 
-	// Legacy: Check patterns from patterns.toon
-	if patterns.IsBlocked(command) {
-		hook.ExitBlockTOON("BASH", "blocked_command")
-	}
+	// Writes into kavach's own config, hooks or binary ask
+	checkSelfWrite(input, "BASH")
 
 	// Check for legacy CLI commands that should use Rust alternatives
 	if legacy, rust, reason := detectLegacyCommand(command); legacy != "" {
@@ -57,27 +63,78 @@ func runBashGate(cmd *cobra.Command, args []string) {
 		hook.ExitBlockTOON("RUST_CLI", msg)
 	}
 
-	// Warn on sudo commands
-	if strings.HasPrefix(strings.TrimSpace(command), "sudo") {
-		hook.ExitModifyTOON("BASH", map[string]string{
+	// Same command failed retry_limit times in a row - stop the loop
+	checkRetryLoop(input, "BASH", enforce.GetOrCreateSession())
+
+	// Commit compliance, protected branches, then commit message format
+	checkGitPolicy(input, command)
+
+	// Warn on sudo commands, except routine ones in bash.safe_sudo
+	if strings.HasPrefix(strings.TrimSpace(command), "sudo") && config.MatchSafeSudo(command) == "" {
+		recordDecision(input, "BASH", audit.DecisionWarn, "bash.warn_commands:sudo")
+		exitAdvisory("BASH", map[string]string{
 			"warn": "sudo_detected",
 		})
 	}
 
 	// Warn on other risky patterns from config
-	cfg := config.LoadGatesConfig()
-	cmdLower := strings.ToLower(command)
-	for _, warn := range cfg.Bash.WarnCommands {
-		if strings.Contains(cmdLower, strings.ToLower(warn)) {
-			hook.ExitModifyTOON("BASH", map[string]string{
-				"warn": warn + "_detected",
-			})
-		}
+	if rule := config.MatchWarnCommand(command); rule != "" {
+		recordDecision(input, "BASH", audit.DecisionWarn, rule)
+		exitAdvisory("BASH", map[string]string{
+			"warn": warnName(rule) + "_detected",
+		})
 	}
 
 	hook.ExitSilent()
 }
 
+// blockedCommandRule checks command against bash.blocked_commands, then
+// the patterns.toon blocklist. A trusted "curl https://... | sh" installer
+// is checked segment by segment, so its pipe into sh does not match but
+// nothing else in the command is exempt. Returns the config rule ("" for a
+// patterns.toon match) and whether the command is blocked.
+func blockedCommandRule(command string) (string, bool) {
+	parts := []string{command}
+	if config.MatchTrustedInstall(command) != "" {
+		parts = patterns.SplitCommand(command)
+	}
+	for _, part := range parts {
+		if rule := config.MatchBlockedCommand(part); rule != "" {
+			return rule, true
+		}
+	}
+	for _, part := range parts {
+		if patterns.IsBlocked(part) {
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// checkProtectedBranch asks on git commit/push aimed at a protected
+// branch, or returns the warning. The branch comes from the command's
+// refspec or the repo's current branch.
+func checkProtectedBranch(input *hook.Input, command string) map[string]string {
+	cfg := config.LoadGatesConfig()
+	v := gitctx.DetectProtected(command, input.Cwd, cfg.Bash.ProtectedBranches)
+	if v == nil {
+		return nil
+	}
+
+	rule := "bash.protected_branches:" + v.Pattern
+	reason := "protected_branch:" + v.Op + ":" + v.Branch
+	if cfg.Bash.ProtectedBranchAction == "ask" {
+		checkLearning(input, "BASH", audit.DecisionAsk, rule)
+		recordDecision(input, "BASH", audit.DecisionAsk, rule)
+		hook.Output(types.NewPreToolUseAsk("BASH: git " + v.Op + " targets protected branch " + v.Branch))
+		hook.Exit()
+	}
+	recordDecision(input, "BASH", audit.DecisionWarn, rule)
+	return map[string]string{
+		"warn": reason,
+	}
+}
+
 func detectLegacyCommand(command string) (string, string, string) {
 	cfg := config.LoadPatterns("rust-cli.toon")
 	blocked := cfg["LEGACY:BLOCKED"]
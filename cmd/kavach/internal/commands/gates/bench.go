@@ -0,0 +1,246 @@
+// Package gates provides hook gates for Claude Code.
+// bench.go: Gate latency benchmark over hook input fixtures.
+// Gates run as child processes, exactly as hooks invoke them; the chain also
+// runs in-process so its allocations can be measured.
+package gates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/types"
+	"github.com/claude/shared/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var benchIterations int
+var benchFixtures string
+var benchGates string
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure gate latency over sample hook inputs",
+	Long: `[GATES_BENCH]
+desc: Run each gate over fixture inputs N times; report p50/p95/max latency
+fixtures: --fixtures file.json|dir (one hook input or an array per file);
+          built-in samples when omitted
+gates: child processes with an isolated HOME (gates config copied in), so
+       session, audit and DAG state are untouched; rss is the peak per run
+chain: also run in-process to report allocations per run
+usage:
+  kavach gates bench
+  kavach gates bench -n 50 --gates pre-tool,chain --fixtures ./fixtures`,
+	Run: runBench,
+}
+
+func init() {
+	benchCmd.Flags().IntVarP(&benchIterations, "iterations", "n", 20, "Runs per gate and fixture")
+	benchCmd.Flags().StringVar(&benchFixtures, "fixtures", "", "Fixture file or directory of hook input JSON")
+	benchCmd.Flags().StringVar(&benchGates, "gates", "pre-tool,pre-write,post-tool,chain", "Comma-separated gate commands")
+}
+
+// benchStats summarizes one gate over all fixtures and iterations.
+type benchStats struct {
+	name      string
+	latencies []time.Duration
+	maxRSSKB  int64
+	allocs    uint64 // Per run, in-process only
+	bytes     uint64
+	errors    int
+}
+
+func runBench(cmd *cobra.Command, args []string) {
+	fixtures, err := loadBenchFixtures(benchFixtures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[BENCH] fixtures: %v\n", err)
+		os.Exit(1)
+	}
+	if benchIterations < 1 {
+		benchIterations = 1
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[BENCH] executable: %v\n", err)
+		os.Exit(1)
+	}
+	home, err := benchHome()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[BENCH] isolated home: %v\n", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(home)
+
+	var results []*benchStats
+	for _, gate := range strings.Split(benchGates, ",") {
+		if gate = strings.TrimSpace(gate); gate != "" {
+			results = append(results, benchGate(exe, home, gate, fixtures))
+		}
+	}
+	results = append(results, benchChainInProcess(fixtures))
+
+	fmt.Printf("[GATES_BENCH]\nfixtures: %d\niterations: %d\n\n", len(fixtures), benchIterations)
+	for _, s := range results {
+		fmt.Println(s)
+	}
+}
+
+// benchGate runs `kavach gates <gate> --hook` once per fixture per iteration.
+func benchGate(exe, home, gate string, fixtures []*types.HookInput) *benchStats {
+	s := &benchStats{name: gate}
+	for _, f := range fixtures {
+		data, _ := json.Marshal(f)
+		for i := 0; i < benchIterations; i++ {
+			c := exec.Command(exe, "gates", gate, "--hook")
+			c.Stdin = bytes.NewReader(data)
+			c.Env = append(os.Environ(), "HOME="+home)
+			start := time.Now()
+			err := c.Run()
+			s.latencies = append(s.latencies, time.Since(start))
+			if _, exit := err.(*exec.ExitError); err != nil && !exit {
+				s.errors++
+				continue
+			}
+			if rss := peakRSSKB(c.ProcessState); rss > s.maxRSSKB {
+				s.maxRSSKB = rss
+			}
+		}
+	}
+	return s
+}
+
+// benchChainInProcess runs the chain without persistence, measuring allocations.
+func benchChainInProcess(fixtures []*types.HookInput) *benchStats {
+	s := &benchStats{name: "chain (in-process)"}
+	opts := append(ChainOptions(config.LoadGatesConfig(), 0, nil), chain.WithStateDir(""))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for _, f := range fixtures {
+		prompt := getPromptFromInput(f)
+		for i := 0; i < benchIterations; i++ {
+			start := time.Now()
+			chain.NewRunner("bench", opts...).RunFull(prompt, f.ToolName, f.ToolInput, false)
+			s.latencies = append(s.latencies, time.Since(start))
+		}
+	}
+	runtime.ReadMemStats(&after)
+	if n := uint64(len(s.latencies)); n > 0 {
+		s.allocs = (after.Mallocs - before.Mallocs) / n
+		s.bytes = (after.TotalAlloc - before.TotalAlloc) / n
+	}
+	return s
+}
+
+func (s *benchStats) String() string {
+	sorted := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	out := fmt.Sprintf("[%s]\nruns: %d\np50: %s\np95: %s\nmax: %s\n",
+		s.name, len(sorted), percentile(sorted, 50), percentile(sorted, 95), percentile(sorted, 100))
+	if s.maxRSSKB > 0 {
+		out += fmt.Sprintf("max_rss_kb: %d\n", s.maxRSSKB)
+	}
+	if s.allocs > 0 {
+		out += fmt.Sprintf("allocs_per_run: %d\nbytes_per_run: %d\n", s.allocs, s.bytes)
+	}
+	if s.errors > 0 {
+		out += fmt.Sprintf("errors: %d\n", s.errors)
+	}
+	return out
+}
+
+// percentile uses nearest rank over sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1].Round(time.Microsecond)
+}
+
+// benchHome is a throwaway HOME holding a copy of the gates config.
+func benchHome() (string, error) {
+	home, err := os.MkdirTemp("", "kavach-bench-")
+	if err != nil {
+		return "", err
+	}
+	src := filepath.Join(util.HomeDir(), ".claude", "gates", "config.json")
+	if data, err := os.ReadFile(src); err == nil {
+		dst := filepath.Join(home, ".claude", "gates", "config.json")
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return home, err
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			return home, err
+		}
+	}
+	return home, nil
+}
+
+// loadBenchFixtures reads fixtures with the same loaders as --input and
+// --batch: each file holds one hook input or an array of them.
+func loadBenchFixtures(path string) ([]*types.HookInput, error) {
+	if path == "" {
+		return defaultBenchFixtures(), nil
+	}
+	files := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	var fixtures []*types.HookInput
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+			inputs, err := hook.ReadHookInputsFrom(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			fixtures = append(fixtures, inputs...)
+			continue
+		}
+		input, err := hook.ReadHookInputFrom(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		fixtures = append(fixtures, input)
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixtures in %s", path)
+	}
+	return fixtures, nil
+}
+
+// defaultBenchFixtures covers the common hot-path tool calls.
+func defaultBenchFixtures() []*types.HookInput {
+	fixture := func(tool string, in map[string]interface{}) *types.HookInput {
+		return &types.HookInput{HookEventName: "PreToolUse", SessionID: "bench", ToolName: tool, ToolInput: in}
+	}
+	return []*types.HookInput{
+		fixture("Bash", map[string]interface{}{"command": "go test ./..."}),
+		fixture("Bash", map[string]interface{}{"command": "rm -rf /"}),
+		fixture("Read", map[string]interface{}{"file_path": "/tmp/kavach-bench/main.go"}),
+		fixture("Write", map[string]interface{}{"file_path": "/tmp/kavach-bench/handler.go", "content": "package main\n\nfunc handler() {}\n"}),
+		fixture("Task", map[string]interface{}{"subagent_type": "backend-engineer", "prompt": "implement the webhook handler"}),
+	}
+}
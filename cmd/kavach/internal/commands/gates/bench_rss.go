@@ -0,0 +1,24 @@
+//go:build unix
+
+// Package gates provides hook gates for Claude Code.
+// bench_rss.go: Peak RSS of a finished bench child process (Unix).
+package gates
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// peakRSSKB returns the peak resident set size of a finished process in
+// KB, or 0 when unknown. Maxrss is KB on Linux and the BSDs, bytes on Darwin.
+func peakRSSKB(state *os.ProcessState) int64 {
+	ru, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return int64(ru.Maxrss) / 1024
+	}
+	return int64(ru.Maxrss)
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+// Package gates provides hook gates for Claude Code.
+// bench_rss_other.go: Peak RSS stub where rusage is unavailable (Windows).
+package gates
+
+import "os"
+
+// peakRSSKB is unknown without rusage; bench omits max_rss_kb.
+func peakRSSKB(state *os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,44 @@
+package gates
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchStatsString(t *testing.T) {
+	s := &benchStats{name: "pre-tool", maxRSSKB: 2048, errors: 1}
+	for i := 1; i <= 20; i++ {
+		s.latencies = append(s.latencies, time.Duration(i)*time.Millisecond)
+	}
+	out := s.String()
+	for _, want := range []string{"[pre-tool]\n", "runs: 20\n", "p50: 10ms\n", "p95: 19ms\n", "max: 20ms\n", "max_rss_kb: 2048\n", "errors: 1\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "allocs_per_run") {
+		t.Errorf("child-process gate reported allocations:\n%s", out)
+	}
+
+	inProcess := (&benchStats{name: "chain (in-process)", latencies: []time.Duration{time.Millisecond}, allocs: 10, bytes: 512}).String()
+	if strings.Contains(inProcess, "max_rss_kb") || !strings.Contains(inProcess, "allocs_per_run: 10\nbytes_per_run: 512\n") {
+		t.Errorf("in-process stats:\n%s", inProcess)
+	}
+}
+
+func TestPeakRSSKB(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no rusage")
+	}
+	c := exec.Command("true")
+	if err := c.Run(); err != nil {
+		t.Skip("no true binary:", err)
+	}
+	// A process of a few MB: KB, not bytes (Darwin) or pages
+	if rss := peakRSSKB(c.ProcessState); rss < 100 || rss > 1<<20 {
+		t.Errorf("peakRSSKB = %d, want a KB figure", rss)
+	}
+}
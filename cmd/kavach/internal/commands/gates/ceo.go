@@ -54,6 +54,7 @@ func runCEOGate(cmd *cobra.Command, args []string) {
 	if !config.IsValidAgent(subagentType) {
 		hook.ExitBlockTOON("CEO", "unknown_agent:"+subagentType)
 	}
+	admitSubagent("CEO", enforce.GetOrCreateSession(), input.AgentID)
 
 	// DACE: Detect skill from task prompt using dynamic config
 	prompt := input.GetString("prompt")
@@ -97,17 +98,19 @@ func runCEOGate(cmd *cobra.Command, args []string) {
 
 		if len(breakdown) > 1 {
 			nodes := dag.Decompose(breakdown, agents)
-			state, err := dag.Schedule(session.SessionID, prompt, nodes)
+			dag.InferSkills(nodes, newAgenticLoader())
+			state, err := dag.Schedule(session.ResolveID(), prompt, nodes, scheduleOptions()...)
 			if err == nil {
+				state.ProjectID = session.Project
 				if saveErr := dag.Save(state); saveErr != nil {
 					fmt.Fprintf(os.Stderr, "[CEO_DAG] Save error: %v\n", saveErr)
 					orchDirective["WARNING"] = "DAG state NOT persisted: " + saveErr.Error()
 				}
-				directive := dag.BuildDirective(state)
+				directive := newDAGBuilder().Directive(state)
 				hook.ExitModifyTOONWithModule("CEO_DAG_DISPATCH", orchDirective, directive)
 			} else {
 				fmt.Fprintf(os.Stderr, "[CEO_DAG] Schedule error: %v\n", err)
-				orchDirective["WARNING"] = "DAG scheduling failed: " + err.Error()
+				orchDirective["WARNING"] = dagScheduleWarning(err)
 			}
 		}
 
@@ -8,14 +8,18 @@ import (
 	"os"
 
 	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/transcript"
 	"github.com/claude/shared/pkg/types"
 	"github.com/spf13/cobra"
 )
 
 var chainHookMode bool
 var chainDebugMode bool
+var chainBatchMode bool
+var chainProfileTranscript string
 
 var chainCmd = &cobra.Command{
 	Use:   "chain",
@@ -28,17 +32,34 @@ The chain validates:
 3. AEGIS: Security verification and threat detection
 4. RESEARCH: TABULA_RASA compliance (research before code)
 
-Use this gate for Write, Edit, Task, and other high-risk tools.`,
+Use this gate for Write, Edit, Task, and other high-risk tools.
+
+On PostToolUse the chain re-evaluates with the tool response: clean results
+downgrade pre-run warnings, and secrets or destructive output block.
+
+Batch mode pre-flights a plan: stdin (or --input) is a JSON array of hook
+inputs and stdout is a JSON array of decisions, one per input:
+  kavach gates chain --batch < plan.json
+
+Profile mode audits a whole session: each code edit in the transcript
+that had no research before it, with line and timestamp:
+  kavach gates chain --profile-transcript ~/.claude/projects/<p>/<id>.jsonl`,
 	Run: runChainGate,
 }
 
 func init() {
 	chainCmd.Flags().BoolVar(&chainHookMode, "hook", false, "Hook mode")
 	chainCmd.Flags().BoolVar(&chainDebugMode, "debug", false, "Debug mode")
+	chainCmd.Flags().BoolVar(&chainBatchMode, "batch", false, "Evaluate a JSON array of hook inputs, print an array of decisions")
+	chainCmd.Flags().StringVar(&chainProfileTranscript, "profile-transcript", "", "Report code edits in a transcript that lacked preceding research")
 }
 
 func runChainGate(cmd *cobra.Command, args []string) {
-	if !chainHookMode {
+	if chainProfileTranscript != "" {
+		runTranscriptProfile(chainProfileTranscript)
+		return
+	}
+	if !chainHookMode && !chainBatchMode {
 		cmd.Help()
 		return
 	}
@@ -48,15 +69,29 @@ func runChainGate(cmd *cobra.Command, args []string) {
 		os.Setenv("KAVACH_DEBUG", "1")
 	}
 
+	if chainBatchMode {
+		runChainBatch()
+		return
+	}
+
 	input := hook.MustReadHookInput()
 	session := enforce.GetOrCreateSession()
 
 	// Get prompt from various sources
 	prompt := getPromptFromInput(input)
 
+	if input.IsEvent("PostToolUse") {
+		runChainPost(input, session, prompt)
+		return
+	}
+
 	// Create and run the chain
-	runner := chain.NewRunner(session.ID)
-	state := runner.RunFull(prompt, input.ToolName, input.ToolInput, session.ResearchDone)
+	runner := newChainRunner(input, session)
+	ctx, cancel := chainContext(config.LoadGatesConfig())
+	defer cancel()
+	state := runner.RunFullContext(ctx, prompt, input.ToolName, input.ToolInput, chainResearchDone(input, session))
+	recordChainState(session, state)
+	recordChainDecision(input, state)
 
 	// Handle result based on chain status
 	if state.IsBlocked() {
@@ -72,7 +107,42 @@ func runChainGate(cmd *cobra.Command, args []string) {
 				AdditionalContext:        context,
 			},
 		})
-		os.Exit(0)
+		hook.Exit()
+	}
+
+	// Same call failed retry_limit times in a row - stop the loop
+	if reason := retryLoopReason(input, session); reason != "" {
+		hook.Output(&types.HookResponse{
+			HookSpecificOutput: &types.HookSpecificOutput{
+				HookEventName:            "PreToolUse",
+				PermissionDecision:       "ask",
+				PermissionDecisionReason: reason,
+				AdditionalContext:        runner.ToTOON(),
+			},
+		})
+		hook.Exit()
+	}
+
+	// Risk budget escalated a finding - require user confirmation
+	if state.IsAsk() {
+		hook.Output(&types.HookResponse{
+			HookSpecificOutput: &types.HookSpecificOutput{
+				HookEventName:            "PreToolUse",
+				PermissionDecision:       "ask",
+				PermissionDecisionReason: state.GetAskReason(),
+				AdditionalContext:        runner.ToTOON(),
+			},
+		})
+		hook.Exit()
+	}
+
+	// Aegis rewrote the command to a safer form (aegis.actions "modify")
+	if updated := state.UpdatedInput(); updated != nil {
+		hook.Note(hook.ExitCodeWarn)
+		resp := types.NewPreToolUseModifyInput("Command rewritten to a safer form", updated)
+		resp.HookSpecificOutput.AdditionalContext = runner.ToTOON()
+		hook.Output(resp)
+		hook.Exit()
 	}
 
 	// Chain passed - add context if there are warnings
@@ -85,6 +155,7 @@ func runChainGate(cmd *cobra.Command, args []string) {
 	}
 
 	if hasWarnings {
+		hook.Note(hook.ExitCodeWarn)
 		context := runner.ToTOON()
 		hook.Output(&types.HookResponse{
 			HookSpecificOutput: &types.HookSpecificOutput{
@@ -94,13 +165,40 @@ func runChainGate(cmd *cobra.Command, args []string) {
 				AdditionalContext:        context,
 			},
 		})
-		os.Exit(0)
+		hook.Exit()
 	}
 
 	// Silent pass
 	hook.ExitSilent()
 }
 
+// runChainPost reports a completed tool call. Session risk was already
+// recorded by the PreToolUse run, so nothing is added here. Failures are
+// counted per call as the failure gate does, so only repeats warn.
+func runChainPost(input *hook.Input, session *enforce.SessionState, prompt string) {
+	failures := 0
+	if chain.ReadPostOutcome(input.ToolName, input.ToolInput, input.ToolResponse).Failed {
+		failures = session.RecordFailure(retrySignature(input.ToolName, input.ToolInput))
+	} else {
+		clearRetries(input, session)
+	}
+	runner := newChainRunner(input, session, chain.WithFailureCount(failures))
+	state := runner.RunPost(prompt, input.ToolName, input.ToolInput, input.ToolResponse)
+
+	if state.IsBlocked() {
+		hook.Output(types.NewPostToolUseBlock(state.GetBlockReason(), runner.ToTOON()))
+		hook.Exit()
+	}
+	for _, r := range state.Results {
+		if r.Status == "warn" {
+			hook.Note(hook.ExitCodeWarn)
+			hook.Output(types.NewPostToolUseContext(runner.ToTOON()))
+			hook.Exit()
+		}
+	}
+	hook.ExitSilent()
+}
+
 // getPromptFromInput extracts the prompt from various input sources.
 func getPromptFromInput(input *hook.Input) string {
 	// Direct prompt (UserPromptSubmit)
@@ -128,5 +226,12 @@ func getPromptFromInput(input *hook.Input) string {
 		return desc
 	}
 
+	// Last user message, so tools without prompt text still classify
+	if input.TranscriptPath != "" {
+		if prompt, err := transcript.LastUserPrompt(input.TranscriptPath); err == nil {
+			return prompt
+		}
+	}
+
 	return ""
 }
@@ -0,0 +1,101 @@
+// Package gates provides hook gates for Claude Code.
+// chain_batch.go: Batch chain evaluation for pre-flighting a plan.
+// One process evaluates many hook inputs; config, classifier and session
+// options are built once and each input gets a fresh Runner.
+package gates
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+)
+
+// batchDecision is the verdict for one input of a --batch run.
+type batchDecision struct {
+	Index       int                `json:"index"`
+	ToolName    string             `json:"tool_name"`
+	ToolUseID   string             `json:"tool_use_id,omitempty"`
+	Decision    string             `json:"decision"` // "allow", "ask", "deny"
+	FinalStatus string             `json:"final_status"`
+	Reason      string             `json:"reason,omitempty"`
+	Gates       []batchGateOutcome `json:"gates"`
+}
+
+// batchGateOutcome summarizes one gate result.
+type batchGateOutcome struct {
+	Gate   string `json:"gate"`
+	Status string `json:"status"`
+	Source string `json:"source,omitempty"`
+}
+
+// runChainBatch reads a JSON array of hook inputs and prints an array of
+// decisions. Planned calls have not run, so session state and chain
+// history are left untouched.
+func runChainBatch() {
+	inputs, err := hook.ReadHookInputs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[CHAIN] Batch input error: %v\n", err)
+		os.Exit(1)
+	}
+
+	decisions := evaluateBatch(inputs, config.LoadGatesConfig(), enforce.GetOrCreateSession())
+	if err := hook.OutputJSON(decisions); err != nil {
+		fmt.Fprintf(os.Stderr, "[CHAIN] Batch output error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// evaluateBatch runs the chain on each input. A null array element cannot
+// be evaluated and is reported as denied rather than allowed.
+func evaluateBatch(inputs []*hook.Input, cfg *config.GatesConfig, session *enforce.SessionState) []batchDecision {
+	sessionID := ""
+	for _, input := range inputs {
+		if input != nil {
+			sessionID = input.SessionID
+			break
+		}
+	}
+	shared := append(sessionChainOptions(cfg, sessionID, session), chain.WithStateDir(""))
+
+	decisions := make([]batchDecision, 0, len(inputs))
+	for i, input := range inputs {
+		if input == nil {
+			decisions = append(decisions, batchDecision{Index: i, Decision: "deny", Reason: "invalid input: null"})
+			continue
+		}
+		opts := append(shared[:len(shared):len(shared)],
+			chain.WithTranscript(input.TranscriptPath, cfg.Research.ResearchTools, cfg.Research.ResearchWindow),
+		)
+		ctx, cancel := chainContext(cfg)
+		state := chain.NewRunner(session.ResolveID(), opts...).
+			RunFullContext(ctx, getPromptFromInput(input), input.ToolName, input.ToolInput, chainResearchDone(input, session))
+		cancel()
+		decisions = append(decisions, newBatchDecision(i, input, state))
+	}
+	return decisions
+}
+
+// newBatchDecision maps a chain state to the hook permission decision.
+func newBatchDecision(index int, input *hook.Input, state *chain.ChainState) batchDecision {
+	d := batchDecision{
+		Index:       index,
+		ToolName:    input.ToolName,
+		ToolUseID:   input.ToolUseID,
+		Decision:    "allow",
+		FinalStatus: state.FinalStatus,
+	}
+	switch {
+	case state.IsBlocked():
+		d.Decision, d.Reason = "deny", state.GetBlockReason()
+	case state.IsAsk():
+		d.Decision, d.Reason = "ask", state.GetAskReason()
+	}
+	for _, r := range state.Results {
+		d.Gates = append(d.Gates, batchGateOutcome{Gate: r.Gate, Status: r.Status, Source: r.Source})
+	}
+	return d
+}
@@ -0,0 +1,33 @@
+package gates
+
+import (
+	"testing"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+)
+
+func TestEvaluateBatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := enforce.NewSessionState(t.TempDir())
+	inputs := []*hook.Input{
+		{SessionID: "s1", ToolName: "Bash", ToolInput: map[string]interface{}{"command": "ls -la"}},
+		nil,
+		{SessionID: "s1", ToolName: "Bash", ToolInput: map[string]interface{}{"command": "rm -rf /"}},
+	}
+
+	got := evaluateBatch(inputs, config.LoadGatesConfig(), session)
+	if len(got) != 3 {
+		t.Fatalf("decisions = %+v, want one per input", got)
+	}
+	if got[0].Decision != "allow" || len(got[0].Gates) == 0 {
+		t.Errorf("ls: %+v, want allow with gate outcomes", got[0])
+	}
+	if got[1].Index != 1 || got[1].Decision != "deny" || got[1].Reason == "" {
+		t.Errorf("null input: %+v, want a reported deny", got[1])
+	}
+	if got[2].Index != 2 || got[2].Decision != "deny" {
+		t.Errorf("rm -rf /: %+v, want deny", got[2])
+	}
+}
@@ -0,0 +1,41 @@
+// Package gates provides hook gates for Claude Code.
+// chain_profile.go: Session-level TABULA_RASA audit (--profile-transcript).
+// Reports code edits in a transcript that had no research before them.
+package gates
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/patterns"
+	"github.com/claude/shared/pkg/transcript"
+)
+
+// runTranscriptProfile prints research coverage for the transcript at path,
+// using the research tools and window from gates config.
+func runTranscriptProfile(path string) {
+	cfg := config.LoadGatesConfig()
+	profile, err := transcript.ProfileResearch(path, cfg.Research.ResearchTools, cfg.Research.ResearchWindow, patterns.IsCodeFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[RESEARCH_PROFILE] %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("[RESEARCH_PROFILE]\ntranscript: %s\ntool_uses: %d\nresearch: %d\ncode_edits: %d\nunresearched: %d\ncoverage: %d%%\n",
+		path, profile.ToolUses, profile.Research, profile.Edits, len(profile.Unresearched), profile.Coverage())
+	if cfg.Research.ResearchWindow > 0 {
+		fmt.Printf("window: %d tool uses\n", cfg.Research.ResearchWindow)
+	}
+	if len(profile.Unresearched) == 0 {
+		return
+	}
+	fmt.Println("\n[UNRESEARCHED_EDITS]")
+	for _, e := range profile.Unresearched {
+		ts := e.Timestamp
+		if ts == "" {
+			ts = "-"
+		}
+		fmt.Printf("  line %d %s %s %s\n", e.Line, ts, e.Tool, e.Path)
+	}
+}
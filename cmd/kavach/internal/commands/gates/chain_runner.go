@@ -0,0 +1,254 @@
+// Package gates provides hook gates for Claude Code.
+// chain_runner.go: Chain runner construction and session write-back.
+// Carries the session risk budget, ask policy, provenance and transcript
+// research detection.
+package gates
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/util"
+)
+
+// newChainRunner creates a chain runner configured from gates config.
+func newChainRunner(input *hook.Input, session *enforce.SessionState, extra ...chain.Option) *chain.Runner {
+	cfg := config.LoadGatesConfig()
+	opts := append(sessionChainOptions(cfg, input.SessionID, session),
+		chain.WithTranscript(input.TranscriptPath, cfg.Research.ResearchTools, cfg.Research.ResearchWindow),
+	)
+	return chain.NewRunner(session.ResolveID(), append(opts, extra...)...)
+}
+
+// chainContext bounds one chain run by enforcer.timeout_ms so a slow gate
+// cannot hold the hook open.
+func chainContext(cfg *config.GatesConfig) (context.Context, context.CancelFunc) {
+	if d := cfg.Enforcer.Timeout(); d > 0 {
+		return context.WithTimeout(context.Background(), d)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// sessionChainOptions adds the session's risk, recommendation and
+// override state to the config options. Shared by single and batch runs.
+func sessionChainOptions(cfg *config.GatesConfig, sessionID string, session *enforce.SessionState) []chain.Option {
+	return append(ChainOptions(cfg, session.RiskScore, session.Recommendations),
+		chain.WithProvenance(config.GatesConfigHash(), sessionOverrides(sessionID)),
+	)
+}
+
+// ChainOptions returns the session-independent chain options for cfg,
+// seeded with the session's risk score and recommendation counts.
+// Used by hooks and chain replay.
+func ChainOptions(cfg *config.GatesConfig, riskScore int, recommendations map[string]int) []chain.Option {
+	opts := []chain.Option{
+		chain.WithParallel(cfg.Enforcer.Parallel),
+		chain.WithResearchTodo(cfg.Research.TodoRiskLevels),
+		chain.WithMinResearchSources(cfg.Research.MinSourcesByIntent),
+		chain.WithResearchPolicy(researchPolicy(cfg)),
+		chain.WithInputRedaction(inputRedaction(cfg)),
+		chain.WithTrustedInstalls(cfg.Bash.TrustedInstallHosts),
+		chain.WithTOONBudget(cfg.Enforcer.ContextMaxChars),
+		chain.WithToolPolicy(cfg.ToolPolicy),
+		chain.WithSelfPaths(selfPaths()),
+		chain.WithAegisActions(cfg.Aegis.Actions),
+		chain.WithMaxFindings(cfg.Aegis.MaxFindings),
+		chain.WithSensitivePaths(sensitivePaths(cfg)),
+	}
+	if len(cfg.Intent.Keywords) > 0 {
+		opts = append(opts, chain.WithIntentClassifier(IntentClassifier(cfg)))
+	}
+	if cfg.Risk.Enabled {
+		opts = append(opts, chain.WithRiskBudget(chain.RiskBudget{
+			Score:          riskScore,
+			WarnWeight:     cfg.Risk.WarnWeight,
+			AskWeight:      cfg.Risk.AskWeight,
+			BlockWeight:    cfg.Risk.BlockWeight,
+			AskThreshold:   cfg.Risk.AskThreshold,
+			BlockThreshold: cfg.Risk.BlockThreshold,
+		}))
+	}
+	if cfg.Recommend.Enabled {
+		opts = append(opts, chain.WithRecommendationBudget(chain.RecommendationBudget{
+			Counts:    recommendations,
+			Threshold: cfg.Recommend.Threshold,
+			Action:    cfg.Recommend.Action,
+		}))
+	}
+	if cfg.Packages.Enabled {
+		opts = append(opts, chain.WithPackagePolicy(chain.PackagePolicy{
+			Trusted: cfg.Packages.Trusted,
+			Action:  cfg.Packages.Action,
+		}))
+	}
+	opts = append(opts, patternOptions.Get(cfg)...)
+	opts = append(opts, deployWindowOptions.Get(cfg)...)
+	if cfg.Ask.Enabled {
+		opts = append(opts, chain.WithAskPolicy(chain.AskPolicy{
+			Commands:   cfg.Ask.Commands,
+			RiskLevels: cfg.Ask.RiskLevels,
+			Gates:      cfg.Ask.Gates,
+		}))
+	}
+	return opts
+}
+
+// patternOptions caches the regex-compiled policies per config load.
+var patternOptions = config.NewDerived(func(cfg *config.GatesConfig) []chain.Option {
+	var opts []chain.Option
+	if cfg.Endpoints.Enabled {
+		opts = append(opts, chain.WithEndpointPolicy(chain.EndpointPolicy{
+			Files:    cfg.Endpoints.Files,
+			Patterns: cfg.Endpoints.Patterns,
+			Action:   cfg.Endpoints.Action,
+		}))
+	}
+	if cfg.Evasion.Enabled {
+		opts = append(opts, chain.WithEvasionPolicy(chain.EvasionPolicy{
+			Patterns:   cfg.Evasion.Patterns,
+			Action:     cfg.Evasion.Action,
+			MinSignals: cfg.Evasion.MinSignals,
+		}))
+	}
+	return opts
+})
+
+// selfPaths lists what kavach is made of: the gates config layers actually
+// loaded (or the project config a write would create), the settings files
+// registering its hooks, the hooks dir and the binary.
+func selfPaths() []string {
+	paths := config.GatesConfigLayers()
+	if len(paths) == 1 {
+		if wd, err := os.Getwd(); err == nil {
+			paths = append(paths, config.ProjectGatesConfigPath(wd))
+		}
+	}
+	paths = append(paths,
+		util.SettingsPath(),
+		filepath.Join(util.ClaudeDir(), "settings.local.json"),
+		filepath.Join(util.ClaudeDir(), "hooks"),
+		filepath.Join(util.BinDir(), "kavach"),
+	)
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		paths = append(paths, exe)
+	}
+	return paths
+}
+
+// inputRedaction combines enforcer.redact_inputs with the global redaction
+// switch: disabled saves raw inputs, hash_paths implies path redaction.
+func inputRedaction(cfg *config.GatesConfig) chain.Redaction {
+	if cfg.Redaction.Disabled {
+		return chain.Redaction{}
+	}
+	red := chain.ParseRedaction(cfg.Enforcer.RedactInputs)
+	if cfg.Redaction.HashPaths {
+		red.Paths, red.HashPaths = true, true
+	}
+	return red
+}
+
+// researchPolicy converts the configured research matrix; with
+// require_before_code off no rule applies.
+func researchPolicy(cfg *config.GatesConfig) chain.ResearchPolicy {
+	var policy chain.ResearchPolicy
+	if !cfg.Research.RequireBeforeCode {
+		return policy
+	}
+	for _, rule := range cfg.Research.Policy {
+		policy.Rules = append(policy.Rules, chain.ResearchRule{
+			Intent:        rule.Intent,
+			MinRisk:       rule.MinRisk,
+			MinComplexity: rule.MinComplexity,
+		})
+	}
+	return policy
+}
+
+// sensitivePaths flattens aegis.sensitive_paths in category order.
+func sensitivePaths(cfg *config.GatesConfig) []chain.SensitivePath {
+	categories := make([]string, 0, len(cfg.Aegis.SensitivePaths))
+	for category := range cfg.Aegis.SensitivePaths {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	var paths []chain.SensitivePath
+	for _, category := range categories {
+		for _, p := range cfg.Aegis.SensitivePaths[category] {
+			paths = append(paths, chain.SensitivePath{Pattern: p, Category: category})
+		}
+	}
+	return paths
+}
+
+// intentClassifiers caches the compiled classifier per config load.
+var intentClassifiers = config.NewDerived(buildIntentClassifier)
+
+// IntentClassifier returns the compiled classifier for the configured
+// keywords, rebuilt only when the config is reloaded.
+func IntentClassifier(cfg *config.GatesConfig) *chain.KeywordClassifier {
+	return intentClassifiers.Get(cfg)
+}
+
+func buildIntentClassifier(cfg *config.GatesConfig) *chain.KeywordClassifier {
+	kw := chain.DefaultIntentKeywords()
+	for category, words := range cfg.Intent.Keywords {
+		if len(words) == 0 {
+			continue
+		}
+		switch category {
+		case "implement":
+			kw.Implement = words
+		case "debug":
+			kw.Debug = words
+		case "refactor":
+			kw.Refactor = words
+		case "deploy":
+			kw.Deploy = words
+		case "security":
+			kw.Security = words
+		case "deletion":
+			kw.Deletion = words
+		}
+	}
+	return chain.NewKeywordClassifier(kw)
+}
+
+// sessionOverrides lists rules the user already overrode this session.
+func sessionOverrides(sessionID string) []string {
+	records, err := audit.ReadRecent(500)
+	if err != nil {
+		return nil
+	}
+	return audit.SessionOverrides(records, sessionID)
+}
+
+// chainResearchDone reports research known before the chain runs.
+// Transcript detection happens inside the chain's Research gate.
+func chainResearchDone(input *hook.Input, session *enforce.SessionState) bool {
+	return session.ResearchDone || config.IsResearchTool(input.ToolName)
+}
+
+// recordChainState persists the run's risk delta, recommendation
+// categories and research findings.
+func recordChainState(session *enforce.SessionState, state *chain.ChainState) {
+	if state.Risk != nil {
+		session.AddRisk(state.Risk.Delta)
+	}
+	if state.Aegis != nil {
+		session.AddRecommendations(state.Aegis.RecommendationCategories)
+	}
+	if state.Research != nil && len(state.Research.Sources) > 0 && !session.ResearchDone {
+		session.MarkResearchDone()
+	}
+}
@@ -0,0 +1,67 @@
+package gates
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/dag"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/statestore"
+)
+
+func TestChainAndDAGShareSessionKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config.ReloadGatesConfig()
+	defer dag.SetStore(statestore.NewMemoryStore())()
+
+	// Older state carries only session_id; the hook input has its own id
+	session := enforce.NewSessionState(t.TempDir())
+	session.ID, session.SessionID = "", "sess_legacy"
+	input := &hook.Input{SessionID: "claude-session", ToolName: "Bash", ToolInput: map[string]interface{}{"command": "ls"}}
+
+	state, err := dag.Schedule(session.ResolveID(), "build", dag.Decompose([]string{"Implement handler"}, nil))
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if err := dag.Save(state); err != nil {
+		t.Fatalf("save DAG: %v", err)
+	}
+	store := statestore.NewMemoryStore()
+	newChainRunner(input, session, chain.WithStore(store)).RunFull("", input.ToolName, input.ToolInput, true)
+
+	runs, err := chain.ListStoreStates(store, 0)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("chain runs = %+v, %v; want one", runs, err)
+	}
+	if runs[0].SessionID != "sess_legacy" {
+		t.Errorf("chain saved under %q, want sess_legacy", runs[0].SessionID)
+	}
+	if state, err := dag.Load(runs[0].SessionID); err != nil || state == nil {
+		t.Errorf("DAG not found under the chain's session key %q: %v", runs[0].SessionID, err)
+	}
+}
+
+func TestSelfPathsProjectConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	os.MkdirAll(sub, 0755)
+	t.Chdir(sub)
+
+	// No project config yet: the one a write would create is protected
+	if paths := selfPaths(); !slices.Contains(paths, config.ProjectGatesConfigPath(sub)) {
+		t.Errorf("selfPaths = %q, want %s", paths, config.ProjectGatesConfigPath(sub))
+	}
+	project, _, err := config.InitProjectGatesConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths := selfPaths(); !slices.Contains(paths, project) {
+		t.Errorf("selfPaths = %q, want the loaded %s", paths, project)
+	}
+}
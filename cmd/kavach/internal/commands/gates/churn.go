@@ -0,0 +1,75 @@
+// Package gates provides hook gates for Claude Code.
+// churn.go: Edit churn. Each write is counted per file in the session; once
+// a file reaches write.churn.threshold edits without a test run or a commit
+// of it, the next write warns or asks the agent to stop and verify.
+package gates
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/gitctx"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/types"
+)
+
+// checkEditChurn applies write.churn.action before another write to a
+// file already edited threshold times since it was last verified.
+func checkEditChurn(input *hook.Input, session *enforce.SessionState, filePath string) {
+	cfg := config.LoadGatesConfig().Write.Churn
+	if filePath == "" || cfg.Threshold <= 0 {
+		return
+	}
+	edits := session.EditCount(filePath)
+	if edits < cfg.Threshold {
+		return
+	}
+
+	rule := "write.churn.threshold:" + strconv.Itoa(cfg.Threshold)
+	if cfg.Action == "ask" {
+		checkLearning(input, "ENFORCER", audit.DecisionAsk, rule)
+		recordDecision(input, "ENFORCER", audit.DecisionAsk, rule)
+		hook.Output(types.NewPreToolUseAsk(fmt.Sprintf(
+			"ENFORCER [%s]: %s edited %d times without a test run or commit; stop and verify before editing again",
+			chain.SourceConfig, filepath.Base(filePath), edits)))
+		hook.Exit()
+	}
+	recordDecision(input, "ENFORCER", audit.DecisionWarn, rule)
+	exitAdvisory("ENFORCER", map[string]string{
+		"warn":   "edit_churn:" + filepath.Base(filePath),
+		"edits":  strconv.Itoa(edits),
+		"action": "run the tests or commit before editing again",
+	})
+}
+
+// ResetEditChurn clears edit counts after a Bash call that verified the
+// work: a test run resets every file, a commit the files it left clean.
+// Called by: memory sync (PostToolUse:Bash), post-tool gate.
+func ResetEditChurn(session *enforce.SessionState, command string) {
+	if len(session.Edits) == 0 || command == "" {
+		return
+	}
+	if config.LoadGatesConfig().Write.Churn.IsTestCommand(command) {
+		session.ResetEdits()
+		return
+	}
+	committed := false
+	for _, t := range gitctx.ParseTargets(command) {
+		committed = committed || t.Op == "commit"
+	}
+	if !committed {
+		return
+	}
+	var clean []string
+	for path := range session.Edits {
+		if gitctx.Uncommitted(path) == "" {
+			clean = append(clean, path)
+		}
+	}
+	session.ResetEdits(clean...)
+}
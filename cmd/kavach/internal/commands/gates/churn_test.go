@@ -0,0 +1,64 @@
+package gates
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/claude/shared/pkg/enforce"
+)
+
+func TestResetEditChurn(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := enforce.NewSessionState(t.TempDir())
+	session.RecordEdit("/src/a.go")
+	session.RecordEdit("/src/b.go")
+
+	ResetEditChurn(session, "ls -la && git status")
+	if len(session.Edits) != 2 {
+		t.Fatalf("non-verifying command reset edits: %v", session.Edits)
+	}
+	ResetEditChurn(session, "cd cmd && go test ./...")
+	if len(session.Edits) != 0 {
+		t.Fatalf("test run kept edits: %v", session.Edits)
+	}
+}
+
+func TestResetEditChurnCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	clean, dirty := filepath.Join(repo, "clean.go"), filepath.Join(repo, "dirty.go")
+	for _, f := range []string{clean, dirty} {
+		if err := os.WriteFile(f, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-qm", "init")
+	if err := os.WriteFile(dirty, []byte("package y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	session := enforce.NewSessionState(t.TempDir())
+	session.RecordEdit(clean)
+	session.RecordEdit(dirty)
+	ResetEditChurn(session, `git commit -m "wip"`)
+	if session.EditCount(clean) != 0 {
+		t.Error("committed file kept its edit count")
+	}
+	if session.EditCount(dirty) != 1 {
+		t.Error("file left dirty by the commit lost its edit count")
+	}
+}
@@ -81,7 +81,7 @@ func runContentGate(cmd *cobra.Command, args []string) {
 	if strings.Contains(content, "http://localhost") ||
 		strings.Contains(content, "127.0.0.1") ||
 		strings.Contains(content, "0.0.0.0") {
-		hook.ExitModifyTOON("CONTENT", map[string]string{
+		exitAdvisory("CONTENT", map[string]string{
 			"warn": "hardcoded_localhost",
 			"hint": "use_config",
 		})
@@ -0,0 +1,96 @@
+// Package gates provides hook gates for Claude Code.
+// dag_builder.go: DAG directive builder with agent model/skill resolution,
+// dag.max_nodes, schedule error reporting and the dag.event_url event sink.
+package gates
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/claude/shared/pkg/agentic"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/dag"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/util"
+)
+
+var _ dag.AgentResolver = (*agentic.DynamicLoader)(nil)
+
+func init() {
+	dag.SetEventSink(&configSink{})
+	hook.OnExit(dag.FlushEvents)
+}
+
+// eventSinks builds the dag.event_url sink for each config load, so a
+// reloaded config takes effect without restarting the process.
+var eventSinks = config.NewDerived(func(cfg *config.GatesConfig) dag.EventSink {
+	if cfg.DAG.EventURL == "" {
+		return dag.NopSink{}
+	}
+	return dag.NewHTTPSink(cfg.DAG.EventURL, time.Duration(cfg.DAG.EventTimeoutMs)*time.Millisecond)
+})
+
+// configSink forwards DAG events to dag.event_url, resolved per event so
+// commands that never touch a DAG skip the config read. Sinks that took
+// events are kept for Flush, even after a reload replaced them.
+type configSink struct {
+	mu   sync.Mutex
+	used []*dag.HTTPSink
+}
+
+// EmitEvent implements dag.EventSink.
+func (c *configSink) EmitEvent(e dag.NodeEvent) {
+	s := eventSinks.Get(config.LoadGatesConfig())
+	if h, ok := s.(*dag.HTTPSink); ok {
+		c.mu.Lock()
+		if !slices.Contains(c.used, h) {
+			c.used = append(c.used, h)
+		}
+		c.mu.Unlock()
+	}
+	s.EmitEvent(e)
+}
+
+// Flush waits for pending deliveries of every sink that took events.
+func (c *configSink) Flush() {
+	c.mu.Lock()
+	used := c.used
+	c.mu.Unlock()
+	for _, h := range used {
+		h.Flush()
+	}
+}
+
+// newDAGBuilder resolves dispatched agents from ~/.claude/agents so each
+// Task carries the agent's model and skills. Unknown agents fall back to
+// the plain directive. Wide levels are split per dag.max_parallel.
+func newDAGBuilder() *dag.DirectiveBuilder {
+	return dag.NewDirectiveBuilder(newAgenticLoader()).
+		WithMaxParallel(config.LoadGatesConfig().DAG.MaxParallel)
+}
+
+// scheduleOptions caps scheduled DAGs at dag.max_nodes.
+func scheduleOptions() []dag.ScheduleOption {
+	return []dag.ScheduleOption{dag.WithMaxNodes(config.LoadGatesConfig().DAG.MaxNodes)}
+}
+
+// newAgenticLoader loads agents and skills from ~/.claude on demand.
+func newAgenticLoader() *agentic.DynamicLoader {
+	base := filepath.Join(util.HomeDir(), ".claude")
+	return agentic.NewDynamicLoader(filepath.Join(base, "agents"), filepath.Join(base, "skills"))
+}
+
+// dagScheduleWarning explains a Schedule failure by error kind.
+func dagScheduleWarning(err error) string {
+	switch {
+	case errors.Is(err, dag.ErrTooLarge):
+		return "DAG too large, split the task breakdown: " + err.Error()
+	case errors.Is(err, dag.ErrCycle):
+		return "DAG has a dependency cycle: " + err.Error()
+	default:
+		return "DAG scheduling failed: " + err.Error()
+	}
+}
@@ -0,0 +1,27 @@
+package gates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/dag"
+)
+
+func TestScheduleOptionsMaxNodes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := config.GatesConfigPath()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(`{"dag": {"max_nodes": 2}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config.ReloadGatesConfig()
+	defer config.ReloadGatesConfig()
+
+	nodes := dag.Decompose([]string{"step 1", "step 2", "step 3"}, nil)
+	if _, err := dag.Schedule("sess", "capped", nodes, scheduleOptions()...); !errors.Is(err, dag.ErrTooLarge) {
+		t.Errorf("3 nodes with dag.max_nodes 2: err = %v, want ErrTooLarge", err)
+	}
+}
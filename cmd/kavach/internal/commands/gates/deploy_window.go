@@ -0,0 +1,106 @@
+// Package gates provides hook gates for Claude Code.
+// deploy_window.go: Converts deploy_window config into the chain's deploy
+// window policy. An invalid window is reported and skipped.
+package gates
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+)
+
+// deployWindowOptions caches the parsed deploy window per config load.
+var deployWindowOptions = config.NewDerived(func(cfg *config.GatesConfig) []chain.Option {
+	if !cfg.Deploy.Enabled {
+		return nil
+	}
+	policy, err := deployWindowPolicy(cfg.Deploy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[DEPLOY_WINDOW] ignored: %v\n", err)
+		return nil
+	}
+	return []chain.Option{chain.WithDeployWindow(policy)}
+})
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func deployWindowPolicy(c config.DeployConfig) (chain.DeployWindowPolicy, error) {
+	policy := chain.DeployWindowPolicy{Action: c.Action, Commands: c.Commands, Location: time.Local}
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return policy, fmt.Errorf("timezone %q: %v", c.Timezone, err)
+		}
+		policy.Location = loc
+	}
+	for _, day := range c.Days {
+		key := strings.ToLower(strings.TrimSpace(day))
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		d, ok := weekdays[key]
+		if !ok {
+			return policy, fmt.Errorf("unknown day %q", day)
+		}
+		policy.Days = append(policy.Days, d)
+	}
+	var err error
+	if policy.Start, policy.End, err = parseHours(c.Hours); err != nil {
+		return policy, err
+	}
+	for _, f := range c.Freezes {
+		from, _, err := parseFreezeTime(f.From, policy.Location)
+		if err != nil {
+			return policy, err
+		}
+		to, dateOnly, err := parseFreezeTime(f.To, policy.Location)
+		if err != nil {
+			return policy, err
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		if !to.After(from) {
+			return policy, fmt.Errorf("freeze %s..%s ends before it starts", f.From, f.To)
+		}
+		policy.Freezes = append(policy.Freezes, chain.FreezeWindow{From: from, To: to, Reason: f.Reason})
+	}
+	return policy, nil
+}
+
+// parseHours parses "HH:MM-HH:MM" into minutes after midnight.
+func parseHours(hours string) (int, int, error) {
+	from, to, ok := strings.Cut(hours, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("hours %q: want HH:MM-HH:MM", hours)
+	}
+	start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("hours %q: want HH:MM-HH:MM", hours)
+	}
+	s, e := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if s >= e {
+		return 0, 0, fmt.Errorf("hours %q: start must be before end", hours)
+	}
+	return s, e, nil
+}
+
+// parseFreezeTime accepts RFC 3339 or a date in loc; the bool reports a
+// date-only value.
+func parseFreezeTime(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("freeze time %q: want RFC 3339 or YYYY-MM-DD", s)
+}
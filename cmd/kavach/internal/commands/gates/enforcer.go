@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/claude/shared/pkg/agentic"
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
 	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
@@ -44,22 +46,31 @@ func runEnforcerGate(cmd *cobra.Command, args []string) {
 	input := hook.MustReadHookInput()
 	session := enforce.GetOrCreateSession()
 
+	// research.research_tools: any configured tool counts as research
+	if config.IsResearchTool(input.ToolName) {
+		session.MarkResearchDone()
+		hook.ExitSilent()
+	}
+
 	switch input.ToolName {
 	case "Task":
 		handleTask(input, session)
 	case "TaskCreate", "TaskUpdate", "TaskGet", "TaskList", "TaskOutput":
 		// Route to task gate for persistent task system (Claude Code 2.1.19+)
 		handleTaskManagement(input, session)
-	case "WebSearch":
-		session.MarkResearchDone()
-		hook.ExitSilent()
 	case "Write", "Edit":
 		handleWrite(input, session)
+	case "NotebookEdit":
+		checkSelfWrite(input, "ENFORCER")
+		hook.ExitSilent()
 	case "Bash":
-		handleBash(input)
+		handleBash(input, session)
 	case "Read":
 		handleRead(input)
 	default:
+		if strings.HasPrefix(input.ToolName, "mcp__") {
+			preToolMCP(input)
+		}
 		hook.ExitSilent()
 	}
 }
@@ -78,7 +89,7 @@ func handleTask(input *hook.Input, session *enforce.SessionState) {
 	if prompt != "" && researchGate != nil {
 		// P1 FIX: Require research for ALL engineer agent delegations
 		// Not just when frameworks are detected - research is the DEFAULT
-		if isEngineerAgent(agent) && !session.ResearchDone {
+		if isEngineerAgent(agent) && !researchSatisfied(input, session) {
 			// Build helpful search query
 			frameworks := agentic.ExtractFrameworkFromTask(prompt)
 			var query string
@@ -121,15 +132,18 @@ func isEngineerAgent(agent string) bool {
 func handleWrite(input *hook.Input, session *enforce.SessionState) {
 	filePath := input.GetString("file_path")
 
-	// DACE: Use research gate for code file detection
-	if patterns.IsCodeFile(filePath) && !session.ResearchDone {
-		// Build helpful search query suggestion
-		query := ""
-		if researchGate != nil {
-			query = researchGate.BuildSearchQuery("implementation patterns")
+	// DACE: Use research gate for code file detection; research.policy
+	// decides which intents need it
+	if patterns.IsCodeFile(filePath) && !researchSatisfied(input, session) {
+		if rule, ok := researchRequired(input); ok {
+			// Build helpful search query suggestion
+			query := ""
+			if researchGate != nil {
+				query = researchGate.BuildSearchQuery("implementation patterns")
+			}
+			hook.ExitBlockTOON("TABULA_RASA",
+				"WebSearch_required_before_code:policy:"+rule.String()+":suggest:"+query)
 		}
-		hook.ExitBlockTOON("TABULA_RASA",
-			"WebSearch_required_before_code:suggest:"+query)
 	}
 
 	// Check content for forbidden phrases
@@ -155,9 +169,16 @@ func handleWrite(input *hook.Input, session *enforce.SessionState) {
 	}
 
 	// Check write blocked paths from config
-	if config.IsBlockedWritePath(filePath) {
-		hook.ExitBlockTOON("ENFORCER", "Write:blocked_path:"+filePath)
+	if rule := config.MatchBlockedWritePath(filePath); rule != "" {
+		checkLearning(input, "ENFORCER", audit.DecisionBlock, rule)
+		recordDecision(input, "ENFORCER", audit.DecisionBlock, rule)
+		hook.ExitBlockTOONFrom("ENFORCER", "Write:blocked_path:"+filePath, chain.SourceConfig)
 	}
+	checkSelfWrite(input, "ENFORCER")
+	checkProtectedFile(input, filePath)
+	checkRetryLoop(input, "ENFORCER", session)
+	checkUncommitted(input, session, filePath)
+	checkEditChurn(input, session, filePath)
 
 	hook.ExitSilent()
 }
@@ -236,34 +257,39 @@ func containsStubMarkers(content string) bool {
 	return false
 }
 
-func handleBash(input *hook.Input) {
+func handleBash(input *hook.Input, session *enforce.SessionState) {
 	cmd := input.GetString("command")
 	if cmd == "" {
 		hook.ExitBlockTOON("ENFORCER", "Bash:empty_command")
 	}
-	// Check config.json blocked commands first
-	if config.IsBlockedCommand(cmd) {
-		hook.ExitBlockTOON("ENFORCER", "Bash:blocked_command")
-	}
-	// Fallback to patterns.toon
-	if patterns.IsBlocked(cmd) {
+	// config.json blocked commands, then patterns.toon
+	if rule, blocked := blockedCommandRule(cmd); blocked {
+		if rule != "" {
+			checkLearning(input, "ENFORCER", audit.DecisionBlock, rule)
+			recordDecision(input, "ENFORCER", audit.DecisionBlock, rule)
+		}
 		hook.ExitBlockTOON("ENFORCER", "Bash:blocked_command")
 	}
+	checkRetryLoop(input, "ENFORCER", session)
 	hook.ExitSilent()
 }
 
 func handleRead(input *hook.Input) {
 	path := input.GetString("file_path")
 	// Check config.json blocked paths first
-	if config.IsBlockedPath(path) {
-		hook.ExitBlockTOON("ENFORCER", "Read:blocked_path")
+	if rule := config.MatchBlockedPath(path); rule != "" {
+		checkLearning(input, "ENFORCER", audit.DecisionBlock, rule)
+		recordDecision(input, "ENFORCER", audit.DecisionBlock, rule)
+		hook.ExitBlockTOONFrom("ENFORCER", "Read:blocked_path", chain.SourceConfig)
 	}
-	if config.IsBlockedExtension(path) {
-		hook.ExitBlockTOON("ENFORCER", "Read:blocked_extension")
+	if rule := config.MatchBlockedExtension(path); rule != "" {
+		checkLearning(input, "ENFORCER", audit.DecisionBlock, rule)
+		recordDecision(input, "ENFORCER", audit.DecisionBlock, rule)
+		hook.ExitBlockTOONFrom("ENFORCER", "Read:blocked_extension", chain.SourceConfig)
 	}
 	// Fallback to patterns.toon
 	if patterns.IsSensitive(path) {
-		hook.ExitBlockTOON("ENFORCER", "Read:sensitive_file")
+		hook.ExitBlockTOONFrom("ENFORCER", "Read:sensitive_file", chain.SourceBuiltin)
 	}
 	hook.ExitSilent()
 }
@@ -281,9 +307,13 @@ func handleTaskManagement(input *hook.Input, session *enforce.SessionState) {
 		if description == "" {
 			hook.ExitBlockTOON("TASK_GATE", "TaskCreate:missing_description")
 		}
+		warning := checkTaskQuality(input, subject, description)
 		// Track task creation
 		session.TasksCreated++
 		session.Save()
+		if warning != nil {
+			exitAdvisory("TASK_GATE", warning)
+		}
 	case "TaskUpdate":
 		taskID := input.GetString("taskId")
 		if taskID == "" {
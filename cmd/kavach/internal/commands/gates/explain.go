@@ -0,0 +1,205 @@
+// Package gates provides hook gates for Claude Code.
+// explain.go: Onboarding soft mode. Block and warn reasons gain a short
+// explanation and an example of how to proceed (onboarding.soft_mode);
+// the terse reason is unchanged, the text goes in AdditionalContext.
+package gates
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/types"
+	"github.com/claude/shared/pkg/util"
+)
+
+func init() {
+	hook.SetExplainer(softExplain)
+}
+
+// explanation is matched by prefix against the rule category of a reason
+// (see ruleCategory) and, when subject is set, by substring against the
+// command or path as well. why completes "This was blocked because" or
+// "This went ahead with a warning because"; {subject} is replaced by the
+// command or path being checked.
+type explanation struct {
+	match   []string
+	subject []string
+	why     string
+	example string
+}
+
+var explanations = []explanation{
+	{
+		match:   []string{"self_protection"},
+		why:     "{subject} is part of kavach itself; changing it could switch off every other gate.",
+		example: "Make the change yourself, or go ahead only if you asked for this exact edit to the kavach config or hooks.",
+	},
+	{
+		match:   []string{"self_recon", "config_recon"},
+		why:     "reading kavach's own config or hooks maps out the rules a bypass would need to avoid.",
+		example: "Ask the user to check the config with `kavach config template` if you need to know which gates are on.",
+	},
+	{
+		match:   []string{"security_evasion"},
+		why:     "it turns off history, logging, error checking or a security check; each alone may be harmless, but together they hide what happened.",
+		example: "Leave the tooling on and fix the failing check, or disable it for one line with a comment explaining why.",
+	},
+	{
+		match:   []string{"exfiltration"},
+		why:     "it reads environment secrets and sends them to the network or a file, where they leave your control.",
+		example: "Check a variable without printing it ([ -n \"$API_TOKEN\" ] && echo set), or pass it straight to the tool that needs it.",
+	},
+	{
+		match:   []string{"deploy_window"},
+		why:     "it deploys outside the allowed deploy window or during a freeze.",
+		example: "Wait for the next allowed time in the reason, or get explicit approval from whoever owns the release.",
+	},
+	{
+		match:   []string{"sensitive", "blocked_path", "may_contain_secrets"},
+		subject: []string{".aws/"},
+		why:     "it reads {subject}, which holds cloud credentials.",
+		example: "If you need AWS config, use environment variables (AWS_PROFILE, AWS_REGION) instead of reading the file.",
+	},
+	{
+		match:   []string{"sensitive", "may_contain_secrets"},
+		why:     "{subject} may contain secrets that would end up in the conversation.",
+		example: "Read a template such as .env.example, or ask for the specific non-secret value you need.",
+	},
+	{
+		match:   []string{"secret"},
+		why:     "the content looks like a credential (API key, token or password).",
+		example: "Load it at runtime instead: os.Getenv(\"API_TOKEN\") or a secrets manager, never a literal in code.",
+	},
+	{
+		match:   []string{"uncommitted"},
+		why:     "the file has local changes that are not committed; writing it would overwrite them.",
+		example: "Ask the user to commit or stash (git stash push -- path) first, or edit only the lines you need.",
+	},
+	{
+		match:   []string{"protected_branch"},
+		why:     "it changes a protected branch directly.",
+		example: "Work on a feature branch: git switch -c feature/my-change, then open a pull request.",
+	},
+	{
+		match:   []string{"mcp_schema"},
+		why:     "the MCP tool arguments do not match the schema registered for the tool.",
+		example: "Fix the argument named in the error ($.owner means the owner field) and retry, or update mcp.schemas if the schema is stale.",
+	},
+	{
+		match:   []string{"blocked_command", "curl", "wget"},
+		subject: []string{"| sh", "|sh", "| bash", "|bash"},
+		why:     "piping a download into a shell runs code nobody has reviewed.",
+		example: "Download first (curl -fsSLo install.sh URL), read it, then run bash install.sh; or add the host to bash.trusted_install_hosts.",
+	},
+	{
+		match:   []string{"sudo"},
+		why:     "sudo runs the command with root privileges.",
+		example: "Install into user space instead (npm install --prefix, pip install --user), or ask the user to run the sudo step; routine commands can go in bash.safe_sudo.",
+	},
+	{
+		match:   []string{"blocked_command", "dangerous"},
+		why:     "{subject} can destroy data or the system and cannot be undone.",
+		example: "Scope the command to the project, e.g. rm -rf ./build instead of an absolute or root path.",
+	},
+	{
+		match:   []string{"blocked_path", "blocked_extension"},
+		why:     "{subject} is on the gates config blocklist.",
+		example: "Use a project file instead, or remove the entry from read/write.blocked_paths in ~/.claude/gates/config.json.",
+	},
+	{
+		match:   []string{"websearch_required", "engineer_delegation_requires_research", "research"},
+		why:     "the change needs current documentation, newer than the model's training data.",
+		example: "Run a WebSearch for the library and version first (e.g. \"cobra v1.8 persistent flags 2026\"), then retry.",
+	},
+	{
+		match:   []string{"task_requires_subagent_type", "no_subagent_type", "unknown_agent", "invalid_agent"},
+		why:     "Task needs a known agent to delegate to.",
+		example: "Pass subagent_type with an agent from ~/.claude/agents, e.g. \"backend-engineer\".",
+	},
+	{
+		match:   []string{"exceeds_100_lines", "folder_depth"},
+		why:     "the file breaks the project's size or layout limits.",
+		example: "Split the file by responsibility (handler.go, handler_validate.go) and keep folders shallow.",
+	},
+	{
+		match:   []string{"frontend_", "backend_", "mock", "antiprod"},
+		why:     "production code must not ship placeholder or mock data.",
+		example: "Fetch the real value from its source, or move the fixture into a _test.go file.",
+	},
+	{
+		match:   []string{"large_file"},
+		why:     "reading the whole file would flood the context.",
+		example: "Read a slice with offset and limit, or search it with Grep first.",
+	},
+}
+
+// softExplain is the hook explainer; it stays silent unless soft mode is on.
+func softExplain(gate, reason string, blocked bool, input *types.HookInput) string {
+	if !config.LoadGatesConfig().Onboarding.SoftMode {
+		return ""
+	}
+	lead, fallback := "This went ahead with a warning because ", "Nothing was stopped; check the warning if it was not expected, or tune the rule in ~/.claude/gates/config.json."
+	if blocked {
+		lead, fallback = "This was blocked because ", "Adjust the command or path as the reason suggests, or tune the rule in ~/.claude/gates/config.json."
+	}
+	category := ruleCategory(reason)
+	subject := explainSubject(input)
+	for _, e := range explanations {
+		if hasAnyPrefix(category, e.match) && (e.subject == nil || containsAnyOf(subject, e.subject)) {
+			return "why: " + lead + strings.ReplaceAll(e.why, "{subject}", subject) + "\nexample: " + e.example + "\n"
+		}
+	}
+	return "why: " + lead + "kavach " + gate + " flagged it to protect your code, secrets or system (" + reason + ").\n" +
+		"example: " + fallback + "\n"
+}
+
+// ruleCategory is the lower-cased category a reason starts with, after any
+// tool name: "Write:blocked_path:/etc/hosts" and "blocked_path" are both
+// "blocked_path", "SELF_RECON: Read reads ..." is "self_recon". A tool name
+// is a leading CamelCase word without underscores.
+func ruleCategory(reason string) string {
+	parts := strings.SplitN(reason, ":", 3)
+	if len(parts) > 1 && isToolName(parts[0]) {
+		parts = parts[1:]
+	}
+	return strings.ToLower(strings.TrimSpace(parts[0]))
+}
+
+func isToolName(s string) bool {
+	return s != "" && unicode.IsUpper(rune(s[0])) && strings.ToUpper(s) != s && !strings.Contains(s, "_")
+}
+
+// explainSubject names what was checked, for the explanation text.
+func explainSubject(input *types.HookInput) string {
+	if input != nil {
+		for _, key := range []string{"file_path", "path", "command"} {
+			if v := input.GetString(key); v != "" {
+				if home := util.HomeDir(); home != "" && home != "/" {
+					v = strings.Replace(v, home, "~", 1)
+				}
+				return v
+			}
+		}
+	}
+	return "this target"
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyOf(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,13 @@
 // failure.go: PostToolUseFailure gate.
-// Reacts to tool failures: logs patterns, suggests fixes.
+// Reacts to tool failures: logs patterns, suggests fixes, and escalates
+// instead once the same call keeps failing (retry.go).
 package gates
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/spf13/cobra"
 )
@@ -37,7 +40,20 @@ func runFailureGate(cmd *cobra.Command, args []string) {
 	toolName := input.ToolName
 
 	// Extract error from tool_response
-	errMsg := extractErrorMessage(input.ToolResponse)
+	errMsg := extractErrorMessage(input)
+
+	// The same call failing again and again: stop suggesting, escalate
+	if limit := retryLimit(); limit > 0 {
+		attempts := enforce.GetOrCreateSession().RecordFailure(retrySignature(toolName, input.ToolInput))
+		if attempts >= limit {
+			hook.ExitModifyTOON("FAILURE_GATE", map[string]string{
+				"tool":       toolName,
+				"error":      truncate(errMsg, 200),
+				"attempts":   fmt.Sprintf("%d", attempts),
+				"escalation": retryEscalation,
+			})
+		}
+	}
 
 	// Detect common failure patterns and suggest fixes
 	suggestion := detectFailurePattern(toolName, errMsg)
@@ -52,9 +68,12 @@ func runFailureGate(cmd *cobra.Command, args []string) {
 	hook.ExitSilent()
 }
 
-func extractErrorMessage(resp map[string]interface{}) string {
+// extractErrorMessage reads the error from tool_response.
+// Object responses use error/stderr; string/array responses use their text.
+func extractErrorMessage(input *hook.Input) string {
+	resp := input.ToolResponse
 	if resp == nil {
-		return ""
+		return input.GetToolResponseText()
 	}
 	if err, ok := resp["error"].(string); ok {
 		return err
@@ -0,0 +1,108 @@
+// Package gates provides hook gates for Claude Code.
+// git_policy.go: Git policy for the Bash gate: commit author/signing
+// compliance, protected branches and conventional-commit messages.
+package gates
+
+import (
+	"strings"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/gitctx"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/logger"
+)
+
+// checkGitPolicy runs the commit compliance, protected branch and
+// conventional-commit checks in that order. The first block or ask exits;
+// warnings are collected so none hides a later check, then emitted
+// together.
+func checkGitPolicy(input *hook.Input, command string) {
+	var warnings []map[string]string
+	for _, check := range []func(*hook.Input, string) map[string]string{
+		checkCommitPolicy, checkProtectedBranch, checkConventionalCommit,
+	} {
+		if warning := check(input, command); warning != nil {
+			warnings = append(warnings, warning)
+		}
+	}
+	exitAdvisories("BASH", warnings)
+}
+
+// checkCommitPolicy blocks git commits that miss the configured author
+// pattern or signing requirement, or returns the warning (git.action
+// warn), suggesting the flags that fix them.
+func checkCommitPolicy(input *hook.Input, command string) map[string]string {
+	cfg := config.LoadGatesConfig()
+	policy := gitctx.CommitPolicy{
+		AuthorPattern:  cfg.Git.AuthorPattern,
+		RequireSigning: cfg.Git.RequireSigning,
+	}
+	issues, err := gitctx.CheckCommits(command, input.Cwd, policy)
+	if err != nil {
+		logger.Warn("gates", "git policy skipped", "error", err.Error())
+		return nil
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	var details, fixes []string
+	for _, i := range issues {
+		details = append(details, i.Detail)
+		fixes = append(fixes, i.Fix)
+	}
+	rule := "git." + issues[0].Rule
+	reason := "git_policy:" + strings.Join(details, "; ")
+	if cfg.Git.Action == "block" {
+		checkLearning(input, "BASH", audit.DecisionBlock, rule)
+		recordDecision(input, "BASH", audit.DecisionBlock, rule)
+		hook.ExitBlockTOON("BASH", reason+" fix: "+strings.Join(fixes, "; "))
+	}
+	recordDecision(input, "BASH", audit.DecisionWarn, rule)
+	return map[string]string{
+		"warn": reason,
+		"fix":  strings.Join(fixes, "; "),
+	}
+}
+
+// checkConventionalCommit blocks git commit messages that are not
+// type(scope): subject, or returns the warning, suggesting a corrected
+// subject line.
+func checkConventionalCommit(input *hook.Input, command string) map[string]string {
+	cfg := config.LoadGatesConfig().Git.Conventional
+	if !cfg.Enabled || !commits(gitctx.ParseTargets(command)) {
+		return nil
+	}
+	issues := gitctx.CheckConventional(gitctx.CommitMessages(command), cfg.Types)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	var details, fixes []string
+	for _, i := range issues {
+		details = append(details, i.Subject+": "+i.Detail)
+		fixes = append(fixes, i.Suggestion)
+	}
+	reason := "conventional_commit:" + strings.Join(details, "; ")
+	if cfg.Action == "block" {
+		checkLearning(input, "BASH", audit.DecisionBlock, "git.conventional")
+		recordDecision(input, "BASH", audit.DecisionBlock, "git.conventional")
+		hook.ExitBlockTOON("BASH", reason+" suggested: "+strings.Join(fixes, "; "))
+	}
+	recordDecision(input, "BASH", audit.DecisionWarn, "git.conventional")
+	return map[string]string{
+		"warn":      reason,
+		"suggested": strings.Join(fixes, "; "),
+	}
+}
+
+// commits reports whether targets include a git commit.
+func commits(targets []gitctx.Target) bool {
+	for _, t := range targets {
+		if t.Op == "commit" {
+			return true
+		}
+	}
+	return false
+}
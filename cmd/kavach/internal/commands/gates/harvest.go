@@ -0,0 +1,64 @@
+// Package gates provides hook gates for Claude Code.
+// harvest.go: Bulk read detection. Each Read/Glob/Grep passing the read or
+// pre-tool gate is logged in the session; a burst over read.harvest.max_reads
+// warns, and one spread over max_dirs directories applies read.harvest.action.
+package gates
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/types"
+)
+
+// harvestDirsShown caps the directories listed in a harvest reason.
+const harvestDirsShown = 5
+
+// checkReadHarvest logs the read of path. A burst spread over max_dirs
+// with action "ask" exits asking; a plain burst returns the warning for
+// the caller to emit after its own advisories, or nil.
+func checkReadHarvest(input *hook.Input, path string) map[string]string {
+	cfg := config.LoadGatesConfig().Read.Harvest
+	if !cfg.Enabled {
+		return nil
+	}
+	dir := path
+	if input.ToolName == "Read" {
+		dir = filepath.Dir(path)
+	}
+	count, dirs := enforce.GetOrCreateSession().RecordRead(dir, time.Now(), cfg.Window())
+	if count <= cfg.MaxReads {
+		return nil
+	}
+
+	reason := fmt.Sprintf("read_harvest:%d reads in %s across %d directories (%s)",
+		count, cfg.Window(), len(dirs), summarizeDirs(dirs))
+	if len(dirs) >= cfg.MaxDirs && cfg.Action == "ask" {
+		checkLearning(input, "READ", audit.DecisionAsk, "read.harvest.max_dirs")
+		recordDecision(input, "READ", audit.DecisionAsk, "read.harvest.max_dirs")
+		hook.Output(types.NewPreToolUseAsk("READ: " + reason))
+		hook.Exit()
+	}
+	recordDecision(input, "READ", audit.DecisionWarn, "read.harvest.max_reads")
+	return map[string]string{
+		"warn":        reason,
+		"reads":       fmt.Sprintf("%d", count),
+		"directories": fmt.Sprintf("%d", len(dirs)),
+		"source":      chain.SourceConfig,
+	}
+}
+
+// summarizeDirs lists the first few directories and how many were left out.
+func summarizeDirs(dirs []string) string {
+	if len(dirs) <= harvestDirsShown {
+		return strings.Join(dirs, ", ")
+	}
+	return fmt.Sprintf("%s, +%d more", strings.Join(dirs[:harvestDirsShown], ", "), len(dirs)-harvestDirsShown)
+}
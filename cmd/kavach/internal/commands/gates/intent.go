@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/claude/shared/pkg/chain"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/spf13/cobra"
@@ -60,13 +61,18 @@ func runIntentGate(cmd *cobra.Command, args []string) {
 		session.MarkReinforcementDone()
 	}
 
-	// 3. STATUS QUERIES
+	// 3. CONFIG RECON: requests to read or get around kavach's own config
+	if target, evasive := chain.DetectConfigRecon(prompt); target != "" {
+		contextBlocks = append(contextBlocks, configReconDirective(input, session, target, evasive))
+	}
+
+	// 4. STATUS QUERIES
 	if isStatusQuery(prompt) {
 		contextBlocks = append(contextBlocks, statusDirective())
 		hook.ExitUserPromptSubmitWithContext(strings.Join(contextBlocks, "\n\n"))
 	}
 
-	// 4. AGI NLU: Classify intent using dynamic config
+	// 5. AGI NLU: Classify intent using dynamic config
 	intent := classifyIntentFromConfig(prompt)
 	if intent != nil {
 		session.MarkNLUParsed()
@@ -81,6 +87,15 @@ func runIntentGate(cmd *cobra.Command, args []string) {
 		contextBlocks = append(contextBlocks, formatIntentDirective(intent, today))
 	}
 
+	// 6. SKILL AUTO-INVOKE: auto_invoke skills whose triggers match the prompt
+	var nluSkills []string
+	if intent != nil {
+		nluSkills = intent.Skills
+	}
+	if block := autoInvokeDirective(newAgenticLoader(), prompt, nluSkills); block != "" {
+		contextBlocks = append(contextBlocks, block)
+	}
+
 	if len(contextBlocks) > 0 {
 		hook.ExitUserPromptSubmitWithContext(strings.Join(contextBlocks, "\n\n"))
 	}
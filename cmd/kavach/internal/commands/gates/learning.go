@@ -0,0 +1,70 @@
+// Package gates provides hook gates for Claude Code.
+// learning.go: Learning periods (learning.rules). A block or ask rule still
+// learning is audited with what it would have done and shown as a warning;
+// the call goes through until the period ends.
+package gates
+
+import (
+	"time"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/hook"
+)
+
+// checkLearning exits with a warning instead of enforcing decision when
+// rule is in its learning period, and returns otherwise. Advisory mode
+// enforces nothing anyway, so the full decision is shown instead.
+func checkLearning(input *hook.Input, gate, decision, rule string) {
+	if hook.AdvisoryMode() {
+		return
+	}
+	until, learning := config.LoadGatesConfig().Learning.Until(gate, rule, time.Now())
+	if !learning {
+		return
+	}
+	rec := decisionRecord(input, gate, audit.DecisionLearn, rule)
+	rec.Would = decision
+	audit.Append(rec)
+	exitAdvisory(gate, map[string]string{
+		"warn":     "learning:" + rule,
+		"would":    decision,
+		"enforces": until.Format(time.RFC3339),
+	})
+}
+
+// recordChainDecision applies checkLearning to the chain's final decision,
+// then records it: the first blocking (or asking) gate, under the config
+// rule that decided it when one did. Gate-level learning keys ("AEGIS")
+// cover the rest. Recorded asks the user approves count as overrides.
+func recordChainDecision(input *hook.Input, state *chain.ChainState) {
+	status, decision := "block", audit.DecisionBlock
+	if state.IsAsk() {
+		status, decision = "ask", audit.DecisionAsk
+	} else if !state.IsBlocked() {
+		return
+	}
+	for _, res := range state.Results {
+		if res.Status == status {
+			rule := chainRule(state, res)
+			checkLearning(input, res.Gate, decision, rule)
+			recordDecision(input, res.Gate, decision, rule)
+			return
+		}
+	}
+}
+
+// chainRule names the config rule behind a chain result, or "".
+func chainRule(state *chain.ChainState, res chain.VerificationResult) string {
+	switch {
+	case res.Context["tool_pattern"] != "":
+		return "tool_policy:" + res.Context["tool_pattern"]
+	case res.Context["ask_command"] != "":
+		return "ask.commands:" + res.Context["ask_command"]
+	case res.Gate == "AEGIS" && res.Context["aegis_action"] != "" && state.Aegis != nil &&
+		len(state.Aegis.ViolationCategories) > 0:
+		return "aegis.actions:" + state.Aegis.ViolationCategories[0]
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package gates
+
+import (
+	"testing"
+
+	"github.com/claude/shared/pkg/chain"
+)
+
+func TestChainRule(t *testing.T) {
+	state := &chain.ChainState{Aegis: &chain.AegisVerification{ViolationCategories: []string{"sensitive_path"}}}
+	cases := []struct {
+		res  chain.VerificationResult
+		want string
+	}{
+		{chain.VerificationResult{Gate: "POLICY", Context: map[string]string{"tool_pattern": "mcp__*"}}, "tool_policy:mcp__*"},
+		{chain.VerificationResult{Gate: "AEGIS", Context: map[string]string{"ask_command": "git push --force"}}, "ask.commands:git push --force"},
+		{chain.VerificationResult{Gate: "AEGIS", Context: map[string]string{"aegis_action": "deny"}}, "aegis.actions:sensitive_path"},
+		{chain.VerificationResult{Gate: "AEGIS", Context: map[string]string{}}, ""},
+		{chain.VerificationResult{Gate: "RESEARCH"}, ""},
+	}
+	for _, tc := range cases {
+		if got := chainRule(state, tc.res); got != tc.want {
+			t.Errorf("chainRule(%s %v) = %q, want %q", tc.res.Gate, tc.res.Context, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Package gates provides hook gates for Claude Code.
+// mcp.go: MCP argument validation. Tool inputs are checked against the
+// JSON Schema registered for the tool in mcp.schemas before the call.
+package gates
+
+import (
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/validate"
+)
+
+// preToolMCP validates an mcp__ tool call's arguments. Tools without a
+// registered schema pass, with a warning when their schema is invalid; a
+// mismatch blocks (or warns, per mcp.action) with the first validation
+// error.
+func preToolMCP(input *hook.Input) {
+	cfg := config.LoadGatesConfig().MCP
+	schema, key := cfg.SchemaFor(input.ToolName)
+	if schema == nil {
+		// A schema that failed to load cannot check anything; say so
+		if key, msg := cfg.InvalidFor(input.ToolName); key != "" {
+			recordDecision(input, "MCP", audit.DecisionWarn, "mcp.schemas:"+key)
+			exitAdvisory("MCP", map[string]string{
+				"warn":   "mcp_schema_invalid:" + key + ": " + msg,
+				"source": chain.SourceConfig,
+			})
+		}
+		hook.ExitSilent()
+	}
+
+	var args interface{} = input.ToolInput
+	if input.ToolInput == nil {
+		args = map[string]interface{}{}
+	}
+	err := validate.ValidateSchema(schema, args)
+	if err == nil {
+		hook.ExitSilent()
+	}
+
+	rule := "mcp.schemas:" + key
+	reason := "mcp_schema:" + input.ToolName + ": " + err.Error()
+	if cfg.Action == "warn" {
+		recordDecision(input, "MCP", audit.DecisionWarn, rule)
+		exitAdvisory("MCP", map[string]string{
+			"warn":   reason,
+			"source": chain.SourceConfig,
+		})
+	}
+	checkLearning(input, "MCP", audit.DecisionBlock, rule)
+	recordDecision(input, "MCP", audit.DecisionBlock, rule)
+	hook.ExitBlockTOONFrom("MCP", reason, chain.SourceConfig)
+}
@@ -1,16 +1,19 @@
 // Package gates provides hook gates for Claude Code.
 // posttool.go: Post-tool umbrella gate (PostToolUse for non-write tools).
-// Routes by tool name to 1-2 L3 gates.
+// Routes by tool name to 1-2 L3 gates. Task results round-trip their
+// dag_node_id to the DAG scheduler.
 package gates
 
 import (
 	"fmt"
 	"os"
 
+	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/context"
 	"github.com/claude/shared/pkg/dag"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
@@ -35,9 +38,22 @@ func runPostToolGate(cmd *cobra.Command, args []string) {
 	input := hook.MustReadHookInput()
 	session := enforce.GetOrCreateSession()
 
+	// Audit: tool ran despite a prior block/ask for the same action
+	recordOverride(input)
+
+	// A success ends retry tracking for this call
+	clearRetries(input, session)
+
+	// research.research_tools: any configured tool counts as research
+	if config.IsResearchTool(input.ToolName) {
+		session.MarkResearchDone()
+		hook.ExitSilent()
+	}
+
 	switch input.ToolName {
 	case "Bash":
-		// Memory sync only (handled externally)
+		// A test run or commit verifies edited files
+		ResetEditChurn(session, input.GetString("command"))
 		hook.ExitSilent()
 
 	case "Read":
@@ -74,11 +90,6 @@ func runPostToolGate(cmd *cobra.Command, args []string) {
 		}
 		hook.ExitSilent()
 
-	case "WebSearch", "WebFetch":
-		// Mark research done
-		session.MarkResearchDone()
-		hook.ExitSilent()
-
 	case "TaskCreate":
 		postToolTaskCreate(input, session)
 
@@ -101,8 +112,8 @@ func postToolTaskCreate(input *hook.Input, session *enforce.SessionState) {
 	session.Save()
 
 	// DAG tracking
-	if state, err := dag.Load(session.SessionID); err == nil {
-		_, _, directive := dag.HandleTaskEvent(state, "TaskCreate", input.ToolInput)
+	if state, err := dag.Load(session.ResolveID()); err == nil {
+		_, _, directive, _ := newDAGBuilder().HandleTaskResult(state, "TaskCreate", input.ToolInput, input.ToolResponse)
 		if err := dag.Save(state); err != nil {
 			fmt.Fprintf(os.Stderr, "[TASK_DAG] Save error: %v\n", err)
 		}
@@ -130,21 +141,36 @@ func postToolTaskUpdate(input *hook.Input, session *enforce.SessionState) {
 	session.Save()
 
 	// DAG advancement
-	if state, err := dag.Load(session.SessionID); err == nil {
-		complete, needsAegis, directive := dag.HandleTaskEvent(state, "TaskUpdate", input.ToolInput)
+	if state, err := dag.Load(session.ResolveID()); err == nil {
+		complete, needsAegis, directive, drift := newDAGBuilder().HandleTaskResult(state, "TaskUpdate", input.ToolInput, input.ToolResponse)
 		if err := dag.Save(state); err != nil {
 			fmt.Fprintf(os.Stderr, "[TASK_DAG] Save error: %v\n", err)
 		}
-		if complete && needsAegis {
-			hook.ExitModifyTOON("TASK_UPDATE_DAG_COMPLETE", map[string]string{
-				"dag_status": "complete",
-				"action":     "Run kavach orch aegis for final verification",
-			})
+		// Completion or advancement first; drift rides along as context
+		gate, kv, module := "", map[string]string{}, ""
+		switch {
+		case complete && needsAegis:
+			gate = "TASK_UPDATE_DAG_COMPLETE"
+			kv["dag_status"] = "complete"
+			kv["action"] = "Run kavach orch aegis for final verification"
+			if c := state.Cost(); !c.Empty() {
+				kv["tokens"] = c.String()
+			}
+		case directive != "":
+			gate, module = "TASK_UPDATE_DAG_ADVANCE", directive
+			kv["dag_status"] = "advancing"
 		}
-		if directive != "" {
-			hook.ExitModifyTOONWithModule("TASK_UPDATE_DAG_ADVANCE", map[string]string{
-				"dag_status": "advancing",
-			}, directive)
+		if drift != nil {
+			logger.Warn("dag", "task completed without dag_node_id", "dag_id", state.ID, "task_id", drift.TaskID, "matched", drift.Matched)
+			kv["warning"] = drift.String()
+			kv["drift_action"] = "Copy metadata {\"dag_node_id\": ...} from the dispatch directive into TaskCreate"
+			if gate == "" {
+				gate = "TASK_UPDATE_DAG_DRIFT"
+				kv["dag_status"] = "drift"
+			}
+		}
+		if gate != "" {
+			hook.ExitModifyTOONWithModule(gate, kv, module)
 		}
 	}
 
@@ -1,6 +1,6 @@
 // Package gates provides hook gates for Claude Code.
 // postwrite.go: Post-write umbrella gate (PostToolUse:Write|Edit|NotebookEdit).
-// Hierarchy: ANTIPROD(P0→P3) → QUALITY → LINT → CONTEXT → MEMORY
+// Hierarchy: AEGIS(verify) → ANTIPROD(P0→P3) → QUALITY → LINT → CONTEXT → MEMORY
 package gates
 
 import (
@@ -22,7 +22,7 @@ var postWriteHookMode bool
 
 var postWriteCmd = &cobra.Command{
 	Use:   "post-write",
-	Short: "Post-write umbrella gate (antiprod → quality → lint → context → memory)",
+	Short: "Post-write umbrella gate (aegis → antiprod → quality → lint → context → memory)",
 	Run:   runPostWriteGate,
 }
 
@@ -39,12 +39,24 @@ func runPostWriteGate(cmd *cobra.Command, args []string) {
 	input := hook.MustReadHookInput()
 	session := enforce.GetOrCreateSession()
 
+	// A success ends retry tracking for this call
+	clearRetries(input, session)
+
 	filePath := input.GetString("file_path")
 	content := input.GetString("content")
 	if input.ToolName == "Edit" {
 		content = input.GetString("new_string")
 	}
 
+	// The write happened: exempt the file from the uncommitted check before
+	// any check below exits. Edit churn is counted by memory sync.
+	if filePath != "" {
+		session.AddFileModified(filePath)
+	}
+
+	// L2: AEGIS — re-scan the written result for secrets/dangerous content
+	runPostAegisCheck(input)
+
 	// L2: ANTIPROD — P0→P3 hierarchy (stops at first P-level hit)
 	if content != "" && filePath != "" {
 		if reason := runAntiProdCheck(filePath, content); reason != "" {
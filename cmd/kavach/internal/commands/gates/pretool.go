@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/claude/shared/pkg/audit"
 	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/dag"
 	"github.com/claude/shared/pkg/enforce"
@@ -21,7 +22,7 @@ var preToolHookMode bool
 
 var preToolCmd = &cobra.Command{
 	Use:   "pre-tool",
-	Short: "Pre-tool umbrella gate (bash|read|ceo|skill|content|task|context)",
+	Short: "Pre-tool umbrella gate (bash|read|ceo|skill|content|task|mcp|context)",
 	Run:   runPreToolGate,
 }
 
@@ -54,6 +55,9 @@ func runPreToolGate(cmd *cobra.Command, args []string) {
 		// Context tracking only — silent pass
 		hook.ExitSilent()
 	default:
+		if strings.HasPrefix(input.ToolName, "mcp__") {
+			preToolMCP(input)
+		}
 		hook.ExitSilent()
 	}
 }
@@ -64,10 +68,11 @@ func preToolBash(input *hook.Input) {
 	if command == "" {
 		hook.ExitBlockTOON("BASH", "empty_command")
 	}
-	if config.IsBlockedCommand(command) {
-		hook.ExitBlockTOON("BASH", "blocked_command")
-	}
-	if patterns.IsBlocked(command) {
+	if rule, blocked := blockedCommandRule(command); blocked {
+		if rule != "" {
+			checkLearning(input, "BASH", audit.DecisionBlock, rule)
+			recordDecision(input, "BASH", audit.DecisionBlock, rule)
+		}
 		hook.ExitBlockTOON("BASH", "blocked_command")
 	}
 
@@ -76,18 +81,19 @@ func preToolBash(input *hook.Input) {
 		hook.ExitBlockTOON("RUST_CLI", "LEGACY_BLOCKED:"+legacy+":USE:"+rust+":"+reason)
 	}
 
-	// Sudo warning
-	if strings.HasPrefix(strings.TrimSpace(command), "sudo") {
-		hook.ExitModifyTOON("BASH", map[string]string{"warn": "sudo_detected"})
+	// Commit compliance, protected branches, then commit message format
+	checkGitPolicy(input, command)
+
+	// Sudo warning, except routine ones in bash.safe_sudo
+	if strings.HasPrefix(strings.TrimSpace(command), "sudo") && config.MatchSafeSudo(command) == "" {
+		recordDecision(input, "BASH", audit.DecisionWarn, "bash.warn_commands:sudo")
+		exitAdvisory("BASH", map[string]string{"warn": "sudo_detected"})
 	}
 
 	// Risky command warnings
-	cfg := config.LoadGatesConfig()
-	cmdLower := strings.ToLower(command)
-	for _, warn := range cfg.Bash.WarnCommands {
-		if strings.Contains(cmdLower, strings.ToLower(warn)) {
-			hook.ExitModifyTOON("BASH", map[string]string{"warn": warn + "_detected"})
-		}
+	if rule := config.MatchWarnCommand(command); rule != "" {
+		recordDecision(input, "BASH", audit.DecisionWarn, rule)
+		exitAdvisory("BASH", map[string]string{"warn": warnName(rule) + "_detected"})
 	}
 
 	hook.ExitSilent()
@@ -110,21 +116,31 @@ func preToolRead(input *hook.Input) {
 		hook.ExitSilent()
 	}
 
-	if config.IsBlockedPath(filePath) {
+	if rule := config.MatchBlockedPath(filePath); rule != "" {
+		checkLearning(input, "READ", audit.DecisionBlock, rule)
+		recordDecision(input, "READ", audit.DecisionBlock, rule)
 		hook.ExitBlockTOON("READ", "blocked_path")
 	}
-	if config.IsBlockedExtension(filePath) {
+	if rule := config.MatchBlockedExtension(filePath); rule != "" {
+		checkLearning(input, "READ", audit.DecisionBlock, rule)
+		recordDecision(input, "READ", audit.DecisionBlock, rule)
 		hook.ExitBlockTOON("READ", "blocked_extension")
 	}
 	if patterns.IsSensitive(filePath) {
 		hook.ExitBlockTOON("READ", "sensitive_file")
 	}
 
-	if config.IsWarnPath(filePath) {
-		hook.ExitModifyTOON("READ", map[string]string{"warn": "may_contain_secrets"})
+	harvest := checkReadHarvest(input, filePath)
+
+	if rule := config.MatchWarnPath(filePath); rule != "" {
+		recordDecision(input, "READ", audit.DecisionWarn, rule)
+		exitAdvisory("READ", map[string]string{"warn": "may_contain_secrets"})
 	}
 	if patterns.IsLargeFile(filePath) {
-		hook.ExitModifyTOON("READ", map[string]string{"warn": "large_file"})
+		exitAdvisory("READ", map[string]string{"warn": "large_file"})
+	}
+	if harvest != nil {
+		exitAdvisory("READ", harvest)
 	}
 
 	hook.ExitSilent()
@@ -139,6 +155,7 @@ func preToolCEO(input *hook.Input) {
 	if !config.IsValidAgent(subagentType) {
 		hook.ExitBlockTOON("CEO", "unknown_agent:"+subagentType)
 	}
+	admitSubagent("CEO", enforce.GetOrCreateSession(), input.AgentID)
 
 	prompt := input.GetString("prompt")
 	skill := detectSkillFromConfig(prompt)
@@ -241,13 +258,16 @@ func preToolTask(input *hook.Input) {
 		if input.GetString("description") == "" {
 			hook.ExitBlockTOON("TASK_GATE", "TaskCreate:missing_description")
 		}
+		warning := checkTaskQuality(input, input.GetString("subject"), input.GetString("description"))
 		taskListID := getTaskListID()
 		today := time.Now().Format("2006-01-02")
-		hook.ExitModifyTOON("TASK_CREATE", map[string]string{
+		metadata := map[string]string{
 			"task_list_id": taskListID,
 			"created_date": today,
-			"session_id":   session.SessionID,
-		})
+			"session_id":   session.ResolveID(),
+		}
+		mergeAdvisory(metadata, warning)
+		hook.ExitModifyTOON("TASK_CREATE", metadata)
 	case "TaskUpdate":
 		if input.GetString("taskId") == "" {
 			hook.ExitBlockTOON("TASK_GATE", "TaskUpdate:missing_taskId")
@@ -281,12 +301,16 @@ func preToolTask(input *hook.Input) {
 // runDAGSchedule handles DAG decomposition and scheduling.
 func runDAGSchedule(session *enforce.SessionState, prompt string, breakdown, agents []string, orchDirective map[string]string) {
 	nodes := dag.Decompose(breakdown, agents)
-	state, err := dag.Schedule(session.SessionID, prompt, nodes)
+	dag.InferSkills(nodes, newAgenticLoader())
+	state, err := dag.Schedule(session.ResolveID(), prompt, nodes, scheduleOptions()...)
 	if err == nil {
+		state.ProjectID = session.Project
 		if err := dag.Save(state); err != nil {
 			fmt.Fprintf(os.Stderr, "[CEO_DAG] Save error: %v\n", err)
 		}
-		directive := dag.BuildDirective(state)
+		directive := newDAGBuilder().Directive(state)
 		hook.ExitModifyTOONWithModule("CEO_DAG_DISPATCH", orchDirective, directive)
 	}
+	fmt.Fprintf(os.Stderr, "[CEO_DAG] Schedule error: %v\n", err)
+	orchDirective["WARNING"] = dagScheduleWarning(err)
 }
@@ -5,14 +5,16 @@ package gates
 
 import (
 	"github.com/claude/shared/pkg/agentic"
+	"github.com/claude/shared/pkg/audit"
 	"github.com/claude/shared/pkg/chain"
 	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/gitctx"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/claude/shared/pkg/patterns"
 	"github.com/claude/shared/pkg/types"
 	"github.com/spf13/cobra"
-	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -38,21 +40,22 @@ func runPreWriteGate(cmd *cobra.Command, args []string) {
 	session := enforce.GetOrCreateSession()
 
 	// L2: SECURITY — chain verification (Intent → CEO → Aegis → Research)
-	if blocked, reason, context := runSecurityChain(input, session); blocked {
+	decision, reason, context := runSecurityChain(input, session)
+	if decision == "deny" || decision == "ask" {
 		hook.Output(&types.HookResponse{
 			HookSpecificOutput: &types.HookSpecificOutput{
 				HookEventName:            "PreToolUse",
-				PermissionDecision:       "deny",
+				PermissionDecision:       decision,
 				PermissionDecisionReason: reason,
 				AdditionalContext:        context,
 			},
 		})
-		os.Exit(0)
+		hook.Exit()
 	}
 
 	// L2: SECURITY — content (secrets/credentials detection)
 	if blocked, reason := runContentCheck(input); blocked {
-		hook.ExitBlockTOON("CONTENT", reason)
+		hook.ExitBlockTOONFrom("CONTENT", reason, chain.SourceBuiltin)
 	}
 
 	// L2: GUARD — code-guard (prevent premature code removal)
@@ -60,29 +63,124 @@ func runPreWriteGate(cmd *cobra.Command, args []string) {
 		runCodeGuardCheck(input)
 	}
 
-	// L2: RESEARCH — TABULA_RASA enforcement
-	runResearchCheck(input, session)
+	// L2: RESEARCH — TABULA_RASA enforcement (skipped when the chain deferred it as a TODO)
+	if decision != "todo" {
+		runResearchCheck(input, session)
+	}
 
 	// Check write blocked paths
 	filePath := input.GetString("file_path")
-	if filePath != "" && config.IsBlockedWritePath(filePath) {
-		hook.ExitBlockTOON("ENFORCER", "Write:blocked_path:"+filePath)
+	if rule := config.MatchBlockedWritePath(filePath); filePath != "" && rule != "" {
+		checkLearning(input, "ENFORCER", audit.DecisionBlock, rule)
+		recordDecision(input, "ENFORCER", audit.DecisionBlock, rule)
+		hook.ExitBlockTOONFrom("ENFORCER", "Write:blocked_path:"+filePath, chain.SourceConfig)
+	}
+	checkProtectedFile(input, filePath)
+	checkUncommitted(input, session, filePath)
+	checkEditChurn(input, session, filePath)
+
+	if decision == "todo" {
+		hook.Output(&types.HookResponse{
+			HookSpecificOutput: &types.HookSpecificOutput{
+				HookEventName:            "PreToolUse",
+				PermissionDecision:       "allow",
+				PermissionDecisionReason: reason,
+				AdditionalContext:        context,
+			},
+		})
+		hook.Exit()
 	}
 
 	hook.ExitSilent()
 }
 
 // runSecurityChain runs the multi-agent verification chain.
-// Returns (blocked, reason, context).
-func runSecurityChain(input *hook.Input, session *enforce.SessionState) (bool, string, string) {
+// Returns (decision, reason, context); decision is "deny", "ask", "todo"
+// (research deferred, continue with directive), or "" to continue.
+func runSecurityChain(input *hook.Input, session *enforce.SessionState) (string, string, string) {
 	prompt := getPromptFromInput(input)
-	runner := chain.NewRunner(session.ID)
-	state := runner.RunFull(prompt, input.ToolName, input.ToolInput, session.ResearchDone)
+	runner := newChainRunner(input, session)
+	ctx, cancel := chainContext(config.LoadGatesConfig())
+	defer cancel()
+	state := runner.RunFullContext(ctx, prompt, input.ToolName, input.ToolInput, chainResearchDone(input, session))
+	recordChainState(session, state)
+	recordChainDecision(input, state)
 
 	if state.IsBlocked() {
-		return true, state.GetBlockReason(), runner.ToTOON()
+		return "deny", state.GetBlockReason(), runner.ToTOON()
+	}
+	if reason := retryLoopReason(input, session); reason != "" {
+		return "ask", reason, runner.ToTOON()
+	}
+	if state.IsAsk() {
+		return "ask", state.GetAskReason(), runner.ToTOON()
+	}
+	if state.Research != nil && state.Research.Todo != "" {
+		return "todo", "Research deferred: " + state.Research.Todo, chain.ResearchTodoDirective(state.Research.Todo)
+	}
+	return "", "", ""
+}
+
+// checkProtectedFile asks or blocks edits to write.protected_files,
+// naming the file and why it is protected.
+func checkProtectedFile(input *hook.Input, filePath string) {
+	pf := config.MatchProtectedFile(filePath)
+	if pf == nil {
+		return
 	}
-	return false, "", ""
+
+	rule := "write.protected_files:" + pf.Pattern
+	reason := "protected file " + filepath.Base(filePath) + " (" + pf.Pattern + ")"
+	if pf.Reason != "" {
+		reason += ": " + pf.Reason
+	}
+	if pf.Blocks() {
+		checkLearning(input, "ENFORCER", audit.DecisionBlock, rule)
+		recordDecision(input, "ENFORCER", audit.DecisionBlock, rule)
+		hook.ExitBlockTOONFrom("ENFORCER", input.ToolName+":"+reason, chain.SourceConfig)
+	}
+	checkLearning(input, "ENFORCER", audit.DecisionAsk, rule)
+	recordDecision(input, "ENFORCER", audit.DecisionAsk, rule)
+	hook.Output(types.NewPreToolUseAsk("ENFORCER [" + chain.SourceConfig + "]: " + input.ToolName + " on " + reason))
+	hook.Exit()
+}
+
+// checkSelfWrite asks before a call that modifies kavach's own config,
+// hooks or binary (selfPaths), which could switch off every other gate.
+// The chain runner does the same; this covers the shipped hooks.
+func checkSelfWrite(input *hook.Input, gate string) {
+	protected, write := chain.SelfAccess(selfPaths(), input.ToolName, input.ToolInput)
+	if !write {
+		return
+	}
+	recordDecision(input, gate, audit.DecisionAsk, "self_protection:"+protected)
+	hook.Output(types.NewPreToolUseAsk(gate + " [" + chain.SourceBuiltin + "]: SELF_PROTECTION: " + input.ToolName + " modifies kavach's own " + protected))
+	hook.Exit()
+}
+
+// checkUncommitted asks or warns before a write to a tracked file with
+// uncommitted changes the session did not make, so local work is not
+// overwritten. Silent outside a git repo.
+func checkUncommitted(input *hook.Input, session *enforce.SessionState, filePath string) {
+	action := config.LoadGatesConfig().Write.Uncommitted
+	if filePath == "" || action == "off" || session.HasModified(filePath) {
+		return
+	}
+	code := gitctx.Uncommitted(filePath)
+	if code == "" {
+		return
+	}
+
+	rule := "write.uncommitted:" + code
+	if action == "warn" {
+		recordDecision(input, "ENFORCER", audit.DecisionWarn, rule)
+		exitAdvisory("ENFORCER", map[string]string{"warn": "uncommitted_changes:" + filepath.Base(filePath)})
+	}
+	checkLearning(input, "ENFORCER", audit.DecisionAsk, rule)
+	recordDecision(input, "ENFORCER", audit.DecisionAsk, rule)
+	hook.Output(types.NewPreToolUseAsk("ENFORCER [" + chain.SourceConfig + "]: " + filepath.Base(filePath) +
+		" has uncommitted changes that will be overwritten (git status " + code + "); commit or stash them first"))
+	hook.Exit()
 }
 
 // runContentCheck checks for secrets and credentials in content.
@@ -157,18 +255,23 @@ func runCodeGuardCheck(input *hook.Input) {
 	}
 }
 
-// runResearchCheck enforces TABULA_RASA (research before code).
+// runResearchCheck enforces TABULA_RASA (research before code) for the
+// intents research.policy requires it for.
 func runResearchCheck(input *hook.Input, session *enforce.SessionState) {
 	filePath := input.GetString("file_path")
 	if filePath == "" {
 		return
 	}
-	if !patterns.IsCodeFile(filePath) || session.ResearchDone {
+	if !patterns.IsCodeFile(filePath) || researchSatisfied(input, session) {
+		return
+	}
+	rule, ok := researchRequired(input)
+	if !ok {
 		return
 	}
 
 	rg := agentic.NewResearchGate()
 	query := rg.BuildSearchQuery("implementation patterns")
 	hook.ExitBlockTOON("TABULA_RASA",
-		"WebSearch_required_before_code:suggest:"+query)
+		"WebSearch_required_before_code:policy:"+rule.String()+":suggest:"+query)
 }
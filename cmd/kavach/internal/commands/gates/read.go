@@ -4,6 +4,8 @@
 package gates
 
 import (
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
 	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/claude/shared/pkg/patterns"
@@ -45,40 +47,55 @@ func runReadGate(cmd *cobra.Command, args []string) {
 
 	// Glob/Grep path is optional (defaults to cwd), so only block Read with no path
 	if filePath == "" && input.ToolName == "Read" {
-		hook.ExitBlockTOON("READ", "no_file_path")
+		hook.ExitBlockTOONFrom("READ", "no_file_path", chain.SourceBuiltin)
 	}
 	if filePath == "" {
 		hook.ExitSilent() // Glob/Grep with no path = cwd, always allowed
 	}
 
 	// Check blocked paths from gates/config.json (priority)
-	if config.IsBlockedPath(filePath) {
-		hook.ExitBlockTOON("READ", "blocked_path")
+	if rule := config.MatchBlockedPath(filePath); rule != "" {
+		checkLearning(input, "READ", audit.DecisionBlock, rule)
+		recordDecision(input, "READ", audit.DecisionBlock, rule)
+		hook.ExitBlockTOONFrom("READ", "blocked_path:"+rule, chain.SourceConfig)
 	}
 
 	// Check blocked extensions (private keys, etc.)
-	if config.IsBlockedExtension(filePath) {
-		hook.ExitBlockTOON("READ", "blocked_extension")
+	if rule := config.MatchBlockedExtension(filePath); rule != "" {
+		checkLearning(input, "READ", audit.DecisionBlock, rule)
+		recordDecision(input, "READ", audit.DecisionBlock, rule)
+		hook.ExitBlockTOONFrom("READ", "blocked_extension:"+rule, chain.SourceConfig)
 	}
 
 	// Legacy: Check sensitive files using shared patterns
 	if patterns.IsSensitive(filePath) {
-		hook.ExitBlockTOON("READ", "sensitive_file")
+		hook.ExitBlockTOONFrom("READ", "sensitive_file", chain.SourceBuiltin)
 	}
 
+	// Behavioral: many reads in a short window may be data harvesting
+	harvest := checkReadHarvest(input, filePath)
+
 	// Warn for files that may contain secrets
-	if config.IsWarnPath(filePath) {
-		hook.ExitModifyTOON("READ", map[string]string{
-			"warn": "may_contain_secrets",
+	if rule := config.MatchWarnPath(filePath); rule != "" {
+		recordDecision(input, "READ", audit.DecisionWarn, rule)
+		exitAdvisory("READ", map[string]string{
+			"warn":   "may_contain_secrets",
+			"rule":   rule,
+			"source": chain.SourceConfig,
 		})
 	}
 
 	// Warn for large files
 	if patterns.IsLargeFile(filePath) {
-		hook.ExitModifyTOON("READ", map[string]string{
-			"warn": "large_file",
+		exitAdvisory("READ", map[string]string{
+			"warn":   "large_file",
+			"source": chain.SourceBuiltin,
 		})
 	}
 
+	if harvest != nil {
+		exitAdvisory("READ", harvest)
+	}
+
 	hook.ExitSilent()
 }
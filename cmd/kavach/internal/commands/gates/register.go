@@ -1,19 +1,42 @@
 // Package gates provides gate enforcement subcommands.
 package gates
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/claude/shared/pkg/hook"
+	"github.com/spf13/cobra"
+)
+
+// hookInputFile is the --input flag shared by all gate commands.
+var hookInputFile string
+
+// strictExit is the --strict-exit flag: exit with the outcome code
+// (hook.ExitCode*) instead of always 0.
+var strictExit bool
 
 // Register adds all gate commands to the parent gates command.
 func Register(gatesCmd *cobra.Command) {
+	// --input replays a saved hook input file instead of reading stdin
+	gatesCmd.PersistentFlags().StringVar(&hookInputFile, "input", "", "Read hook input JSON from file instead of stdin")
+	// --strict-exit lets scripts and CI branch on the outcome; hooks keep exit 0
+	gatesCmd.PersistentFlags().BoolVar(&strictExit, "strict-exit", false, "Exit 0=pass, 1=warn, 2=block/ask, 3=error instead of always 0")
+	gatesCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		hook.SetInputFile(hookInputFile)
+		hook.SetStrictExit(strictExit)
+		setupAdvisoryMode()
+	}
+
 	// Umbrella gates (4 — called by hooks in settings.json)
 	gatesCmd.AddCommand(preWriteCmd)  // PreToolUse:Write|Edit|NotebookEdit
 	gatesCmd.AddCommand(postWriteCmd) // PostToolUse:Write|Edit|NotebookEdit
-	gatesCmd.AddCommand(preToolCmd)   // PreToolUse:Bash|Read|Glob|Grep|Task|Skill|...
+	gatesCmd.AddCommand(preToolCmd)   // PreToolUse:Bash|Read|Glob|Grep|Task|Skill|mcp__.*|...
 	gatesCmd.AddCommand(postToolCmd)  // PostToolUse:Bash|Read|Glob|Grep|Task|WebSearch|...
 
 	// Intent gate (standalone — UserPromptSubmit)
 	gatesCmd.AddCommand(intentCmd)
 
+	// Setup gate (standalone — Setup)
+	gatesCmd.AddCommand(setupCmd)
+
 	// Legacy individual gates (kept for direct invocation / testing)
 	gatesCmd.AddCommand(ceoCmd)
 	gatesCmd.AddCommand(astCmd)
@@ -32,5 +55,9 @@ func Register(gatesCmd *cobra.Command) {
 	gatesCmd.AddCommand(chainCmd)
 	gatesCmd.AddCommand(subagentCmd)
 	gatesCmd.AddCommand(failureCmd)
+	gatesCmd.AddCommand(verifyCmd)
 	gatesCmd.AddCommand(mockdataCmd)
+
+	// Latency benchmark over fixture inputs
+	gatesCmd.AddCommand(benchCmd)
 }
@@ -4,6 +4,7 @@
 package gates
 
 import (
+	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/claude/shared/pkg/patterns"
@@ -32,8 +33,8 @@ func runResearchGate(cmd *cobra.Command, args []string) {
 	ctx := enforce.NewContext()
 	session := enforce.GetOrCreateSession()
 
-	// Both WebSearch and WebFetch count as research
-	if input.ToolName == "WebSearch" || input.ToolName == "WebFetch" {
+	// research.research_tools: any configured tool counts as research
+	if config.IsResearchTool(input.ToolName) {
 		session.MarkResearchDone()
 		hook.ExitSilent()
 	}
@@ -42,7 +43,7 @@ func runResearchGate(cmd *cobra.Command, args []string) {
 		filePath := input.GetString("file_path")
 
 		// Use shared patterns for code detection
-		if patterns.IsCodeFile(filePath) && !session.ResearchDone {
+		if patterns.IsCodeFile(filePath) && !researchSatisfied(input, session) {
 			hook.ExitBlockTOON("TABULA_RASA", "WebSearch_required,cutoff:"+session.TrainingCutoff+",today:"+ctx.Today)
 		}
 	}
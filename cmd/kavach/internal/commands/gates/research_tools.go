@@ -0,0 +1,46 @@
+// Package gates provides hook gates for Claude Code.
+// research_tools.go: Config-driven research detection (research.research_tools)
+// and the research.policy decision for direct code writes.
+// DACE: Session flag first, transcript scan as fallback.
+package gates
+
+import (
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/transcript"
+)
+
+// researchSatisfied reports whether TABULA_RASA research is satisfied.
+// Counts the session flag, the current tool, or any configured research
+// tool used within the recent transcript window. Marks the session on hit.
+func researchSatisfied(input *hook.Input, session *enforce.SessionState) bool {
+	if session.ResearchDone {
+		return true
+	}
+	if input == nil {
+		return false
+	}
+	if config.IsResearchTool(input.ToolName) {
+		session.MarkResearchDone()
+		return true
+	}
+	cfg := config.LoadGatesConfig()
+	if transcript.HasRecentToolUse(input.TranscriptPath, cfg.Research.ResearchTools, cfg.Research.ResearchWindow) {
+		session.MarkResearchDone()
+		return true
+	}
+	return false
+}
+
+// researchRequired classifies the call's prompt like the chain's INTENT
+// gate and returns the research.policy rule requiring research for it.
+func researchRequired(input *hook.Input) (chain.ResearchRule, bool) {
+	cfg := config.LoadGatesConfig()
+	if !cfg.Research.Enabled {
+		return chain.ResearchRule{}, false
+	}
+	policy := researchPolicy(cfg)
+	return policy.Requires(IntentClassifier(cfg).Classify(getPromptFromInput(input)))
+}
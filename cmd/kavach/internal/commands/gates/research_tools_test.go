@@ -0,0 +1,26 @@
+package gates
+
+import (
+	"testing"
+
+	"github.com/claude/shared/pkg/hook"
+)
+
+func TestResearchRequired(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cases := []struct {
+		prompt string
+		rule   string
+	}{
+		{"deploy to production", "deploy"},
+		{"add a flag", ""},
+		{"implement the parser, create the lexer and add a printer", "implement complexity>=complex"},
+		{"what time is it", ""},
+	}
+	for _, tc := range cases {
+		rule, ok := researchRequired(&hook.Input{Prompt: tc.prompt})
+		if ok != (tc.rule != "") || (ok && rule.String() != tc.rule) {
+			t.Errorf("researchRequired(%q) = %q, %t; want %q", tc.prompt, rule, ok, tc.rule)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package gates provides hook gates for Claude Code.
+// retry.go: Retry loop breaking. The failure gate counts consecutive
+// failures of the same call; past failure.retry_limit it stops suggesting
+// fixes and the chain, bash and enforcer gates ask before the call runs
+// again.
+package gates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/types"
+)
+
+// retryEscalation replaces the fix suggestion once the limit is reached.
+const retryEscalation = "STOP retrying this call. Ask the user, or try a fundamentally different approach"
+
+// retrySignature identifies a call for retry counting: Bash by its
+// whitespace-normalized command, file tools by path (Edit also by
+// old_string), anything else by its full input.
+func retrySignature(toolName string, toolInput map[string]interface{}) string {
+	str := func(key string) string {
+		s, _ := toolInput[key].(string)
+		return s
+	}
+	var key string
+	switch toolName {
+	case "Bash":
+		key = strings.Join(strings.Fields(str("command")), " ")
+	case "Read", "Write":
+		key = str("file_path")
+	case "Edit":
+		key = str("file_path") + "\x00" + str("old_string")
+	default:
+		data, _ := json.Marshal(toolInput) // Map keys marshal sorted
+		key = string(data)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return toolName + ":" + hex.EncodeToString(sum[:6])
+}
+
+// retryLimit is failure.retry_limit, or 0 when loop breaking is off.
+func retryLimit() int {
+	if limit := config.LoadGatesConfig().Failure.RetryLimit; limit > 0 {
+		return limit
+	}
+	return 0
+}
+
+// retryLoopReason returns the ask reason when this exact call already
+// failed retry_limit times in a row, or "".
+func retryLoopReason(input *hook.Input, session *enforce.SessionState) string {
+	limit := retryLimit()
+	if limit == 0 {
+		return ""
+	}
+	n := session.Failures(retrySignature(input.ToolName, input.ToolInput))
+	if n < limit {
+		return ""
+	}
+	return fmt.Sprintf("retry_loop: this %s call failed %d times in a row; %s", input.ToolName, n, retryEscalation)
+}
+
+// checkRetryLoop asks before a call that already failed retry_limit times
+// in a row runs again, and returns otherwise.
+func checkRetryLoop(input *hook.Input, gate string, session *enforce.SessionState) {
+	reason := retryLoopReason(input, session)
+	if reason == "" {
+		return
+	}
+	checkLearning(input, gate, audit.DecisionAsk, "failure.retry_limit")
+	recordDecision(input, gate, audit.DecisionAsk, "failure.retry_limit")
+	hook.Output(types.NewPreToolUseAsk(gate + ": " + reason))
+	hook.Exit()
+}
+
+// clearRetries ends retry tracking when the tracked call succeeds.
+func clearRetries(input *hook.Input, session *enforce.SessionState) {
+	if session.RetryKey != "" {
+		session.ResetFailures(retrySignature(input.ToolName, input.ToolInput))
+	}
+}
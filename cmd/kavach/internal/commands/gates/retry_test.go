@@ -0,0 +1,56 @@
+package gates
+
+import (
+	"testing"
+
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/claude/shared/pkg/hook"
+)
+
+func TestRetrySignature(t *testing.T) {
+	bash := func(cmd string) string {
+		return retrySignature("Bash", map[string]interface{}{"command": cmd})
+	}
+	if bash("go  test ./...") != bash(" go test ./... ") {
+		t.Error("whitespace changed the Bash signature")
+	}
+	if bash("go test ./...") == bash("go test ./cmd/...") {
+		t.Error("different commands share a signature")
+	}
+
+	edit := func(path, old, new string) string {
+		return retrySignature("Edit", map[string]interface{}{"file_path": path, "old_string": old, "new_string": new})
+	}
+	if edit("/a.go", "x", "y") != edit("/a.go", "x", "z") {
+		t.Error("new_string changed the Edit signature")
+	}
+	if edit("/a.go", "x", "y") == edit("/a.go", "w", "y") {
+		t.Error("old_string did not change the Edit signature")
+	}
+
+	other := map[string]interface{}{"pattern": "TODO", "path": "/src"}
+	if retrySignature("Grep", other) != retrySignature("Grep", map[string]interface{}{"path": "/src", "pattern": "TODO"}) {
+		t.Error("input order changed the signature")
+	}
+	if retrySignature("Grep", other) == retrySignature("Glob", other) {
+		t.Error("tools share a signature")
+	}
+}
+
+func TestClearRetries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := enforce.NewSessionState(t.TempDir())
+	failing := &hook.Input{ToolName: "Bash", ToolInput: map[string]interface{}{"command": "make"}}
+	other := &hook.Input{ToolName: "Bash", ToolInput: map[string]interface{}{"command": "ls"}}
+	session.RecordFailure(retrySignature(failing.ToolName, failing.ToolInput))
+	session.RecordFailure(retrySignature(failing.ToolName, failing.ToolInput))
+
+	clearRetries(other, session)
+	if got := session.Failures(retrySignature(failing.ToolName, failing.ToolInput)); got != 2 {
+		t.Fatalf("another call's success cleared failures: %d", got)
+	}
+	clearRetries(failing, session)
+	if session.RetryKey != "" || session.RetryCount != 0 {
+		t.Errorf("success kept failures: %q %d", session.RetryKey, session.RetryCount)
+	}
+}
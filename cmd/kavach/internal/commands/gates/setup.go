@@ -0,0 +1,109 @@
+// Package gates provides hook gates for Claude Code.
+// setup.go: Setup gate. Reports which gates protect the project and, with
+// --init, bootstraps a project gates config (.kavach/gates.json).
+package gates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	setupHookMode bool
+	setupInit     bool
+)
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Setup gate: project gates config and active protections",
+	Long: `[SETUP_GATE]
+desc: Summarize the active gates for the project as Setup context
+hook: Setup
+config: ~/.claude/gates/config.json, tightened by .kavach/gates.json in the project (adds blocks, never loosens)
+init: --init writes an empty project config to .kavach/gates.json if missing (never overwrites)
+
+[USAGE]
+kavach gates setup --hook
+kavach gates setup --hook --init`,
+	Run: runSetupGate,
+}
+
+func init() {
+	setupCmd.Flags().BoolVar(&setupHookMode, "hook", false, "Hook mode")
+	setupCmd.Flags().BoolVar(&setupInit, "init", false, "Create an empty .kavach/gates.json when missing")
+}
+
+func runSetupGate(cmd *cobra.Command, args []string) {
+	if !setupHookMode {
+		cmd.Help()
+		return
+	}
+
+	input := hook.MustReadHookInput()
+	dir := input.Cwd
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	hook.ExitSetup(setupSummary(dir, setupInit))
+}
+
+// setupSummary ensures the project config when init is set and renders
+// the gate summary for the config that applies to dir.
+func setupSummary(dir string, init bool) string {
+	path := config.ProjectGatesConfigPath(dir)
+	state := "missing"
+	if init {
+		if _, created, err := config.InitProjectGatesConfig(dir); err != nil {
+			state = "error: " + err.Error()
+		} else if created {
+			state = "created"
+		}
+	}
+
+	// The project config tightens the user config, as the gates load it
+	source := config.GatesConfigPath()
+	if _, err := os.Stat(path); err == nil {
+		if state == "missing" {
+			state = "present"
+		}
+		source += " + " + path
+	}
+	cfg, err := config.ReadGatesConfigLayers(config.GatesConfigPath(), path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		cfg = config.LoadGatesConfig()
+	case err != nil:
+		state = "invalid: " + err.Error()
+		cfg, source = config.LoadGatesConfig(), config.GatesConfigPath()
+	}
+
+	var active, inactive []string
+	for _, g := range config.ActiveGates(cfg) {
+		if g.Active {
+			active = append(active, g.Name)
+		} else {
+			inactive = append(inactive, g.Name)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[KAVACH_SETUP]\n")
+	fmt.Fprintf(&sb, "project_config: %s (%s)\n", path, state)
+	fmt.Fprintf(&sb, "config: %s\n", source)
+	fmt.Fprintf(&sb, "active: %s\n", strings.Join(active, ","))
+	if len(inactive) > 0 {
+		fmt.Fprintf(&sb, "inactive: %s\n", strings.Join(inactive, ","))
+	}
+	if len(cfg.ToolPolicy) > 0 {
+		fmt.Fprintf(&sb, "tool_policy: %d tools\n", len(cfg.ToolPolicy))
+	}
+	fmt.Fprintf(&sb, "date: %s\n", hook.Today())
+	return sb.String()
+}
@@ -3,6 +3,10 @@
 package gates
 
 import (
+	"strconv"
+	"time"
+
+	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/claude/shared/pkg/patterns"
@@ -17,6 +21,7 @@ var subagentCmd = &cobra.Command{
 	Long: `[SUBAGENT_GATE]
 desc: Track subagent spawning and verify output quality
 hooks: SubagentStart, SubagentStop
+depth: Length of the spawner chain; spawns nested past subagent.max_depth are blocked
 
 [USAGE]
 kavach gates subagent --hook`,
@@ -56,12 +61,17 @@ func handleSubagentStart(input *hook.Input, session *enforce.SessionState) {
 	}
 
 	// Enforce research for engineer-type subagents
-	if isEngineerAgent(agentType) && !session.ResearchDone {
+	if isEngineerAgent(agentType) && !researchSatisfied(input, session) {
 		hook.ExitBlockTOON("SUBAGENT_GATE",
 			"engineer_subagent_requires_research:agent:"+agentType+":id:"+agentID)
 	}
 
-	hook.ExitSubagentStart("[SUBAGENT:START] type:" + agentType + " id:" + agentID)
+	depth := session.EnterSubagent(agentID, time.Now())
+	if max := config.LoadGatesConfig().Subagent.MaxDepth; max >= 0 && depth > max {
+		session.ExitSubagent(agentID)
+		hook.ExitBlockTOON("SUBAGENT_GATE", "subagent_depth_exceeded:depth:"+strconv.Itoa(depth)+":max:"+strconv.Itoa(max))
+	}
+	hook.ExitSubagentStart("[SUBAGENT:START] type:" + agentType + " id:" + agentID + " depth:" + strconv.Itoa(depth))
 }
 
 func handleSubagentStop(input *hook.Input, session *enforce.SessionState) {
@@ -69,7 +79,19 @@ func handleSubagentStop(input *hook.Input, session *enforce.SessionState) {
 	agentID := input.AgentID
 
 	// Log subagent completion for DAG tracking
-	hook.ExitSubagentStop("[SUBAGENT:STOP] type:" + agentType + " id:" + agentID)
+	depth := session.ExitSubagent(agentID)
+	hook.ExitSubagentStop("[SUBAGENT:STOP] type:" + agentType + " id:" + agentID + " depth:" + strconv.Itoa(depth))
+}
+
+// admitSubagent blocks a Task spawn by agent parent ("" = main agent)
+// that would nest a subagent deeper than subagent.max_depth, and otherwise
+// queues it so SubagentStart can attribute the new subagent to parent.
+func admitSubagent(gate string, session *enforce.SessionState, parent string) {
+	max := config.LoadGatesConfig().Subagent.MaxDepth
+	if depth := session.SubagentDepth(parent) + 1; max >= 0 && depth > max {
+		hook.ExitBlockTOON(gate, "subagent_depth_exceeded:depth:"+strconv.Itoa(depth)+":max:"+strconv.Itoa(max))
+	}
+	session.QueueSpawn(parent, time.Now())
 }
 
 // isBuiltinAgent checks for Claude Code built-in agent types.
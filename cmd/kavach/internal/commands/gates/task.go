@@ -10,6 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
 	"github.com/claude/shared/pkg/dag"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
@@ -60,6 +63,35 @@ func runTaskGate(cmd *cobra.Command, args []string) {
 	}
 }
 
+// checkTaskQuality blocks on a placeholder or too-short task subject or
+// description (tasks.action block), or returns the warning for the
+// caller's output (tasks.action warn) so task tracking still runs.
+func checkTaskQuality(input *hook.Input, subject, description string) map[string]string {
+	cfg := config.LoadGatesConfig().Tasks
+	if cfg.Action == "off" {
+		return nil
+	}
+	issue := cfg.Check(subject, description)
+	if issue == "" {
+		return nil
+	}
+
+	rule := "tasks.quality:" + issue
+	reason := "TaskCreate:low_quality_" + strings.Replace(issue, ":", "_", 1)
+	if cfg.Action == "warn" {
+		recordDecision(input, "TASK_GATE", audit.DecisionWarn, rule)
+		return map[string]string{
+			"warn":   "task_quality:" + issue,
+			"hint":   "Name the concrete change and describe scope and done criteria",
+			"source": chain.SourceConfig,
+		}
+	}
+	checkLearning(input, "TASK_GATE", audit.DecisionBlock, rule)
+	recordDecision(input, "TASK_GATE", audit.DecisionBlock, rule)
+	hook.ExitBlockTOONFrom("TASK_GATE", reason, chain.SourceConfig)
+	return nil
+}
+
 // handleTaskCreate validates new task creation
 func handleTaskCreate(input *hook.Input, session *enforce.SessionState) {
 	subject := input.GetString("subject")
@@ -71,6 +103,10 @@ func handleTaskCreate(input *hook.Input, session *enforce.SessionState) {
 	if description == "" {
 		hook.ExitBlockTOON("TASK_GATE", "TaskCreate:missing_description")
 	}
+	var warning map[string]string
+	if input.HookEventName != "PostToolUse" {
+		warning = checkTaskQuality(input, subject, description)
+	}
 
 	// Inject task list context for multi-session awareness
 	taskListID := getTaskListID()
@@ -82,24 +118,26 @@ func handleTaskCreate(input *hook.Input, session *enforce.SessionState) {
 	// Track in health monitoring system
 	health := GetTaskHealth()
 	taskID := generateTaskID(subject)
-	health.TrackTaskCreation(taskID, description, session.SessionID, isBackground)
+	health.TrackTaskCreation(taskID, description, session.ResolveID(), isBackground)
 
 	// Check for headless mode issues
 	if issue := health.DetectHeadlessMode(); issue != nil {
 		metadata := map[string]string{
 			"task_list_id":   taskListID,
 			"created_date":   today,
-			"session_id":     session.SessionID,
+			"session_id":     session.ResolveID(),
 			"health_warning": issue.Description,
 		}
+		mergeAdvisory(metadata, warning)
 		hook.ExitModifyTOON("TASK_CREATE", metadata)
 	}
 
 	metadata := map[string]string{
 		"task_list_id": taskListID,
 		"created_date": today,
-		"session_id":   session.SessionID,
+		"session_id":   session.ResolveID(),
 	}
+	mergeAdvisory(metadata, warning)
 
 	// Only increment on PostToolUse (settings.json fires this gate for both Pre and Post).
 	if input.HookEventName == "PostToolUse" {
@@ -109,8 +147,8 @@ func handleTaskCreate(input *hook.Input, session *enforce.SessionState) {
 	}
 
 	// DAG Scheduler: map Claude task ID to DAG node
-	if state, err := dag.Load(session.SessionID); err == nil {
-		_, _, directive := dag.HandleTaskEvent(state, "TaskCreate", input.ToolInput)
+	if state, err := dag.Load(session.ResolveID()); err == nil {
+		_, _, directive := newDAGBuilder().HandleTaskEvent(state, "TaskCreate", input.ToolInput)
 		if err := dag.Save(state); err != nil {
 			fmt.Fprintf(os.Stderr, "[TASK_DAG] Save error: %v\n", err)
 		}
@@ -154,8 +192,8 @@ func handleTaskUpdate(input *hook.Input, session *enforce.SessionState) {
 	}
 
 	// DAG Scheduler: advance state on task updates
-	if state, err := dag.Load(session.SessionID); err == nil {
-		complete, needsAegis, directive := dag.HandleTaskEvent(state, "TaskUpdate", input.ToolInput)
+	if state, err := dag.Load(session.ResolveID()); err == nil {
+		complete, needsAegis, directive := newDAGBuilder().HandleTaskEvent(state, "TaskUpdate", input.ToolInput)
 		if err := dag.Save(state); err != nil {
 			fmt.Fprintf(os.Stderr, "[TASK_DAG] Save error: %v\n", err)
 		}
@@ -0,0 +1,119 @@
+// Package gates provides hook gates for Claude Code.
+// verify.go: Post-write Aegis verification (PostToolUse:Write|Edit|NotebookEdit).
+// Defense-in-depth: scans written files as they landed on disk, and the
+// text each edit introduced.
+package gates
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/hook"
+	"github.com/claude/shared/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// maxVerifyBytes bounds how much of a written file is re-scanned.
+const maxVerifyBytes = 1 << 20
+
+var verifyHookMode bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Post-write Aegis verification of written content",
+	Long: `[VERIFY_GATE]
+desc: Re-run Aegis on the written result (secrets, dangerous content)
+hook: PostToolUse:Write|Edit|NotebookEdit
+action: PostToolUse block context asking the model to revert/clean up
+
+[USAGE]
+kavach gates verify --hook`,
+	Run: runVerifyGate,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyHookMode, "hook", false, "Hook mode")
+}
+
+func runVerifyGate(cmd *cobra.Command, args []string) {
+	if !verifyHookMode {
+		cmd.Help()
+		return
+	}
+
+	input := hook.MustReadHookInput()
+	runPostAegisCheck(input)
+	hook.ExitSilent()
+}
+
+// runPostAegisCheck re-runs Aegis on written content.
+// Exits with a PostToolUse block when a violation is found.
+func runPostAegisCheck(input *hook.Input) {
+	switch input.ToolName {
+	case "Write", "Edit", "MultiEdit", "NotebookEdit":
+	default:
+		return
+	}
+	content := writtenContent(input)
+	if content == "" {
+		return
+	}
+
+	cfg := config.LoadGatesConfig()
+	aegis := chain.AegisVerifyOutput(content, cfg.Write.SecretPatterns)
+	if aegis.Passed {
+		return
+	}
+
+	filePath := input.GetString("file_path")
+	context := "[AEGIS:POST_WRITE]\n" +
+		"file: " + filePath + "\n" +
+		"threat: " + aegis.ThreatLevel + "\n" +
+		"violations: " + strings.Join(chain.CapFindings(aegis.ViolationsFound, cfg.Aegis.MaxFindings), ",") + "\n" +
+		"action: " + strings.Join(chain.CapFindings(aegis.Recommendations, cfg.Aegis.MaxFindings), "; ") + "\n"
+	hook.Output(types.NewPostToolUseBlock("AEGIS: "+aegis.ViolationsFound[0]+" written to "+filePath, context))
+	hook.Exit()
+}
+
+// writtenContent returns what actually landed. Edits are scanned by the
+// text they introduced, so content already in the file is not re-flagged
+// on every edit; a Write by the resulting file on disk (its first
+// maxVerifyBytes), then tool_response text, then the tool input.
+func writtenContent(input *hook.Input) string {
+	switch input.ToolName {
+	case "Edit":
+		return input.GetString("new_string")
+	case "MultiEdit":
+		edits, _ := input.ToolInput["edits"].([]interface{})
+		var added []string
+		for _, e := range edits {
+			if m, ok := e.(map[string]interface{}); ok {
+				if s, _ := m["new_string"].(string); s != "" {
+					added = append(added, s)
+				}
+			}
+		}
+		return strings.Join(added, "\n")
+	case "NotebookEdit":
+		return input.GetString("new_source")
+	}
+	if filePath := input.GetString("file_path"); filePath != "" {
+		if f, err := os.Open(filePath); err == nil {
+			data, err := io.ReadAll(io.LimitReader(f, maxVerifyBytes))
+			f.Close()
+			if err == nil && len(data) > 0 {
+				return string(data)
+			}
+		}
+	}
+	if text := input.GetToolResponseText(); text != "" {
+		return text
+	}
+	if c, ok := input.ToolResponse["content"].(string); ok && c != "" {
+		return c
+	}
+	return input.GetString("content")
+}
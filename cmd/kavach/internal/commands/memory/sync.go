@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/claude/cmd/kavach/internal/commands/gates"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/claude/shared/pkg/util"
@@ -126,9 +127,12 @@ func syncFileChange(input *hook.Input, project, today string) {
 	if filePath == "" {
 		return
 	}
-	// Track modified file in session state for task scoping
+	// Track modified file in session state for task scoping; this also
+	// exempts the session's own edits from the enforcer's uncommitted check
+	// and counts the write towards edit churn
 	session := enforce.GetOrCreateSession()
 	session.AddFileModified(filePath)
+	session.RecordEdit(filePath)
 
 	appendToSTMLog(project, today, "file_"+input.ToolName, filePath, "")
 }
@@ -138,6 +142,9 @@ func syncBashResult(input *hook.Input, project, today string) {
 	if command == "" {
 		return
 	}
+	// A test run or commit verifies the files edited so far
+	gates.ResetEditChurn(enforce.GetOrCreateSession(), command)
+
 	// Only log significant commands (builds, tests, deploys)
 	for _, sig := range []string{"build", "test", "deploy", "cargo", "go ", "bun ", "npm ", "git commit"} {
 		if containsStr(command, sig) {
@@ -78,8 +78,8 @@ func runAegisCmd(cmd *cobra.Command, args []string) {
 		// Hook mode - read from stdin
 		input := hook.MustReadHookInput()
 
-		// PostToolUse provides ToolResponse (map), not a string field
-		if input.ToolResponse == nil || len(input.ToolResponse) == 0 {
+		// PostToolUse provides ToolResponse (map, or string/array text)
+		if len(input.ToolResponse) == 0 && input.GetToolResponseText() == "" {
 			hook.ExitSilent()
 			return
 		}
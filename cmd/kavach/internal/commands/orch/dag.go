@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/claude/shared/pkg/dag"
 	"github.com/claude/shared/pkg/enforce"
@@ -15,6 +16,13 @@ import (
 var dagStatusFlag bool
 var dagResetFlag bool
 var dagVisualizeFlag bool
+var dagWatchFlag bool
+var dagCostFlag bool
+var dagHistoryFlag bool
+var dagReplanFlag bool
+var dagResumeProjectFlag bool
+var dagExportFlag string
+var dagFilterFlag string
 
 var dagOrcCmd = &cobra.Command{
 	Use:   "dag",
@@ -23,8 +31,16 @@ var dagOrcCmd = &cobra.Command{
 desc: Inspect and manage parallel task DAG state
 usage:
   kavach orch dag --status     Show current DAG state
+  kavach orch dag --filter area=payments  Only nodes with that label (key alone: any value)
   kavach orch dag --reset      Clear DAG for session
-  kavach orch dag --visualize  ASCII visualization`,
+  kavach orch dag --visualize  ASCII visualization
+  kavach orch dag --watch      Live visualization until completion
+  kavach orch dag --cost       Estimated vs actual tokens per node
+  kavach orch dag --history    Status transitions per node, for stuck runs
+  kavach orch dag --replan     Re-decompose failed subtrees, keeping completed work
+  kavach orch dag --resume-project  Continue the project's latest unfinished DAG
+  kavach orch dag --export json  Nodes, edges and levels for external runners
+  kavach orch dag list --since 24h  Recent DAGs`,
 	Run: runDAGOrch,
 }
 
@@ -32,11 +48,18 @@ func init() {
 	dagOrcCmd.Flags().BoolVar(&dagStatusFlag, "status", false, "Show current DAG state")
 	dagOrcCmd.Flags().BoolVar(&dagResetFlag, "reset", false, "Clear DAG for session")
 	dagOrcCmd.Flags().BoolVar(&dagVisualizeFlag, "visualize", false, "ASCII DAG visualization")
+	dagOrcCmd.Flags().BoolVar(&dagWatchFlag, "watch", false, "Redraw the visualization every second until complete")
+	dagOrcCmd.Flags().BoolVar(&dagCostFlag, "cost", false, "Estimated vs actual token/cost report")
+	dagOrcCmd.Flags().BoolVar(&dagHistoryFlag, "history", false, "Show each node's status transitions")
+	dagOrcCmd.Flags().BoolVar(&dagReplanFlag, "replan", false, "Replace failed and skipped subtrees with a fresh sub-plan")
+	dagOrcCmd.Flags().StringVar(&dagExportFlag, "export", "", "Export the DAG as adjacency (json)")
+	dagOrcCmd.Flags().StringVar(&dagFilterFlag, "filter", "", "Status view: only nodes labeled key=value (or having key)")
+	dagOrcCmd.Flags().BoolVar(&dagResumeProjectFlag, "resume-project", false, "Carry the project's latest unfinished DAG into this session")
 }
 
 func runDAGOrch(cmd *cobra.Command, args []string) {
 	session := enforce.GetOrCreateSession()
-	sid := session.SessionID
+	sid := session.ResolveID()
 
 	if dagResetFlag {
 		if err := dag.Delete(sid); err != nil {
@@ -47,32 +70,117 @@ func runDAGOrch(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if dagResumeProjectFlag {
+		resumeProjectDAG(sid, session.Project)
+		return
+	}
+
 	state, err := dag.Load(sid)
 	if err != nil {
 		fmt.Println("[DAG] No active DAG for this session")
 		return
 	}
 
+	if dagExportFlag != "" {
+		exportDAG(state, dagExportFlag)
+		return
+	}
+
+	if dagCostFlag {
+		printDAGCost(state)
+		return
+	}
+
+	if dagReplanFlag {
+		replanDAG(state)
+		return
+	}
+
+	if dagHistoryFlag {
+		printDAGHistory(state)
+		return
+	}
+
+	if dagWatchFlag {
+		watchDAG(sid, state)
+		return
+	}
+
 	if dagVisualizeFlag {
 		visualize(state)
 		return
 	}
 
 	// Default: --status
-	fmt.Printf("[DAG_STATE]\nid: %s\nsession: %s\nstatus: %s\nlevels: %d\nnodes: %d\n\n",
+	fmt.Printf("[DAG_STATE]\nid: %s\nsession: %s\nstatus: %s\nlevels: %d\nnodes: %d\n",
 		state.ID, state.SessionID, state.Status, state.MaxLevel+1, len(state.Nodes))
-	for _, n := range state.Nodes {
+	if state.ProjectID != "" {
+		fmt.Printf("project: %s\n", state.ProjectID)
+	}
+	if state.ParentDAG != "" {
+		fmt.Printf("parent_dag: %s\n", state.ParentDAG)
+	}
+	nodes := state.SortedNodes()
+	if dagFilterFlag != "" {
+		key, value, _ := strings.Cut(dagFilterFlag, "=")
+		nodes = dag.FindNodesByLabel(state, key, value)
+		fmt.Printf("filter: %s (%d of %d)\n", dagFilterFlag, len(nodes), len(state.Nodes))
+	}
+	fmt.Println()
+	for _, n := range nodes {
 		deps := "none"
 		if len(n.DependsOn) > 0 {
-			deps = strings.Join(n.DependsOn, ",")
+			deps = strings.Join(depLabels(n), ",")
+		}
+		fmt.Printf("  [%s] %s (L%d) status=%s deps=%s%s\n", n.ID, n.Subject, n.Level, n.Status, deps, nodeTokens(n))
+	}
+	if c := state.Cost(); !c.Empty() {
+		fmt.Printf("\ntokens: %s\n", c)
+	}
+}
+
+// printDAGHistory lists every node's transitions and how long it has
+// been in its current status.
+func printDAGHistory(state *dag.DAGState) {
+	fmt.Printf("[DAG_HISTORY]\nid: %s\n\n", state.ID)
+	for _, n := range state.SortedNodes() {
+		since := ""
+		if at, ok := n.EnteredAt[n.Status]; ok {
+			since = " for " + time.Since(at).Round(time.Second).String()
+		}
+		fmt.Printf("  [%s] %s status=%s%s\n", n.ID, n.Subject, n.Status, since)
+		for _, tr := range n.History {
+			fmt.Printf("    %s %s -> %s\n", tr.At.Format(time.RFC3339), tr.From, tr.To)
+		}
+	}
+}
+
+// depLabels marks conditional deps as id?status/result ("id?" = optional)
+// and OR-group members as id|group.
+func depLabels(n *dag.Node) []string {
+	labels := make([]string, len(n.DependsOn))
+	for i, dep := range n.DependsOn {
+		labels[i] = dep
+		if group, ok := n.AnyOf[dep]; ok {
+			labels[i] += "|" + group
+		}
+		if cond, ok := n.Conditions[dep]; ok {
+			var parts []string
+			if cond.Status != "" {
+				parts = append(parts, string(cond.Status))
+			}
+			if cond.Result != "" {
+				parts = append(parts, "result="+cond.Result)
+			}
+			labels[i] += "?" + strings.Join(parts, "/")
 		}
-		fmt.Printf("  [%s] %s (L%d) status=%s deps=%s\n", n.ID, n.Subject, n.Level, n.Status, deps)
 	}
+	return labels
 }
 
 func visualize(state *dag.DAGState) {
 	levels := make(map[int][]*dag.Node)
-	for _, n := range state.Nodes {
+	for _, n := range state.SortedNodes() {
 		levels[n.Level] = append(levels[n.Level], n)
 	}
 	for l := 0; l <= state.MaxLevel; l++ {
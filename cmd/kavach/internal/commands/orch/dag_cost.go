@@ -0,0 +1,40 @@
+// Package orch provides orchestration subcommands.
+// dag_cost.go: Token/cost report for the session DAG (--cost).
+package orch
+
+import (
+	"fmt"
+
+	"github.com/claude/shared/pkg/dag"
+)
+
+// printDAGCost lists estimated vs used tokens per node, in level order.
+func printDAGCost(state *dag.DAGState) {
+	nodes := state.SortedNodes()
+
+	c := state.Cost()
+	fmt.Printf("[DAG_COST]\nid: %s\nstatus: %s\ntokens_estimated: %d\ntokens_used: %d\n", state.ID, state.Status, c.Estimated, c.Used)
+	if c.Estimated > 0 {
+		fmt.Printf("variance: %+d (%.0f%%)\n", c.Used-c.Estimated, float64(c.Used-c.Estimated)*100/float64(c.Estimated))
+	}
+	if c.CostCents > 0 {
+		fmt.Printf("cost: $%d.%02d\n", c.CostCents/100, c.CostCents%100)
+	}
+	fmt.Printf("reported: %d/%d\n\n", c.Reported, c.Nodes)
+
+	for _, n := range nodes {
+		fmt.Printf("  [%s] L%d %s estimate=%d used=%d", n.ID, n.Level, n.Status, n.TokenEstimate, n.TokensUsed)
+		if n.CostCents > 0 {
+			fmt.Printf(" cost_cents=%d", n.CostCents)
+		}
+		fmt.Println()
+	}
+}
+
+// nodeTokens is the --status suffix for nodes with cost fields.
+func nodeTokens(n *dag.Node) string {
+	if n.TokenEstimate == 0 && n.TokensUsed == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" tokens=%d/%d", n.TokensUsed, n.TokenEstimate)
+}
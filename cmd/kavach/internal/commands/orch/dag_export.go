@@ -0,0 +1,27 @@
+// Package orch provides orchestration subcommands.
+// dag_export.go: Machine-readable DAG export for external runners (--export).
+package orch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/claude/shared/pkg/dag"
+)
+
+// exportDAG writes the adjacency form of state to stdout in format.
+func exportDAG(state *dag.DAGState, format string) {
+	if format != "json" {
+		fmt.Fprintf(os.Stderr, "[DAG] Unknown export format %q (supported: json)\n", format)
+		os.Exit(1)
+	}
+	adj, err := dag.ToAdjacency(state)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[DAG] Export failed: %v\n", err)
+		os.Exit(1)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(adj)
+}
@@ -0,0 +1,56 @@
+// Package orch provides orchestration subcommands.
+// dag_list.go: List persisted DAG states with a --since filter.
+package orch
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/claude/shared/pkg/dag"
+	"github.com/spf13/cobra"
+)
+
+var dagListSince time.Duration
+
+var dagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List persisted DAG states",
+	Long: `[DAG_LIST]
+desc: One line per persisted DAG (~/.claude/dag), newest first
+fields: time, session, status, done/total nodes, levels
+
+usage:
+  kavach orch dag list              # All DAGs
+  kavach orch dag list --since 24h  # DAGs from the last day`,
+	Run: runDAGList,
+}
+
+func init() {
+	dagListCmd.Flags().DurationVar(&dagListSince, "since", 0, "Only DAGs modified within this duration (e.g. 24h)")
+	dagOrcCmd.AddCommand(dagListCmd)
+}
+
+func runDAGList(cmd *cobra.Command, args []string) {
+	dir := dag.StateDir()
+	states, err := dag.List(dagListSince)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[DAG] List error: %v\n", err)
+		return
+	}
+
+	fmt.Println("[DAG_LIST]")
+	fmt.Printf("dir: %s\n", dir)
+	if dagListSince > 0 {
+		fmt.Printf("since: %s\n", dagListSince)
+	}
+	fmt.Printf("count: %d\n", len(states))
+	if len(states) == 0 {
+		return
+	}
+	fmt.Println()
+	for _, s := range states {
+		fmt.Printf("%s session=%s id=%s status=%s nodes=%d/%d levels=%d\n",
+			s.Time.Format(time.RFC3339), s.SessionID, s.ID, s.Status, s.Done, s.Nodes, s.Levels)
+	}
+}
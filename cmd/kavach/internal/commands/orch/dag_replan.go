@@ -0,0 +1,37 @@
+// Package orch provides orchestration subcommands.
+// dag_replan.go: Replaces failed subtrees of the session's DAG with a
+// fresh sub-plan, keeping completed nodes.
+package orch
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/claude/shared/pkg/dag"
+)
+
+// replanDAG re-decomposes every failed subtree and prints the dispatch
+// directive for the nodes that are ready.
+func replanDAG(state *dag.DAGState) {
+	results, err := dag.Replan(state, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[DAG] Replan %s: %v\n", state.ID, err)
+		os.Exit(1)
+	}
+	if err := dag.Save(state); err != nil {
+		fmt.Fprintf(os.Stderr, "[DAG] Save: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("[DAG_REPLANNED]\nid: %s\nsubtrees: %d\n", state.ID, len(results))
+	for i, res := range results {
+		added := make([]string, len(res.Added))
+		for j, n := range res.Added {
+			added[j] = n.ID
+		}
+		fmt.Printf("subtree_%d: removed=%s added=%s\n", i+1, strings.Join(res.Removed, ","), strings.Join(added, ","))
+	}
+	fmt.Println()
+	fmt.Print(dag.BuildDirective(state))
+}
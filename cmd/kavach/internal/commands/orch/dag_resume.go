@@ -0,0 +1,47 @@
+// Package orch provides orchestration subcommands.
+// dag_resume.go: Resume a project's unfinished DAG in the current session.
+package orch
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/claude/shared/pkg/dag"
+)
+
+// resumeProjectDAG links the project's latest unfinished DAG from another
+// session into sid and prints the dispatch directive for its ready nodes.
+func resumeProjectDAG(sid, project string) {
+	if project == "" {
+		fmt.Fprintln(os.Stderr, "[DAG] No project detected for this session")
+		os.Exit(1)
+	}
+	if current, err := dag.Load(sid); err == nil && current.Unfinished() {
+		fmt.Printf("[DAG] Session already has an unfinished DAG: %s\n", current.ID)
+		return
+	}
+
+	parent, err := dag.LatestUnfinished(project, sid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[DAG] %v\n", err)
+		os.Exit(1)
+	}
+	if parent == nil {
+		fmt.Printf("[DAG] No unfinished DAG for project %s\n", project)
+		return
+	}
+
+	state, err := dag.Link(parent, sid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[DAG] Link %s: %v\n", parent.ID, err)
+		os.Exit(1)
+	}
+	if err := dag.Save(state); err != nil {
+		fmt.Fprintf(os.Stderr, "[DAG] Save: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("[DAG_RESUMED]\nid: %s\nproject: %s\nparent_dag: %s\nparent_session: %s\ncarried: %d/%d\n\n",
+		state.ID, project, parent.ID, parent.SessionID, len(state.Nodes), len(parent.Nodes))
+	fmt.Print(dag.BuildDirective(state))
+}
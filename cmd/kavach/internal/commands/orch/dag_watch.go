@@ -0,0 +1,76 @@
+// Package orch provides orchestration subcommands.
+// dag_watch.go: Live-redrawing DAG level view (--watch).
+package orch
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/claude/shared/pkg/dag"
+)
+
+const (
+	dagWatchInterval = time.Second
+	ansiClear        = "\033[H\033[2J"
+	ansiHideCursor   = "\033[?25l"
+	ansiShowCursor   = "\033[?25h"
+)
+
+// watchDAG redraws the session DAG every second until every node is
+// terminal, the DAG is reset, or the user interrupts.
+func watchDAG(sid string, state *dag.DAGState) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	fmt.Print(ansiHideCursor)
+	defer fmt.Print(ansiShowCursor)
+
+	ticker := time.NewTicker(dagWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		drawDAGWatch(state)
+		if state.IsComplete() || state.Status != dag.DAGActive {
+			fmt.Printf("\n[DAG] %s\n", watchEndStatus(state))
+			return
+		}
+
+		select {
+		case <-interrupt:
+			fmt.Println("\n[DAG] watch stopped")
+			return
+		case <-ticker.C:
+		}
+
+		next, err := dag.Load(sid)
+		if err != nil {
+			fmt.Println("\n[DAG] state removed, watch stopped")
+			return
+		}
+		state = next
+	}
+}
+
+func drawDAGWatch(state *dag.DAGState) {
+	done := 0
+	for _, n := range state.Nodes {
+		if n.Status.IsTerminal() {
+			done++
+		}
+	}
+	fmt.Print(ansiClear)
+	fmt.Printf("[DAG_WATCH] id=%s status=%s done=%d/%d updated=%s (Ctrl-C to exit)\n\n",
+		state.ID, state.Status, done, len(state.Nodes), time.Now().Format("15:04:05"))
+	visualize(state)
+}
+
+func watchEndStatus(state *dag.DAGState) string {
+	if state.Status == dag.DAGActive {
+		return string(dag.DAGComplete)
+	}
+	return string(state.Status)
+}
@@ -6,7 +6,10 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/claude/cmd/kavach/internal/commands/agentic"
 	"github.com/claude/cmd/kavach/internal/commands/agents"
+	"github.com/claude/cmd/kavach/internal/commands/chain"
+	"github.com/claude/cmd/kavach/internal/commands/config"
 	"github.com/claude/cmd/kavach/internal/commands/gates"
 	"github.com/claude/cmd/kavach/internal/commands/lint"
 	"github.com/claude/cmd/kavach/internal/commands/memory"
@@ -14,6 +17,7 @@ import (
 	"github.com/claude/cmd/kavach/internal/commands/quality"
 	"github.com/claude/cmd/kavach/internal/commands/session"
 	"github.com/claude/cmd/kavach/internal/commands/skills"
+	"github.com/claude/shared/pkg/dag"
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/spf13/cobra"
 )
@@ -62,6 +66,9 @@ gates:    Hook enforcement (PreToolUse, PostToolUse)
 memory:   Query/write memory bank, context injection
 session:  Lifecycle management (init, validate, end)
 orch:     Multi-agent orchestration, verification
+config:   Inspect and tune gates config
+agentic:  Dynamic loader cache status and reset
+chain:    Persisted verification chain runs
 status:   System health check
 agents:   List available agents with models
 skills:   List available skills
@@ -79,12 +86,16 @@ PostToolUseFailure:  gates failure --hook
 SubagentStart:       gates subagent --hook
 SubagentStop:        gates subagent --hook
 PermissionRequest:   gates read --hook
+Setup:               gates setup --hook (--init creates .kavach/gates.json)
 Stop:                session end
 PreCompact:          session compact
 
 [EXAMPLES]
 kavach status                              # System health
 kavach gates enforcer --hook < input.json  # Full pipeline
+kavach gates chain --hook --input input.json # Replay saved input
+kavach config diff                         # Effective config vs defaults
+kavach agentic status --trigger cargo      # Is a skill trigger indexed
 kavach memory bank                         # Query memory bank
 kavach session init                        # Start session`,
 }
@@ -94,7 +105,9 @@ func Execute(version string) error {
 	rootCmd.Version = version
 	handleSymlinkDispatch()
 	registerSubcommands()
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	dag.FlushEvents() // Commands returning normally skip hook.Exit
+	return err
 }
 
 // handleSymlinkDispatch rewrites args if invoked via symlink.
@@ -112,11 +125,17 @@ func registerSubcommands() {
 	memory.Register(memoryCmd)
 	session.Register(sessionCmd)
 	orch.Register(orchCmd)
+	config.Register(configCmd)
+	agentic.Register(agenticCmd)
+	chain.Register(chainCmd)
 
 	rootCmd.AddCommand(gatesCmd)
 	rootCmd.AddCommand(memoryCmd)
 	rootCmd.AddCommand(sessionCmd)
 	rootCmd.AddCommand(orchCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(agenticCmd)
+	rootCmd.AddCommand(chainCmd)
 	rootCmd.AddCommand(statusCmd)
 
 	// DACE: Dynamic agents and skills (micro-modular)
@@ -157,7 +176,7 @@ var gatesCmd = &cobra.Command{
 	Short: "Enforcement gates (ceo, ast, bash, read, etc.)",
 	Long: `[GATES]
 desc: Hook-based enforcement for PreToolUse/PostToolUse
-input: JSON via stdin (HookInput format)
+input: JSON via stdin (HookInput format), or --input <file>
 output: JSON decision (approve/block)
 
 [AVAILABLE_GATES]
@@ -172,6 +191,7 @@ lint:      Code style and lint checking
 research:  TABULA_RASA enforcement (WebSearch before code)
 content:   Content validation for writes
 quality:   Code quality checks (AST + lint chain)
+verify:    Post-write Aegis re-scan of written content
 
 [WHEN_TO_USE]
 PreToolUse:      gates enforcer --hook (recommended)
@@ -213,6 +233,7 @@ init:     Initialize session (injects today's date, loads context)
 validate: Validate session state
 end:      End session, persist state
 compact:  Pre-compact save (before context compaction)
+health:   Dashboard of research, decisions, chain runs and DAG progress
 
 [WHEN_TO_USE]
 SessionStart:     session init
@@ -238,6 +259,52 @@ PostToolUse:Write: orch aegis --hook (verify code quality)
 TaskComplete:      orch post (final verification)`,
 }
 
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Gates configuration tools",
+	Long: `[CONFIG]
+desc: Inspect and tune ~/.claude/gates/config.json
+
+[AVAILABLE_COMMANDS]
+diff:    Effective config vs defaults, with value sources
+suggest: JSON Patch from audit history (promote warns, demote overridden blocks and asks)
+template: Every field with its default and docs (JSONC or Markdown)
+
+[WHEN_TO_USE]
+Debugging: config diff (why is this blocked/allowed)
+Tuning:    config suggest (after a few sessions of audit data)
+Authoring: config template (annotated reference of every field)`,
+}
+
+var agenticCmd = &cobra.Command{
+	Use:   "agentic",
+	Short: "Dynamic loader cache tools",
+	Long: `[AGENTIC]
+desc: Inspect and reset the DynamicLoader (lazy agents/skills) cache
+
+[AVAILABLE_COMMANDS]
+status: Loaded agents/skills, memory estimate, trigger index size
+clear:  Drop cached agents, skills and trigger index
+
+[WHEN_TO_USE]
+Skill not firing: agentic status --trigger <keyword>
+Memory growth:    agentic status, then agentic clear`,
+}
+
+var chainCmd = &cobra.Command{
+	Use:   "chain",
+	Short: "Verification chain state tools",
+	Long: `[CHAIN]
+desc: Inspect persisted verification chain runs (~/.claude/chain)
+hook: kavach gates chain --hook runs the chain itself
+
+[AVAILABLE_COMMANDS]
+list:     One line per run (--since 24h to filter)
+replay:   Re-run a saved run against the current config, show the diff
+classify: Intent type/risk for a file of prompts, with histograms
+topology: Configured pipeline as a Mermaid or dot diagram`,
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show system status (SP/1.0 TOON format)",
@@ -0,0 +1,161 @@
+// Package session provides session management subcommands.
+// health.go: One dashboard over the session, audit, chain and DAG stores.
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/claude/shared/pkg/audit"
+	"github.com/claude/shared/pkg/chain"
+	"github.com/claude/shared/pkg/dag"
+	"github.com/claude/shared/pkg/enforce"
+	"github.com/spf13/cobra"
+)
+
+// healthMaxFailures bounds the RECENT_FAILURES section.
+const healthMaxFailures = 5
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Consolidated session dashboard (research, decisions, chain, DAG)",
+	Long: `[HEALTH]
+desc: Read-only view stitched from the session state, the audit log
+      (~/.claude/audit), persisted chain runs (~/.claude/chain) and the DAG
+
+[SECTIONS]
+SESSION:         research/memory/CEO flags, risk score, turns
+DECISIONS_TODAY: block/ask/warn/override counts today (this session and all)
+CHAIN:           runs this session and the latest verdict
+DAG:             nodes done/total, failed and in flight
+RECENT_FAILURES: latest blocked or asking gates with their suggested fix
+
+[USAGE]
+kavach session health`,
+	Run: runHealthCmd,
+}
+
+func runHealthCmd(cmd *cobra.Command, args []string) {
+	session := enforce.GetOrCreateSession()
+
+	fmt.Println("[SESSION_HEALTH]")
+	fmt.Println("session: " + session.ResolveID())
+	fmt.Println("project: " + session.Project)
+	fmt.Println("today: " + session.Today)
+	fmt.Println()
+
+	fmt.Println("[SESSION]")
+	fmt.Printf("research_done: %t\n", session.ResearchDone)
+	fmt.Printf("memory_queried: %t\n", session.MemoryQueried)
+	fmt.Printf("ceo_invoked: %t\n", session.CEOInvoked)
+	fmt.Printf("risk_score: %d\n", session.RiskScore)
+	fmt.Printf("turns: %d\n", session.TurnCount)
+	fmt.Printf("tasks: %d/%d completed\n", session.TasksCompleted, session.TasksCreated)
+	fmt.Println()
+
+	printDecisionsToday(session.ResolveID())
+	fmt.Println()
+	failures := printChainHealth(session.ResolveID())
+	fmt.Println()
+	printDAGHealth(session.ResolveID())
+
+	fmt.Println()
+	fmt.Println("[RECENT_FAILURES]")
+	if len(failures) == 0 {
+		fmt.Println("none")
+	}
+	for _, f := range failures {
+		fmt.Printf("%s %s %s: %s\n", f.time.Format("15:04:05"), f.result.Gate, f.result.Status, f.result.Reason)
+		if f.result.NextAction != "" {
+			fmt.Printf("  fix: %s\n", f.result.NextAction)
+		}
+	}
+}
+
+// printDecisionsToday counts today's audit decisions, for sessionID and overall.
+func printDecisionsToday(sessionID string) {
+	fmt.Println("[DECISIONS_TODAY]")
+	records, err := audit.ReadAll()
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	y, m, d := time.Now().Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	session := make(map[string]int)
+	all := make(map[string]int)
+	for _, r := range records {
+		if r.Time.Before(midnight) {
+			continue
+		}
+		all[r.Decision]++
+		if sessionID != "" && r.SessionID == sessionID {
+			session[r.Decision]++
+		}
+	}
+	for _, decision := range []string{audit.DecisionBlock, audit.DecisionAsk, audit.DecisionWarn, audit.DecisionOverride, audit.DecisionLearn, audit.DecisionAdvisory} {
+		fmt.Printf("%s: %d (all sessions: %d)\n", decision, session[decision], all[decision])
+	}
+}
+
+// chainFailure is a non-passing gate result from a persisted chain run.
+type chainFailure struct {
+	time   time.Time
+	result chain.VerificationResult
+}
+
+// printChainHealth summarizes this session's chain runs and returns the
+// most recent block/ask results, newest first.
+func printChainHealth(sessionID string) []chainFailure {
+	fmt.Println("[CHAIN]")
+	states, err := chain.ListStates(chain.StateDir(), 0)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return nil
+	}
+	var failures []chainFailure
+	runs := 0
+	for _, s := range states {
+		if s.SessionID != sessionID {
+			continue
+		}
+		runs++
+		if runs == 1 {
+			fmt.Printf("latest: %s status=%s gates=%d\n", s.Time.Format(time.RFC3339), s.FinalStatus, s.Gates)
+		}
+		if len(failures) >= healthMaxFailures || s.FinalStatus == "approved" {
+			continue
+		}
+		state, err := chain.LoadState(s.Path)
+		if err != nil {
+			continue
+		}
+		for _, r := range state.Results {
+			if (r.Status == "block" || r.Status == "ask") && len(failures) < healthMaxFailures {
+				failures = append(failures, chainFailure{time: s.Time, result: r})
+			}
+		}
+	}
+	fmt.Printf("runs: %d\n", runs)
+	return failures
+}
+
+// printDAGHealth reports progress of the session's DAG, if any.
+func printDAGHealth(sessionID string) {
+	fmt.Println("[DAG]")
+	state, err := dag.Load(sessionID)
+	if err != nil {
+		fmt.Println("active: none")
+		return
+	}
+	counts := make(map[dag.NodeStatus]int)
+	for _, n := range state.Nodes {
+		counts[n.Status]++
+	}
+	fmt.Printf("id: %s\n", state.ID)
+	fmt.Printf("status: %s\n", state.Status)
+	fmt.Printf("nodes: %d/%d done\n", counts[dag.StatusDone], len(state.Nodes))
+	fmt.Printf("in_flight: %d\n", counts[dag.StatusDispatched]+counts[dag.StatusRunning])
+	fmt.Printf("failed: %d\n", counts[dag.StatusFailed])
+	fmt.Printf("skipped: %d\n", counts[dag.StatusSkipped])
+}
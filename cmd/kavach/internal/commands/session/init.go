@@ -53,12 +53,23 @@ func runInitCmd(cmd *cobra.Command, args []string) {
 		"type":       getSessionType(session),
 	})
 
+	// Subagents do not outlive their session's start (any source)
+	session.ResetSubagents()
+
 	// Detect post-compact mode and handle specially
 	if session.IsPostCompact() {
 		runPostCompactInit(ctx, session, bank)
 		return
 	}
 
+	// Fresh session (startup/clear): reset the cumulative risk budget
+	session.ResetRisk()
+	session.ResetRecommendations()
+	session.ResetAdvisories()
+	session.ResetReads()
+	session.ResetFailures("")
+	session.ResetEdits()
+
 	// DACE: Ultra-minimal output (~100 tokens)
 	fmt.Println("[META]")
 	fmt.Printf("protocol: SP/1.0\ndate: %s\nsession: %s\ntype: %s\n\n",
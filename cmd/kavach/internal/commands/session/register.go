@@ -10,6 +10,7 @@ func Register(sessionCmd *cobra.Command) {
 	sessionCmd.AddCommand(endCmd)
 	sessionCmd.AddCommand(compactCmd)
 	sessionCmd.AddCommand(resumeCmd)
+	sessionCmd.AddCommand(healthCmd)
 	sessionCmd.AddCommand(landCmd)           // Beads-inspired "land the plane" protocol
 	sessionCmd.AddCommand(sessionEndHookCmd) // SessionEnd lifecycle hook
 }
@@ -4,13 +4,19 @@
 package session
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/claude/shared/pkg/enforce"
 	"github.com/claude/shared/pkg/hook"
 	"github.com/spf13/cobra"
 )
 
+var sessionEndJSON bool
+
 var sessionEndHookCmd = &cobra.Command{
 	Use:   "end-hook",
 	Short: "SessionEnd lifecycle hook (memory sync + cleanup)",
@@ -20,10 +26,38 @@ hook: SessionEnd
 note: Cannot block session termination
 
 [USAGE]
-kavach session end-hook`,
+kavach session end-hook
+kavach session end-hook --json
+
+[ANALYTICS]
+Every run also writes the summary to ~/.claude/analytics/session_<id>.json`,
 	Run: runSessionEndHook,
 }
 
+func init() {
+	sessionEndHookCmd.Flags().BoolVar(&sessionEndJSON, "json", false, "Print the summary as one JSON object")
+}
+
+// SessionSummary is the end-of-session record printed by --json and
+// persisted for analytics.
+type SessionSummary struct {
+	Date           string    `json:"date"`
+	EndedAt        time.Time `json:"ended_at"`
+	Session        string    `json:"session"`
+	Project        string    `json:"project"`
+	Reason         string    `json:"reason"`
+	ResearchDone   bool      `json:"research_done"`
+	Memory         bool      `json:"memory"`
+	CEO            bool      `json:"ceo"`
+	Aegis          bool      `json:"aegis"`
+	TasksCreated   int       `json:"tasks_created"`
+	TasksCompleted int       `json:"tasks_completed"`
+	SubagentDepth  int       `json:"subagent_depth"`
+	RiskScore      int       `json:"risk_score"`
+	CompactCount   int       `json:"compact_count"`
+	TurnCount      int       `json:"turn_count"`
+}
+
 func runSessionEndHook(cmd *cobra.Command, args []string) {
 	input := hook.MustReadHookInput()
 	ctx := enforce.NewContext()
@@ -37,15 +71,71 @@ func runSessionEndHook(cmd *cobra.Command, args []string) {
 	// Persist final session state
 	session.Save()
 
+	summary := SessionSummary{
+		Date:           ctx.Today,
+		EndedAt:        time.Now().UTC(),
+		Session:        session.ID,
+		Project:        session.Project,
+		Reason:         reason,
+		ResearchDone:   session.ResearchDone,
+		Memory:         session.MemoryQueried,
+		CEO:            session.CEOInvoked,
+		Aegis:          session.AegisVerified,
+		TasksCreated:   session.TasksCreated,
+		TasksCompleted: session.TasksCompleted,
+		SubagentDepth:  session.MaxSubagentDepth(),
+		RiskScore:      session.RiskScore,
+		CompactCount:   session.CompactCount,
+		TurnCount:      session.TurnCount,
+	}
+	if summary.Session == "" {
+		summary.Session = input.SessionID
+	}
+	if err := saveSessionSummary(&summary); err != nil {
+		fmt.Fprintf(os.Stderr, "[SESSION_END] analytics: %v\n", err)
+	}
+
+	if sessionEndJSON {
+		// One line so analytics pipelines can consume it as JSONL
+		data, _ := json.Marshal(summary)
+		fmt.Println(string(data))
+		return
+	}
+
 	// Output cleanup summary
 	fmt.Println("[SESSION_END]")
 	fmt.Printf("date: %s\nsession: %s\nproject: %s\nreason: %s\n\n",
-		ctx.Today, session.ID, session.Project, reason)
+		summary.Date, summary.Session, summary.Project, summary.Reason)
 
 	fmt.Println("[FINAL_STATE]")
 	fmt.Printf("research_done: %s\nmemory: %s\nceo: %s\naegis: %s\n",
-		boolStr(session.ResearchDone), boolStr(session.MemoryQueried),
-		boolStr(session.CEOInvoked), boolStr(session.AegisVerified))
+		boolStr(summary.ResearchDone), boolStr(summary.Memory),
+		boolStr(summary.CEO), boolStr(summary.Aegis))
 	fmt.Printf("tasks_created: %d\ntasks_completed: %d\n",
-		session.TasksCreated, session.TasksCompleted)
+		summary.TasksCreated, summary.TasksCompleted)
+	fmt.Printf("subagent_depth: %d\n", summary.SubagentDepth)
+}
+
+// analyticsDir holds one summary file per ended session.
+func analyticsDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude", "analytics")
+}
+
+// saveSessionSummary writes s to analyticsDir()/session_<id>.json,
+// replacing the file if the session ends more than once.
+func saveSessionSummary(s *SessionSummary) error {
+	id := s.Session
+	if id == "" {
+		id = "unknown"
+	}
+	dir := analyticsDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "session_"+filepath.Base(id)+".json"), data, 0o600)
 }
@@ -2,6 +2,8 @@
 package agentic
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -248,6 +250,134 @@ func BenchmarkRoute(b *testing.B) {
 	}
 }
 
+// =============================================================================
+// DynamicLoader Cache Tests
+// =============================================================================
+
+func TestDynamicLoaderStatsAndClear(t *testing.T) {
+	skillDir := t.TempDir()
+	for name, body := range map[string]string{
+		"rust":  "description: Rust patterns\ntriggers: cargo, rustc\n",
+		"react": "description: React patterns\ntriggers: jsx\n",
+	} {
+		os.MkdirAll(filepath.Join(skillDir, name), 0755)
+		os.WriteFile(filepath.Join(skillDir, name, "SKILL.md"), []byte(body), 0644)
+	}
+
+	dl := NewDynamicLoader(t.TempDir(), skillDir)
+	if failed := dl.WarmSkills(); len(failed) != 0 {
+		t.Fatalf("WarmSkills failed: %v", failed)
+	}
+
+	stats := dl.Stats()
+	if len(stats.LoadedSkills) != 2 || stats.LoadedSkills[0] != "react" {
+		t.Errorf("LoadedSkills = %v, want [react rust]", stats.LoadedSkills)
+	}
+	if stats.TriggerIndexSize != 3 {
+		t.Errorf("TriggerIndexSize = %d, want 3", stats.TriggerIndexSize)
+	}
+	if stats.MemoryBytes == 0 {
+		t.Error("MemoryBytes should be non-zero after warm")
+	}
+	if dl.FindSkillByTrigger("cargo") != "rust" {
+		t.Error("cargo should be indexed to rust")
+	}
+
+	if _, skills := dl.Clear(); skills != 2 {
+		t.Errorf("Clear() skills = %d, want 2", skills)
+	}
+	stats = dl.Stats()
+	if len(stats.LoadedSkills) != 0 || stats.TriggerIndexSize != 0 || stats.MemoryBytes != 0 {
+		t.Errorf("expected empty cache after Clear, got %+v", stats)
+	}
+}
+
+func TestIndexSaveLoadRemove(t *testing.T) {
+	skillDir := t.TempDir()
+	os.MkdirAll(filepath.Join(skillDir, "rust"), 0755)
+	os.WriteFile(filepath.Join(skillDir, "rust", "SKILL.md"), []byte("triggers: cargo, rustc\npriority: 3\n"), 0644)
+
+	path := filepath.Join(t.TempDir(), "agentic", "triggers.json")
+	warm := NewDynamicLoader(t.TempDir(), skillDir)
+	warm.WarmSkills()
+	if err := warm.SaveIndex(path); err != nil {
+		t.Fatalf("SaveIndex: %v", err)
+	}
+
+	cold := NewDynamicLoader(t.TempDir(), skillDir)
+	if err := cold.LoadIndex(path); err != nil {
+		t.Fatalf("LoadIndex: %v", err)
+	}
+	if got := cold.FindSkillByTrigger("cargo"); got != "rust" {
+		t.Errorf("loaded index: cargo -> %q, want rust", got)
+	}
+
+	// Clear deletes the stored index; a later load finds nothing
+	if removed, err := RemoveIndex(path); !removed || err != nil {
+		t.Fatalf("RemoveIndex = %v, %v; want true, nil", removed, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("index still on disk after RemoveIndex: %v", err)
+	}
+	if removed, err := RemoveIndex(path); removed || err != nil {
+		t.Errorf("second RemoveIndex = %v, %v; want false, nil", removed, err)
+	}
+	fresh := NewDynamicLoader(t.TempDir(), skillDir)
+	if err := fresh.LoadIndex(path); err != nil {
+		t.Fatalf("LoadIndex of missing file: %v", err)
+	}
+	if fresh.Stats().TriggerIndexSize != 0 {
+		t.Error("index reloaded after RemoveIndex")
+	}
+}
+
+func TestDetectTriggerConflicts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	skillDir := t.TempDir()
+	for name, body := range map[string]string{
+		"rust":   "triggers: cargo, build\npriority: 5\n",
+		"build":  "triggers: build, make\n",
+		"golang": "triggers: build\npriority: 5\n",
+	} {
+		os.MkdirAll(filepath.Join(skillDir, name), 0755)
+		os.WriteFile(filepath.Join(skillDir, name, "SKILL.md"), []byte(body), 0644)
+	}
+
+	dl := NewDynamicLoader(t.TempDir(), skillDir)
+	dl.WarmSkills()
+
+	conflicts := dl.DetectTriggerConflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %v", conflicts)
+	}
+	if got := strings.Join(conflicts["build"], ","); got != "build,golang,rust" {
+		t.Errorf("conflicts[build] = %s, want build,golang,rust", got)
+	}
+
+	// Priority 5 beats 0; golang and rust tie, name breaks it
+	if got := dl.FindSkillByTrigger("build"); got != "golang" {
+		t.Errorf("FindSkillByTrigger(build) = %s, want golang", got)
+	}
+	if got := dl.FindSkillByTrigger("make"); got != "build" {
+		t.Errorf("FindSkillByTrigger(make) = %s, want build", got)
+	}
+}
+
+func TestResolveAgent(t *testing.T) {
+	agentDir := t.TempDir()
+	os.WriteFile(filepath.Join(agentDir, "backend.md"),
+		[]byte("---\nname: backend\nmodel: sonnet\nskills: [rust, debug]\n---\n"), 0644)
+
+	dl := NewDynamicLoader(agentDir, t.TempDir())
+	model, skills, ok := dl.ResolveAgent("backend")
+	if !ok || model != "sonnet" || strings.Join(skills, ",") != "rust,debug" {
+		t.Errorf("ResolveAgent(backend) = %q, %v, %v", model, skills, ok)
+	}
+	if _, _, ok := dl.ResolveAgent("missing"); ok {
+		t.Error("ResolveAgent(missing) should not resolve")
+	}
+}
+
 func BenchmarkExtractFramework(b *testing.B) {
 	task := "build react frontend with kubernetes deployment and terraform infrastructure"
 
@@ -266,3 +396,42 @@ func BenchmarkCheckForbiddenPhrases(b *testing.B) {
 		gate.CheckForForbiddenPhrases(text)
 	}
 }
+
+func TestEvaluateAutoInvoke(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	skillDir := t.TempDir()
+	for name, body := range map[string]string{
+		"rust":     "triggers: cargo, build\npriority: 5\nauto_invoke: true\n",
+		"golang":   "triggers: build, go mod\nauto_invoke: true\n",
+		"security": "triggers: secret, token\npriority: 9\nauto_invoke: yes\n",
+		"docs":     "triggers: readme\n",
+		"sql":      "triggers: query\nauto_invoke: true\n",
+	} {
+		os.MkdirAll(filepath.Join(skillDir, name), 0755)
+		os.WriteFile(filepath.Join(skillDir, name, "SKILL.md"), []byte(body), 0644)
+	}
+	dl := NewDynamicLoader(t.TempDir(), skillDir)
+
+	names := func(skills []*SkillDef) string {
+		var out []string
+		for _, s := range skills {
+			out = append(out, s.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// "build" is shared: rust wins on priority, golang still matches "go mod"
+	got := dl.EvaluateAutoInvokeN("Build the crate, run go mod tidy and rotate the token", 0)
+	if n := names(got); n != "security,rust,golang" {
+		t.Errorf("EvaluateAutoInvokeN = %s, want security,rust,golang", n)
+	}
+
+	// Non auto_invoke skills and partial words never match
+	if n := names(dl.EvaluateAutoInvoke("update the readme and rebuild tokens")); n != "" {
+		t.Errorf("EvaluateAutoInvoke = %s, want none", n)
+	}
+
+	if got := dl.EvaluateAutoInvokeN("cargo build, query the token, go mod", 2); names(got) != "security,rust" {
+		t.Errorf("capped = %s, want security,rust", names(got))
+	}
+}
@@ -0,0 +1,89 @@
+// Package agentic provides Dynamic Agentic Context Engineering.
+// autoinvoke.go: Pick auto_invoke skills whose triggers appear in a prompt.
+package agentic
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MaxAutoInvoke caps how many skills a single prompt may auto-invoke.
+const MaxAutoInvoke = 3
+
+// EvaluateAutoInvoke returns the auto_invoke skills triggered by prompt,
+// capped at MaxAutoInvoke.
+func (dl *DynamicLoader) EvaluateAutoInvoke(prompt string) []*SkillDef {
+	return dl.EvaluateAutoInvokeN(prompt, MaxAutoInvoke)
+}
+
+// EvaluateAutoInvokeN warms the skill index, matches triggers against prompt
+// on word boundaries and returns the auto_invoke winners, highest priority
+// first (ties by name). A shared trigger yields only its priority winner, so
+// a losing skill is not invoked through it. limit <= 0 means no cap.
+func (dl *DynamicLoader) EvaluateAutoInvokeN(prompt string, limit int) []*SkillDef {
+	dl.WarmSkills()
+	lower := strings.ToLower(prompt)
+
+	dl.mu.RLock()
+	var matched []string
+	for trigger := range dl.skillIndex {
+		if containsWord(lower, strings.ToLower(trigger)) {
+			matched = append(matched, trigger)
+		}
+	}
+	dl.mu.RUnlock()
+	sort.Strings(matched)
+
+	seen := make(map[string]bool)
+	var out []*SkillDef
+	for _, trigger := range matched {
+		name := dl.FindSkillByTrigger(trigger)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		skill, err := dl.GetSkill(name)
+		if err != nil || !skill.AutoInvoke {
+			continue
+		}
+		out = append(out, skill)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority > out[j].Priority
+		}
+		return out[i].Name < out[j].Name
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// containsWord reports whether word occurs in s bounded by non-alphanumerics.
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for from := 0; ; {
+		i := strings.Index(s[from:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(word)
+		if !isWordByte(s, start-1) && !isWordByte(s, end) {
+			return true
+		}
+		from = start + 1
+	}
+}
+
+func isWordByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	r := rune(s[i])
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,81 @@
+// Package agentic provides Dynamic Agentic Context Engineering.
+// index.go: Persisted trigger index (~/.claude/agentic/triggers.json), so
+// status --cold and later runs see what the last warm indexed.
+package agentic
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// IndexPath returns where the trigger index is persisted.
+func IndexPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude", "agentic", "triggers.json")
+}
+
+// storedIndex is the on-disk form of the trigger index.
+type storedIndex struct {
+	Triggers map[string][]string `json:"triggers"`
+	Priority map[string]int      `json:"priority,omitempty"`
+	Saved    time.Time           `json:"saved"`
+}
+
+// SaveIndex writes the current trigger index to path.
+func (dl *DynamicLoader) SaveIndex(path string) error {
+	dl.mu.RLock()
+	idx := storedIndex{Triggers: dl.skillIndex, Priority: dl.priority, Saved: time.Now()}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	dl.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadIndex merges the trigger index saved at path into the loader.
+// A missing file is not an error: nothing has been saved yet.
+func (dl *DynamicLoader) LoadIndex(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var idx storedIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return err
+	}
+
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	for trigger, names := range idx.Triggers {
+		for _, name := range names {
+			if !containsString(dl.skillIndex[trigger], name) {
+				dl.skillIndex[trigger] = append(dl.skillIndex[trigger], name)
+			}
+		}
+	}
+	for name, p := range idx.Priority {
+		dl.priority[name] = p
+	}
+	return nil
+}
+
+// RemoveIndex deletes the trigger index saved at path. removed is false
+// when there was nothing to delete.
+func RemoveIndex(path string) (removed bool, err error) {
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
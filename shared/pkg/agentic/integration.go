@@ -126,6 +126,11 @@ func (sys *AgenticSystem) Stats() map[string]interface{} {
 	}
 }
 
+// Loader returns the underlying dynamic loader.
+func (sys *AgenticSystem) Loader() *DynamicLoader {
+	return sys.loader
+}
+
 // ProcessRequest handles a complete request through the agentic pipeline.
 // This is the main entry point for integrating with Go binaries.
 func (sys *AgenticSystem) ProcessRequest(ctx context.Context, req *Request) (*Response, error) {
@@ -5,10 +5,13 @@ package agentic
 import (
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/claude/shared/pkg/dsa"
+	"github.com/claude/shared/pkg/logger"
 )
 
 // AgentDef represents a dynamically loaded agent definition.
@@ -26,6 +29,7 @@ type SkillDef struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
 	Triggers    []string `json:"triggers"`
+	Priority    int      `json:"priority"` // Higher wins shared triggers
 	AutoInvoke  bool     `json:"auto_invoke"`
 	Content     string   `json:"-"` // Loaded on demand
 	Loaded      bool     `json:"-"`
@@ -38,7 +42,8 @@ type DynamicLoader struct {
 	skillDir   string
 	agents     *dsa.LazyMap[string, *AgentDef]
 	skills     *dsa.LazyMap[string, *SkillDef]
-	skillIndex map[string]string // trigger -> skill name
+	skillIndex map[string][]string // trigger -> skill names claiming it
+	priority   map[string]int      // skill name -> priority, for conflicts
 	mu         sync.RWMutex
 }
 
@@ -47,7 +52,8 @@ func NewDynamicLoader(agentDir, skillDir string) *DynamicLoader {
 	dl := &DynamicLoader{
 		agentDir:   agentDir,
 		skillDir:   skillDir,
-		skillIndex: make(map[string]string),
+		skillIndex: make(map[string][]string),
+		priority:   make(map[string]int),
 	}
 
 	// Create lazy agent loader
@@ -82,6 +88,8 @@ func (dl *DynamicLoader) loadAgent(name string) (*AgentDef, error) {
 	}
 	// Extract description from first line after ---
 	agent.Description = extractDescription(string(data))
+	agent.Model = extractField(string(data), "model:")
+	agent.Skills = extractList(string(data), "skills:")
 
 	return agent, nil
 }
@@ -101,11 +109,16 @@ func (dl *DynamicLoader) loadSkill(name string) (*SkillDef, error) {
 	}
 	skill.Description = extractDescription(string(data))
 	skill.Triggers = extractTriggers(string(data))
+	skill.Priority = extractPriority(string(data))
+	skill.AutoInvoke = extractBool(string(data), "auto_invoke:")
 
-	// Index triggers for fast lookup
+	// Index triggers for fast lookup; shared triggers keep every claimant
 	dl.mu.Lock()
+	dl.priority[name] = skill.Priority
 	for _, trigger := range skill.Triggers {
-		dl.skillIndex[trigger] = name
+		if !containsString(dl.skillIndex[trigger], name) {
+			dl.skillIndex[trigger] = append(dl.skillIndex[trigger], name)
+		}
 	}
 	dl.mu.Unlock()
 
@@ -124,10 +137,63 @@ func (dl *DynamicLoader) GetSkill(name string) (*SkillDef, error) {
 
 // FindSkillByTrigger finds a skill that matches a trigger keyword.
 // Returns the skill name if found, empty string otherwise.
+// When several skills claim the trigger, the highest priority wins
+// (ties broken by name) and the disambiguation is logged.
 func (dl *DynamicLoader) FindSkillByTrigger(trigger string) string {
+	dl.mu.RLock()
+	names := dl.skillIndex[trigger]
+	if len(names) <= 1 {
+		dl.mu.RUnlock()
+		if len(names) == 1 {
+			return names[0]
+		}
+		return ""
+	}
+	best := dl.resolveLocked(names)
+	dl.mu.RUnlock()
+
+	logger.Info("agentic", "trigger conflict resolved",
+		"trigger", trigger, "candidates", strings.Join(names, ","), "chosen", best)
+	return best
+}
+
+// resolveLocked picks the highest-priority skill. Caller holds dl.mu.
+func (dl *DynamicLoader) resolveLocked(names []string) string {
+	best := names[0]
+	for _, name := range names[1:] {
+		p, bp := dl.priority[name], dl.priority[best]
+		if p > bp || (p == bp && name < best) {
+			best = name
+		}
+	}
+	return best
+}
+
+// DetectTriggerConflicts reports triggers claimed by more than one loaded
+// skill. Skill names are sorted; only skills already loaded are considered.
+func (dl *DynamicLoader) DetectTriggerConflicts() map[string][]string {
 	dl.mu.RLock()
 	defer dl.mu.RUnlock()
-	return dl.skillIndex[trigger]
+
+	conflicts := make(map[string][]string)
+	for trigger, names := range dl.skillIndex {
+		if len(names) > 1 {
+			sorted := append([]string(nil), names...)
+			sort.Strings(sorted)
+			conflicts[trigger] = sorted
+		}
+	}
+	return conflicts
+}
+
+// ResolveAgent returns the model and skills declared by an agent file.
+// Satisfies dag.AgentResolver; ok is false when the agent can't be loaded.
+func (dl *DynamicLoader) ResolveAgent(name string) (model string, skills []string, ok bool) {
+	agent, err := dl.GetAgent(name)
+	if err != nil || agent == nil {
+		return "", nil, false
+	}
+	return agent.Model, agent.Skills, true
 }
 
 // IsAgentLoaded checks if an agent is currently in memory.
@@ -150,12 +216,105 @@ func (dl *DynamicLoader) LoadedSkills() []string {
 	return dl.skills.LoadedKeys()
 }
 
+// LoaderStats summarizes what the loader currently holds in memory.
+type LoaderStats struct {
+	LoadedAgents     []string
+	LoadedSkills     []string
+	TriggerIndexSize int
+	MemoryBytes      int // Estimate: skill content plus descriptions and triggers
+}
+
+// Stats returns a snapshot of the loader cache.
+func (dl *DynamicLoader) Stats() LoaderStats {
+	stats := LoaderStats{
+		LoadedAgents: dl.LoadedAgents(),
+		LoadedSkills: dl.LoadedSkills(),
+	}
+	sort.Strings(stats.LoadedAgents)
+	sort.Strings(stats.LoadedSkills)
+
+	for _, name := range stats.LoadedAgents {
+		if agent, err := dl.agents.Get(name); err == nil && agent != nil {
+			stats.MemoryBytes += len(agent.Name) + len(agent.Description)
+		}
+	}
+	for _, name := range stats.LoadedSkills {
+		if skill, err := dl.skills.Get(name); err == nil && skill != nil {
+			stats.MemoryBytes += len(skill.Name) + len(skill.Description) + len(skill.Content)
+		}
+	}
+
+	dl.mu.RLock()
+	stats.TriggerIndexSize = len(dl.skillIndex)
+	for trigger, names := range dl.skillIndex {
+		stats.MemoryBytes += len(trigger)
+		for _, name := range names {
+			stats.MemoryBytes += len(name)
+		}
+	}
+	dl.mu.RUnlock()
+
+	return stats
+}
+
+// Triggers returns a copy of the trigger -> skill names index.
+func (dl *DynamicLoader) Triggers() map[string][]string {
+	dl.mu.RLock()
+	defer dl.mu.RUnlock()
+	out := make(map[string][]string, len(dl.skillIndex))
+	for trigger, names := range dl.skillIndex {
+		out[trigger] = append([]string(nil), names...)
+	}
+	return out
+}
+
+// WarmSkills loads every skill directory containing a SKILL.md so the
+// trigger index is complete. Returns the names that failed to load.
+func (dl *DynamicLoader) WarmSkills() []string {
+	entries, err := os.ReadDir(dl.skillDir)
+	if err != nil {
+		return nil
+	}
+	var failed []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dl.skillDir, e.Name(), "SKILL.md")); err != nil {
+			continue
+		}
+		if _, err := dl.skills.Get(e.Name()); err != nil {
+			failed = append(failed, e.Name())
+		}
+	}
+	return failed
+}
+
+// Clear drops all cached agents, skills and the trigger index.
+// Returns the number of agents and skills that were loaded.
+func (dl *DynamicLoader) Clear() (agents, skills int) {
+	agents = dl.agents.Clear()
+	skills = dl.skills.Clear()
+
+	dl.mu.Lock()
+	dl.skillIndex = make(map[string][]string)
+	dl.priority = make(map[string]int)
+	dl.mu.Unlock()
+
+	return agents, skills
+}
+
 // Helper: extract description from markdown content
 func extractDescription(content string) string {
+	return extractField(content, "description:")
+}
+
+// Helper: extract the value of the first "key: value" line.
+func extractField(content, key string) string {
 	for _, line := range strings.Split(content, "\n") {
 		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "description:") {
-			return strings.TrimSpace(strings.TrimPrefix(trimmed, "description:"))
+		if strings.HasPrefix(trimmed, key) {
+			return strings.TrimSpace(strings.TrimPrefix(trimmed, key))
 		}
 	}
 	return ""
@@ -164,20 +323,50 @@ func extractDescription(content string) string {
 // Helper: extract triggers from skill content.
 // Looks for "triggers:" line followed by comma-separated values.
 func extractTriggers(content string) []string {
-	var triggers []string
+	return extractList(content, "triggers:")
+}
+
+// Helper: extract a comma-separated list from the first "key:" line.
+// Tolerates YAML inline brackets: skills: [rust, debug].
+func extractList(content, key string) []string {
+	var items []string
+	raw := strings.Trim(extractField(content, key), "[]")
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.Trim(strings.TrimSpace(t), `"'`); t != "" {
+			items = append(items, t)
+		}
+	}
+	return items
+}
+
+// Helper: extract "priority: N" from skill content (default 0).
+func extractPriority(content string) int {
 	for _, line := range strings.Split(content, "\n") {
 		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "triggers:") {
-			raw := strings.TrimPrefix(trimmed, "triggers:")
-			for _, t := range strings.Split(raw, ",") {
-				if t = strings.TrimSpace(t); t != "" {
-					triggers = append(triggers, t)
-				}
-			}
-			break
+		if strings.HasPrefix(trimmed, "priority:") {
+			p, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(trimmed, "priority:")))
+			return p
+		}
+	}
+	return 0
+}
+
+// Helper: extract a "key: true|yes" flag (default false).
+func extractBool(content, key string) bool {
+	switch strings.ToLower(extractField(content, key)) {
+	case "true", "yes":
+		return true
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
 		}
 	}
-	return triggers
+	return false
 }
 
 // splitLines removed: replaced by strings.Split(s, "\n") at call sites
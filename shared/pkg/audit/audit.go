@@ -0,0 +1,190 @@
+// Package audit provides a JSONL log of gate decisions.
+// audit.go: Record persistence at ~/.claude/audit/decisions.jsonl.
+// DACE: Append-only; consumers (config suggest) aggregate on demand.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/claude/shared/pkg/config"
+)
+
+// Decision values recorded by gates.
+const (
+	DecisionWarn     = "warn"
+	DecisionAsk      = "ask"
+	DecisionBlock    = "block"
+	DecisionOverride = "override" // Tool ran after a prior block/ask for the same action
+	DecisionLearn    = "learn"    // Rule in its learning period matched; Would is what it would have done
+	DecisionAdvisory = "advisory" // Block/ask not enforced in advisory mode; Would is what it would have done
+)
+
+// Record is a single gate decision.
+type Record struct {
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id,omitempty"`
+	Gate      string    `json:"gate"`
+	Decision  string    `json:"decision"`
+	Rule      string    `json:"rule,omitempty"` // Config list and entry, e.g. "bash.warn_commands:sudo"
+	ToolName  string    `json:"tool_name,omitempty"`
+	ToolUseID string    `json:"tool_use_id,omitempty"`
+	Subject   string    `json:"subject,omitempty"` // Command or path the rule matched
+	Diff      string    `json:"diff,omitempty"`    // Edit/MultiEdit line summary, e.g. "+3 -1 (net +2) in 1 edit"
+	Would     string    `json:"would,omitempty"`   // Learn and advisory records: the block/ask not enforced
+}
+
+// maxSubjectLen bounds stored commands/paths.
+const maxSubjectLen = 200
+
+// Path returns the audit log path.
+func Path() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude", "audit", "decisions.jsonl")
+}
+
+// Append writes a record to the audit log. Time defaults to now.
+func Append(rec Record) error {
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	rec.Subject = storedSubject(rec.Subject)
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// storedSubject is the subject as written: secrets redacted, then bounded.
+func storedSubject(subject string) string {
+	subject = config.RedactString(subject)
+	if len(subject) > maxSubjectLen {
+		subject = subject[:maxSubjectLen]
+	}
+	return subject
+}
+
+// ReadAll returns every record in the audit log.
+// A missing log returns no records and no error.
+func ReadAll() ([]Record, error) {
+	return ReadRecent(0)
+}
+
+// ReadRecent returns the last n records (n <= 0 returns all). For n > 0
+// only the end of the log is read, growing back until it holds n records.
+// Malformed lines are skipped.
+func ReadRecent(n int) ([]Record, error) {
+	f, err := os.Open(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	window := size
+	if n > 0 {
+		window = min(int64(n)*recordSizeHint, size)
+	}
+	for {
+		if _, err := f.Seek(-window, io.SeekEnd); err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		if window < size {
+			// Drop the line the window starts inside
+			if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+				data = data[idx+1:]
+			} else {
+				data = nil
+			}
+		}
+		records := parseRecords(data, n)
+		if window == size || len(records) >= n {
+			return records, nil
+		}
+		window = min(window*2, size)
+	}
+}
+
+// recordSizeHint is the typical encoded record size, for sizing tail reads.
+const recordSizeHint = 512
+
+// parseRecords decodes the JSONL records in data, keeping the last n
+// (n <= 0: all).
+func parseRecords(data []byte, n int) []Record {
+	var records []Record
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var rec Record
+		if len(line) == 0 || json.Unmarshal(line, &rec) != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if n > 0 && len(records) > n {
+		records = records[len(records)-n:]
+	}
+	return records
+}
+
+// FindPriorDenial returns the latest block/ask record for the same action:
+// same ToolUseID, or same session, tool and subject. Returns nil if none,
+// or if that denial was already followed by a recorded override.
+func FindPriorDenial(records []Record, sessionID, toolUseID, toolName, subject string) *Record {
+	subject = storedSubject(subject)
+	for i := len(records) - 1; i >= 0; i-- {
+		r := &records[i]
+		sameAction := (toolUseID != "" && r.ToolUseID == toolUseID) ||
+			(subject != "" && r.SessionID == sessionID && r.ToolName == toolName && r.Subject == subject)
+		if !sameAction {
+			continue
+		}
+		switch r.Decision {
+		case DecisionOverride:
+			return nil
+		case DecisionBlock, DecisionAsk:
+			return r
+		}
+	}
+	return nil
+}
+
+// SessionOverrides returns the distinct rules overridden in a session,
+// in first-seen order.
+func SessionOverrides(records []Record, sessionID string) []string {
+	var rules []string
+	seen := make(map[string]bool)
+	for _, r := range records {
+		if r.Decision != DecisionOverride || r.SessionID != sessionID || r.Rule == "" || seen[r.Rule] {
+			continue
+		}
+		seen[r.Rule] = true
+		rules = append(rules, r.Rule)
+	}
+	return rules
+}
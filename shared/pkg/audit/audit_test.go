@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/claude/shared/pkg/config"
+	"github.com/claude/shared/pkg/patterns"
+)
+
+func TestAppendRedactsSecrets(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	token := "ghp_" + strings.Repeat("a", 36)
+	cmd := "curl -H 'Authorization: token " + token + "' https://api.github.com"
+	if err := Append(Record{Gate: "BASH", Decision: DecisionAsk, SessionID: "s1", ToolName: "Bash", Subject: cmd}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	recs, _ := ReadAll()
+	if len(recs) != 1 || strings.Contains(recs[0].Subject, token) || !strings.Contains(recs[0].Subject, patterns.RedactedPlaceholder) {
+		t.Fatalf("stored subject = %+v", recs)
+	}
+	// Lookups with the raw command still match the redacted record
+	if FindPriorDenial(recs, "s1", "", "Bash", cmd) == nil {
+		t.Error("prior denial not found for raw subject")
+	}
+}
+
+func TestAppendReadRecent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if recs, err := ReadAll(); err != nil || len(recs) != 0 {
+		t.Fatalf("missing log: got %d records, err %v", len(recs), err)
+	}
+	for _, d := range []string{DecisionWarn, DecisionBlock, DecisionAsk} {
+		if err := Append(Record{Gate: "BASH", Decision: d, ToolName: "Bash"}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	all, err := ReadAll()
+	if err != nil || len(all) != 3 {
+		t.Fatalf("ReadAll = %d records, err %v", len(all), err)
+	}
+	if all[0].Time.IsZero() {
+		t.Error("Append should default Time")
+	}
+	recent, _ := ReadRecent(2)
+	if len(recent) != 2 || recent[0].Decision != DecisionBlock || recent[1].Decision != DecisionAsk {
+		t.Errorf("ReadRecent(2) = %+v", recent)
+	}
+
+	// Records longer than the size hint, and malformed lines, grow the tail
+	f, err := os.OpenFile(Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(strings.Repeat("not json\n", 200))
+	f.Close()
+	for i := 0; i < 3; i++ {
+		Append(Record{Gate: "BASH", Decision: DecisionWarn, Rule: strings.Repeat("r", 2*recordSizeHint)})
+	}
+	recent, err = ReadRecent(4)
+	if err != nil || len(recent) != 4 || recent[0].Decision != DecisionAsk || recent[3].Rule == "" {
+		t.Errorf("ReadRecent(4) = %d records, err %v; want the ask and 3 long warns", len(recent), err)
+	}
+}
+
+func TestFindPriorDenial(t *testing.T) {
+	records := []Record{
+		{SessionID: "s1", Decision: DecisionWarn, ToolName: "Bash", Subject: "sudo ls"},
+		{SessionID: "s1", Decision: DecisionAsk, ToolName: "Bash", Subject: "sudo ls", Rule: "bash.warn_commands:sudo"},
+		{SessionID: "s2", Decision: DecisionBlock, ToolUseID: "tu_1", Rule: "bash.blocked_commands:curl | bash"},
+	}
+	if r := FindPriorDenial(records, "s1", "", "Bash", "sudo ls"); r == nil || r.Decision != DecisionAsk {
+		t.Errorf("subject match = %+v, want ask record", r)
+	}
+	if r := FindPriorDenial(records, "s9", "tu_1", "Bash", ""); r == nil || r.Decision != DecisionBlock {
+		t.Errorf("tool_use_id match = %+v, want block record", r)
+	}
+	if r := FindPriorDenial(records, "s2", "", "Bash", "sudo ls"); r != nil {
+		t.Errorf("other session should not match, got %+v", r)
+	}
+
+	records = append(records, Record{SessionID: "s1", Decision: DecisionOverride, ToolName: "Bash", Subject: "sudo ls"})
+	if r := FindPriorDenial(records, "s1", "", "Bash", "sudo ls"); r != nil {
+		t.Errorf("denial already overridden should not match again, got %+v", r)
+	}
+}
+
+func TestSessionOverrides(t *testing.T) {
+	records := []Record{
+		{SessionID: "s1", Decision: DecisionOverride, Rule: "bash.warn_commands:sudo"},
+		{SessionID: "s1", Decision: DecisionBlock, Rule: "bash.blocked_commands:rm -rf /"},
+		{SessionID: "s2", Decision: DecisionOverride, Rule: "read.blocked_paths:/.ssh/"},
+		{SessionID: "s1", Decision: DecisionOverride, Rule: "bash.warn_commands:sudo"},
+		{SessionID: "s1", Decision: DecisionOverride, Rule: "read.warn_patterns:.env"},
+	}
+	got := SessionOverrides(records, "s1")
+	if len(got) != 2 || got[0] != "bash.warn_commands:sudo" || got[1] != "read.warn_patterns:.env" {
+		t.Errorf("SessionOverrides(s1) = %v", got)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	file := map[string]interface{}{"bash": map[string]interface{}{
+		"blocked_commands": []interface{}{"rm -rf /", "curl | bash"},
+		"warn_commands":    []interface{}{"sudo", "npm publish"},
+	}}
+	cfg := &config.GatesConfig{
+		Bash: config.BashConfig{
+			BlockedCommands: []string{"rm -rf /", "curl | bash"},
+			WarnCommands:    []string{"sudo", "npm publish"},
+		},
+		Ask: config.AskConfig{Commands: []string{"git push --force", "terraform apply"}},
+	}
+	target := PatchTarget{File: file, Config: cfg}
+	records := []Record{
+		{SessionID: "a", Decision: DecisionWarn, Rule: "bash.warn_commands:npm publish"},
+		{SessionID: "b", Decision: DecisionWarn, Rule: "bash.warn_commands:npm publish"},
+		{SessionID: "a", Decision: DecisionWarn, Rule: "bash.warn_commands:sudo"},
+		{SessionID: "a", Decision: DecisionOverride, Rule: "bash.blocked_commands:curl | bash"},
+		{SessionID: "b", Decision: DecisionOverride, Rule: "bash.blocked_commands:curl | bash"},
+	}
+
+	got := Suggest(records, target, SuggestOptions{MinWarnSessions: 2, MinOverrides: 2})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", got)
+	}
+	if got[0].Rule != "bash.blocked_commands:curl | bash" || got[0].Action != "demote" {
+		t.Errorf("got[0] = %+v, want demote curl | bash", got[0])
+	}
+	if got[1].Rule != "bash.warn_commands:npm publish" || got[1].Action != "promote" {
+		t.Errorf("got[1] = %+v, want promote npm publish", got[1])
+	}
+
+	want := []PatchOp{
+		{Op: "remove", Path: "/bash/blocked_commands/1"},
+		{Op: "remove", Path: "/bash/warn_commands/1"},
+		{Op: "add", Path: "/bash/blocked_commands/-", Value: "npm publish"},
+		{Op: "add", Path: "/bash/warn_commands/-", Value: "curl | bash"},
+	}
+	if patch := Patch(got, target); !reflect.DeepEqual(patch, want) {
+		t.Errorf("patch = %+v, want %+v", patch, want)
+	}
+
+	// Indexes come from the file, not the loaded config
+	file["bash"].(map[string]interface{})["blocked_commands"] = []interface{}{"curl | bash"}
+	want = []PatchOp{
+		{Op: "remove", Path: "/bash/blocked_commands/0"},
+		{Op: "add", Path: "/bash/warn_commands/-", Value: "curl | bash"},
+	}
+	if patch := Patch(got[:1], target); !reflect.DeepEqual(patch, want) {
+		t.Errorf("file index patch = %+v, want %+v", patch, want)
+	}
+
+	// A list the file does not set is written whole from the loaded
+	// config, or its whole section when that is missing, with every
+	// change merged
+	approved := []Record{
+		{SessionID: "a", Decision: DecisionOverride, Rule: "ask.commands:git push --force"},
+		{SessionID: "b", Decision: DecisionOverride, Rule: "ask.commands:git push --force"},
+		{SessionID: "a", Decision: DecisionOverride, Rule: "bash.blocked_commands:curl | bash"},
+		{SessionID: "b", Decision: DecisionOverride, Rule: "bash.blocked_commands:curl | bash"},
+	}
+	target.File = map[string]interface{}{"bash": map[string]interface{}{"enabled": true}}
+	got = Suggest(approved, target, SuggestOptions{MinWarnSessions: 2, MinOverrides: 2})
+	if len(got) != 2 || got[0].Action != "demote" || got[1].Action != "demote" {
+		t.Fatalf("overrides: got %+v, want two demotes", got)
+	}
+	ask := configObject(cfg)["ask"].(map[string]interface{})
+	ask["commands"] = []string{"terraform apply"}
+	want = []PatchOp{
+		{Op: "add", Path: "/ask", Value: ask},
+		{Op: "add", Path: "/bash/blocked_commands", Value: []string{"rm -rf /"}},
+		{Op: "add", Path: "/bash/warn_commands", Value: []string{"sudo", "npm publish", "git push --force", "curl | bash"}},
+	}
+	if patch := Patch(got, target); !reflect.DeepEqual(patch, want) {
+		t.Errorf("absent list patch = %+v, want %+v", patch, want)
+	}
+
+	// No file: the whole loaded config is written, so nothing else reverts
+	target.File = nil
+	patch := Patch(got[:1], target)
+	doc, _ := patch[0].Value.(map[string]interface{})
+	if len(patch) != 1 || patch[0].Path != "" || doc["bash"] == nil {
+		t.Fatalf("no file patch = %+v, want one add of the whole config", patch)
+	}
+	if commands := doc["ask"].(map[string]interface{})["commands"]; !reflect.DeepEqual(commands, []string{"terraform apply"}) {
+		t.Errorf("ask.commands = %v, want the entry removed", commands)
+	}
+}
@@ -0,0 +1,342 @@
+// Package audit provides a JSONL log of gate decisions.
+// suggest.go: Data-driven config tuning from audit history.
+// Frequent warns → promote to block; frequently overridden blocks and asks
+// → demote to warn.
+package audit
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/claude/shared/pkg/config"
+)
+
+// PatchOp is one RFC 6902 JSON Patch operation against config.json.
+// Value is an entry appended to a list, a whole list or a new section.
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PatchTarget is the config.json a patch applies to: its contents as
+// written (nil when there is no file), and the config the user loads from
+// it (defaults filled, no project layer) that lists absent from the file
+// fall back to.
+type PatchTarget struct {
+	File   map[string]interface{}
+	Config *config.GatesConfig
+}
+
+// Suggestion explains a recommended config change.
+type Suggestion struct {
+	Rule     string    `json:"rule"`
+	Action   string    `json:"action"` // "promote" or "demote"
+	Count    int       `json:"count"`
+	Sessions int       `json:"sessions"`
+	Patch    []PatchOp `json:"patch"`
+}
+
+// SuggestOptions sets thresholds for suggestions.
+type SuggestOptions struct {
+	MinWarnSessions int // Distinct sessions a warn must recur in to promote
+	MinOverrides    int // Overrides of a block or ask needed to demote
+}
+
+// promoteTo maps warn lists to their block counterparts.
+var promoteTo = map[string]string{
+	"bash.warn_commands":   "bash.blocked_commands",
+	"read.warn_extensions": "read.blocked_extensions",
+	"read.warn_patterns":   "read.blocked_paths",
+}
+
+// demoteTo maps block and ask lists to the warn list an often-overridden
+// entry moves to. Blocks are overridden when the call later runs anyway;
+// asks each time the user approves one.
+var demoteTo = map[string]string{
+	"bash.blocked_commands":   "bash.warn_commands",
+	"read.blocked_extensions": "read.warn_extensions",
+	"read.blocked_paths":      "read.warn_patterns",
+	"ask.commands":            "bash.warn_commands",
+}
+
+// ruleStats aggregates records for one rule.
+type ruleStats struct {
+	warns     int
+	overrides int
+	sessions  map[string]bool
+}
+
+// Suggest recommends config changes from audit records, each with the
+// patch that makes it on its own against target. Results are sorted by
+// rule for stable output.
+func Suggest(records []Record, target PatchTarget, opts SuggestOptions) []Suggestion {
+	stats := make(map[string]*ruleStats)
+	for _, r := range records {
+		if r.Rule == "" {
+			continue
+		}
+		st := stats[r.Rule]
+		if st == nil {
+			st = &ruleStats{sessions: make(map[string]bool)}
+			stats[r.Rule] = st
+		}
+		switch r.Decision {
+		case DecisionWarn:
+			st.warns++
+			st.sessions[r.SessionID] = true
+		case DecisionOverride:
+			st.overrides++
+		}
+	}
+
+	var suggestions []Suggestion
+	for rule, st := range stats {
+		list, entry := splitRule(rule)
+		if block, ok := promoteTo[list]; ok && len(st.sessions) >= opts.MinWarnSessions && st.warns > 0 {
+			suggestions = append(suggestions, Suggestion{
+				Rule: rule, Action: "promote", Count: st.warns, Sessions: len(st.sessions),
+				Patch: newPatchBuilder(target).move(list, block, entry).ops(),
+			})
+		}
+		if warn, ok := demoteTo[list]; ok && opts.MinOverrides > 0 && st.overrides >= opts.MinOverrides {
+			suggestions = append(suggestions, Suggestion{
+				Rule: rule, Action: "demote", Count: st.overrides, Sessions: len(st.sessions),
+				Patch: newPatchBuilder(target).move(list, warn, entry).ops(),
+			})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Rule < suggestions[j].Rule })
+	return suggestions
+}
+
+// Patch combines suggestions into a single JSON Patch against target.
+// Moves touching the same list are merged, so a list absent from the file
+// is written once with every change.
+func Patch(suggestions []Suggestion, target PatchTarget) []PatchOp {
+	b := newPatchBuilder(target)
+	for _, s := range suggestions {
+		list, entry := splitRule(s.Rule)
+		to := promoteTo[list]
+		if s.Action == "demote" {
+			to = demoteTo[list]
+		}
+		b.move(list, to, entry)
+	}
+	return b.ops()
+}
+
+// patchBuilder accumulates entry moves between config lists.
+type patchBuilder struct {
+	target PatchTarget
+	lists  map[string]*listEdit
+}
+
+// listEdit is one dotted config list and the changes made to it. A list
+// in the file is patched by index; one absent from it starts from the
+// loaded config and is added whole.
+type listEdit struct {
+	inFile  bool
+	orig    []string
+	removed map[int]bool
+	added   []string
+}
+
+func newPatchBuilder(target PatchTarget) *patchBuilder {
+	return &patchBuilder{target: target, lists: make(map[string]*listEdit)}
+}
+
+// move removes entry from the "from" list (if present) and appends it to
+// "to" (unless already there).
+func (b *patchBuilder) move(from, to, entry string) *patchBuilder {
+	b.list(from).remove(entry)
+	if t := b.list(to); !t.contains(entry) {
+		t.added = append(t.added, entry)
+	}
+	return b
+}
+
+func (b *patchBuilder) list(name string) *listEdit {
+	if l := b.lists[name]; l != nil {
+		return l
+	}
+	l := &listEdit{removed: make(map[int]bool)}
+	l.orig, l.inFile = fileList(b.target.File, name)
+	if !l.inFile {
+		l.orig = configList(b.target.Config, name)
+	}
+	b.lists[name] = l
+	return l
+}
+
+func (l *listEdit) remove(entry string) {
+	for i, v := range l.orig {
+		if v == entry && !l.removed[i] {
+			l.removed[i] = true
+			return
+		}
+	}
+	if i := indexOf(l.added, entry); i >= 0 {
+		l.added = append(l.added[:i], l.added[i+1:]...)
+	}
+}
+
+func (l *listEdit) contains(entry string) bool {
+	for i, v := range l.orig {
+		if v == entry && !l.removed[i] {
+			return true
+		}
+	}
+	return indexOf(l.added, entry) >= 0
+}
+
+// current returns the list with the edits applied.
+func (l *listEdit) current() []string {
+	list := []string{}
+	for i, v := range l.orig {
+		if !l.removed[i] {
+			list = append(list, v)
+		}
+	}
+	return append(list, l.added...)
+}
+
+// ops renders the edits: removals by descending index per list so
+// indexes stay valid, then additions. A list the file does not set is
+// written whole, or the defaults it stood for would be replaced by just
+// this entry; when its section is missing too the loaded section is
+// added with it, and when there is no file the whole loaded config.
+func (b *patchBuilder) ops() []PatchOp {
+	names := make([]string, 0, len(b.lists))
+	for name, l := range b.lists {
+		if l.inFile || len(l.removed) > 0 || len(l.added) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil
+	}
+	if b.target.File == nil {
+		doc := configObject(b.target.Config)
+		for _, name := range names {
+			section, key := splitList(name)
+			if obj, ok := doc[section].(map[string]interface{}); ok {
+				obj[key] = b.lists[name].current()
+			}
+		}
+		return []PatchOp{{Op: "add", Path: "", Value: doc}}
+	}
+
+	var removes, adds []PatchOp
+	sections := make(map[string]map[string]interface{})
+	for _, name := range names {
+		l := b.lists[name]
+		section, key := splitList(name)
+		switch {
+		case l.inFile:
+			for i := len(l.orig) - 1; i >= 0; i-- {
+				if l.removed[i] {
+					removes = append(removes, PatchOp{Op: "remove", Path: pointer(name) + "/" + strconv.Itoa(i)})
+				}
+			}
+			for _, entry := range l.added {
+				adds = append(adds, PatchOp{Op: "add", Path: pointer(name) + "/-", Value: entry})
+			}
+		case b.target.File[section] != nil:
+			adds = append(adds, PatchOp{Op: "add", Path: pointer(name), Value: l.current()})
+		default:
+			obj := sections[section]
+			if obj == nil {
+				obj, _ = configObject(b.target.Config)[section].(map[string]interface{})
+				if obj == nil {
+					obj = make(map[string]interface{})
+				}
+				sections[section] = obj
+				adds = append(adds, PatchOp{Op: "add", Path: "/" + section, Value: obj})
+			}
+			obj[key] = l.current()
+		}
+	}
+	return append(removes, adds...)
+}
+
+// configObject returns cfg as its JSON object form.
+func configObject(cfg *config.GatesConfig) map[string]interface{} {
+	doc := make(map[string]interface{})
+	if data, err := json.Marshal(cfg); err == nil {
+		json.Unmarshal(data, &doc)
+	}
+	return doc
+}
+
+// fileList returns a dotted list as written in the config file, and
+// whether the file sets it.
+func fileList(file map[string]interface{}, list string) ([]string, bool) {
+	section, key := splitList(list)
+	obj, _ := file[section].(map[string]interface{})
+	raw, ok := obj[key].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(raw))
+	for _, v := range raw {
+		s, _ := v.(string)
+		out = append(out, s)
+	}
+	return out, true
+}
+
+// configList returns the contents of a dotted config list.
+func configList(cfg *config.GatesConfig, list string) []string {
+	if cfg == nil {
+		return nil
+	}
+	switch list {
+	case "bash.warn_commands":
+		return cfg.Bash.WarnCommands
+	case "bash.blocked_commands":
+		return cfg.Bash.BlockedCommands
+	case "read.warn_extensions":
+		return cfg.Read.WarnExtensions
+	case "read.blocked_extensions":
+		return cfg.Read.BlockedExtensions
+	case "read.warn_patterns":
+		return cfg.Read.WarnPatterns
+	case "read.blocked_paths":
+		return cfg.Read.BlockedPaths
+	case "ask.commands":
+		return cfg.Ask.Commands
+	}
+	return nil
+}
+
+// splitList splits "bash.warn_commands" into section and key.
+func splitList(list string) (string, string) {
+	section, key, _ := strings.Cut(list, ".")
+	return section, key
+}
+
+// splitRule splits "bash.warn_commands:sudo" into list and entry.
+func splitRule(rule string) (string, string) {
+	if idx := strings.Index(rule, ":"); idx > 0 {
+		return rule[:idx], rule[idx+1:]
+	}
+	return rule, ""
+}
+
+func pointer(list string) string {
+	return "/" + strings.ReplaceAll(list, ".", "/")
+}
+
+func indexOf(list []string, entry string) int {
+	for i, v := range list {
+		if v == entry {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,146 @@
+// Package chain provides multi-agent verification chain for kavach.
+// aegis_action.go: What an Aegis finding does, per violation category:
+// deny, ask the user, or modify the input to a safer form (SafeRewrite).
+package chain
+
+import (
+	"strings"
+
+	"github.com/claude/shared/pkg/types"
+)
+
+// Violation categories recorded in AegisVerification.ViolationCategories.
+// ViolationAskCommand is the ask-policy command match, not a violation, but
+// takes an action the same way.
+const (
+	ViolationDangerousCommand = "dangerous_command"
+	ViolationPipeInstall      = "pipe_install"
+	ViolationEnvExfil         = "env_exfil"
+	ViolationSensitivePath    = "sensitive_path"
+	ViolationCodeRemoval      = "code_removal"
+	ViolationAskCommand       = "ask_command"
+)
+
+// Aegis actions.
+const (
+	ActionDeny   = "deny"
+	ActionAsk    = "ask"
+	ActionModify = "modify"
+)
+
+// defaultAegisActions is the built-in action per category. A "modify" with
+// no applicable rewrite falls back to this.
+var defaultAegisActions = map[string]string{
+	ViolationDangerousCommand: ActionDeny,
+	ViolationPipeInstall:      ActionDeny,
+	ViolationEnvExfil:         ActionDeny,
+	ViolationSensitivePath:    ActionDeny,
+	ViolationCodeRemoval:      ActionDeny,
+	ViolationAskCommand:       ActionAsk,
+}
+
+// actionRank orders actions by strictness.
+var actionRank = map[string]int{ActionModify: 0, ActionAsk: 1, ActionDeny: 2}
+
+// WithAegisActions sets the action per violation category ("deny", "ask"
+// or "modify"). Keys may be globs ("*", "*_command"). Unset or unknown
+// entries keep the built-in action.
+func WithAegisActions(actions map[string]string) Option {
+	return func(r *Runner) {
+		r.aegisActions = make(map[string]string, len(actions))
+		for category, action := range actions {
+			action = strings.ToLower(action)
+			if _, ok := actionRank[action]; ok {
+				r.aegisActions[strings.ToLower(category)] = action
+			}
+		}
+	}
+}
+
+// aegisAction returns the configured action for category: the exact entry,
+// else the longest matching glob (ties by key order), else the built-in.
+func (r *Runner) aegisAction(category string) string {
+	if action, ok := r.aegisActions[category]; ok {
+		return action
+	}
+	action, key := "", ""
+	for pattern, a := range r.aegisActions {
+		if !types.MatchToolName(pattern, category) {
+			continue
+		}
+		if len(pattern) > len(key) || (len(pattern) == len(key) && pattern < key) {
+			action, key = a, pattern
+		}
+	}
+	if action != "" {
+		return action
+	}
+	return defaultAegisActions[category]
+}
+
+// applyAegisAction turns the default block/ask of an Aegis finding into the
+// strictest action configured for its categories. "modify" keeps the call
+// as a warn carrying the rewritten command in Context["updated_command"].
+func (r *Runner) applyAegisAction(categories []string, toolName string, toolInput map[string]interface{}, result *VerificationResult) {
+	if len(categories) == 0 {
+		return
+	}
+	action := ActionModify
+	for _, c := range categories {
+		if a := r.aegisAction(c); actionRank[a] > actionRank[action] {
+			action = a
+		}
+	}
+	if action == ActionModify {
+		cmd, _ := toolInput["command"].(string)
+		if rewritten, note, ok := SafeRewrite(cmd); ok && toolName == "Bash" {
+			result.Status = "warn"
+			result.Reason += "; rewritten: " + note
+			result.Source = SourceConfig
+			result.NextAction = "Run the rewritten command: " + rewritten
+			result.Context["aegis_action"] = action
+			result.Context["updated_command"] = rewritten
+			return
+		}
+		// No safe form: the strictest built-in action stands
+		action = ActionAsk
+		for _, c := range categories {
+			if actionRank[defaultAegisActions[c]] > actionRank[action] {
+				action = defaultAegisActions[c]
+			}
+		}
+	}
+
+	switch {
+	case action == ActionAsk && result.Status == "block":
+		result.Status = "ask"
+		result.Source = SourceConfig
+		result.NextAction = "User confirmation required"
+	case action == ActionDeny && result.Status == "ask":
+		result.Status = "block"
+		result.Source = SourceConfig
+		result.NextAction = "Denied by aegis.actions in gates config"
+	}
+	result.Context["aegis_action"] = action
+}
+
+// UpdatedInput returns the tool input with an Aegis rewrite applied, or nil
+// when no gate rewrote it or the chain did not approve the call.
+func (c *ChainState) UpdatedInput() map[string]interface{} {
+	if c.IsBlocked() || c.IsAsk() || c.Input == nil {
+		return nil
+	}
+	for _, r := range c.Results {
+		cmd := r.Context["updated_command"]
+		if cmd == "" {
+			continue
+		}
+		updated := make(map[string]interface{}, len(c.Input.ToolInput))
+		for k, v := range c.Input.ToolInput {
+			updated[k] = v
+		}
+		updated["command"] = cmd
+		return updated
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package chain provides multi-agent verification chain for kavach.
+// aegis_post.go: Post-execution Aegis pass over content actually written.
+// Defense-in-depth: templating or partial writes can differ from the
+// pre-checked tool input, so the result is scanned again.
+package chain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/claude/shared/pkg/patterns"
+)
+
+// AegisVerifyOutput scans written content for secrets and dangerous commands.
+// extraPatterns are case-insensitive substrings (config write.secret_patterns).
+func AegisVerifyOutput(content string, extraPatterns []string) *AegisVerification {
+	verification := &AegisVerification{
+		Passed:          true,
+		SecurityScore:   1.0,
+		ThreatLevel:     "none",
+		ViolationsFound: []string{},
+		Recommendations: []string{},
+	}
+
+	for _, s := range patterns.SecretPatterns {
+		if s.Re.MatchString(content) {
+			verification.ViolationsFound = append(verification.ViolationsFound, "secret:"+s.Name)
+		}
+	}
+	contentLower := strings.ToLower(content)
+	for _, p := range extraPatterns {
+		if p != "" && strings.Contains(contentLower, strings.ToLower(p)) {
+			verification.ViolationsFound = append(verification.ViolationsFound, "secret:"+p)
+		}
+	}
+	if len(verification.ViolationsFound) > 0 {
+		verification.Passed = false
+		verification.ThreatLevel = "high"
+		verification.SecurityScore = 0.0
+		verification.Recommendations = append(verification.Recommendations,
+			"Remove the credential from the file and load it from env/secret store; rotate it if it was real")
+	}
+
+	if isDangerousCommand(content) {
+		verification.Passed = false
+		if verification.ThreatLevel == "none" {
+			verification.ThreatLevel = "medium"
+			verification.SecurityScore = 0.3
+		}
+		verification.ViolationsFound = append(verification.ViolationsFound, "dangerous_command_in_content")
+		verification.Recommendations = append(verification.Recommendations,
+			"Remove destructive command from written content")
+	}
+
+	verification.dedupe()
+	prov := NewProvenance("post_write_verification")
+	prov.AddInput("content_bytes", fmt.Sprint(len(content)))
+	prov.AddInput("extra_patterns", fmt.Sprint(len(extraPatterns)))
+	verification.MemoryProvenance = prov
+	return verification
+}
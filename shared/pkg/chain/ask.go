@@ -0,0 +1,60 @@
+// Package chain provides multi-agent verification chain for kavach.
+// ask.go: Ask policy - high-risk but legitimate actions prompt the user
+// instead of passing silently or being hard-denied.
+package chain
+
+import "strings"
+
+// AskPolicy decides which findings map to an "ask" result.
+// Block stays reserved for the truly dangerous (Aegis violations).
+type AskPolicy struct {
+	Commands   []string // Bash substrings that ask (force-push, prod deploys)
+	RiskLevels []string // Intent risk levels that ask for mutating tools
+	Gates      []string // Gates whose "warn" results become "ask"
+}
+
+// WithAskPolicy enables ask results for matching commands, risk levels and gates.
+func WithAskPolicy(p AskPolicy) Option {
+	return func(r *Runner) {
+		r.ask = &p
+	}
+}
+
+// MatchCommand returns the first configured pattern found in cmd, or "".
+func (p *AskPolicy) MatchCommand(cmd string) string {
+	cmdLower := strings.ToLower(cmd)
+	for _, pattern := range p.Commands {
+		if pattern != "" && strings.Contains(cmdLower, strings.ToLower(pattern)) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// AsksForRisk reports whether an intent risk level should ask for toolName.
+// Read-only tools never ask.
+func (p *AskPolicy) AsksForRisk(riskLevel, toolName string) bool {
+	return isMutatingTool(toolName) && containsFold(p.RiskLevels, riskLevel)
+}
+
+// AsksForGate reports whether a gate's warn result should become ask.
+func (p *AskPolicy) AsksForGate(gate string) bool {
+	return containsFold(p.Gates, gate)
+}
+
+func isMutatingTool(toolName string) bool {
+	switch toolName {
+	case "Bash", "Write", "Edit", "MultiEdit", "NotebookEdit":
+		return true
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
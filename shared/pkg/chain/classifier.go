@@ -0,0 +1,311 @@
+// Package chain provides multi-agent verification chain for kavach.
+// classifier.go: Intent classification. IntentClassifier is the pluggable
+// interface; the default KeywordClassifier compiles keyword sets once into
+// a single Aho-Corasick matcher and reuses it across calls.
+package chain
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/claude/shared/pkg/dsa"
+)
+
+// IntentKeywords holds the keyword sets per intent category.
+// Keywords are matched as lowercase substrings of the prompt.
+type IntentKeywords struct {
+	Implement []string          `json:"implement"`
+	Debug     []string          `json:"debug"`
+	Refactor  []string          `json:"refactor"`
+	Deploy    []string          `json:"deploy"`
+	Security  []string          `json:"security"`
+	Deletion  []string          `json:"deletion"`
+	Agents    map[string]string `json:"agents"` // keyword -> agent
+}
+
+// DefaultIntentKeywords returns the built-in keyword sets.
+func DefaultIntentKeywords() IntentKeywords {
+	return IntentKeywords{
+		Implement: []string{"implement", "create", "build", "add", "develop", "write"},
+		Debug:     []string{"fix", "bug", "error", "debug", "broken", "not working", "crash"},
+		Refactor:  []string{"refactor", "restructure", "clean up", "improve", "optimize"},
+		Deploy:    []string{"deploy", "release", "publish", "production", "go live"},
+		Security:  []string{"security", "auth", "encrypt", "vulnerability", "password"},
+		Deletion:  []string{"delete", "remove", "drop", "destroy", "purge"},
+		Agents: map[string]string{
+			"backend":  "backend-engineer",
+			"frontend": "frontend-engineer",
+			"database": "database-engineer",
+			"devops":   "devops-engineer",
+			"security": "security-engineer",
+			"test":     "qa-lead",
+			"explore":  "Explore",
+			"plan":     "Plan",
+		},
+	}
+}
+
+// Intent keyword categories (pattern tags in the compiled matcher).
+const (
+	catImplement = iota
+	catDebug
+	catRefactor
+	catDeploy
+	catSecurity
+	catDeletion
+	catAgent
+	numCategories
+)
+
+// IntentClassifier classifies a prompt for the Intent gate. A nil result
+// falls back to the built-in keywords. Implementations may call out to a
+// model: under RunFullContext one still running at the deadline is
+// abandoned for the built-in keywords, and a ContextClassifier is handed
+// the chain's context so it can stop the call itself.
+type IntentClassifier interface {
+	Classify(prompt string) *IntentAnalysis
+}
+
+// ContextClassifier is an IntentClassifier that honours cancellation.
+type ContextClassifier interface {
+	IntentClassifier
+	ClassifyContext(ctx context.Context, prompt string) *IntentAnalysis
+}
+
+var _ IntentClassifier = (*KeywordClassifier)(nil)
+
+// ClassifierFunc adapts a function to IntentClassifier.
+type ClassifierFunc func(prompt string) *IntentAnalysis
+
+// Classify calls f(prompt).
+func (f ClassifierFunc) Classify(prompt string) *IntentAnalysis {
+	return f(prompt)
+}
+
+// KeywordClassifier classifies prompts with a precompiled matcher.
+// Safe for concurrent use.
+type KeywordClassifier struct {
+	matcher  *dsa.AhoCorasick
+	category []int    // pattern index -> category
+	agent    []string // pattern index -> agent (catAgent only)
+}
+
+// NewKeywordClassifier compiles the keyword sets into a single matcher.
+func NewKeywordClassifier(kw IntentKeywords) *KeywordClassifier {
+	c := &KeywordClassifier{}
+	var patterns []string
+	add := func(cat int, words []string, agent func(string) string) {
+		for _, w := range words {
+			patterns = append(patterns, strings.ToLower(w))
+			c.category = append(c.category, cat)
+			c.agent = append(c.agent, agent(w))
+		}
+	}
+	none := func(string) string { return "" }
+	add(catImplement, kw.Implement, none)
+	add(catDebug, kw.Debug, none)
+	add(catRefactor, kw.Refactor, none)
+	add(catDeploy, kw.Deploy, none)
+	add(catSecurity, kw.Security, none)
+	add(catDeletion, kw.Deletion, none)
+
+	// Sorted so RequiredAgents order is deterministic
+	agentWords := make([]string, 0, len(kw.Agents))
+	for w := range kw.Agents {
+		agentWords = append(agentWords, w)
+	}
+	sort.Strings(agentWords)
+	add(catAgent, agentWords, func(w string) string { return kw.Agents[w] })
+
+	c.matcher = dsa.NewAhoCorasick(patterns)
+	return c
+}
+
+// intentProfile is what a keyword category contributes to the analysis.
+type intentProfile struct {
+	name       string
+	risk       string
+	complexity string
+	confidence float64
+	research   bool
+	skill      string
+}
+
+// intentProfiles per category. Slice order is the tie-break among equal
+// risk: later entries win (security over deploy, debug over implement).
+var intentProfiles = [...]intentProfile{
+	catImplement: {"implement", "low", "", 0.8, true, ""}, // complexity from implementScope
+	catDebug:     {"debug", "low", "moderate", 0.85, false, "debug-like-expert"},
+	catRefactor:  {"refactor", "medium", "complex", 0.8, true, ""},
+	catDeploy:    {"deploy", "high", "complex", 0.9, true, "cloud-infrastructure-mastery"},
+	catSecurity:  {"security", "high", "", 0.85, true, "security"},
+}
+
+var (
+	riskRank       = map[string]int{"low": 0, "medium": 1, "high": 2, "critical": 3}
+	complexityRank = map[string]int{"simple": 0, "moderate": 1, "complex": 2}
+)
+
+// intentSignal is how strongly a category claims the prompt: high-risk
+// categories (deploy, security) outweigh any number of lower-risk words,
+// then distinct keyword hits decide.
+type intentSignal struct {
+	elevated bool
+	hits     int
+}
+
+func (s intentSignal) beats(o intentSignal) bool {
+	if s.elevated != o.elevated {
+		return s.elevated
+	}
+	return s.hits > o.hits
+}
+
+// Classify returns the intent analysis for prompt in one pass over it.
+// Every matched category contributes skills, research and the highest risk
+// and complexity; Type is the category with the strongest intentSignal,
+// so "fix the crash before release" is a deploy. Ties go to the
+// higher-risk category so ambiguity errs toward scrutiny, and are recorded
+// in MatchedSignals as "tie:a,b->winner".
+func (c *KeywordClassifier) Classify(prompt string) *IntentAnalysis {
+	analysis := &IntentAnalysis{
+		Type:             "general",
+		Confidence:       0.5,
+		RequiredSkills:   []string{},
+		RequiredAgents:   []string{},
+		RequiresResearch: false,
+		Complexity:       "simple",
+		RiskLevel:        "low",
+	}
+
+	var hits [numCategories]int
+	for idx, found := range c.matcher.MatchSet(strings.ToLower(prompt)) {
+		if !found {
+			continue
+		}
+		cat := c.category[idx]
+		hits[cat]++
+		if cat == catAgent && !containsString(analysis.RequiredAgents, c.agent[idx]) {
+			analysis.RequiredAgents = append(analysis.RequiredAgents, c.agent[idx])
+		}
+	}
+
+	winner, best := -1, intentSignal{}
+	var tied []string
+	for cat, p := range intentProfiles {
+		n := hits[cat]
+		if n == 0 {
+			continue
+		}
+		sig := intentSignal{elevated: riskRank[p.risk] >= riskRank["high"], hits: n}
+		analysis.MatchedSignals = append(analysis.MatchedSignals, fmt.Sprintf("%s:%d", p.name, n))
+		if p.skill != "" {
+			analysis.RequiredSkills = append(analysis.RequiredSkills, p.skill)
+		}
+		analysis.RequiresResearch = analysis.RequiresResearch || p.research
+		raiseLevel(&analysis.RiskLevel, p.risk, riskRank)
+		raiseLevel(&analysis.Complexity, p.complexity, complexityRank)
+
+		switch {
+		case winner < 0 || sig.beats(best):
+			winner, best, tied = cat, sig, []string{p.name}
+		case sig == best:
+			tied = append(tied, p.name)
+			if riskRank[p.risk] >= riskRank[intentProfiles[winner].risk] {
+				winner = cat
+			}
+		}
+	}
+	if winner >= 0 {
+		analysis.Type = intentProfiles[winner].name
+		analysis.Confidence = intentProfiles[winner].confidence
+		if len(tied) > 1 {
+			analysis.MatchedSignals = append(analysis.MatchedSignals,
+				"tie:"+strings.Join(tied, ",")+"->"+analysis.Type)
+		}
+	}
+	if hits[catImplement] > 0 {
+		raiseLevel(&analysis.Complexity, implementScope(prompt, hits[catImplement]), complexityRank)
+	}
+	// Deletion/removal intent - HIGH RISK
+	if hits[catDeletion] > 0 {
+		analysis.MatchedSignals = append(analysis.MatchedSignals, fmt.Sprintf("deletion:%d", hits[catDeletion]))
+		analysis.RiskLevel = "critical"
+		analysis.Complexity = "complex"
+	}
+
+	return analysis
+}
+
+// implementScope sizes an implement request from the prompt: several
+// distinct implement verbs or a long prompt describe more than one change.
+func implementScope(prompt string, verbs int) string {
+	words := len(strings.Fields(prompt))
+	switch {
+	case verbs >= 3 || words > 80:
+		return "complex"
+	case verbs >= 2 || words > 25:
+		return "moderate"
+	}
+	return "simple"
+}
+
+// raiseLevel sets *level to candidate when candidate ranks higher.
+func raiseLevel(level *string, candidate string, rank map[string]int) {
+	if candidate != "" && rank[candidate] > rank[*level] {
+		*level = candidate
+	}
+}
+
+// defaultClassifier is compiled on first use from the built-in keywords.
+var defaultClassifier = sync.OnceValue(func() *KeywordClassifier {
+	return NewKeywordClassifier(DefaultIntentKeywords())
+})
+
+// WithIntentClassifier replaces the default classifier used by the Intent
+// gate, e.g. a KeywordClassifier with configured keywords or a model-based
+// one.
+// A nil classifier, typed or not, keeps the built-in keywords.
+func WithIntentClassifier(c IntentClassifier) Option {
+	return func(r *Runner) {
+		r.classifier = nil
+		if !isNilClassifier(c) {
+			r.classifier = c
+		}
+	}
+}
+
+// isNilClassifier reports whether c is nil or a nil pointer, func or other
+// nillable value wrapped in the interface.
+func isNilClassifier(c IntentClassifier) bool {
+	if c == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(c); v.Kind() {
+	case reflect.Pointer, reflect.Func, reflect.Map, reflect.Slice, reflect.Interface, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// classify runs c on prompt within ctx. A ContextClassifier gets ctx; any
+// other classifier is abandoned, returning nil, once ctx is done.
+func classify(ctx context.Context, c IntentClassifier, prompt string) *IntentAnalysis {
+	if cc, ok := c.(ContextClassifier); ok {
+		return cc.ClassifyContext(ctx, prompt)
+	}
+	if ctx.Done() == nil {
+		return c.Classify(prompt)
+	}
+	out, ok := awaitGate(ctx, startGate(func() (*IntentAnalysis, VerificationResult) {
+		return c.Classify(prompt), VerificationResult{}
+	}))
+	if !ok {
+		return nil
+	}
+	return out.status
+}
@@ -0,0 +1,206 @@
+package chain
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// naiveAnalyze is the original per-call containsAny scan, kept as an oracle.
+func naiveAnalyze(kw IntentKeywords, prompt string) (string, string, bool) {
+	p := strings.ToLower(prompt)
+	typ, risk, research := "general", "low", false
+	if containsAny(p, kw.Implement) {
+		typ, research = "implement", true
+	}
+	if containsAny(p, kw.Debug) {
+		typ = "debug"
+	}
+	if containsAny(p, kw.Refactor) {
+		typ, risk, research = "refactor", "medium", true
+	}
+	if containsAny(p, kw.Deploy) {
+		typ, risk, research = "deploy", "high", true
+	}
+	if containsAny(p, kw.Security) {
+		typ, risk, research = "security", "high", true
+	}
+	if containsAny(p, kw.Deletion) {
+		risk = "critical"
+	}
+	return typ, risk, research
+}
+
+func TestIntentClassifierMatchesNaiveScan(t *testing.T) {
+	kw := DefaultIntentKeywords()
+	c := NewKeywordClassifier(kw)
+	prompts := []string{
+		"Implement the backend handler",
+		"fix the crash in the frontend",
+		"refactor and optimize the database layer",
+		"deploy to production",
+		"add auth with password hashing",
+		"delete the old test fixtures",
+		"what time is it",
+		"",
+	}
+	for _, p := range prompts {
+		got := c.Classify(p)
+		typ, risk, research := naiveAnalyze(kw, p)
+		if got.Type != typ || got.RiskLevel != risk || got.RequiresResearch != research {
+			t.Errorf("Classify(%q) = %s/%s/%v, want %s/%s/%v", p,
+				got.Type, got.RiskLevel, got.RequiresResearch, typ, risk, research)
+		}
+	}
+
+	got := c.Classify("plan the backend and frontend tests")
+	want := []string{"backend-engineer", "frontend-engineer", "Plan", "qa-lead"}
+	if !reflect.DeepEqual(got.RequiredAgents, want) {
+		t.Errorf("RequiredAgents = %v, want %v", got.RequiredAgents, want)
+	}
+}
+
+func TestIntentClassifierCustomKeywords(t *testing.T) {
+	kw := DefaultIntentKeywords()
+	kw.Deploy = append(kw.Deploy, "ship it")
+	if got := NewKeywordClassifier(kw).Classify("ok, ship it"); got.Type != "deploy" {
+		t.Errorf("custom keyword: Type = %s, want deploy", got.Type)
+	}
+	if got := AnalyzeIntent("ok, ship it"); got.Type != "general" {
+		t.Errorf("default classifier should not see custom keywords, got %s", got.Type)
+	}
+}
+
+func TestIntentClassifierTieBreak(t *testing.T) {
+	c := NewKeywordClassifier(DefaultIntentKeywords())
+	cases := []struct {
+		prompt, typ, risk string
+		signals           string
+	}{
+		// One hit each: refactor outranks implement on risk
+		{"refactor the parser and add tests", "refactor", "medium", "implement:1,refactor:1,tie:implement,refactor->refactor"},
+		{"add refactor tooling", "refactor", "medium", "implement:1,refactor:1,tie:implement,refactor->refactor"},
+		// Equal risk falls back to the fixed order: debug over implement
+		{"fix the build", "debug", "low", "implement:1,debug:1,tie:implement,debug->debug"},
+		// A stronger signal wins outright
+		{"fix the crash and bug in the parser", "debug", "low", "debug:3"},
+		{"implement and build it, then fix the bug", "debug", "low", "implement:2,debug:2,tie:implement,debug->debug"},
+		// Deploy and security signals outweigh any number of debug words
+		{"fix the crash and error before release", "deploy", "high", "debug:3,deploy:1"},
+		{"fix the bug, crash and error in auth", "security", "high", "debug:4,security:1"},
+		{"delete it", "general", "critical", "deletion:1"},
+	}
+	for _, tc := range cases {
+		got := c.Classify(tc.prompt)
+		if got.Type != tc.typ || got.RiskLevel != tc.risk || strings.Join(got.MatchedSignals, ",") != tc.signals {
+			t.Errorf("Classify(%q) = %s/%s %v, want %s/%s %s", tc.prompt, got.Type, got.RiskLevel, got.MatchedSignals, tc.typ, tc.risk, tc.signals)
+		}
+		if again := c.Classify(tc.prompt); !reflect.DeepEqual(got, again) {
+			t.Errorf("Classify(%q) not reproducible", tc.prompt)
+		}
+	}
+}
+
+func TestImplementComplexity(t *testing.T) {
+	c := NewKeywordClassifier(DefaultIntentKeywords())
+	cases := []struct{ prompt, complexity string }{
+		{"add a flag", "simple"},
+		{"implement the parser and write tests", "moderate"},
+		{"implement the parser, create the lexer and add a printer", "complex"},
+		{"add a flag" + strings.Repeat(" to the command", 30), "complex"},
+		{"refactor the parser and add tests", "complex"}, // refactor's own complexity
+	}
+	for _, tc := range cases {
+		if got := c.Classify(tc.prompt).Complexity; got != tc.complexity {
+			t.Errorf("Classify(%q).Complexity = %s, want %s", tc.prompt, got, tc.complexity)
+		}
+	}
+}
+
+func BenchmarkIntentClassify(b *testing.B) {
+	c := NewKeywordClassifier(DefaultIntentKeywords())
+	prompt := strings.Repeat("please refactor the backend service and deploy it after tests ", 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Classify(prompt)
+	}
+}
+
+func TestCustomIntentClassifier(t *testing.T) {
+	var calls []string
+	custom := ClassifierFunc(func(prompt string) *IntentAnalysis {
+		calls = append(calls, prompt)
+		if !strings.Contains(prompt, "roll out") {
+			return nil
+		}
+		return &IntentAnalysis{Type: "deploy", RiskLevel: "high", Confidence: 0.9}
+	})
+
+	r := NewRunner("sess_test", WithStateDir(""), WithIntentClassifier(custom))
+	state := r.RunFull("roll out the new build", "Bash", map[string]interface{}{"command": "ls"}, true)
+	if state.Intent == nil || state.Intent.Type != "deploy" || state.Intent.Confidence != 0.9 {
+		t.Fatalf("intent = %+v, want custom deploy", state.Intent)
+	}
+	if len(calls) != 1 || calls[0] != "roll out the new build" {
+		t.Errorf("classifier calls = %v", calls)
+	}
+
+	// A nil result falls back to the built-in keywords
+	state = NewRunner("sess_test", WithStateDir(""), WithIntentClassifier(custom)).
+		RunFull("fix the crash", "Bash", map[string]interface{}{"command": "ls"}, true)
+	if state.Intent == nil || state.Intent.Type != "debug" {
+		t.Errorf("fallback intent = %+v, want debug", state.Intent)
+	}
+
+	if got := r.Topology().Stages[0].Detail; !strings.HasPrefix(got, "custom (") {
+		t.Errorf("topology detail = %q", got)
+	}
+}
+
+// ctxClassifier records the context it was handed.
+type ctxClassifier struct{ got context.Context }
+
+func (c *ctxClassifier) Classify(prompt string) *IntentAnalysis { return nil }
+
+func (c *ctxClassifier) ClassifyContext(ctx context.Context, prompt string) *IntentAnalysis {
+	c.got = ctx
+	return &IntentAnalysis{Type: "security", RiskLevel: "low", Confidence: 0.9}
+}
+
+func TestIntentClassifierNilAndDeadline(t *testing.T) {
+	input := map[string]interface{}{"command": "ls"}
+
+	// Typed nils keep the built-in keywords instead of panicking
+	for _, c := range []IntentClassifier{(*KeywordClassifier)(nil), ClassifierFunc(nil)} {
+		r := NewRunner("sess_test", WithStateDir(""), WithIntentClassifier(c))
+		if state := r.RunFull("fix the crash", "Bash", input, true); state.Intent == nil || state.Intent.Type != "debug" {
+			t.Errorf("%T(nil): intent = %+v, want built-in debug", c, state.Intent)
+		}
+	}
+
+	// A ContextClassifier is handed the run's context
+	cc := &ctxClassifier{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	state := NewRunner("sess_test", WithStateDir(""), WithIntentClassifier(cc)).RunFullContext(ctx, "audit", "Bash", input, true)
+	if cc.got != ctx || state.Intent.Type != "security" {
+		t.Errorf("ClassifyContext ctx = %v, intent = %+v", cc.got, state.Intent)
+	}
+
+	// Any other classifier still running at the deadline is abandoned
+	release := make(chan struct{})
+	defer close(release)
+	slow := ClassifierFunc(func(string) *IntentAnalysis {
+		<-release
+		return &IntentAnalysis{Type: "deploy"}
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	state = NewRunner("sess_test", WithStateDir(""), WithIntentClassifier(slow)).RunFullContext(ctx, "fix the crash", "Bash", input, true)
+	if time.Since(start) > 2*time.Second || state.Intent.Type != "debug" || !state.IsAsk() {
+		t.Errorf("slow classifier: intent = %+v, status = %s after %s", state.Intent, state.FinalStatus, time.Since(start))
+	}
+}
@@ -0,0 +1,73 @@
+// Package chain provides multi-agent verification chain for kavach.
+// deadline.go: Bounding a chain run with a context. Gates that read the
+// transcript run in goroutines so an expired deadline returns promptly.
+package chain
+
+import (
+	"context"
+	"strings"
+)
+
+// pipelineGates lists the gates of RunFull a deadline can skip, in order.
+// The tool_policy floor is applied however the run ends.
+var pipelineGates = []string{"INTENT", "CEO", "AEGIS", "RESEARCH"}
+
+// gateOutcome is the return of an eval*Gate function.
+type gateOutcome[T any] struct {
+	status T
+	result VerificationResult
+}
+
+// startGate runs eval in its own goroutine. eval must not touch r.state,
+// since a deadline may abandon it while it is still running.
+func startGate[T any](eval func() (T, VerificationResult)) <-chan gateOutcome[T] {
+	ch := make(chan gateOutcome[T], 1)
+	go func() {
+		status, result := eval()
+		ch <- gateOutcome[T]{status, result}
+	}()
+	return ch
+}
+
+// awaitGate waits for a started gate; ok is false when ctx ended first.
+func awaitGate[T any](ctx context.Context, ch <-chan gateOutcome[T]) (out gateOutcome[T], ok bool) {
+	select {
+	case out = <-ch:
+		return out, true
+	case <-ctx.Done():
+		return out, false
+	}
+}
+
+// deadlineExceeded reports whether ctx has ended and, if so, records that
+// gate and the ones after it were not evaluated. A skip always asks: every
+// skip leaves at least RESEARCH unchecked, and an unchecked call must not
+// pass silently.
+func (r *Runner) deadlineExceeded(ctx context.Context, gate string) bool {
+	err := ctx.Err()
+	if err == nil {
+		return false
+	}
+	var skipped []string
+	for i, g := range pipelineGates {
+		if g == gate {
+			skipped = pipelineGates[i:]
+			break
+		}
+	}
+	r.debug("Chain deadline exceeded before %s: %v", gate, err)
+
+	r.state.AddResult(VerificationResult{
+		Gate:       gate,
+		Status:     "ask",
+		Reason:     "Deadline exceeded: " + strings.Join(skipped, ", ") + " not evaluated",
+		Source:     SourceConfig,
+		NextAction: "Raise enforcer.timeout_ms or retry the call",
+		Context: map[string]string{
+			"deadline_exceeded": err.Error(),
+			"skipped_gates":     strings.Join(skipped, ","),
+		},
+	})
+	r.state.Deadline = gate
+	return true
+}
@@ -0,0 +1,176 @@
+// Package chain provides multi-agent verification chain for kavach.
+// deploy_window.go: Time-based deploy policy. Deploys outside the allowed
+// days and hours, or inside a freeze window, ask or block with the next
+// time a deploy would be allowed.
+package chain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FreezeWindow forbids deploys from From until To.
+type FreezeWindow struct {
+	From   time.Time
+	To     time.Time
+	Reason string
+}
+
+// DeployWindowPolicy allows deploys on Days between Start and End
+// (minutes after midnight in Location, Start < End) outside every freeze.
+// A Bash call is a deploy when the command contains one of Commands,
+// whatever the session's intent.
+type DeployWindowPolicy struct {
+	Location *time.Location // nil = time.Local
+	Days     []time.Weekday // Empty allows every day
+	Start    int
+	End      int
+	Freezes  []FreezeWindow
+	Commands []string // Lowercased command substrings, e.g. "terraform apply"
+	Action   string   // "ask" (default) or "block"
+}
+
+// WithDeployWindow enables the deploy window check for Bash.
+func WithDeployWindow(p DeployWindowPolicy) Option {
+	return func(r *Runner) {
+		r.deployWindow = &p
+	}
+}
+
+// maxWindowSearch bounds the search for the next allowed time.
+const maxWindowSearch = 400
+
+// blocked returns why a deploy at t is not allowed and the next allowed
+// time (zero when none is found within a year), or "" when it is allowed.
+func (p *DeployWindowPolicy) blocked(t time.Time) (string, time.Time) {
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	reason := ""
+	if f := p.freezeAt(t); f != nil {
+		reason = "deploy freeze until " + f.To.In(loc).Format(time.RFC3339)
+		if f.Reason != "" {
+			reason += " (" + f.Reason + ")"
+		}
+	} else if !p.inWindow(t) {
+		reason = fmt.Sprintf("outside deploy window %s %s %s",
+			p.daysLabel(), clockLabel(p.Start)+"-"+clockLabel(p.End), loc)
+	} else {
+		return "", time.Time{}
+	}
+	return reason, p.nextAllowed(t)
+}
+
+// nextAllowed returns the first allowed time at or after t, jumping to
+// freeze ends and window starts.
+func (p *DeployWindowPolicy) nextAllowed(t time.Time) time.Time {
+	limit := t.AddDate(1, 0, 0)
+	for i := 0; i < maxWindowSearch && t.Before(limit); i++ {
+		if f := p.freezeAt(t); f != nil {
+			t = f.To.In(t.Location())
+			continue
+		}
+		if p.inWindow(t) {
+			return t
+		}
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		start := midnight.Add(time.Duration(p.Start) * time.Minute)
+		if p.allowsDay(t.Weekday()) && t.Before(start) {
+			t = start
+		} else {
+			next := midnight.AddDate(0, 0, 1)
+			t = next.Add(time.Duration(p.Start) * time.Minute)
+		}
+	}
+	return time.Time{}
+}
+
+func (p *DeployWindowPolicy) inWindow(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	return p.allowsDay(t.Weekday()) && m >= p.Start && m < p.End
+}
+
+func (p *DeployWindowPolicy) allowsDay(d time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	for _, a := range p.Days {
+		if a == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *DeployWindowPolicy) freezeAt(t time.Time) *FreezeWindow {
+	for i := range p.Freezes {
+		f := &p.Freezes[i]
+		if !t.Before(f.From) && t.Before(f.To) {
+			return f
+		}
+	}
+	return nil
+}
+
+// daysLabel renders Days as "Mon,Tue,..." or "daily".
+func (p *DeployWindowPolicy) daysLabel() string {
+	if len(p.Days) == 0 {
+		return "daily"
+	}
+	names := make([]string, len(p.Days))
+	for i, d := range p.Days {
+		names[i] = d.String()[:3]
+	}
+	return strings.Join(names, ",")
+}
+
+func clockLabel(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// isDeploy reports whether a Bash command is a deploy under the policy.
+// Only the command decides: a deploy intent does not make "go test" one.
+func (r *Runner) isDeploy(cmd string) (bool, string) {
+	lower := strings.ToLower(cmd)
+	for _, c := range r.deployWindow.Commands {
+		if c != "" && strings.Contains(lower, strings.ToLower(c)) {
+			return true, c
+		}
+	}
+	return false, ""
+}
+
+// checkDeployWindow applies the deploy window to a passing or warning
+// Aegis result for a Bash deploy.
+func (r *Runner) checkDeployWindow(toolName string, toolInput map[string]interface{}, result *VerificationResult) {
+	if r.deployWindow == nil || toolName != "Bash" || (result.Status != "pass" && result.Status != "warn") {
+		return
+	}
+	cmd, _ := toolInput["command"].(string)
+	deploy, trigger := r.isDeploy(cmd)
+	if !deploy {
+		return
+	}
+	reason, next := r.deployWindow.blocked(r.clock.Now())
+	if reason == "" {
+		return
+	}
+
+	result.Status = "ask"
+	if r.deployWindow.Action == "block" {
+		result.Status = "block"
+	}
+	result.Reason = "deploy_window: " + reason
+	result.Source = SourceConfig
+	result.Context["deploy_trigger"] = trigger
+	if next.IsZero() {
+		result.NextAction = "No deploy window within a year; check deploy_window in gates config"
+		return
+	}
+	result.Reason += "; next allowed " + next.Format(time.RFC3339)
+	result.NextAction = "Wait until " + next.Format("Mon 2006-01-02 15:04 MST") + ", or get explicit approval"
+	result.Context["next_allowed"] = next.Format(time.RFC3339)
+}
@@ -0,0 +1,89 @@
+package chain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/claude/shared/pkg/util"
+)
+
+func TestDeployWindow(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	policy := DeployWindowPolicy{
+		Location: loc,
+		Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Start:    9 * 60,
+		End:      17 * 60,
+		Freezes: []FreezeWindow{{
+			From:   time.Date(2026, 12, 24, 0, 0, 0, 0, loc),
+			To:     time.Date(2026, 12, 28, 0, 0, 0, 0, loc),
+			Reason: "holiday freeze",
+		}},
+		Commands: []string{"terraform apply"},
+	}
+	run := func(now time.Time, command string, p DeployWindowPolicy) VerificationResult {
+		t.Helper()
+		r := NewRunner("sess_test", WithStateDir(""), WithDeployWindow(p), WithClock(util.NewMockClock(now)))
+		state := r.RunFull("update infra", "Bash", map[string]interface{}{"command": command}, true)
+		for _, res := range state.Results {
+			if res.Gate == "AEGIS" {
+				return res
+			}
+		}
+		t.Fatal("no AEGIS result")
+		return VerificationResult{}
+	}
+
+	// Wednesday 10:00 IST is inside the window.
+	if res := run(time.Date(2026, 10, 14, 10, 0, 0, 0, loc), "terraform apply", policy); res.Status != "pass" {
+		t.Errorf("in window: status = %s (%s)", res.Status, res.Reason)
+	}
+
+	// Friday 18:00 IST asks and points at Monday 09:00 IST.
+	res := run(time.Date(2026, 10, 16, 18, 0, 0, 0, loc), "terraform apply -auto-approve", policy)
+	if res.Status != "ask" || res.Context["next_allowed"] != "2026-10-19T09:00:00+05:30" || res.Context["deploy_trigger"] != "terraform apply" {
+		t.Errorf("after hours: %+v", res)
+	}
+
+	// The same instant in UTC is still evaluated in the policy's zone.
+	res = run(time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC), "terraform apply", policy)
+	if res.Status != "ask" {
+		t.Errorf("utc clock: status = %s, want ask", res.Status)
+	}
+
+	// Inside the freeze the next window opens after it ends.
+	block := policy
+	block.Action = "block"
+	res = run(time.Date(2026, 12, 24, 11, 0, 0, 0, loc), "terraform apply", block)
+	if res.Status != "block" || res.Context["next_allowed"] != "2026-12-28T09:00:00+05:30" {
+		t.Errorf("freeze: %+v", res)
+	}
+
+	// Other commands are not deploys.
+	if res := run(time.Date(2026, 10, 17, 3, 0, 0, 0, loc), "terraform plan", policy); res.Status != "pass" {
+		t.Errorf("non-deploy: status = %s (%s)", res.Status, res.Reason)
+	}
+
+	// A deploy intent does not make every command a deploy, nor does
+	// another intent exempt a deploy command.
+	for _, tc := range []struct{ prompt, command, want string }{
+		{"deploy the api to production", "go test ./...", "pass"},
+		{"fix the typo in the readme", "terraform apply", "ask"},
+	} {
+		r := NewRunner("sess_test", WithStateDir(""), WithDeployWindow(policy), WithClock(util.NewMockClock(time.Date(2026, 10, 17, 3, 0, 0, 0, loc))))
+		state := r.RunFull(tc.prompt, "Bash", map[string]interface{}{"command": tc.command}, true)
+		for _, res := range state.Results {
+			if res.Gate == "AEGIS" && res.Status != tc.want {
+				t.Errorf("%q / %s: status = %s (%s), want %s", tc.prompt, tc.command, res.Status, res.Reason, tc.want)
+			}
+		}
+	}
+
+	// A window that never opens reports no next time.
+	never := policy
+	never.Days = []time.Weekday{}
+	never.Start, never.End = 0, 0
+	if reason, next := never.blocked(time.Date(2026, 10, 14, 10, 0, 0, 0, loc)); reason == "" || !next.IsZero() {
+		t.Errorf("never: reason = %q next = %v", reason, next)
+	}
+}
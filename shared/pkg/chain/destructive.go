@@ -0,0 +1,303 @@
+// Package chain provides multi-agent verification chain for kavach.
+// destructive.go: Graded destructiveness of filesystem commands. The block
+// list in detect.go is binary; this scores how much a command could destroy
+// from its target breadth, recursion and force flags.
+package chain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Destructiveness thresholds for Aegis: at or above block the command is a
+// dangerous_command violation, at or above warn it raises the threat level.
+const (
+	destructiveBlock = 0.8
+	destructiveWarn  = 0.5
+)
+
+// systemDirs are top-level directories whose removal breaks the machine.
+var systemDirs = []string{
+	"/bin", "/boot", "/dev", "/etc", "/home", "/lib", "/lib64", "/opt",
+	"/proc", "/root", "/sbin", "/sys", "/users", "/usr", "/var",
+}
+
+// homeRoots hold one home directory per user (Linux, macOS).
+var homeRoots = []string{"/home/", "/users/"}
+
+// systemTrees are system directories where any path is machine state.
+var systemTrees = []string{"/etc/", "/var/"}
+
+// findFilters narrow what find matches, so a find -delete with them is
+// less broad than its starting directory.
+var findFilters = []string{"-name", "-iname", "-path", "-ipath", "-regex", "-type", "-mtime", "-mmin", "-newer", "-size", "-user", "-empty"}
+
+// targetBreadth rates how much a path argument covers, 0.2 (one relative
+// path) to 1.0 (the filesystem root). t is lowercased.
+func targetBreadth(t string) (float64, string) {
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(t, "*"), "/")
+	switch {
+	case trimmed == "" && strings.HasPrefix(t, "/"):
+		return 1.0, "filesystem root"
+	case containsString(systemDirs, trimmed):
+		return 0.9, "system directory " + trimmed
+	case trimmed == "~" || trimmed == "$home" || trimmed == "${home}":
+		return 0.8, "home directory"
+	case isUserHome(trimmed):
+		return 0.8, "home directory " + trimmed
+	case hasAnyPrefix(trimmed, systemTrees):
+		return 0.7, "system path " + t
+	case trimmed == ".." || strings.HasPrefix(t, "../"):
+		return 0.6, "parent directory"
+	case trimmed == "" || trimmed == "." || trimmed == "$(pwd)" || trimmed == "$pwd":
+		return 0.5, "working directory"
+	case strings.HasPrefix(t, "~/") || strings.HasPrefix(t, "$home/") || strings.HasPrefix(t, "${home}/"):
+		return 0.4, "home subdirectory " + t
+	case strings.HasPrefix(t, "/"):
+		return 0.3, "absolute path " + t
+	}
+	return 0.2, "relative path " + t
+}
+
+// isUserHome reports whether p is a user's home directory (/home/alice).
+func isUserHome(p string) bool {
+	for _, root := range homeRoots {
+		if user, ok := strings.CutPrefix(p, root); ok && user != "" && !strings.Contains(user, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScoreDestructiveness grades cmd from 0 (nothing destroyed) to 1
+// (catastrophic, e.g. rm -rf /) and explains each destructive part.
+// Chained commands score as their most destructive part.
+func ScoreDestructiveness(cmd string) (score float64, reasons []string) {
+	for _, seg := range commandSegments(normalizeCommand(cmd)) {
+		seg = stripRedirects(seg)
+		for i, tok := range seg {
+			var s float64
+			var reason string
+			switch {
+			case tok == "rm" || strings.HasSuffix(tok, "/rm"):
+				s, reason = scoreRemove(seg[i+1:])
+			case tok == "find" && (containsString(seg[i:], "-delete") || containsSequence(seg[i:], "-exec", "rm")):
+				s, reason = scoreFind(seg[i+1:])
+			case tok == "shred":
+				s, reason = scoreShred(seg[i+1:])
+			case strings.HasPrefix(tok, "mkfs"):
+				s, reason = 1.0, tok+": formats a filesystem"
+			case tok == "dd" && containsPrefix(seg[i+1:], "of=/dev/"):
+				s, reason = 1.0, "dd: writes a raw device"
+			case tok == "git" && i+1 < len(seg) && seg[i+1] == "clean":
+				s, reason = scoreGitClean(seg[i+2:])
+			}
+			if reason == "" {
+				continue
+			}
+			reasons = append(reasons, reason)
+			if s > score {
+				score = s
+			}
+		}
+	}
+	return score, reasons
+}
+
+// scoreRemove grades rm by its broadest target: recursion doubles the
+// weight and force adds to it.
+func scoreRemove(args []string) (float64, string) {
+	recursive, force := false, false
+	var targets []string
+	for _, a := range args {
+		switch {
+		case a == "-rf":
+			recursive, force = true, true
+		case a == "--recursive":
+			recursive = true
+		case a == "--force":
+			force = true
+		case strings.HasPrefix(a, "--"):
+		case strings.HasPrefix(a, "-"):
+			recursive = recursive || strings.Contains(a, "r")
+			force = force || strings.Contains(a, "f")
+		default:
+			targets = append(targets, a)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, ""
+	}
+	breadth, what := broadestTarget(targets)
+	score := breadth
+	flags := "rm"
+	if recursive {
+		flags += " -r"
+	} else {
+		score *= 0.5
+	}
+	if force {
+		flags += " -f"
+		score += 0.1
+	}
+	return clampScore(score), fmt.Sprintf("%s: %s", flags, what)
+}
+
+// scoreFind grades find -delete (or -exec rm) by its starting directory;
+// name/type/age filters narrow it.
+func scoreFind(args []string) (float64, string) {
+	var roots []string
+	for _, a := range args {
+		if strings.HasPrefix(a, "-") || a == "(" || a == "!" {
+			break
+		}
+		roots = append(roots, a)
+	}
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	score, what := broadestTarget(roots)
+	filtered := false
+	for _, f := range findFilters {
+		if containsString(args, f) {
+			filtered = true
+			break
+		}
+	}
+	if filtered {
+		score *= 0.6
+		what += " (filtered)"
+	}
+	return clampScore(score), "find -delete: " + what
+}
+
+// scoreShred grades shred, which overwrites files beyond recovery.
+func scoreShred(args []string) (float64, string) {
+	var targets []string
+	for _, a := range args {
+		if strings.HasPrefix(a, "/dev/") {
+			return 1.0, "shred: overwrites a raw device"
+		}
+		if !strings.HasPrefix(a, "-") {
+			targets = append(targets, a)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, ""
+	}
+	breadth, what := broadestTarget(targets)
+	return clampScore(breadth*0.5 + 0.1), "shred: " + what
+}
+
+// scoreGitClean grades git clean -f, which deletes untracked files.
+func scoreGitClean(args []string) (float64, string) {
+	force, dirs, ignored := false, false, false
+	for _, a := range args {
+		if a == "--force" {
+			force = true
+		}
+		if strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--") {
+			force = force || strings.Contains(a, "f")
+			dirs = dirs || strings.Contains(a, "d")
+			ignored = ignored || strings.Contains(a, "x")
+		}
+		if a == "--dry-run" || (strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--") && strings.Contains(a, "n")) {
+			return 0, ""
+		}
+	}
+	if !force {
+		return 0, ""
+	}
+	score, what := 0.2, "git clean -f: untracked files"
+	if dirs {
+		score, what = 0.3, "git clean -fd: untracked files and directories"
+	}
+	if ignored {
+		score += 0.1
+		what += ", including ignored"
+	}
+	return score, what
+}
+
+// stripRedirects drops I/O redirections and their targets from a segment,
+// so "rm -f out.txt > /dev/null" scores out.txt alone.
+func stripRedirects(seg []string) []string {
+	out := make([]string, 0, len(seg))
+	for i := 0; i < len(seg); i++ {
+		op := strings.TrimLeft(seg[i], "0123456789")
+		if !strings.HasPrefix(op, ">") && !strings.HasPrefix(op, "<") {
+			out = append(out, seg[i])
+			continue
+		}
+		if strings.Trim(op, "<>|") == "" {
+			i++ // Target is the next token
+		}
+	}
+	return out
+}
+
+// broadestTarget returns the highest breadth among targets.
+func broadestTarget(targets []string) (float64, string) {
+	best, what := 0.0, ""
+	for _, t := range targets {
+		if b, w := targetBreadth(t); b > best {
+			best, what = b, w
+		}
+	}
+	return best, what
+}
+
+// commandSegments splits normalized tokens at shell operators.
+func commandSegments(norm string) [][]string {
+	var segs [][]string
+	var cur []string
+	for _, tok := range strings.Fields(norm) {
+		switch tok {
+		case ";", "&", "|", "(", ")", "`":
+			if len(cur) > 0 {
+				segs = append(segs, cur)
+			}
+			cur = nil
+		default:
+			cur = append(cur, tok)
+		}
+	}
+	if len(cur) > 0 {
+		segs = append(segs, cur)
+	}
+	return segs
+}
+
+func containsSequence(tokens []string, a, b string) bool {
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i] == a && (tokens[i+1] == b || strings.HasSuffix(tokens[i+1], "/"+b)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPrefix(tokens []string, prefix string) bool {
+	for _, t := range tokens {
+		if strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func clampScore(s float64) float64 {
+	if s > 1 {
+		return 1
+	}
+	return s
+}
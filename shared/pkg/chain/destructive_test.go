@@ -0,0 +1,63 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScoreDestructiveness(t *testing.T) {
+	cases := []struct {
+		cmd      string
+		min, max float64
+	}{
+		{"rm -rf /", 1, 1},
+		{"sudo rm -r -f /etc", 1, 1},
+		{"rm -rf ~", 0.9, 0.9},
+		{"rm -rf *", 0.6, 0.6},
+		{"rm -rf ~/project", 0.5, 0.5},
+		{"find . -delete", 0.5, 0.5},
+		{"find . -name '*.o' -delete", 0.3, 0.3},
+		{"rm -rf ./build", 0.3, 0.3},
+		{"rm notes.txt", 0.1, 0.1},
+		{"git clean -fdx", 0.4, 0.4},
+		{"git clean -n -fd", 0, 0},
+		{"dd if=/dev/zero of=/dev/sda", 1, 1},
+		{"go test ./... && rm -rf dist", 0.3, 0.3},
+		{"ls -la", 0, 0},
+		{"rm -rf /home/alice", 0.9, 0.9},
+		{"rm -rf /Users/alice/", 0.9, 0.9},
+		{"rm -rf /etc/nginx", 0.8, 0.8},
+		{"rm -r /var/lib/postgresql", 0.7, 0.7},
+		{"rm -rf /home/alice/project/dist", 0.4, 0.4},
+		{"rm -f out.txt > /dev/null", 0.2, 0.2},
+		{"rm -f out.txt 2>/dev/null", 0.2, 0.2},
+		{"rm -f out.txt >> /var/log/cleanup.log 2>&1", 0.2, 0.2},
+	}
+	for _, tc := range cases {
+		score, reasons := ScoreDestructiveness(tc.cmd)
+		if score < tc.min-1e-9 || score > tc.max+1e-9 {
+			t.Errorf("ScoreDestructiveness(%q) = %.2f %q, want %.2f", tc.cmd, score, reasons, tc.min)
+		}
+		if (score > 0) != (len(reasons) > 0) {
+			t.Errorf("ScoreDestructiveness(%q): score %.2f with reasons %q", tc.cmd, score, reasons)
+		}
+	}
+}
+
+func TestAegisDestructiveness(t *testing.T) {
+	aegis := func(cmd string) VerificationResult {
+		r := NewRunner("sess_test")
+		r.cacheDir = ""
+		_, res := r.evalAegisGate("Bash", map[string]interface{}{"command": cmd})
+		return res
+	}
+	if res := aegis("rm -rf ~"); res.Status != "block" || !strings.Contains(res.Reason, "home directory") {
+		t.Errorf("rm -rf ~: %+v, want block", res)
+	}
+	if res := aegis("rm -rf ~/project"); res.Status != "warn" || res.Context["destructiveness"] != "0.50" {
+		t.Errorf("rm -rf ~/project: %+v, want warn at 0.50", res)
+	}
+	if res := aegis("rm -rf ./build"); res.Status != "pass" || res.Context["security_score"] != "0.70" {
+		t.Errorf("rm -rf ./build: %+v, want pass scored 0.70", res)
+	}
+}
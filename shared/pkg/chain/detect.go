@@ -0,0 +1,113 @@
+// Package chain provides multi-agent verification chain for kavach.
+// detect.go: Aegis command and edit detectors (paths: sensitive.go).
+// Inputs are normalized first so spacing, quoting, case and flag order
+// cannot slip a known-bad pattern past a substring match.
+package chain
+
+import "strings"
+
+// dangerousCommands are matched against the normalized command.
+var dangerousCommands = []string{
+	"rm -rf /", "dd if=/dev/zero", "chmod -r 777 /",
+	"curl | bash", "wget | sh",
+}
+
+// dangerousSymbols are matched with all whitespace removed.
+var dangerousSymbols = []string{
+	">/dev/sda", ":(){:|:&};:",
+}
+
+func isDangerousCommand(cmd string) bool {
+	norm := normalizeCommand(cmd)
+	for _, d := range dangerousCommands {
+		if strings.Contains(norm, d) {
+			return true
+		}
+	}
+	compact := strings.Join(strings.Fields(strings.ToLower(cmd)), "")
+	for _, d := range dangerousSymbols {
+		if strings.Contains(compact, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellOperators are split into their own tokens ("true;rm" → "true ; rm").
+var shellOperators = strings.NewReplacer(
+	";", " ; ", "&", " & ", "|", " | ", "(", " ( ", ")", " ) ", "`", " ` ",
+	"\\", "", "'", "", `"`, "",
+)
+
+// normalizeCommand lowercases cmd, drops quotes and escapes, collapses
+// whitespace and rewrites rm's recursive+force flags, in any order or
+// spelling, to "-rf".
+func normalizeCommand(cmd string) string {
+	fields := strings.Fields(shellOperators.Replace(strings.ToLower(cmd)))
+	out := make([]string, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		if fields[i] != "rm" && !strings.HasSuffix(fields[i], "/rm") {
+			out = append(out, fields[i])
+			continue
+		}
+		recursive, force := false, false
+		var flags []string
+		j := i + 1
+		for ; j < len(fields) && strings.HasPrefix(fields[j], "-"); j++ {
+			flag := fields[j]
+			if flag == "--" {
+				j++
+				break
+			}
+			switch {
+			case flag == "--recursive":
+				recursive = true
+			case flag == "--force":
+				force = true
+			case !strings.HasPrefix(flag, "--"):
+				recursive = recursive || strings.Contains(flag, "r")
+				force = force || strings.Contains(flag, "f")
+			}
+			flags = append(flags, flag)
+		}
+		out = append(out, "rm")
+		if recursive && force {
+			out = append(out, "-rf")
+		} else {
+			out = append(out, flags...)
+		}
+		i = j - 1
+	}
+	return strings.Join(out, " ")
+}
+
+var stubMarkers = []string{"todo", "fixme", "stub", "placeholder"}
+
+func isProblematicEdit(old, new string) bool {
+	// Empty (or comment-only) replacement of significant code
+	if isBlankCode(new) && len(old) > 100 {
+		return true
+	}
+	// Removing TODO/FIXME without expanding code
+	oldHasStub := containsAny(strings.ToLower(old), stubMarkers)
+	newHasStub := containsAny(strings.ToLower(new), stubMarkers)
+	if oldHasStub && !newHasStub && len(new) <= len(old) {
+		return true
+	}
+	return false
+}
+
+// zeroWidth are invisible runes that strings.TrimSpace keeps.
+var zeroWidth = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "")
+
+// isBlankCode reports whether s has no code: only whitespace, invisible
+// runes and line comments.
+func isBlankCode(s string) bool {
+	for _, line := range strings.Split(zeroWidth.Replace(s), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+// rmRootEvasions all normalize to "rm -rf /".
+var rmRootEvasions = []string{
+	"rm -rf /",
+	"rm -fr /",
+	"rm -r -f /",
+	"rm  -rf\t/",
+	"RM -RF /",
+	"rm -Rf /",
+	"rm --recursive --force /",
+	"rm --no-preserve-root -rf /",
+	"rm -rf -- /",
+	"/bin/rm -rf /",
+	`\rm -rf /`,
+	`'rm' "-rf" /`,
+	"true;rm -rf /",
+	"$(rm -rf /)",
+}
+
+func FuzzDangerousCommand(f *testing.F) {
+	for _, seed := range []string{"", "ls", "echo ok &&", "sudo", "x'", `\`, "rm -", "rm --"} {
+		f.Add(seed, "")
+		f.Add(seed, "*")
+	}
+	f.Fuzz(func(t *testing.T, prefix, suffix string) {
+		for _, form := range rmRootEvasions {
+			cmd := prefix + " " + form + " " + suffix
+			if !isDangerousCommand(cmd) {
+				t.Fatalf("not flagged: %q (normalized %q)", cmd, normalizeCommand(cmd))
+			}
+		}
+		for _, form := range []string{":(){ :|:& };:", ": ( ) { : | : & } ; :", "cat x >/dev/sda", "cat x > /dev/sda"} {
+			if cmd := prefix + "\n" + form; !isDangerousCommand(cmd) {
+				t.Fatalf("not flagged: %q", cmd)
+			}
+		}
+	})
+}
+
+func TestDangerousCommandSafe(t *testing.T) {
+	for _, cmd := range []string{"rm -rf ./build", "rm -r dist", "rm -f /tmp/x.lock", "go test ./...", "chmod 644 README.md"} {
+		if isDangerousCommand(cmd) {
+			t.Errorf("%q flagged", cmd)
+		}
+	}
+}
+
+func FuzzSensitivePath(f *testing.F) {
+	for _, seed := range []string{"", "/home/dev", "~", ".", "..", "a/..", "/", "C:"} {
+		f.Add(seed)
+	}
+	forms := []string{
+		"/etc/shadow", "/etc/./shadow", "//etc//shadow", "/etc/x/../shadow", "/ETC/SHADOW",
+		"/.ssh/id_rsa", "/./.ssh/id_ed25519", "/x/../.ssh/config",
+		"/.aws/credentials", "/.aws//credentials",
+		"/certs/server.PEM", "/tls.key",
+	}
+	f.Fuzz(func(t *testing.T, prefix string) {
+		for _, form := range forms {
+			if path := prefix + form; !isSensitivePath(path) {
+				t.Fatalf("not flagged: %q", path)
+			}
+		}
+	})
+}
+
+func TestSensitivePathRelative(t *testing.T) {
+	if !isSensitivePath(".ssh/id_rsa") || !isSensitivePath("etc/shadow") {
+		t.Error("relative sensitive paths not flagged")
+	}
+	if isSensitivePath("/home/dev/src/main.go") {
+		t.Error("source file flagged")
+	}
+}
+
+func TestSensitivePathWindows(t *testing.T) {
+	for _, p := range []string{
+		`C:\Users\dev\.ssh\id_rsa`,
+		`c:\users\dev\.aws\credentials`,
+		`%APPDATA%\gnupg\secring.gpg`,
+		`%LOCALAPPDATA%\Microsoft\Credentials\x`,
+		`C:\Windows\System32\config\SAM`,
+		`D:\keys\server.key`,
+	} {
+		if !isSensitivePath(p) {
+			t.Errorf("%s not flagged", p)
+		}
+	}
+	if isSensitivePath(`C:\Users\dev\src\main.go`) {
+		t.Error("Windows source file flagged")
+	}
+}
+
+func FuzzProblematicEdit(f *testing.F) {
+	f.Add("func handler() {}", "")
+	f.Add("// TODO: implement", "return nil")
+	f.Fuzz(func(t *testing.T, old, filler string) {
+		significant := old + strings.Repeat("x := compute()\n", 8)
+		// Whitespace, invisible runes and comments never count as code
+		for _, blank := range []string{"", " \n\t", "\u200b", "\ufeff\n", "// removed\n# gone"} {
+			if !isProblematicEdit(significant, blank) {
+				t.Fatalf("blanking %d bytes with %q not flagged", len(significant), blank)
+			}
+		}
+		// Dropping a stub marker without growing the code
+		stub := old + "// TODO"
+		if !isProblematicEdit(stub, "") {
+			t.Fatalf("stub removal not flagged: %q", stub)
+		}
+		_ = isProblematicEdit(old, filler)
+	})
+}
+
+func TestSensitivePathCategories(t *testing.T) {
+	cases := map[string]string{
+		"/home/dev/.bash_history": SensitiveHistory,
+		"~/.zsh_history":          SensitiveHistory,
+		"/home/dev/.netrc":        SensitiveCredential,
+		"/home/dev/.config/google-chrome/Default/Login Data": SensitiveCredential,
+		"/home/dev/.mozilla/firefox/abc.default/logins.json": SensitiveCredential,
+		"/Users/dev/.docker/config.json":                     SensitiveConfig,
+		"/home/dev/.kube/config":                             SensitiveConfig,
+		`C:\Users\dev\.ssh\id_rsa`:                           SensitiveCredential,
+	}
+	for p, want := range cases {
+		if got := matchSensitivePath(p, nil); got == nil || got.Category != want {
+			t.Errorf("matchSensitivePath(%q) = %v, want %s", p, got, want)
+		}
+	}
+
+	extra := []SensitivePath{{Pattern: `\Secrets\Team.kdbx`, Category: SensitiveCredential}}
+	if matchSensitivePath("/srv/secrets/team.kdbx", nil) != nil {
+		t.Fatal("extra pattern matched without being configured")
+	}
+	r := NewRunner("sess_test", WithStateDir(""), WithSensitivePaths(extra))
+	_, res := r.evalAegisGate("Read", map[string]interface{}{"file_path": "/srv/secrets/team.kdbx"})
+	if res.Status != "block" || res.Reason != "Sensitive file access (credential): /srv/secrets/team.kdbx" {
+		t.Errorf("configured path = %+v", res)
+	}
+	_, res = r.evalAegisGate("Read", map[string]interface{}{"file_path": "/home/dev/.bash_history"})
+	if res.Reason != "Sensitive file access (history): /home/dev/.bash_history" {
+		t.Errorf("history reason = %q", res.Reason)
+	}
+}
@@ -0,0 +1,124 @@
+// Package chain provides multi-agent verification chain for kavach.
+// endpoints.go: Aegis advisory for hardcoded localhost/IP addresses and
+// developer-specific paths written into deployment config.
+package chain
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EndpointFinding is one hardcoded endpoint on a line of written content.
+type EndpointFinding struct {
+	Line  int    // 1-based, within the written content
+	Match string // Matched text
+}
+
+func (f EndpointFinding) String() string {
+	return fmt.Sprintf("line %d: %s", f.Line, f.Match)
+}
+
+// EndpointPolicy flags hardcoded endpoints in files likely destined for
+// production. Files are base-name globs ("docker-compose*.yml", "config.yaml")
+// or, when they contain "/", path substrings ("k8s/"). Patterns are regexps.
+type EndpointPolicy struct {
+	Files    []string
+	Patterns []string
+	Action   string // "warn" (default), "ask" or "block"
+
+	compiled []*regexp.Regexp
+}
+
+// WithEndpointPolicy enables the hardcoded endpoint check for Write/Edit.
+// Invalid patterns are skipped.
+func WithEndpointPolicy(p EndpointPolicy) Option {
+	for _, pat := range p.Patterns {
+		if re, err := regexp.Compile(pat); err == nil {
+			p.compiled = append(p.compiled, re)
+		}
+	}
+	return func(r *Runner) {
+		r.endpoints = &p
+	}
+}
+
+// targets reports whether path is a config file the policy applies to.
+func (p *EndpointPolicy) targets(path string) bool {
+	slashed := filepath.ToSlash(strings.ToLower(path))
+	base := filepath.Base(slashed)
+	for _, f := range p.Files {
+		f = strings.ToLower(f)
+		if strings.Contains(f, "/") {
+			if strings.Contains(slashed, f) {
+				return true
+			}
+		} else if ok, _ := filepath.Match(f, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// detectHardcodedEndpoints returns every pattern match in content with its
+// line number, or nil when path is not a targeted config file. Comment
+// lines are ignored.
+func (p *EndpointPolicy) detectHardcodedEndpoints(path, content string) []EndpointFinding {
+	if path == "" || content == "" || !p.targets(path) {
+		return nil
+	}
+	var findings []EndpointFinding
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		for _, re := range p.compiled {
+			if m := re.FindString(line); m != "" {
+				findings = append(findings, EndpointFinding{Line: i + 1, Match: m})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// checkEndpoints applies the endpoint policy to an otherwise passing Aegis result.
+func (r *Runner) checkEndpoints(toolName string, toolInput map[string]interface{}, result *VerificationResult) {
+	if r.endpoints == nil || result.Status != "pass" {
+		return
+	}
+	var content string
+	switch toolName {
+	case "Write":
+		content, _ = toolInput["content"].(string)
+	case "Edit":
+		content, _ = toolInput["new_string"].(string)
+	default:
+		return
+	}
+	path, _ := toolInput["file_path"].(string)
+	findings := r.endpoints.detectHardcodedEndpoints(path, content)
+	if len(findings) == 0 {
+		return
+	}
+
+	switch r.endpoints.Action {
+	case "ask", "block":
+		result.Status = r.endpoints.Action
+	default:
+		result.Status = "warn"
+	}
+	lines := make([]string, len(findings))
+	details := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = strconv.Itoa(f.Line)
+		details[i] = f.String()
+	}
+	result.Reason = "Hardcoded endpoints in " + filepath.Base(path) + ": " + strings.Join(details, "; ")
+	result.Source = SourceConfig
+	result.NextAction = "Read hosts, IPs and paths from environment variables or per-environment config"
+	result.Context["endpoint_lines"] = strings.Join(lines, ",")
+}
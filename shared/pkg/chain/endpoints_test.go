@@ -0,0 +1,59 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/claude/shared/pkg/config"
+)
+
+func TestDetectHardcodedEndpoints(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.ReloadGatesConfig().Endpoints
+	opt := WithEndpointPolicy(EndpointPolicy{Files: cfg.Files, Patterns: cfg.Patterns})
+	r := &Runner{}
+	opt(r)
+	policy := r.endpoints
+
+	compose := "services:\n  api:\n    environment:\n      DB_HOST: 127.0.0.1\n      # local: http://localhost:5432\n      CACHE: redis://192.168.1.20:6379\n    volumes:\n      - /Users/dev/src:/app\n"
+	findings := policy.detectHardcodedEndpoints("deploy/docker-compose.yml", compose)
+	want := []EndpointFinding{{4, "127.0.0.1"}, {6, "192.168.1.20"}, {8, "/Users/dev/"}}
+	if len(findings) != len(want) {
+		t.Fatalf("findings = %v, want %v", findings, want)
+	}
+	for i := range want {
+		if findings[i] != want[i] {
+			t.Errorf("finding %d = %v, want %v", i, findings[i], want[i])
+		}
+	}
+
+	for _, path := range []string{"k8s/api/deployment.yaml", "/srv/app/config.yaml", "compose.yml"} {
+		if len(policy.detectHardcodedEndpoints(path, "url: http://localhost:8080")) != 1 {
+			t.Errorf("%s not targeted", path)
+		}
+	}
+	for _, path := range []string{"internal/server_test.go", "internal/config.go", "src/settings.py", "config.ts"} {
+		if got := policy.detectHardcodedEndpoints(path, "addr := \"localhost:8080\""); got != nil {
+			t.Errorf("%s: non-config file flagged: %v", path, got)
+		}
+	}
+	for _, path := range []string{"settings.toml", "config.json", "app/config.env"} {
+		if len(policy.detectHardcodedEndpoints(path, "url = \"http://localhost:8080\"")) != 1 {
+			t.Errorf("%s not targeted", path)
+		}
+	}
+
+	state := NewRunner("sess_test", WithStateDir(""), opt).
+		RunFull("add compose file", "Write", map[string]interface{}{"file_path": "docker-compose.yml", "content": compose}, false)
+	found := false
+	for _, res := range state.Results {
+		if res.Gate == "AEGIS" {
+			found = true
+			if res.Status != "warn" || res.Context["endpoint_lines"] != "4,6,8" {
+				t.Errorf("aegis = %+v", res)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("no AEGIS result")
+	}
+}
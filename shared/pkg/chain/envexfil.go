@@ -0,0 +1,248 @@
+// Package chain provides multi-agent verification chain for kavach.
+// envexfil.go: Aegis detector for environment secrets leaving the machine:
+// a command that enumerates the environment (printenv, env, echo $SECRET)
+// whose output is piped, substituted or redirected into a network or
+// file-write sink. Either half alone, or both merely in sequence, is benign.
+package chain
+
+import "strings"
+
+// networkSinks send data off the machine.
+var networkSinks = []string{
+	"curl", "wget", "nc", "ncat", "netcat", "socat", "telnet",
+	"scp", "sftp", "rsync", "ftp", "ssh",
+}
+
+// secretVarWords are the "_"-separated parts of a variable name that mark
+// it as likely holding a secret.
+var secretVarWords = []string{
+	"secret", "token", "password", "passwd", "key", "apikey", "credential", "auth", "session", "cookie", "private",
+}
+
+// detectEnvExfil returns "<source> -> <sink>" when cmd reads environment
+// secrets and their output reaches the network or a file, or "". The
+// output flows through pipes, $(...) substitutions and redirects; a source
+// merely sequenced with a sink ("env && curl ...") is benign.
+func detectEnvExfil(cmd string) string {
+	segs := shellFlow(normalizeCommand(cmd))
+	for i, seg := range segs {
+		source := envSource(seg.toks)
+		if source == "" {
+			continue
+		}
+		for _, j := range segs.reach(i) {
+			if sink := exfilSink(segs[j].toks); sink != "" {
+				return source + " -> " + sink
+			}
+		}
+	}
+	return ""
+}
+
+// flowSeg is one simple command of a shell line and where its output goes.
+type flowSeg struct {
+	toks  []string
+	from  int // segment piped into this one's stdin, or -1
+	outer int // segment whose arguments this $(...) output becomes, or -1
+}
+
+type flowSegs []flowSeg
+
+// shellFlow splits normalized tokens into segments, recording pipes and
+// command substitutions. "||", "&&", ";" and "&" only sequence commands.
+func shellFlow(norm string) flowSegs {
+	toks := strings.Fields(norm)
+	segs := flowSegs{{from: -1, outer: -1}}
+	cur := 0
+	var stack []int
+	inBacktick := false
+	next := func(from, outer int) {
+		segs = append(segs, flowSeg{from: from, outer: outer})
+		cur = len(segs) - 1
+	}
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		switch {
+		case tok == "|" && i+1 < len(toks) && toks[i+1] == "|":
+			i++
+			next(-1, -1)
+		case tok == "|":
+			if i+1 < len(toks) && toks[i+1] == "&" {
+				i++
+			}
+			next(cur, -1)
+		case tok == "&" && i+1 < len(toks) && strings.HasPrefix(toks[i+1], ">"):
+			// "&>file" redirects both streams
+			toks[i+1] = "&" + toks[i+1]
+		case strings.HasSuffix(tok, ">") && i+2 < len(toks) && toks[i+1] == "&":
+			// "2>&1" duplicates a descriptor
+			segs[cur].toks = append(segs[cur].toks, tok+"&"+toks[i+2])
+			i += 2
+		case tok == ";" || tok == "&":
+			next(-1, -1)
+		case tok == "(" || (tok == "`" && !inBacktick):
+			inBacktick = inBacktick || tok == "`"
+			stack = append(stack, cur)
+			outer := cur
+			if len(segs[cur].toks) == 0 {
+				outer = -1 // a subshell group, not a substitution
+			}
+			next(-1, outer)
+		case (tok == ")" || tok == "`") && len(stack) > 0:
+			inBacktick = inBacktick && tok != "`"
+			cur = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		default:
+			segs[cur].toks = append(segs[cur].toks, tok)
+		}
+	}
+	return segs
+}
+
+// reach returns the segments the output of segment i reaches, in order:
+// itself, the pipe stages after it, and any command a substitution feeds.
+func (segs flowSegs) reach(i int) []int {
+	out := []int{i}
+	seen := map[int]bool{i: true}
+	for k := 0; k < len(out); k++ {
+		add := func(j int) {
+			if j >= 0 && !seen[j] {
+				seen[j] = true
+				out = append(out, j)
+			}
+		}
+		for j, seg := range segs {
+			if seg.from == out[k] {
+				add(j)
+			}
+		}
+		add(segs[out[k]].outer)
+	}
+	return out
+}
+
+// envSource names how seg reads the environment: a full dump, a secret
+// variable, or the environ file of a process.
+func envSource(seg []string) string {
+	for _, tok := range seg {
+		if strings.Contains(tok, "/environ") {
+			return tok
+		}
+	}
+	name, args := commandName(seg)
+	switch name {
+	case "printenv":
+		if len(args) == 0 || hasSecretVar(args, false) {
+			return strings.Join(append([]string{name}, args...), " ")
+		}
+	case "env":
+		// "env FOO=1 cmd" runs cmd; only flags alone print the environment
+		for _, a := range args {
+			if !strings.HasPrefix(a, "-") {
+				return ""
+			}
+		}
+		return "env"
+	case "export", "declare", "typeset":
+		if containsString(args, "-p") || containsString(args, "-x") {
+			return name + " " + args[0]
+		}
+	case "set":
+		if len(args) == 0 {
+			return "set"
+		}
+	case "echo", "printf":
+		if hasSecretVar(args, true) {
+			return name + " " + strings.Join(args, " ")
+		}
+	}
+	return ""
+}
+
+// exfilSink names the network client or file redirect in seg.
+func exfilSink(seg []string) string {
+	if name, _ := commandName(seg); containsString(networkSinks, name) || name == "tee" {
+		return name
+	}
+	for i, tok := range seg {
+		if strings.Contains(tok, "/dev/tcp/") || strings.Contains(tok, "/dev/udp/") {
+			return tok
+		}
+		target, ok := redirectTarget(tok)
+		if !ok {
+			continue
+		}
+		if target == "" && i+1 < len(seg) {
+			target = seg[i+1]
+		}
+		if target != "" && !strings.HasPrefix(target, "/dev/") && !strings.HasPrefix(target, "&") {
+			return "> " + target
+		}
+	}
+	return ""
+}
+
+// redirectTarget reports whether tok is an output redirect ("> f", ">>f",
+// "2>f") and returns the target written in the same token.
+func redirectTarget(tok string) (string, bool) {
+	tok = strings.TrimLeft(tok, "0123456789&")
+	if !strings.HasPrefix(tok, ">") {
+		return "", false
+	}
+	return strings.TrimLeft(tok, ">|"), true
+}
+
+// commandName returns the command a segment runs and its arguments up to
+// the first redirect, skipping sudo and leading VAR=value assignments.
+func commandName(seg []string) (string, []string) {
+	for i, tok := range seg {
+		if tok == "sudo" || (strings.Contains(tok, "=") && !strings.HasPrefix(tok, "-")) {
+			continue
+		}
+		args := seg[i+1:]
+		for j, a := range args {
+			if _, ok := redirectTarget(a); ok {
+				args = args[:j]
+				break
+			}
+		}
+		return tok[strings.LastIndex(tok, "/")+1:], args
+	}
+	return "", nil
+}
+
+// hasSecretVar reports whether args name a secret-looking variable; with
+// deref only "$NAME" / "${NAME}" references count.
+func hasSecretVar(args []string, deref bool) bool {
+	for _, a := range args {
+		for _, ref := range strings.Split(a, "$")[1:] {
+			if isSecretVar(strings.TrimPrefix(ref, "{")) {
+				return true
+			}
+		}
+		if !deref && isSecretVar(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretVar reports whether the variable name at the start of s has a
+// "_"-separated part that is a secret word ("AWS_SECRET_KEY", "API_TOKENS"
+// but not "KEYBOARD_LAYOUT").
+func isSecretVar(s string) bool {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end >= 0 {
+		s = s[:end]
+	}
+	for _, part := range strings.Split(strings.ToLower(s), "_") {
+		for _, w := range secretVarWords {
+			if part == w || part == w+"s" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// Package chain provides multi-agent verification chain for kavach.
+// evasion.go: Aegis advisory for Bash commands that switch off logging,
+// shell history, error checking, linters or security tooling. Each is
+// often harmless alone, so a command is flagged only when it carries
+// several, and the default is a warn rather than a block.
+package chain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// EvasionPolicy flags commands matching at least MinSignals of Patterns.
+// Patterns are regexps over the normalized command (lowercased, quotes
+// dropped, whitespace collapsed), so "export HISTFILE='/dev/null'" is seen
+// as "export histfile=/dev/null".
+type EvasionPolicy struct {
+	Patterns   []string
+	Action     string // "warn" (default), "ask" or "block"
+	MinSignals int    // Independent matches needed in one command; <= 0 means 2
+
+	compiled []*regexp.Regexp
+}
+
+// WithEvasionPolicy enables the security evasion check for Bash.
+// Invalid patterns are skipped.
+func WithEvasionPolicy(p EvasionPolicy) Option {
+	for _, pat := range p.Patterns {
+		if re, err := regexp.Compile(pat); err == nil {
+			p.compiled = append(p.compiled, re)
+		}
+	}
+	return func(r *Runner) {
+		r.evasion = &p
+	}
+}
+
+// detectSecurityEvasion returns the matched text of each pattern that hits
+// cmd, in pattern order. A match overlapping an earlier one is the same
+// signal seen twice and is left out.
+func (p *EvasionPolicy) detectSecurityEvasion(cmd string) []string {
+	if cmd == "" {
+		return nil
+	}
+	norm := normalizeCommand(cmd)
+	var matches []string
+	var spans [][]int
+	for _, re := range p.compiled {
+		loc := re.FindStringIndex(norm)
+		if loc == nil || loc[0] == loc[1] || overlapsAny(loc, spans) {
+			continue
+		}
+		spans = append(spans, loc)
+		matches = append(matches, strings.TrimSpace(norm[loc[0]:loc[1]]))
+	}
+	return matches
+}
+
+// minSignals is the number of independent matches that flag a command.
+func (p *EvasionPolicy) minSignals() int {
+	if p.MinSignals <= 0 {
+		return 2
+	}
+	return p.MinSignals
+}
+
+func overlapsAny(loc []int, spans [][]int) bool {
+	for _, s := range spans {
+		if loc[0] < s[1] && s[0] < loc[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEvasion applies the evasion policy to an otherwise passing Aegis result.
+func (r *Runner) checkEvasion(toolName string, toolInput map[string]interface{}, result *VerificationResult) {
+	if r.evasion == nil || toolName != "Bash" || result.Status != "pass" {
+		return
+	}
+	cmd, _ := toolInput["command"].(string)
+	matches := r.evasion.detectSecurityEvasion(cmd)
+	if len(matches) < r.evasion.minSignals() {
+		return
+	}
+
+	switch r.evasion.Action {
+	case "ask", "block":
+		result.Status = r.evasion.Action
+	default:
+		result.Status = "warn"
+	}
+	result.Reason = "security_evasion: " + strings.Join(matches, "; ")
+	result.Source = SourceConfig
+	result.NextAction = "Keep logging, history, linters and security tooling enabled; fix the underlying issue instead"
+	result.Context["security_evasion"] = strings.Join(matches, "; ")
+}
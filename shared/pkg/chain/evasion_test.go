@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/claude/shared/pkg/config"
+)
+
+func TestDetectSecurityEvasion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.ReloadGatesConfig().Evasion
+	opt := WithEvasionPolicy(EvasionPolicy{Patterns: cfg.Patterns})
+	r := &Runner{}
+	opt(r)
+	policy := r.evasion
+
+	evasions := map[string]string{
+		"set +e":                   "set +e && ./deploy.sh",
+		"set +eu":                  "set +eu; make",
+		"set +o errexit":           "set +o errexit",
+		"set +o history":           "set +o history",
+		"unset histfile":           "unset HISTFILE",
+		"histfile=/dev/null":       `export HISTFILE="/dev/null"`,
+		"histsize=0":               "export HISTSIZE=0",
+		"histfilesize=0":           "HISTFILESIZE=0 bash",
+		"history -c":               "history -c",
+		"rm history":               "rm -f ~/.bash_history",
+		"redirect log":             "echo '' > /var/log/auth.log",
+		"truncate log":             "truncate -s 0 /var/log/syslog",
+		"journalctl vacuum":        "journalctl --vacuum-time=1s",
+		"auditctl disable":         "sudo auditctl -e 0",
+		"auditctl delete":          "auditctl -D",
+		"systemctl stop auditd":    "systemctl stop auditd",
+		"service stop rsyslog":     "service rsyslog stop",
+		"setenforce":               "setenforce 0",
+		"ufw disable":              "ufw disable",
+		"iptables flush":           "iptables -F",
+		"no-verify":                "git commit --no-verify -m 'wip'",
+		"hooks path":               "git config core.hooksPath /dev/null",
+		"eslint-disable":           "sed -i '1i /* eslint-disable */' src/app.js",
+		"no-eslintrc":              "npx eslint --no-eslintrc src",
+		"chmod 777":                "chmod -R 777 .",
+		"chmod hooks":              "chmod -x .git/hooks/pre-commit",
+		"chattr immutable removed": "chattr -i /etc/resolv.conf",
+	}
+	hit := make([]bool, len(policy.compiled))
+	for name, cmd := range evasions {
+		if len(policy.detectSecurityEvasion(cmd)) == 0 {
+			t.Errorf("%s: %q not flagged", name, cmd)
+		}
+		for i, re := range policy.compiled {
+			if re.MatchString(normalizeCommand(cmd)) {
+				hit[i] = true
+			}
+		}
+	}
+	for i, ok := range hit {
+		if !ok {
+			t.Errorf("default pattern %q has no test case", policy.compiled[i])
+		}
+	}
+
+	for _, cmd := range []string{"set -euo pipefail", "history | tail", "git commit -m 'fix: verify input'", "chmod 644 README.md", "cat /var/log/syslog", "eslint src"} {
+		if got := policy.detectSecurityEvasion(cmd); got != nil {
+			t.Errorf("%q flagged: %v", cmd, got)
+		}
+	}
+
+	// A match overlapping an earlier one is not a second signal
+	overlap := &Runner{}
+	WithEvasionPolicy(EvasionPolicy{Patterns: []string{`histfile=/dev/null`, `export histfile`}})(overlap)
+	if got := overlap.evasion.detectSecurityEvasion("export HISTFILE=/dev/null"); len(got) != 1 {
+		t.Errorf("overlapping matches = %q, want one", got)
+	}
+
+	// One signal alone passes; two in the same command warn
+	aegis := func(command string) VerificationResult {
+		state := NewRunner("sess_test", WithStateDir(""), opt).
+			RunFull("run deploy", "Bash", map[string]interface{}{"command": command}, false)
+		for _, res := range state.Results {
+			if res.Gate == "AEGIS" {
+				return res
+			}
+		}
+		t.Fatal("no AEGIS result")
+		return VerificationResult{}
+	}
+	if res := aegis("unset HISTFILE; ./deploy.sh"); res.Status != "pass" {
+		t.Errorf("one signal: %+v", res)
+	}
+	if res := aegis("unset HISTFILE; ./deploy.sh; history -c"); res.Status != "warn" || res.Context["security_evasion"] != "unset histfile; history -c" {
+		t.Errorf("two signals: %+v", res)
+	}
+}
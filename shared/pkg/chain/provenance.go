@@ -0,0 +1,103 @@
+// Package chain provides multi-agent verification chain for kavach.
+// provenance.go: Decision provenance - what config, overrides and inputs
+// led Aegis to its verdict, so an audit can reconstruct the decision.
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxInputSummary caps each summarized input value.
+const maxInputSummary = 80
+
+// Provenance records what contributed to a verification decision.
+type Provenance struct {
+	Source           string            `json:"source"` // "chain_verification", "post_write_verification"
+	Timestamp        string            `json:"timestamp"`
+	ConfigHash       string            `json:"config_hash,omitempty"`
+	OverridesApplied []string          `json:"overrides_applied,omitempty"` // Prior session overrides (audit rules)
+	Inputs           map[string]string `json:"inputs,omitempty"`            // Gate inputs, truncated
+}
+
+// NewProvenance starts a provenance record stamped now.
+func NewProvenance(source string) Provenance {
+	return newProvenance(source, time.Now())
+}
+
+// newProvenance starts a provenance record stamped at the given time.
+func newProvenance(source string, at time.Time) Provenance {
+	return Provenance{
+		Source:    source,
+		Timestamp: at.Format(time.RFC3339),
+		Inputs:    map[string]string{},
+	}
+}
+
+// AddInput records a summarized gate input; empty values are skipped.
+func (p *Provenance) AddInput(key, value string) {
+	if value == "" {
+		return
+	}
+	if p.Inputs == nil {
+		p.Inputs = map[string]string{}
+	}
+	if len(value) > maxInputSummary {
+		value = value[:maxInputSummary] + "..."
+	}
+	p.Inputs[key] = value
+}
+
+// String renders provenance on one line for TOON output.
+func (p Provenance) String() string {
+	out := p.Source + ":" + p.Timestamp
+	if p.ConfigHash != "" {
+		out += " config=" + p.ConfigHash
+	}
+	if len(p.OverridesApplied) > 0 {
+		out += " overrides=" + strings.Join(p.OverridesApplied, ",")
+	}
+	if len(p.Inputs) > 0 {
+		keys := make([]string, 0, len(p.Inputs))
+		for k := range p.Inputs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = fmt.Sprintf("%s=%q", k, p.Inputs[k])
+		}
+		out += " inputs=" + strings.Join(parts, ",")
+	}
+	return out
+}
+
+// UnmarshalJSON accepts the legacy "source:timestamp" string form so chain
+// state persisted before provenance became a struct still loads.
+func (p *Provenance) UnmarshalJSON(data []byte) error {
+	var legacy string
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		source, ts, _ := strings.Cut(legacy, ":")
+		*p = Provenance{Source: source, Timestamp: ts}
+		return nil
+	}
+	type alias Provenance
+	var a alias
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*p = Provenance(a)
+	return nil
+}
+
+// WithProvenance stamps Aegis decisions with the effective config hash and
+// the session's prior overrides.
+func WithProvenance(configHash string, overrides []string) Option {
+	return func(r *Runner) {
+		r.configHash = configHash
+		r.overrides = overrides
+	}
+}
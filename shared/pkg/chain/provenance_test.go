@@ -0,0 +1,40 @@
+package chain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAegisProvenance(t *testing.T) {
+	r := NewRunner("sess_test", WithProvenance("abc123def456", []string{"bash.warn_commands:sudo"}))
+	r.cacheDir = ""
+	state := r.RunFull("fix typo", "Bash", map[string]interface{}{"command": "ls -la"}, true)
+
+	prov := state.Aegis.MemoryProvenance
+	if prov.Source != "chain_verification" || prov.ConfigHash != "abc123def456" {
+		t.Errorf("provenance = %+v", prov)
+	}
+	if len(prov.OverridesApplied) != 1 || prov.Inputs["tool"] != "Bash" || prov.Inputs["command"] != "ls -la" {
+		t.Errorf("provenance overrides/inputs = %+v", prov)
+	}
+	if toon := r.ToTOON(); !strings.Contains(toon, "provenance: chain_verification:") || !strings.Contains(toon, "config=abc123def456") {
+		t.Errorf("ToTOON missing provenance line:\n%s", toon)
+	}
+}
+
+func TestProvenanceLegacyJSON(t *testing.T) {
+	var a AegisVerification
+	if err := json.Unmarshal([]byte(`{"passed":true,"memory_provenance":"chain_verification:2026-01-01T00:00:00Z"}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.MemoryProvenance.Source != "chain_verification" || a.MemoryProvenance.Timestamp != "2026-01-01T00:00:00Z" {
+		t.Errorf("legacy provenance = %+v", a.MemoryProvenance)
+	}
+
+	long := NewProvenance("x")
+	long.AddInput("command", strings.Repeat("a", 200))
+	if n := len(long.Inputs["command"]); n != maxInputSummary+3 {
+		t.Errorf("input summary length = %d, want truncated", n)
+	}
+}
@@ -0,0 +1,122 @@
+// Package chain provides multi-agent verification chain for kavach.
+// recommend.go: Advisory Aegis recommendations and their session-level
+// escalation once the same category keeps recurring.
+package chain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// recommendationRule flags an allowed but avoidable pattern.
+type recommendationRule struct {
+	category string
+	tools    []string
+	patterns []string // Lowercase substrings of the command or written code
+	except   []string // Substrings removed before matching (e.g. localhost URLs)
+	advice   string
+}
+
+var recommendationRules = []recommendationRule{
+	{"privilege", []string{"Bash"}, []string{"sudo "}, nil,
+		"Avoid sudo; scope the command to user-owned paths"},
+	{"world_writable", []string{"Bash"}, []string{"chmod 777", "chmod -r 777", "chmod a+w", "chmod o+w"}, nil,
+		"Use the narrowest file mode instead of world-writable permissions"},
+	{"recursive_delete", []string{"Bash"}, []string{"rm -rf ", "rm -fr "}, nil,
+		"Prefer targeted deletes (or a dry run) over recursive removal"},
+	{"insecure_transport", []string{"Bash", "Write", "Edit"}, []string{"http://", "--insecure", "curl -k ", "verify=false"},
+		[]string{"http://localhost", "http://127.0.0.1"},
+		"Use HTTPS and keep TLS verification on"},
+	{"shell_eval", []string{"Write", "Edit"}, []string{"eval(", "shell=true", "os.system(", "child_process.exec("}, nil,
+		"Pass argument lists instead of evaluating or shelling out dynamic strings"},
+	// An entity declaration, not a plain "<!DOCTYPE html>", is the XXE risk
+	{"xml_entities", []string{"Write", "Edit"}, []string{"<!entity", "resolve_entities=true"}, nil,
+		"Disable DTD entity expansion when parsing XML (XXE)"},
+}
+
+// recommendationText returns the tool input text that rules inspect.
+func recommendationText(toolName string, toolInput map[string]interface{}) string {
+	key := ""
+	switch toolName {
+	case "Bash":
+		key = "command"
+	case "Write":
+		key = "content"
+	case "Edit":
+		key = "new_string"
+	}
+	text, _ := toolInput[key].(string)
+	return strings.ToLower(text)
+}
+
+// addRecommendations appends advisory findings; they never fail the gate.
+func addRecommendations(v *AegisVerification, toolName string, toolInput map[string]interface{}) {
+	text := recommendationText(toolName, toolInput)
+	if text == "" {
+		return
+	}
+	for _, rule := range recommendationRules {
+		if !containsString(rule.tools, toolName) {
+			continue
+		}
+		scan := text
+		for _, e := range rule.except {
+			scan = strings.ReplaceAll(scan, e, "")
+		}
+		if containsAny(scan, rule.patterns) {
+			v.Recommendations = append(v.Recommendations, rule.advice)
+			v.RecommendationCategories = append(v.RecommendationCategories, rule.category)
+		}
+	}
+}
+
+// RecommendationBudget escalates Aegis recommendations that recur within
+// a session. Counts holds occurrences from earlier runs.
+type RecommendationBudget struct {
+	Counts    map[string]int
+	Threshold int    // Occurrences, including this run, that escalate
+	Action    string // "warn" or "ask"
+}
+
+// WithRecommendationBudget turns repeated recommendations into a warn/ask.
+func WithRecommendationBudget(b RecommendationBudget) Option {
+	return func(r *Runner) {
+		r.recommend = &b
+	}
+}
+
+// recurring returns "category=count" for categories at or over threshold.
+func (b *RecommendationBudget) recurring(categories []string) []string {
+	if b.Threshold <= 0 {
+		return nil
+	}
+	var out []string
+	for _, c := range categories {
+		if n := b.Counts[c] + 1; n >= b.Threshold {
+			out = append(out, fmt.Sprintf("%s=%d", c, n))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// escalateRecommendations consolidates recurring recommendations into a
+// single warn/ask on an otherwise passing Aegis result.
+func (r *Runner) escalateRecommendations(aegis *AegisVerification, result *VerificationResult) {
+	if r.recommend == nil || result.Status != "pass" {
+		return
+	}
+	hits := r.recommend.recurring(aegis.RecommendationCategories)
+	if len(hits) == 0 {
+		return
+	}
+	result.Status = "warn"
+	if r.recommend.Action == "ask" {
+		result.Status = "ask"
+	}
+	result.Reason = "Recurring security recommendations this session: " + strings.Join(hits, ",")
+	result.Source = SourceConfig
+	result.NextAction = strings.Join(CapFindings(aegis.Recommendations, r.maxFindings), "; ")
+	result.Context["recurring_recommendations"] = strings.Join(hits, ",")
+}
@@ -0,0 +1,58 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAegisRecommendations(t *testing.T) {
+	cases := []struct {
+		tool  string
+		input map[string]interface{}
+		want  []string
+	}{
+		{"Bash", map[string]interface{}{"command": "sudo apt install jq"}, []string{"privilege"}},
+		{"Bash", map[string]interface{}{"command": "curl http://localhost:8080/health"}, nil},
+		{"Bash", map[string]interface{}{"command": "curl -k https://api.example.com"}, []string{"insecure_transport"}},
+		{"Write", map[string]interface{}{"content": "subprocess.run(cmd, shell=True)"}, []string{"shell_eval"}},
+		{"Read", map[string]interface{}{"file_path": "/src/sudo .go"}, nil},
+		{"Write", map[string]interface{}{"content": "<!DOCTYPE html>\n<html></html>"}, nil},
+		{"Write", map[string]interface{}{"content": `<!DOCTYPE r [<!ENTITY x SYSTEM "file:///etc/passwd">]><r>&x;</r>`}, []string{"xml_entities"}},
+	}
+	for _, tc := range cases {
+		v := AegisVerify(nil, tc.tool, tc.input)
+		if !v.Passed {
+			t.Errorf("%s %v: recommendations must not fail the gate", tc.tool, tc.input)
+		}
+		if strings.Join(v.RecommendationCategories, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s %v: categories = %v, want %v", tc.tool, tc.input, v.RecommendationCategories, tc.want)
+		}
+	}
+}
+
+func TestRecommendationEscalation(t *testing.T) {
+	input := map[string]interface{}{"command": "sudo systemctl restart nginx"}
+	run := func(counts map[string]int, action string) *ChainState {
+		r := NewRunner("sess_test", WithStateDir(""), WithRecommendationBudget(RecommendationBudget{
+			Counts: counts, Threshold: 3, Action: action,
+		}))
+		return r.RunFull("restart nginx", "Bash", input, true)
+	}
+
+	if state := run(map[string]int{"privilege": 1}, "warn"); state.Results[2].Status != "pass" {
+		t.Errorf("second occurrence: AEGIS = %q, want pass", state.Results[2].Status)
+	}
+
+	state := run(map[string]int{"privilege": 2}, "warn")
+	aegis := state.Results[2]
+	if aegis.Gate != "AEGIS" || aegis.Status != "warn" {
+		t.Fatalf("third occurrence: %s = %q, want AEGIS warn", aegis.Gate, aegis.Status)
+	}
+	if aegis.Context["recurring_recommendations"] != "privilege=3" || !strings.Contains(aegis.NextAction, "sudo") {
+		t.Errorf("consolidated message = %q / %q", aegis.Context["recurring_recommendations"], aegis.NextAction)
+	}
+
+	if state := run(map[string]int{"privilege": 5}, "ask"); state.FinalStatus != "ask" {
+		t.Errorf("ask action: FinalStatus = %q, want ask", state.FinalStatus)
+	}
+}
@@ -0,0 +1,42 @@
+// Package chain provides multi-agent verification chain for kavach.
+// recon.go: Prompts that ask the agent to read, print or list kavach's own
+// gates config or hook definitions. Knowing the rules is the first step to
+// bypassing them, so such prompts are a reconnaissance signal.
+package chain
+
+import "strings"
+
+// reconTargets name kavach's configuration in a prompt.
+var reconTargets = []string{
+	"gates/config.json", "gates config", "gate config", "kavach config", "kavach's config",
+	"kavach rules", "kavach settings", "hook definitions", "hook config", "hooks config",
+	".claude/hooks", "configured hooks", "hooks are configured", "hooks in settings",
+	"security config", "blocked_commands", "blocked_paths",
+}
+
+// reconVerbs ask for the target's content. A user asking to see their own
+// config is common, so these alone are not evasive.
+var reconVerbs = []string{
+	"show", "print", "cat ", "read", "dump", "display", "reveal", "list", "output",
+	"what is in", "what's in", "contents", "enumerate", "paste",
+}
+
+// evasionVerbs ask for a way around the target.
+var evasionVerbs = []string{"bypass", "get around", "evade", "disable", "turn off", "avoid"}
+
+// DetectConfigRecon returns the target named by a prompt that asks to read,
+// reveal or get around kavach's configuration, or "". evasive is true when
+// the prompt asks to get around it rather than only to see it.
+func DetectConfigRecon(prompt string) (target string, evasive bool) {
+	p := strings.ToLower(prompt)
+	evasive = containsAny(p, evasionVerbs)
+	if !evasive && !containsAny(p, reconVerbs) {
+		return "", false
+	}
+	for _, t := range reconTargets {
+		if strings.Contains(p, t) {
+			return t, evasive
+		}
+	}
+	return "", false
+}
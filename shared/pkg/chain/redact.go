@@ -0,0 +1,144 @@
+// Package chain provides multi-agent verification chain for kavach.
+// redact.go: Scrub secrets and paths from recorded inputs before they
+// are written to ~/.claude/chain.
+package chain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/claude/shared/pkg/patterns"
+	"github.com/claude/shared/pkg/util"
+)
+
+// Redaction selects what is scrubbed from persisted chain inputs.
+type Redaction struct {
+	Secrets bool // Credentials matched by the Aegis secret patterns
+	Paths   bool // Home directory and path-valued tool fields
+	// Path fields keep a hash of their directory instead of dropping it
+	HashPaths bool
+}
+
+// ParseRedaction maps config modes ("secrets", "paths") to a Redaction.
+func ParseRedaction(modes []string) Redaction {
+	return Redaction{
+		Secrets: containsString(modes, "secrets"),
+		Paths:   containsString(modes, "paths"),
+	}
+}
+
+// WithInputRedaction scrubs the recorded input when the run is persisted.
+// The in-memory state returned by RunFull is left intact.
+func WithInputRedaction(red Redaction) Option {
+	return func(r *Runner) {
+		r.redaction = red
+	}
+}
+
+// pathKeys are tool input fields that hold a file system path.
+var pathKeys = map[string]bool{
+	"file_path":     true,
+	"path":          true,
+	"notebook_path": true,
+	"cwd":           true,
+}
+
+// Redact returns a copy of in with the selected values scrubbed.
+func (red Redaction) Redact(in *ChainInput) *ChainInput {
+	if in == nil || (!red.Secrets && !red.Paths) {
+		return in
+	}
+	home, _ := os.UserHomeDir()
+	out := *in
+	out.Redacted = true
+	out.Prompt = red.redactString(in.Prompt, home)
+	out.TranscriptPath = red.redactString(in.TranscriptPath, home)
+	out.ToolInput = red.redactTool(in.ToolInput, home)
+	out.ToolResponse = red.redactTool(in.ToolResponse, home)
+	return &out
+}
+
+// redactTool scrubs a tool input/response map; path fields keep their base name.
+func (red Redaction) redactTool(m map[string]interface{}, home string) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if s, ok := v.(string); ok && red.Paths && pathKeys[k] {
+			out[k] = red.redactPath(s)
+			continue
+		}
+		out[k] = red.redactValue(v, home)
+	}
+	return out
+}
+
+func (red Redaction) redactValue(v interface{}, home string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return red.redactString(val, home)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, inner := range val {
+			m[k] = red.redactValue(inner, home)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, inner := range val {
+			s[i] = red.redactValue(inner, home)
+		}
+		return s
+	}
+	return v
+}
+
+func (red Redaction) redactString(s, home string) string {
+	if red.Secrets {
+		s = patterns.RedactSecrets(s)
+	}
+	if red.Paths && home != "" && home != "/" {
+		s = strings.ReplaceAll(s, home, "~")
+	}
+	return s
+}
+
+// redactPath keeps only the base name, enough to tell files apart.
+func (red Redaction) redactPath(p string) string {
+	if p == "" {
+		return p
+	}
+	if red.HashPaths {
+		return patterns.HashPath(p)
+	}
+	return "[REDACTED]/" + filepath.Base(p)
+}
+
+// editKeys are the Edit/MultiEdit fields replaced by a diff summary.
+var editKeys = []string{"old_string", "new_string", "edits"}
+
+// compactEdits returns in with Edit/MultiEdit replacement strings swapped
+// for their line diff summary ("diff"), so persisted runs stay small.
+// Replaying such a run cannot re-check the removed code.
+func compactEdits(in *ChainInput) *ChainInput {
+	if in == nil {
+		return nil
+	}
+	diff := util.SummarizeEdit(in.ToolName, in.ToolInput)
+	if diff == nil {
+		return in
+	}
+	out := *in
+	out.Redacted = true
+	out.ToolInput = make(map[string]interface{}, len(in.ToolInput))
+	for k, v := range in.ToolInput {
+		out.ToolInput[k] = v
+	}
+	for _, k := range editKeys {
+		delete(out.ToolInput, k)
+	}
+	out.ToolInput["diff"] = diff.String()
+	return &out
+}
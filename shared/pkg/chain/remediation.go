@@ -0,0 +1,74 @@
+// Package chain provides multi-agent verification chain for kavach.
+// remediation.go: Consolidated "next steps" for a blocked chain, so the
+// model can work through one checklist instead of per-gate hints.
+package chain
+
+import "fmt"
+
+// RemediationPlan returns the ordered steps that would unblock the chain:
+// the fixes of each blocking or asking gate in pipeline order, then a
+// retry. It is empty unless the chain blocked. Aegis findings are capped
+// at WithMaxFindings.
+func (r *Runner) RemediationPlan() []string {
+	return r.state.remediationPlan(r.maxFindings)
+}
+
+func (c *ChainState) remediationPlan(maxFindings int) []string {
+	if !c.IsBlocked() {
+		return nil
+	}
+	var steps []string
+	add := func(step string) {
+		if step != "" && !containsString(steps, step) {
+			steps = append(steps, step)
+		}
+	}
+	for _, result := range c.Results {
+		if result.Status != "block" && result.Status != "ask" {
+			continue
+		}
+		switch {
+		case result.Gate == "CEO" && c.CEO != nil && len(c.CEO.Blockers) > 0:
+			for _, b := range c.CEO.Blockers {
+				add("Resolve: " + b)
+			}
+		case result.Gate == "AEGIS" && c.Aegis != nil && len(c.Aegis.ViolationsFound) > 0:
+			violations := CapFindings(c.Aegis.ViolationsFound, maxFindings)
+			for i, v := range violations {
+				if i < len(c.Aegis.ViolationsFound) && v == c.Aegis.ViolationsFound[i] {
+					add("Remove or rework: " + v)
+				} else {
+					add(v + " violations")
+				}
+			}
+			for _, rec := range CapFindings(c.Aegis.Recommendations, maxFindings) {
+				add(rec)
+			}
+			if result.Status == "ask" {
+				add(result.NextAction)
+			}
+		case result.NextAction != "":
+			add(result.NextAction)
+		default:
+			add(fmt.Sprintf("Resolve %s: %s", result.Gate, result.Reason))
+		}
+	}
+	if c.Input != nil && c.Input.ToolName != "" {
+		add("Retry the " + c.Input.ToolName + " call")
+	} else {
+		add("Retry")
+	}
+	return steps
+}
+
+// remediationTOON renders steps as a numbered [REMEDIATION] block.
+func remediationTOON(steps []string) string {
+	if len(steps) == 0 {
+		return ""
+	}
+	s := "[REMEDIATION]\n"
+	for i, step := range steps {
+		s += fmt.Sprintf("%d. %s\n", i+1, step)
+	}
+	return s + "\n"
+}
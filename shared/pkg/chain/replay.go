@@ -0,0 +1,82 @@
+// Package chain provides multi-agent verification chain for kavach.
+// replay.go: Re-evaluate a persisted chain run against the current config.
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNoInput is returned when a saved run predates input capture.
+var ErrNoInput = errors.New("chain state has no recorded input")
+
+// LoadState reads a persisted chain run, migrating older schema versions.
+func LoadState(path string) (*ChainState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	state, err := decodeState(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// Replay re-runs the saved inputs through a fresh runner built from opts,
+// using RunPost when the run recorded a tool response.
+// The replayed run is never persisted.
+func Replay(saved *ChainState, opts ...Option) (*ChainState, error) {
+	if saved.Input == nil {
+		return nil, ErrNoInput
+	}
+	in := saved.Input
+	r := NewRunner(saved.SessionID, append(opts, WithStateDir(""))...)
+	if in.ToolResponse != nil {
+		return r.RunPost(in.Prompt, in.ToolName, in.ToolInput, in.ToolResponse), nil
+	}
+	return r.RunFull(in.Prompt, in.ToolName, in.ToolInput, in.ResearchDone), nil
+}
+
+// PriorRiskScore returns the session risk score before this run added to it.
+func (s *ChainState) PriorRiskScore() int {
+	if s.Risk == nil {
+		return 0
+	}
+	return s.Risk.Score - s.Risk.Delta
+}
+
+// GateDiff compares one gate's status across two runs.
+// An empty status means the gate did not run.
+type GateDiff struct {
+	Gate   string `json:"gate"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Changed reports whether the gate's status differs between runs.
+func (d GateDiff) Changed() bool {
+	return d.Before != d.After
+}
+
+// DiffStates pairs gate results from two runs in pipeline order.
+func DiffStates(before, after *ChainState) []GateDiff {
+	var diffs []GateDiff
+	index := make(map[string]int)
+	for _, r := range before.Results {
+		if _, ok := index[r.Gate]; !ok {
+			index[r.Gate] = len(diffs)
+			diffs = append(diffs, GateDiff{Gate: r.Gate})
+		}
+		diffs[index[r.Gate]].Before = r.Status
+	}
+	for _, r := range after.Results {
+		if _, ok := index[r.Gate]; !ok {
+			index[r.Gate] = len(diffs)
+			diffs = append(diffs, GateDiff{Gate: r.Gate})
+		}
+		diffs[index[r.Gate]].After = r.Status
+	}
+	return diffs
+}
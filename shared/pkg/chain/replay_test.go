@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplayAgainstNewPolicy(t *testing.T) {
+	dir := t.TempDir()
+	input := map[string]interface{}{"command": "git push --force origin main"}
+	NewRunner("sess_replay", WithStateDir(dir)).RunFull("fix typo", "Bash", input, true)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("saved runs = %v, %v; want 1", entries, err)
+	}
+	saved, err := LoadState(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Input == nil || saved.Input.ToolName != "Bash" || saved.Input.ToolInput["command"] != input["command"] {
+		t.Fatalf("saved input = %+v, want the original Bash command", saved.Input)
+	}
+	if saved.FinalStatus != "approved" {
+		t.Fatalf("saved FinalStatus = %q, want approved", saved.FinalStatus)
+	}
+
+	replayed, err := Replay(saved, WithAskPolicy(AskPolicy{Commands: []string{"git push --force"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.FinalStatus != "ask" {
+		t.Errorf("replayed FinalStatus = %q, want ask", replayed.FinalStatus)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("replay persisted a run: %d files", len(entries))
+	}
+
+	changed := 0
+	for _, d := range DiffStates(saved, replayed) {
+		if d.Changed() {
+			changed++
+			if d.Gate != "AEGIS" || d.Before != "pass" || d.After != "ask" {
+				t.Errorf("diff = %+v, want AEGIS pass -> ask", d)
+			}
+		}
+	}
+	if changed != 1 {
+		t.Errorf("changed gates = %d, want 1", changed)
+	}
+
+	if _, err := Replay(&ChainState{SessionID: "old"}); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Replay(no input) err = %v, want ErrNoInput", err)
+	}
+}
+
+func TestSavedInputRedaction(t *testing.T) {
+	dir := t.TempDir()
+	home, _ := os.UserHomeDir()
+	token := "ghp_" + strings.Repeat("a", 36)
+	input := map[string]interface{}{
+		"file_path": filepath.Join(home, "src", "main.go"),
+		"content":   "token := \"" + token + "\"",
+	}
+	r := NewRunner("sess_redact", WithStateDir(dir), WithInputRedaction(ParseRedaction([]string{"secrets", "paths"})))
+	state := r.RunFull("use "+token, "Write", input, true)
+
+	// The caller still sees the raw input
+	if state.Input.Redacted || state.Input.ToolInput["content"] != input["content"] {
+		t.Fatalf("in-memory input was redacted: %+v", state.Input)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("saved runs = %d, want 1", len(entries))
+	}
+	saved, err := LoadState(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := saved.Input
+	if !in.Redacted {
+		t.Error("saved input not marked redacted")
+	}
+	if strings.Contains(in.Prompt, token) || strings.Contains(in.ToolInput["content"].(string), token) {
+		t.Errorf("token persisted: prompt=%q content=%q", in.Prompt, in.ToolInput["content"])
+	}
+	if got := in.ToolInput["file_path"]; got != "[REDACTED]/main.go" {
+		t.Errorf("file_path = %q, want [REDACTED]/main.go", got)
+	}
+	hashed := Redaction{Paths: true, HashPaths: true}.redactPath(input["file_path"].(string))
+	if !strings.HasPrefix(hashed, "path:") || !strings.HasSuffix(hashed, "/main.go") || strings.Contains(hashed, home) {
+		t.Errorf("hashed path = %q", hashed)
+	}
+
+	if (Redaction{}).Redact(state.Input) != state.Input {
+		t.Error("zero Redaction should return the input unchanged")
+	}
+}
+
+func TestLoadStateMigration(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "chain_old_1.json")
+	os.WriteFile(legacy, []byte(`{"session_id":"old"}`), 0644)
+	state, err := LoadState(legacy)
+	if err != nil {
+		t.Fatalf("LoadState(v0): %v", err)
+	}
+	if state.SchemaVersion != StateSchemaVersion || state.FinalStatus != "pending" || state.Results == nil || state.Metadata == nil {
+		t.Errorf("migrated = %+v, want current version with defaults", state)
+	}
+
+	future := filepath.Join(dir, "chain_new_1.json")
+	os.WriteFile(future, []byte(`{"schema_version":99,"session_id":"new"}`), 0644)
+	if _, err := LoadState(future); !errors.Is(err, ErrUnknownSchema) {
+		t.Errorf("LoadState(v99) = %v, want ErrUnknownSchema", err)
+	}
+
+	NewRunner("sess_schema", WithStateDir(dir)).RunFull("fix typo", "Read", map[string]interface{}{"file_path": "main.go"}, true)
+	saved, _ := filepath.Glob(filepath.Join(dir, "chain_sess_schema_*.json"))
+	if len(saved) != 1 {
+		t.Fatalf("saved = %v", saved)
+	}
+	data, _ := os.ReadFile(saved[0])
+	if !strings.Contains(string(data), `"schema_version": 1`) {
+		t.Errorf("saved run not stamped:\n%s", data)
+	}
+}
@@ -0,0 +1,68 @@
+// Package chain provides multi-agent verification chain for kavach.
+// research_policy.go: Research requirement as a matrix over intent type,
+// risk and complexity, replacing the per-category classifier flag.
+package chain
+
+import "fmt"
+
+// ResearchRule requires research for intents of type Intent ("" or "*"
+// for any) whose risk is at least MinRisk and complexity at least
+// MinComplexity. Empty minimums match every level.
+type ResearchRule struct {
+	Intent        string
+	MinRisk       string
+	MinComplexity string
+}
+
+// matches reports whether the rule applies to intent.
+func (rule ResearchRule) matches(intent *IntentAnalysis) bool {
+	if rule.Intent != "" && rule.Intent != "*" && rule.Intent != intent.Type {
+		return false
+	}
+	if rule.MinRisk != "" && riskRank[intent.RiskLevel] < riskRank[rule.MinRisk] {
+		return false
+	}
+	if rule.MinComplexity != "" && complexityRank[intent.Complexity] < complexityRank[rule.MinComplexity] {
+		return false
+	}
+	return true
+}
+
+func (rule ResearchRule) String() string {
+	intent := rule.Intent
+	if intent == "" {
+		intent = "*"
+	}
+	s := intent
+	if rule.MinRisk != "" {
+		s += fmt.Sprintf(" risk>=%s", rule.MinRisk)
+	}
+	if rule.MinComplexity != "" {
+		s += fmt.Sprintf(" complexity>=%s", rule.MinComplexity)
+	}
+	return s
+}
+
+// ResearchPolicy decides RequiresResearch: research is required when any
+// rule matches. With no rules nothing requires research.
+type ResearchPolicy struct {
+	Rules []ResearchRule
+}
+
+// WithResearchPolicy replaces the classifier's per-category research flag
+// with policy. Without it the classifier's flag stands.
+func WithResearchPolicy(policy ResearchPolicy) Option {
+	return func(r *Runner) {
+		r.researchPolicy = &policy
+	}
+}
+
+// Requires returns the first rule requiring research for intent, if any.
+func (p *ResearchPolicy) Requires(intent *IntentAnalysis) (ResearchRule, bool) {
+	for _, rule := range p.Rules {
+		if rule.matches(intent) {
+			return rule, true
+		}
+	}
+	return ResearchRule{}, false
+}
@@ -0,0 +1,62 @@
+// Package chain provides multi-agent verification chain for kavach.
+// research_sources.go: Minimum distinct research sources per intent.
+// High-stakes intents (deploy, security) can require N sources in the
+// transcript before TABULA_RASA counts as satisfied.
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/claude/shared/pkg/transcript"
+)
+
+// WithMinResearchSources requires, per intent type, a minimum number of
+// distinct research sources (sites fetched with WebFetch or another research
+// tool; searches do not count) in the transcript. Needs WithTranscript; without a transcript nothing is counted.
+func WithMinResearchSources(byIntent map[string]int) Option {
+	return func(r *Runner) {
+		r.minSources = byIntent
+	}
+}
+
+// checkResearchSources downgrades a passing Research result when the
+// transcript shows fewer sources than the intent requires. Risk levels
+// deferred to a TODO warn instead of blocking.
+func (r *Runner) checkResearchSources(ctx context.Context, research *ResearchStatus, prompt string, result *VerificationResult) {
+	intent := r.state.Intent
+	if intent == nil || r.transcriptPath == "" || result.Status != "pass" || research.Bypass {
+		return
+	}
+	required := r.minSources[strings.ToLower(intent.Type)]
+	if required <= 0 {
+		return
+	}
+	sources := transcript.ResearchSourcesContext(ctx, r.transcriptPath, r.researchTools, r.researchWindow)
+	research.Sources = sources
+	if len(sources) >= required {
+		return
+	}
+
+	research.Done = false
+	if research.SuggestedQuery == "" {
+		research.SuggestedQuery = buildSearchQuery(intent.Type, prompt, r.clock.Now())
+	}
+	result.Source = SourceConfig
+	result.Reason = fmt.Sprintf("TABULA_RASA: %d/%d research sources before %s", len(sources), required, intent.Type)
+	result.NextAction = fmt.Sprintf("Fetch %d more distinct sources (WebFetch): %s", required-len(sources), research.SuggestedQuery)
+	result.Context = map[string]string{
+		"research_sources": strconv.Itoa(len(sources)),
+		"required_sources": strconv.Itoa(required),
+		"suggested_query":  research.SuggestedQuery,
+	}
+	if containsFold(r.researchTodoLevels, intent.RiskLevel) {
+		research.Todo = "Research: " + research.SuggestedQuery
+		result.Status = "warn"
+		result.Context["research_todo"] = research.Todo
+		return
+	}
+	result.Status = "block"
+}
@@ -0,0 +1,152 @@
+// Package chain provides multi-agent verification chain for kavach.
+// rewrite.go: Safe rewrites of risky Bash commands for the "modify" aegis
+// action: dry-run flags, previews instead of deletes, download instead of
+// pipe-to-shell. Only single, unchained commands are rewritten.
+package chain
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/claude/shared/pkg/patterns"
+)
+
+// commandRewrite turns one risky command into a safer one.
+type commandRewrite struct {
+	command []string // Leading words that select the rule ("git", "push")
+	apply   func(cmd string, fields []string) (string, string, bool)
+}
+
+var (
+	forceFlag       = regexp.MustCompile(`(\s)(--force|-f)(\s|$)`)
+	autoApproveFlag = regexp.MustCompile(`\s+-auto-approve(=\S+)?`)
+)
+
+// appendFlag returns a rewrite that appends flag unless already present.
+func appendFlag(flag, note string) func(string, []string) (string, string, bool) {
+	return func(cmd string, fields []string) (string, string, bool) {
+		for _, f := range fields {
+			if f == flag || strings.HasPrefix(f, flag+"=") {
+				return "", "", false
+			}
+		}
+		return cmd + " " + flag, note, true
+	}
+}
+
+// commandRewrites is checked in order; the first matching rule applies.
+var commandRewrites = []commandRewrite{
+	{[]string{"git", "push"}, func(cmd string, _ []string) (string, string, bool) {
+		out := forceFlag.ReplaceAllString(cmd, "${1}--force-with-lease${3}")
+		return out, "force push limited to --force-with-lease", out != cmd
+	}},
+	{[]string{"git", "clean"}, appendFlag("--dry-run", "dry run: lists what git clean would delete")},
+	{[]string{"rsync"}, appendFlag("--dry-run", "dry run: shows what rsync would transfer or delete")},
+	{[]string{"kubectl", "apply"}, appendFlag("--dry-run=client", "dry run: validates the manifests without applying")},
+	{[]string{"kubectl", "delete"}, appendFlag("--dry-run=client", "dry run: lists what kubectl would delete")},
+	{[]string{"helm", "install"}, appendFlag("--dry-run", "dry run: renders the release without installing")},
+	{[]string{"helm", "upgrade"}, appendFlag("--dry-run", "dry run: renders the release without upgrading")},
+	{[]string{"helm", "uninstall"}, appendFlag("--dry-run", "dry run: simulates the uninstall")},
+	{[]string{"npm", "publish"}, appendFlag("--dry-run", "dry run: packs without publishing")},
+	{[]string{"cargo", "publish"}, appendFlag("--dry-run", "dry run: packages without uploading")},
+	{[]string{"terraform", "apply"}, func(cmd string, _ []string) (string, string, bool) {
+		out := strings.Replace(autoApproveFlag.ReplaceAllString(cmd, ""), "apply", "plan", 1)
+		return out, "plan instead of apply", true
+	}},
+	{[]string{"terraform", "destroy"}, func(cmd string, _ []string) (string, string, bool) {
+		out := strings.Replace(autoApproveFlag.ReplaceAllString(cmd, ""), "destroy", "plan -destroy", 1)
+		return out, "plan -destroy instead of destroy", true
+	}},
+	{[]string{"rm"}, rewriteBroadRemove},
+}
+
+// broadTargets are rm targets that wipe a root, home or whole directory.
+var broadTargets = map[string]bool{
+	"/": true, "/*": true, "~": true, "~/": true, "~/*": true,
+	"$HOME": true, "$HOME/": true, "${HOME}": true, "$HOME/*": true,
+	".": true, "./": true, "./*": true, "*": true, "..": true,
+}
+
+// rewriteBroadRemove previews a recursive rm of a broad target with ls.
+func rewriteBroadRemove(cmd string, fields []string) (string, string, bool) {
+	if strings.ContainsAny(cmd, `'"\`) {
+		return "", "", false
+	}
+	recursive, broad := false, false
+	var targets []string
+	for _, f := range fields[1:] {
+		switch {
+		case f == "--recursive" || (strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "--") && strings.ContainsAny(f, "rR")):
+			recursive = true
+		case strings.HasPrefix(f, "-"):
+		default:
+			targets = append(targets, f)
+			broad = broad || broadTargets[f]
+		}
+	}
+	if !recursive || !broad {
+		return "", "", false
+	}
+	return "ls -la " + strings.Join(targets, " "), "preview: lists what rm would delete", true
+}
+
+// SafeRewrite returns a safer form of cmd and a note saying what changed,
+// or ok=false when no rewrite applies. Chained, redirected or substituted
+// commands are never rewritten.
+func SafeRewrite(cmd string) (rewritten, note string, ok bool) {
+	cmd = strings.TrimSpace(cmd)
+	if cmd == "" || strings.ContainsAny(cmd, ";&<>`\n") || strings.Contains(cmd, "$(") {
+		return "", "", false
+	}
+	if strings.Contains(cmd, "|") {
+		return rewritePipeInstall(cmd)
+	}
+	fields := strings.Fields(cmd)
+	for _, rw := range commandRewrites {
+		if len(fields) < len(rw.command) {
+			continue
+		}
+		match := true
+		for i, word := range rw.command {
+			if fields[i] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return rw.apply(cmd, fields)
+		}
+	}
+	return "", "", false
+}
+
+// rewritePipeInstall turns "curl|wget <url> | sh" into a download of the
+// script to install.sh, so it can be reviewed before it runs.
+func rewritePipeInstall(cmd string) (string, string, bool) {
+	stages := strings.Split(cmd, "|")
+	if len(stages) != 2 || !patterns.IsPipeToShell(cmd) {
+		return "", "", false
+	}
+	fields := strings.Fields(stages[0])
+	if len(fields) == 0 {
+		return "", "", false
+	}
+	url := ""
+	for _, f := range fields[1:] {
+		if strings.HasPrefix(f, "https://") || strings.HasPrefix(f, "http://") {
+			url = f
+			break
+		}
+	}
+	if url == "" {
+		return "", "", false
+	}
+	note := "download only: review install.sh before running it"
+	switch fields[0] {
+	case "curl":
+		return "curl -fsSL " + url + " -o install.sh", note, true
+	case "wget":
+		return "wget -O install.sh " + url, note, true
+	}
+	return "", "", false
+}
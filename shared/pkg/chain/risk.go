@@ -0,0 +1,71 @@
+// Package chain provides multi-agent verification chain for kavach.
+// risk.go: Session risk budget - cumulative escalation across chain runs.
+// Many medium-risk actions in one session earn more scrutiny than one.
+package chain
+
+// Risk levels reported in RiskStatus.Level.
+const (
+	RiskNormal   = "normal"
+	RiskElevated = "elevated" // warn → ask
+	RiskCritical = "critical" // warn/ask → block
+)
+
+// RiskBudget configures cumulative risk escalation for a session.
+// Score is carried in from session state; weights price each gate finding.
+type RiskBudget struct {
+	Score          int
+	WarnWeight     int
+	AskWeight      int
+	BlockWeight    int
+	AskThreshold   int // Score at which warn escalates to ask (0 = disabled)
+	BlockThreshold int // Score at which warn/ask escalate to block (0 = disabled)
+}
+
+// RiskStatus reports the risk budget after a chain run.
+type RiskStatus struct {
+	Score          int    `json:"score"` // Cumulative, including this run
+	Delta          int    `json:"delta"` // Added by this run
+	Level          string `json:"level"`
+	AskThreshold   int    `json:"ask_threshold"`
+	BlockThreshold int    `json:"block_threshold"`
+}
+
+// Weight returns the risk points for a gate status.
+func (b *RiskBudget) Weight(status string) int {
+	switch status {
+	case "warn":
+		return b.WarnWeight
+	case "ask":
+		return b.AskWeight
+	case "block":
+		return b.BlockWeight
+	}
+	return 0
+}
+
+// Level returns the escalation level for a cumulative score.
+func (b *RiskBudget) Level(score int) string {
+	if b.BlockThreshold > 0 && score >= b.BlockThreshold {
+		return RiskCritical
+	}
+	if b.AskThreshold > 0 && score >= b.AskThreshold {
+		return RiskElevated
+	}
+	return RiskNormal
+}
+
+// Escalate raises a gate status according to the level at score.
+// Passes are never escalated; blocks are already final.
+func (b *RiskBudget) Escalate(status string, score int) string {
+	switch b.Level(score) {
+	case RiskCritical:
+		if status == "warn" || status == "ask" {
+			return "block"
+		}
+	case RiskElevated:
+		if status == "warn" {
+			return "ask"
+		}
+	}
+	return status
+}
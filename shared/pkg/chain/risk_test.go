@@ -0,0 +1,71 @@
+package chain
+
+import "testing"
+
+func testBudget(score int) RiskBudget {
+	return RiskBudget{
+		Score:          score,
+		WarnWeight:     1,
+		AskWeight:      3,
+		BlockWeight:    5,
+		AskThreshold:   10,
+		BlockThreshold: 25,
+	}
+}
+
+func TestRiskBudgetEscalate(t *testing.T) {
+	b := testBudget(0)
+	tests := []struct {
+		status string
+		score  int
+		want   string
+	}{
+		{"pass", 30, "pass"},
+		{"warn", 5, "warn"},
+		{"warn", 10, "ask"},
+		{"ask", 10, "ask"},
+		{"warn", 25, "block"},
+		{"ask", 25, "block"},
+		{"block", 0, "block"},
+	}
+	for _, tt := range tests {
+		if got := b.Escalate(tt.status, tt.score); got != tt.want {
+			t.Errorf("Escalate(%q, %d) = %q, want %q", tt.status, tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestRiskBudgetDisabledThresholds(t *testing.T) {
+	b := RiskBudget{WarnWeight: 1}
+	if got := b.Escalate("warn", 1000); got != "warn" {
+		t.Errorf("zero thresholds should never escalate, got %q", got)
+	}
+	if got := b.Level(1000); got != RiskNormal {
+		t.Errorf("Level = %q, want %q", got, RiskNormal)
+	}
+}
+
+func TestRunnerRiskBudgetEscalatesWarn(t *testing.T) {
+	// Critical-risk implement intent makes CEO warn; budget is already elevated.
+	r := NewRunner("sess_test", WithRiskBudget(testBudget(10)))
+	r.cacheDir = ""
+	state := r.RunFull("implement delete handler", "Bash", map[string]interface{}{"command": "ls"}, true)
+
+	if state.Risk == nil {
+		t.Fatal("expected risk status on chain state")
+	}
+	if state.Risk.Level != RiskElevated {
+		t.Errorf("Level = %q, want %q", state.Risk.Level, RiskElevated)
+	}
+	if !state.IsAsk() {
+		t.Errorf("FinalStatus = %q, want ask", state.FinalStatus)
+	}
+	for _, res := range state.Results {
+		if res.Status == "warn" {
+			t.Errorf("gate %s still warns at elevated risk", res.Gate)
+		}
+	}
+	if state.Risk.Score != 10+state.Risk.Delta {
+		t.Errorf("Score = %d, want %d", state.Risk.Score, 10+state.Risk.Delta)
+	}
+}
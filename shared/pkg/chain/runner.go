@@ -3,74 +3,304 @@
 package chain
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
-	"time"
+	"strings"
+
+	"github.com/claude/shared/pkg/statestore"
+	"github.com/claude/shared/pkg/transcript"
+	"github.com/claude/shared/pkg/util"
 )
 
 // Runner orchestrates the verification chain.
 type Runner struct {
-	state     *ChainState
-	cacheDir  string
-	debugMode bool
+	state        *ChainState
+	cacheDir     string
+	store        statestore.Store // Overrides cacheDir when set
+	debugMode    bool
+	risk         *RiskBudget
+	ask          *AskPolicy
+	recommend    *RecommendationBudget
+	packages     *PackagePolicy
+	endpoints    *EndpointPolicy
+	evasion      *EvasionPolicy
+	deployWindow *DeployWindowPolicy
+	parallel     bool
+	maxFindings  int // Findings listed in reasons and remediation; 0 = DefaultMaxFindings
+
+	// Intent risk levels where missing research becomes a TODO, not a block
+	researchTodoLevels []string
+
+	// Research requirement by intent type, risk and complexity; nil keeps
+	// the classifier's flag
+	researchPolicy *ResearchPolicy
+
+	// Distinct research sources required per intent type
+	minSources map[string]int
+
+	// Action per Aegis violation category; unset keeps the built-in action
+	aegisActions map[string]string
+
+	// Decision floor per lowercased tool name
+	toolPolicy map[string]string
+
+	// kavach's own config, hooks and binary; writes there always ask
+	selfPaths []string
+
+	classifier IntentClassifier
+
+	clock util.Clock
+
+	// RunPost: the tool already ran, so pre-run verdicts cap at warn
+	post bool
+
+	// RunPost: consecutive failures of this call, including this run
+	failures int
+
+	// Character budget for ToTOON; 0 means unlimited
+	toonMaxChars int
+
+	// "curl | sh" installers exempt from the pipe-to-shell block
+	trustedInstalls []string
+
+	// Sensitive paths added to the built-in list
+	sensitivePaths []SensitivePath
+
+	// Scrubbing applied to the recorded input on save
+	redaction Redaction
+
+	// Provenance stamped onto Aegis decisions
+	configHash string
+	overrides  []string
+
+	// Transcript-based research detection (Research gate fallback)
+	transcriptPath string
+	researchTools  []string
+	researchWindow int
+}
+
+// Option configures a Runner.
+type Option func(*Runner)
+
+// WithRiskBudget enables session risk escalation for this run.
+func WithRiskBudget(b RiskBudget) Option {
+	return func(r *Runner) {
+		r.risk = &b
+	}
+}
+
+// WithParallel runs the independent Aegis and Research gates concurrently.
+// Results are merged in pipeline order, so output matches sequential mode.
+func WithParallel(enabled bool) Option {
+	return func(r *Runner) {
+		r.parallel = enabled
+	}
+}
+
+// WithTranscript lets the Research gate detect recent research tool use
+// in the session transcript when the session flag is not yet set.
+func WithTranscript(path string, researchTools []string, window int) Option {
+	return func(r *Runner) {
+		r.transcriptPath = path
+		r.researchTools = researchTools
+		r.researchWindow = window
+	}
+}
+
+// WithResearchTodo defers missing research to a TODO directive for the
+// given intent risk levels; other levels still block.
+func WithResearchTodo(riskLevels []string) Option {
+	return func(r *Runner) {
+		r.researchTodoLevels = riskLevels
+	}
+}
+
+// WithStateDir overrides where the run is persisted; "" disables saving.
+func WithStateDir(dir string) Option {
+	return func(r *Runner) {
+		r.cacheDir = dir
+	}
+}
+
+// WithStore persists runs to st (e.g. a statestore.MemoryStore in tests)
+// instead of the state dir.
+func WithStore(st statestore.Store) Option {
+	return func(r *Runner) {
+		r.store = st
+	}
+}
+
+// WithClock sets the time source for timestamps, state filenames and
+// research queries. Defaults to util.SystemClock.
+func WithClock(c util.Clock) Option {
+	return func(r *Runner) {
+		r.clock = c
+	}
+}
+
+// WithTrustedInstalls lets "curl https://<trusted> | sh" installers pass
+// Aegis. Entries are "host" or "host/path-prefix".
+func WithTrustedInstalls(entries []string) Option {
+	return func(r *Runner) {
+		r.trustedInstalls = entries
+	}
+}
+
+// WithTOONBudget caps ToTOON output at maxChars; 0 disables the cap.
+func WithTOONBudget(maxChars int) Option {
+	return func(r *Runner) {
+		r.toonMaxChars = maxChars
+	}
 }
 
 // NewRunner creates a new chain runner.
-func NewRunner(sessionID string) *Runner {
-	home, _ := os.UserHomeDir()
-	return &Runner{
+func NewRunner(sessionID string, opts ...Option) *Runner {
+	r := &Runner{
 		state:     NewChainState(sessionID),
-		cacheDir:  filepath.Join(home, ".claude", "chain"),
+		cacheDir:  StateDir(),
 		debugMode: os.Getenv("KAVACH_DEBUG") == "1",
+		clock:     util.SystemClock,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.state.clock = r.clock
+	if r.risk != nil {
+		r.state.Risk = &RiskStatus{
+			Score:          r.risk.Score,
+			Level:          r.risk.Level(r.risk.Score),
+			AskThreshold:   r.risk.AskThreshold,
+			BlockThreshold: r.risk.BlockThreshold,
+		}
 	}
+	return r
 }
 
 // RunFull executes the complete verification chain.
 // Returns the final state after all gates have run.
 func (r *Runner) RunFull(prompt, toolName string, toolInput map[string]interface{}, researchDone bool) *ChainState {
+	return r.RunFullContext(context.Background(), prompt, toolName, toolInput, researchDone)
+}
+
+// RunFullContext is RunFull bounded by ctx. Once ctx is done the chain
+// stops before the next gate, abandons a running Aegis or Research
+// evaluation (transcript reads stop at ctx too) and asks, with the skipped
+// gates in the result. The tool_policy floor applies either way.
+func (r *Runner) RunFullContext(ctx context.Context, prompt, toolName string, toolInput map[string]interface{}, researchDone bool) *ChainState {
 	r.debug("Starting verification chain for tool: %s", toolName)
+	r.state.Input = &ChainInput{
+		Prompt:         prompt,
+		ToolName:       toolName,
+		ToolInput:      toolInput,
+		ResearchDone:   researchDone,
+		TranscriptPath: r.transcriptPath,
+	}
 
 	// Gate 1: Intent Analysis
-	r.runIntentGate(prompt)
+	if r.deadlineExceeded(ctx, "INTENT") {
+		return r.endRun(toolName)
+	}
+	r.runIntentGate(ctx, prompt, toolName)
 	if r.state.IsBlocked() {
-		return r.finalize()
+		return r.endRun(toolName)
 	}
 
 	// Gate 2: CEO Validation
+	if r.deadlineExceeded(ctx, "CEO") {
+		return r.endRun(toolName)
+	}
 	agentType := ""
 	if at, ok := toolInput["subagent_type"].(string); ok {
 		agentType = at
 	}
 	r.runCEOGate(toolName, agentType)
 	if r.state.IsBlocked() {
-		return r.finalize()
+		return r.endRun(toolName)
 	}
 
-	// Gate 3: Aegis Security
-	r.runAegisGate(toolName, toolInput)
+	// Gates 3+4: Aegis Security and Research Check depend only on Intent
+	evalAegis := func() (*AegisVerification, VerificationResult) {
+		return r.evalAegisGate(toolName, toolInput)
+	}
+	evalResearch := func() (*ResearchStatus, VerificationResult) {
+		return r.evalResearchGate(ctx, researchDone, prompt)
+	}
+	aegisCh := startGate(evalAegis)
+	var researchCh <-chan gateOutcome[*ResearchStatus]
+	if r.parallel {
+		researchCh = startGate(evalResearch)
+	}
+
+	// Merge in pipeline order: Aegis fail-fast hides a concurrent Research result
+	aegis, ok := awaitGate(ctx, aegisCh)
+	if !ok {
+		r.deadlineExceeded(ctx, "AEGIS")
+		return r.endRun(toolName)
+	}
+	r.state.Aegis = aegis.status
+	r.addResult(aegis.result)
 	if r.state.IsBlocked() {
-		return r.finalize()
+		return r.endRun(toolName)
 	}
 
-	// Gate 4: Research Check
-	r.runResearchGate(researchDone, prompt)
+	if researchCh == nil {
+		if r.deadlineExceeded(ctx, "RESEARCH") {
+			return r.endRun(toolName)
+		}
+		researchCh = startGate(evalResearch)
+	}
+	research, ok := awaitGate(ctx, researchCh)
+	if !ok {
+		r.deadlineExceeded(ctx, "RESEARCH")
+		return r.endRun(toolName)
+	}
+	r.state.Research = research.status
+	r.addResult(research.result)
+	if r.state.IsBlocked() {
+		return r.endRun(toolName)
+	}
+
+	r.applyToolPolicy(toolName)
 	if r.state.IsBlocked() {
 		return r.finalize()
 	}
 
-	// All gates passed
-	r.state.FinalStatus = "approved"
+	// All gates passed (ask results keep the chain pending user approval)
+	if r.state.FinalStatus == "pending" {
+		r.state.FinalStatus = "approved"
+	}
+	return r.finalize()
+}
+
+// endRun finishes a RunFullContext that stopped early: a gate blocked or
+// the deadline passed. The tool_policy floor still applies.
+func (r *Runner) endRun(toolName string) *ChainState {
+	r.applyToolPolicy(toolName)
 	return r.finalize()
 }
 
 // runIntentGate executes the Intent classification gate.
-func (r *Runner) runIntentGate(prompt string) {
+func (r *Runner) runIntentGate(ctx context.Context, prompt, toolName string) {
 	r.debug("Running Intent gate")
 
-	intent := AnalyzeIntent(prompt)
+	var intent *IntentAnalysis
+	if !isNilClassifier(r.classifier) {
+		intent = classify(ctx, r.classifier, prompt)
+	}
+	if intent == nil {
+		intent = defaultClassifier().Classify(prompt)
+	}
 	r.state.Intent = intent
+	researchRule := ""
+	if r.researchPolicy != nil {
+		rule, ok := r.researchPolicy.Requires(intent)
+		intent.RequiresResearch = ok
+		if ok {
+			researchRule = rule.String()
+		}
+	}
 
 	result := VerificationResult{
 		Gate:   "INTENT",
@@ -82,15 +312,24 @@ func (r *Runner) runIntentGate(prompt string) {
 			"risk_level": intent.RiskLevel,
 		},
 	}
+	if researchRule != "" {
+		result.Context["research_rule"] = researchRule
+	}
 
 	// Block if critical risk and low confidence
 	if intent.RiskLevel == "critical" && intent.Confidence < 0.7 {
 		result.Status = "block"
 		result.Reason = "Critical risk with low confidence - requires explicit verification"
+		result.Source = SourceBuiltin
 		result.NextAction = "Clarify user intent before proceeding"
+	} else if r.ask != nil && r.ask.AsksForRisk(intent.RiskLevel, toolName) {
+		result.Status = "ask"
+		result.Reason = fmt.Sprintf("%s risk %s intent - confirm before %s", intent.RiskLevel, intent.Type, toolName)
+		result.Source = SourceConfig
+		result.NextAction = "User confirmation required"
 	}
 
-	r.state.AddResult(result)
+	r.addResult(result)
 }
 
 // runCEOGate executes the CEO validation gate.
@@ -108,6 +347,7 @@ func (r *Runner) runCEOGate(toolName, agentType string) {
 
 	if !ceo.Approved {
 		result.Status = "block"
+		result.Source = SourceBuiltin
 		if len(ceo.Blockers) > 0 {
 			result.Reason = ceo.Blockers[0]
 		}
@@ -115,6 +355,7 @@ func (r *Runner) runCEOGate(toolName, agentType string) {
 	} else if len(ceo.Warnings) > 0 {
 		result.Status = "warn"
 		result.Reason = ceo.Warnings[0]
+		result.Source = SourceBuiltin
 	}
 
 	if ceo.DelegationPlan != "" {
@@ -123,15 +364,17 @@ func (r *Runner) runCEOGate(toolName, agentType string) {
 		}
 	}
 
-	r.state.AddResult(result)
+	r.addResult(result)
 }
 
-// runAegisGate executes the Aegis security gate.
-func (r *Runner) runAegisGate(toolName string, toolInput map[string]interface{}) {
+// evalAegisGate evaluates the Aegis security gate without touching state.
+// Safe to run concurrently with evalResearchGate.
+func (r *Runner) evalAegisGate(toolName string, toolInput map[string]interface{}) (*AegisVerification, VerificationResult) {
 	r.debug("Running Aegis gate")
 
-	aegis := AegisVerify(r.state.Intent, toolName, toolInput)
-	r.state.Aegis = aegis
+	aegis := aegisVerify(r.clock, r.trustedInstalls, r.sensitivePaths, r.state.Intent, toolName, toolInput)
+	aegis.MemoryProvenance.ConfigHash = r.configHash
+	aegis.MemoryProvenance.OverridesApplied = r.overrides
 
 	result := VerificationResult{
 		Gate:   "AEGIS",
@@ -145,26 +388,66 @@ func (r *Runner) runAegisGate(toolName string, toolInput map[string]interface{})
 
 	if !aegis.Passed {
 		result.Status = "block"
+		result.Source = SourceBuiltin
 		if len(aegis.ViolationsFound) > 0 {
 			result.Reason = aegis.ViolationsFound[0]
 		}
 		result.NextAction = "Address security violations before proceeding"
+		r.applyAegisAction(aegis.ViolationCategories, toolName, toolInput, &result)
+	} else if r.ask != nil && toolName == "Bash" {
+		cmd, _ := toolInput["command"].(string)
+		if pattern := r.ask.MatchCommand(cmd); pattern != "" {
+			result.Status = "ask"
+			result.Reason = "High-risk command requires confirmation: " + pattern
+			result.Source = SourceConfig
+			result.NextAction = "User confirmation required"
+			result.Context["ask_command"] = pattern
+			r.applyAegisAction([]string{ViolationAskCommand}, toolName, toolInput, &result)
+		}
 	}
 
+	if aegis.Destructiveness > 0 {
+		result.Context["destructiveness"] = fmt.Sprintf("%.2f", aegis.Destructiveness)
+	}
+	if result.Status == "pass" && aegis.Destructiveness >= destructiveWarn {
+		result.Status = "warn"
+		result.Reason = fmt.Sprintf("Destructive command (score %.2f): %s", aegis.Destructiveness, strings.Join(aegis.DestructiveReasons, "; "))
+		result.Source = SourceBuiltin
+		result.NextAction = "Narrow the target or preview it first (ls, --dry-run)"
+	}
+
+	r.checkSelfProtection(aegis, toolName, toolInput, &result)
+	aegis.dedupe()
+
 	if len(aegis.Recommendations) > 0 {
 		result.Context["recommendations"] = aegis.Recommendations[0]
 	}
+	if diff := util.SummarizeEdit(toolName, toolInput); diff != nil {
+		result.Context["edit_diff"] = diff.String()
+	}
+	r.checkPackages(toolName, toolInput, &result)
+	r.checkEndpoints(toolName, toolInput, &result)
+	r.checkEvasion(toolName, toolInput, &result)
+	r.checkDeployWindow(toolName, toolInput, &result)
+	r.escalateRecommendations(aegis, &result)
 
-	r.state.AddResult(result)
+	return aegis, result
 }
 
-// runResearchGate executes the Research (TABULA_RASA) gate.
+// evalResearchGate evaluates the Research (TABULA_RASA) gate without touching state.
 // STRICT: High-risk intents always require fresh research.
-func (r *Runner) runResearchGate(researchDone bool, prompt string) {
+func (r *Runner) evalResearchGate(ctx context.Context, researchDone bool, prompt string) (*ResearchStatus, VerificationResult) {
 	r.debug("Running Research gate")
 
-	research := ResearchCheck(r.state.Intent, researchDone, prompt)
-	r.state.Research = research
+	var sources []string
+	if !researchDone && r.transcriptPath != "" &&
+		transcript.HasRecentToolUseContext(ctx, r.transcriptPath, r.researchTools, r.researchWindow) {
+		researchDone = true
+		sources = append(sources, "transcript")
+	}
+
+	research := researchCheck(r.clock, r.state.Intent, researchDone, prompt)
+	research.Sources = sources
 
 	result := VerificationResult{
 		Gate:   "RESEARCH",
@@ -175,14 +458,26 @@ func (r *Runner) runResearchGate(researchDone bool, prompt string) {
 	// If bypassed, just pass
 	if research.Bypass {
 		result.Reason = "Bypassed: " + research.BypassReason
-		r.state.AddResult(result)
-		return
+		return research, result
 	}
 
 	// Block only if research is required AND not yet done
 	if !research.Done && r.state.Intent != nil && r.state.Intent.RequiresResearch {
+		if containsFold(r.researchTodoLevels, r.state.Intent.RiskLevel) {
+			research.Todo = "Research: " + research.SuggestedQuery
+			result.Status = "warn"
+			result.Reason = "TABULA_RASA: Research deferred as TODO before " + r.state.Intent.Type
+			result.Source = SourceConfig
+			result.NextAction = "TodoWrite: " + research.Todo
+			result.Context = map[string]string{
+				"suggested_query": research.SuggestedQuery,
+				"research_todo":   research.Todo,
+			}
+			return research, result
+		}
 		result.Status = "block"
 		result.Reason = "TABULA_RASA: Research required before " + r.state.Intent.Type
+		result.Source = SourceStrict
 		if research.SuggestedQuery != "" {
 			result.NextAction = "WebSearch: " + research.SuggestedQuery
 			result.Context = map[string]string{
@@ -190,35 +485,71 @@ func (r *Runner) runResearchGate(researchDone bool, prompt string) {
 			}
 		}
 	}
+	r.checkResearchSources(ctx, research, prompt, &result)
+
+	return research, result
+}
 
+// addResult records a gate result, applying risk budget escalation.
+// Weights use the gate's original status so escalation does not compound.
+func (r *Runner) addResult(result VerificationResult) {
+	if r.ask != nil && result.Status == "warn" && r.ask.AsksForGate(result.Gate) {
+		result.Status = "ask"
+		result.Source = SourceConfig
+	}
+	if r.risk != nil {
+		original := result.Status
+		score := r.state.Risk.Score
+		if escalated := r.risk.Escalate(original, score); escalated != original {
+			r.debug("Risk budget escalated %s: %s -> %s (score=%d)", result.Gate, original, escalated, score)
+			result.Status = escalated
+			result.Reason = fmt.Sprintf("risk_budget:%d escalated %s: %s", score, original, result.Reason)
+			result.Source = SourceConfig
+			if result.Context == nil {
+				result.Context = map[string]string{}
+			}
+			result.Context["escalated_from"] = original
+		}
+		weight := r.risk.Weight(original)
+		r.state.Risk.Delta += weight
+		r.state.Risk.Score += weight
+		r.state.Risk.Level = r.risk.Level(r.state.Risk.Score)
+	}
+	if r.post {
+		capPostResult(&result)
+	}
 	r.state.AddResult(result)
 }
 
-// finalize saves state and returns the final chain state.
+// finalize records the remediation plan, saves state and returns the
+// final chain state.
 func (r *Runner) finalize() *ChainState {
+	r.state.Remediation = r.state.remediationPlan(r.maxFindings)
 	r.saveState()
 	return r.state
 }
 
 // saveState persists the chain state for debugging/audit.
 func (r *Runner) saveState() {
-	if r.cacheDir == "" {
-		return
+	st := r.store
+	if st == nil {
+		if r.cacheDir == "" {
+			return
+		}
+		st = statestore.FileStore{Dir: r.cacheDir}
 	}
 
-	// Ensure directory exists
-	os.MkdirAll(r.cacheDir, 0755)
-
-	// Save state as JSON
-	filename := fmt.Sprintf("chain_%s_%d.json", r.state.SessionID, time.Now().Unix())
-	filepath := filepath.Join(r.cacheDir, filename)
-
+	// Swap in the scrubbed input only for the saved copy; callers keep the original
+	input := r.state.Input
+	r.state.SchemaVersion = StateSchemaVersion
+	r.state.Input = compactEdits(r.redaction.Redact(input))
 	data, err := json.MarshalIndent(r.state, "", "  ")
+	r.state.Input = input
 	if err != nil {
 		return
 	}
 
-	os.WriteFile(filepath, data, 0644)
+	st.Save(fmt.Sprintf("chain_%s_%d", r.state.SessionID, r.clock.Now().Unix()), data)
 }
 
 // debug logs debug messages if debug mode is enabled.
@@ -233,25 +564,19 @@ func (r *Runner) GetState() *ChainState {
 	return r.state
 }
 
-// ToTOON converts the chain state to TOON format for context injection.
+// ToTOON converts the chain state to TOON format for context injection,
+// trimmed to the runner's budget (WithTOONBudget).
 func (r *Runner) ToTOON() string {
-	toon := "[VERIFICATION_CHAIN]\n"
-	toon += fmt.Sprintf("session: %s\n", r.state.SessionID)
-	toon += fmt.Sprintf("status: %s\n", r.state.FinalStatus)
-	toon += fmt.Sprintf("timestamp: %s\n", time.Now().Format(time.RFC3339))
-	toon += "\n"
-
-	for _, result := range r.state.Results {
-		toon += fmt.Sprintf("[%s]\n", result.Gate)
-		toon += fmt.Sprintf("status: %s\n", result.Status)
-		toon += fmt.Sprintf("reason: %s\n", result.Reason)
-		if result.NextAction != "" {
-			toon += fmt.Sprintf("next_action: %s\n", result.NextAction)
-		}
-		toon += "\n"
-	}
+	return r.ToTOONWithin(r.toonMaxChars)
+}
 
-	return toon
+// ResearchTodoDirective instructs the model to track deferred research as a
+// TODO so work continues while the research debt stays visible.
+func ResearchTodoDirective(todo string) string {
+	return "[RESEARCH_TODO]\n" +
+		"instruction: Add this item with TodoWrite (status: pending) before continuing\n" +
+		fmt.Sprintf("todo: %s\n", todo) +
+		"complete_when: WebSearch/WebFetch results inform the implementation\n\n"
 }
 
 // ToJSON converts the chain state to JSON.
@@ -0,0 +1,60 @@
+// Package chain provides multi-agent verification chain for kavach.
+// schema.go: Versioned persistence for saved chain runs. saveState stamps
+// StateSchemaVersion; LoadState upgrades older files and refuses newer ones.
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// StateSchemaVersion is the ChainState layout written to disk. Files
+// written before the field existed read as version 0.
+const StateSchemaVersion = 1
+
+// ErrUnknownSchema is returned when a saved run is from a newer kavach.
+var ErrUnknownSchema = errors.New("unknown schema version")
+
+// migrations[v] upgrades a state from version v to v+1.
+var migrations = []func(*ChainState){
+	migrateV0,
+}
+
+// migrateV0 fills fields that pre-versioned files could leave empty.
+func migrateV0(s *ChainState) {
+	if s.Results == nil {
+		s.Results = make([]VerificationResult, 0)
+	}
+	if s.FinalStatus == "" {
+		s.FinalStatus = "pending"
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]interface{})
+	}
+}
+
+// Migrate upgrades state in place to StateSchemaVersion. A version newer
+// than this build understands returns ErrUnknownSchema.
+func Migrate(state *ChainState) error {
+	if state.SchemaVersion < 0 || state.SchemaVersion > StateSchemaVersion {
+		return fmt.Errorf("%w: chain state v%d, supported up to v%d", ErrUnknownSchema, state.SchemaVersion, StateSchemaVersion)
+	}
+	for v := state.SchemaVersion; v < StateSchemaVersion; v++ {
+		migrations[v](state)
+	}
+	state.SchemaVersion = StateSchemaVersion
+	return nil
+}
+
+// decodeState parses a saved chain run and migrates it.
+func decodeState(data []byte) (*ChainState, error) {
+	var state ChainState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	if err := Migrate(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -0,0 +1,170 @@
+// Package chain provides multi-agent verification chain for kavach.
+// self_protect.go: Edits to kavach's own config, hooks or binary could
+// switch off every other gate, so they always need user confirmation.
+// Reading them is reconnaissance for a bypass and warns.
+package chain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/claude/shared/pkg/patterns"
+)
+
+// WithSelfPaths sets the files and directories that make up kavach itself
+// (gates config, hook settings, binary). Writes under them ask the user.
+func WithSelfPaths(paths []string) Option {
+	return func(r *Runner) {
+		r.selfPaths = nil
+		for _, p := range paths {
+			if p != "" {
+				r.selfPaths = append(r.selfPaths, filepath.Clean(p))
+			}
+		}
+	}
+}
+
+// selfPath returns the entry of paths covering path, or "".
+func selfPath(paths []string, path string) string {
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(path)
+	for _, p := range paths {
+		if path == p || strings.HasPrefix(path, p+string(filepath.Separator)) {
+			return p
+		}
+	}
+	return ""
+}
+
+// bashSelfWrites are commands that modify the paths they name.
+var bashSelfWrites = []string{"tee", "rm", "mv", "cp", "ln", "chmod", "truncate", "install"}
+
+// bashRedirect captures the target of a > or >> redirect.
+var bashRedirect = regexp.MustCompile(`>>?\s*([^\s<>]+)`)
+
+// bashSelfPath returns the entry of paths a command names, written as an
+// absolute, ~/ or $HOME/ path, or "".
+func bashSelfPath(paths []string, command string) string {
+	for _, p := range paths {
+		if containsAny(command, selfPathForms(p)) {
+			return p
+		}
+	}
+	return ""
+}
+
+// selfPathForms are the ways a command can name protected path p.
+func selfPathForms(p string) []string {
+	forms := []string{p}
+	home, _ := os.UserHomeDir()
+	if home != "" && strings.HasPrefix(p, home+string(filepath.Separator)) {
+		rel := p[len(home)+1:]
+		forms = append(forms, "~/"+rel, "$HOME/"+rel, "${HOME}/"+rel)
+	}
+	return forms
+}
+
+// bashWritesSelf reports whether command modifies protected path p: a
+// simple command that is a write (bashSelfWrites, sed -i) naming it, or a
+// redirect into it. Reading it with stderr sent elsewhere is not a write.
+func bashWritesSelf(command, p string) bool {
+	forms := selfPathForms(p)
+	for _, seg := range patterns.SplitCommand(command) {
+		for _, m := range bashRedirect.FindAllStringSubmatch(seg, -1) {
+			if containsAny(strings.Trim(m[1], `'"`), forms) {
+				return true
+			}
+		}
+		fields := strings.Fields(seg)
+		if len(fields) > 1 && fields[0] == "sudo" {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || !containsAny(seg, forms) {
+			continue
+		}
+		verb := filepath.Base(fields[0])
+		if slices.Contains(bashSelfWrites, verb) || (verb == "sed" && slices.ContainsFunc(fields, isSedInPlace)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSedInPlace matches sed's -i, -i.bak and --in-place flags.
+func isSedInPlace(arg string) bool {
+	return strings.HasPrefix(arg, "-i") || strings.HasPrefix(arg, "--in-place")
+}
+
+// SelfAccess returns the entry of paths (kavach's own files, see
+// WithSelfPaths) a tool call touches, or "", and whether it modifies it.
+// Used by the runner and by the hook gates that see writes first.
+func SelfAccess(paths []string, toolName string, toolInput map[string]interface{}) (protected string, write bool) {
+	if len(paths) == 0 {
+		return "", false
+	}
+	switch toolName {
+	case "Bash":
+		command, _ := toolInput["command"].(string)
+		protected = bashSelfPath(paths, command)
+		return protected, protected != "" && bashWritesSelf(command, protected)
+	case "Glob", "Grep":
+		path, _ := toolInput["path"].(string)
+		return selfPath(paths, path), false
+	}
+	path, _ := toolInput["file_path"].(string)
+	if path == "" {
+		path, _ = toolInput["notebook_path"].(string)
+	}
+	protected = selfPath(paths, path)
+	return protected, protected != "" && isMutatingTool(toolName)
+}
+
+// checkSelfProtection raises writes to kavach's own files to a high-threat
+// ask and reads of them to a reconnaissance warn. It overrides pass (and
+// warn, for writes) but never lowers a block.
+func (r *Runner) checkSelfProtection(aegis *AegisVerification, toolName string, toolInput map[string]interface{}, result *VerificationResult) {
+	if len(r.selfPaths) == 0 || result.Status == "block" {
+		return
+	}
+	protected, write := SelfAccess(r.selfPaths, toolName, toolInput)
+	if protected == "" {
+		return
+	}
+	if !write {
+		r.flagSelfRecon(aegis, toolName, protected, result)
+		return
+	}
+	violation := fmt.Sprintf("SELF_PROTECTION: %s modifies kavach's own %s", toolName, protected)
+	aegis.ThreatLevel = "high"
+	aegis.ViolationsFound = append(aegis.ViolationsFound, violation)
+
+	result.Status = "ask"
+	result.Reason = violation
+	result.Source = SourceBuiltin
+	result.NextAction = "Confirm this change to kavach's protections is intended"
+	result.Context["threat_level"] = aegis.ThreatLevel
+	result.Context["self_path"] = protected
+}
+
+// flagSelfRecon warns on a read of kavach's own config, hooks or binary,
+// which maps the protections an adversarial prompt would need to bypass.
+func (r *Runner) flagSelfRecon(aegis *AegisVerification, toolName, protected string, result *VerificationResult) {
+	if result.Status != "pass" {
+		return
+	}
+	if aegis.ThreatLevel == "none" {
+		aegis.ThreatLevel = "medium"
+	}
+	result.Status = "warn"
+	result.Reason = fmt.Sprintf("SELF_RECON: %s reads kavach's own %s (config enumeration)", toolName, protected)
+	result.Source = SourceBuiltin
+	result.NextAction = "Only inspect kavach's configuration when the user asked for it"
+	result.Context["threat_level"] = aegis.ThreatLevel
+	result.Context["self_path"] = protected
+}
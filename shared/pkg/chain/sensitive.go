@@ -0,0 +1,110 @@
+// Package chain provides multi-agent verification chain for kavach.
+// sensitive.go: Sensitive file detection for Aegis. Each pattern carries a
+// category so the violation says what kind of file it is.
+package chain
+
+import (
+	"path"
+	"strings"
+
+	"github.com/claude/shared/pkg/patterns"
+)
+
+// Sensitive path categories.
+const (
+	SensitiveCredential = "credential" // Keys, tokens, password stores
+	SensitiveHistory    = "history"    // Shell and REPL histories (typed secrets)
+	SensitiveConfig     = "config"     // Tool configs that embed auth or system accounts
+)
+
+// SensitivePath is a normalized path substring and the kind of file it marks.
+type SensitivePath struct {
+	Pattern  string
+	Category string
+}
+
+// sensitivePaths are matched against normalized paths (see isSensitivePath).
+var sensitivePaths = append([]SensitivePath{
+	{"/etc/shadow", SensitiveCredential},
+	{"/.ssh/", SensitiveCredential},
+	{"/.aws/credentials", SensitiveCredential},
+	{"/.gnupg/", SensitiveCredential},
+	{".pem", SensitiveCredential},
+	{".key", SensitiveCredential},
+	{"/.netrc", SensitiveCredential},
+	{"/.git-credentials", SensitiveCredential},
+	{"/.vault-token", SensitiveCredential},
+	{"/library/keychains/", SensitiveCredential},
+	// Browser password and cookie stores (Chrome/Chromium/Edge, Firefox)
+	{"/login data", SensitiveCredential},
+	{"/default/cookies", SensitiveCredential},
+	{"/logins.json", SensitiveCredential},
+	{"/key4.db", SensitiveCredential},
+
+	{"/.bash_history", SensitiveHistory},
+	{"/.zsh_history", SensitiveHistory},
+	{"/.zhistory", SensitiveHistory},
+	{"/fish/fish_history", SensitiveHistory},
+	{"/.python_history", SensitiveHistory},
+	{"/.node_repl_history", SensitiveHistory},
+	{"/.psql_history", SensitiveHistory},
+	{"/.mysql_history", SensitiveHistory},
+	{"/.lesshst", SensitiveHistory},
+	{"/psreadline/consolehost_history.txt", SensitiveHistory},
+
+	{"/etc/passwd", SensitiveConfig},
+	{"/.docker/config.json", SensitiveConfig},
+	{"/.kube/config", SensitiveConfig},
+	{"/.config/gh/hosts.yml", SensitiveConfig},
+	{"/.npmrc", SensitiveConfig},
+	{"/.pypirc", SensitiveConfig},
+	{"/.config/gcloud/", SensitiveConfig},
+	{"/.azure/", SensitiveConfig},
+}, windowsSensitivePaths()...)
+
+// windowsSensitivePaths tags the shared Windows credential stores.
+func windowsSensitivePaths() []SensitivePath {
+	out := make([]SensitivePath, len(patterns.WindowsSensitivePaths))
+	for i, p := range patterns.WindowsSensitivePaths {
+		out[i] = SensitivePath{p, SensitiveCredential}
+	}
+	return out
+}
+
+// WithSensitivePaths adds path substrings to the built-in sensitive list.
+// Patterns are normalized like the paths they match.
+func WithSensitivePaths(extra []SensitivePath) Option {
+	return func(r *Runner) {
+		r.sensitivePaths = nil
+		for _, sp := range extra {
+			if sp.Pattern == "" {
+				continue
+			}
+			sp.Pattern = patterns.NormalizePath(sp.Pattern)
+			r.sensitivePaths = append(r.sensitivePaths, sp)
+		}
+	}
+}
+
+// matchSensitivePath returns the first built-in or extra entry that p
+// hits. The normalized path and its cleaned, rooted form are both checked,
+// so "/etc/./shadow", "//etc//shadow", a relative ".ssh/id_rsa" and
+// C:\Users\x\.ssh\id_rsa all match.
+func matchSensitivePath(p string, extra []SensitivePath) *SensitivePath {
+	raw := patterns.NormalizePath(p)
+	cleaned := "/" + path.Clean(raw)
+	for _, list := range [][]SensitivePath{sensitivePaths, extra} {
+		for i := range list {
+			s := list[i].Pattern
+			if strings.Contains(raw, s) || strings.Contains(cleaned, s) {
+				return &list[i]
+			}
+		}
+	}
+	return nil
+}
+
+// isSensitivePath reports whether p hits a built-in sensitive path.
+func isSensitivePath(p string) bool {
+	return matchSensitivePath(p, nil) != nil
+}
@@ -0,0 +1,85 @@
+// Package chain provides multi-agent verification chain for kavach.
+// store.go: Listing of persisted chain runs (~/.claude/chain, or any
+// statestore.Store set with WithStore).
+package chain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/claude/shared/pkg/statestore"
+)
+
+// StateDir returns the directory where chain runs are persisted.
+func StateDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude", "chain")
+}
+
+// StateSummary is a one-line view of a persisted chain run.
+type StateSummary struct {
+	Key         string    `json:"key"`            // Store key, e.g. chain_<session>_<unix>
+	Path        string    `json:"path,omitempty"` // File, for runs in a file store
+	SessionID   string    `json:"session_id"`
+	FinalStatus string    `json:"final_status"`
+	Gates       int       `json:"gates"`
+	Time        time.Time `json:"time"`
+}
+
+// ListStates returns summaries of persisted chain runs in dir, newest first.
+// since > 0 keeps only files modified within that window.
+func ListStates(dir string, since time.Duration) ([]StateSummary, error) {
+	return ListStoreStates(statestore.FileStore{Dir: dir}, since)
+}
+
+// ListStoreStates is ListStates over any store.
+func ListStoreStates(st statestore.Store, since time.Duration) ([]StateSummary, error) {
+	records, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Time{}
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+
+	var out []StateSummary
+	for _, rec := range records {
+		if !strings.HasPrefix(rec.Key, "chain_") || rec.ModTime.Before(cutoff) {
+			continue
+		}
+		state, err := decodeState(rec.Data)
+		if err != nil {
+			continue
+		}
+		sum := StateSummary{
+			Key:         rec.Key,
+			SessionID:   state.SessionID,
+			FinalStatus: state.FinalStatus,
+			Gates:       len(state.Results),
+			Time:        rec.ModTime,
+		}
+		if fs, ok := st.(statestore.FileStore); ok {
+			sum.Path = fs.Path(rec.Key)
+		}
+		out = append(out, sum)
+	}
+	return out, nil
+}
+
+// LoadStoreState reads the chain run saved under key.
+func LoadStoreState(st statestore.Store, key string) (*ChainState, error) {
+	data, err := st.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	state, err := decodeState(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return state, nil
+}
@@ -0,0 +1,77 @@
+package chain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/claude/shared/pkg/statestore"
+)
+
+func TestListStatesSince(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string, age time.Duration) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mt := time.Now().Add(-age)
+		os.Chtimes(path, mt, mt)
+	}
+	write("chain_new_1.json", `{"session_id":"new","final_status":"approved","results":[{"gate":"INTENT"},{"gate":"CEO"}]}`, time.Hour)
+	write("chain_old_1.json", `{"session_id":"old","final_status":"blocked","results":[{"gate":"INTENT"}]}`, 72*time.Hour)
+	write("other.json", `{}`, time.Hour)
+
+	all, err := ListStates(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].SessionID != "new" {
+		t.Fatalf("ListStates(all) = %+v, want new then old", all)
+	}
+	if all[0].Gates != 2 || all[0].FinalStatus != "approved" {
+		t.Errorf("summary = %+v, want 2 gates approved", all[0])
+	}
+
+	recent, _ := ListStates(dir, 24*time.Hour)
+	if len(recent) != 1 || recent[0].SessionID != "new" {
+		t.Errorf("ListStates(24h) = %+v, want only new", recent)
+	}
+
+	if missing, err := ListStates(filepath.Join(dir, "nope"), 0); err != nil || missing != nil {
+		t.Errorf("missing dir: %v, %v", missing, err)
+	}
+}
+
+func TestSavedEditIsSummarized(t *testing.T) {
+	st := statestore.NewMemoryStore()
+	input := map[string]interface{}{"file_path": "main.go", "old_string": "a\nb", "new_string": "a\nc\nd"}
+	state := NewRunner("sess_edit", WithStore(st)).RunFull("fix the bug", "Edit", input, true)
+	if _, ok := state.Input.ToolInput["old_string"]; !ok {
+		t.Error("in-memory input lost old_string")
+	}
+
+	var aegis *VerificationResult
+	for i := range state.Results {
+		if state.Results[i].Gate == "AEGIS" {
+			aegis = &state.Results[i]
+		}
+	}
+	if aegis == nil || aegis.Context["edit_diff"] != "+2 -1 (net +1) in 1 edit" {
+		t.Errorf("AEGIS result = %+v", aegis)
+	}
+
+	saved, err := ListStoreStates(st, 0)
+	if err != nil || len(saved) != 1 || saved[0].Path != "" {
+		t.Fatalf("ListStoreStates = %v, %v", saved, err)
+	}
+	loaded, err := LoadStoreState(st, saved[0].Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := loaded.Input.ToolInput
+	if in["diff"] != "+2 -1 (net +1) in 1 edit" || in["old_string"] != nil || in["new_string"] != nil || !loaded.Input.Redacted {
+		t.Errorf("saved input = %+v", loaded.Input)
+	}
+}
@@ -0,0 +1,69 @@
+// Package chain provides multi-agent verification chain for kavach.
+// tool_policy.go: Per-tool decision floor ("Bash always asks") applied
+// after the gates. Findings can escalate above the floor, never below it.
+package chain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/claude/shared/pkg/types"
+)
+
+// policyRank orders decisions; unknown values rank as allow.
+var policyRank = map[string]int{"allow": 0, "pass": 0, "warn": 1, "ask": 2, "block": 3}
+
+// WithToolPolicy sets the decision floor per tool name ("allow", "warn",
+// "ask" or "block"). Keys are exact names or globs ("mcp__github__*")
+// and match case-insensitively.
+func WithToolPolicy(policy map[string]string) Option {
+	return func(r *Runner) {
+		r.toolPolicy = make(map[string]string, len(policy))
+		for tool, decision := range policy {
+			r.toolPolicy[strings.ToLower(tool)] = strings.ToLower(decision)
+		}
+	}
+}
+
+// toolFloor returns the floor for toolName and the key that set it: the
+// exact entry, else the longest matching glob (ties by key order).
+func (r *Runner) toolFloor(toolName string) (floor, key string) {
+	name := strings.ToLower(toolName)
+	if floor, ok := r.toolPolicy[name]; ok {
+		return floor, name
+	}
+	for pattern, decision := range r.toolPolicy {
+		if !types.MatchToolName(pattern, name) {
+			continue
+		}
+		if len(pattern) > len(key) || (len(pattern) == len(key) && pattern < key) {
+			floor, key = decision, pattern
+		}
+	}
+	return floor, key
+}
+
+// applyToolPolicy adds a POLICY result when no gate reached the tool's
+// floor. Precedence: a gate block or ask above the floor stands; a floor
+// above every gate result raises the final decision to the floor. The
+// floor is a baseline, not a finding, so it does not feed the risk budget.
+func (r *Runner) applyToolPolicy(toolName string) {
+	floor, key := r.toolFloor(toolName)
+	if policyRank[floor] == 0 {
+		return
+	}
+	for _, res := range r.state.Results {
+		if policyRank[res.Status] >= policyRank[floor] {
+			return
+		}
+	}
+	r.debug("Tool policy floor for %s: %s", toolName, floor)
+	r.state.AddResult(VerificationResult{
+		Gate:       "POLICY",
+		Status:     floor,
+		Reason:     fmt.Sprintf("tool_policy: %s requires %s", toolName, floor),
+		Context:    map[string]string{"tool_policy": floor, "tool_pattern": key},
+		NextAction: "Decision set by tool_policy in gates config",
+		Source:     SourceConfig,
+	})
+}
@@ -0,0 +1,115 @@
+// Package chain provides multi-agent verification chain for kavach.
+// toon.go: TOON rendering of chain state within a context budget.
+package chain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// toonDetail is how much of each result ToTOONWithin keeps.
+type toonDetail int
+
+const (
+	toonFull        toonDetail = iota // Everything
+	toonCompactPass                   // Pass results reduced to status; no provenance
+	toonDropPass                      // Pass results listed by name only; risk on one line
+	toonEssential                     // Non-block reasons clipped; block reasons kept whole
+)
+
+var toonDetailNames = []string{"full", "compact_pass", "drop_pass", "essential"}
+
+// toonClip bounds warn/ask reasons at toonEssential.
+const toonClip = 120
+
+// ToTOONWithin renders the chain state, dropping verbose pass details first
+// until the output fits maxChars. The final status and block reasons are
+// always kept, so the result may still exceed a very small budget.
+// maxChars <= 0 renders everything.
+func (r *Runner) ToTOONWithin(maxChars int) string {
+	toon := r.renderTOON(toonFull, 0)
+	if maxChars <= 0 || len(toon) <= maxChars {
+		return toon
+	}
+	for detail := toonCompactPass; detail <= toonEssential; detail++ {
+		toon = r.renderTOON(detail, maxChars)
+		if len(toon) <= maxChars {
+			break
+		}
+	}
+	return toon
+}
+
+func (r *Runner) renderTOON(detail toonDetail, maxChars int) string {
+	var sb strings.Builder
+	sb.WriteString("[VERIFICATION_CHAIN]\n")
+	fmt.Fprintf(&sb, "session: %s\n", r.state.SessionID)
+	fmt.Fprintf(&sb, "status: %s\n", r.state.FinalStatus)
+	fmt.Fprintf(&sb, "timestamp: %s\n", r.clock.Now().Format(time.RFC3339))
+	if detail > toonFull {
+		fmt.Fprintf(&sb, "truncated: budget=%d detail=%s\n", maxChars, toonDetailNames[detail])
+	}
+	if detail >= toonDropPass {
+		var passed []string
+		for _, result := range r.state.Results {
+			if result.Status == "pass" {
+				passed = append(passed, result.Gate)
+			}
+		}
+		if len(passed) > 0 {
+			fmt.Fprintf(&sb, "passed: %s\n", strings.Join(passed, ","))
+		}
+	}
+	sb.WriteString("\n")
+
+	if risk := r.state.Risk; risk != nil {
+		if detail >= toonDropPass {
+			fmt.Fprintf(&sb, "[RISK_BUDGET] score=%d delta=%d level=%s\n\n", risk.Score, risk.Delta, risk.Level)
+		} else {
+			sb.WriteString("[RISK_BUDGET]\n")
+			fmt.Fprintf(&sb, "score: %d\n", risk.Score)
+			fmt.Fprintf(&sb, "delta: %d\n", risk.Delta)
+			fmt.Fprintf(&sb, "level: %s\n", risk.Level)
+			fmt.Fprintf(&sb, "thresholds: ask=%d,block=%d\n", risk.AskThreshold, risk.BlockThreshold)
+			sb.WriteString("\n")
+		}
+	}
+
+	if r.state.Research != nil && r.state.Research.Todo != "" {
+		sb.WriteString(ResearchTodoDirective(r.state.Research.Todo))
+	}
+
+	for _, result := range r.state.Results {
+		r.renderResult(&sb, result, detail)
+	}
+	sb.WriteString(remediationTOON(r.state.Remediation))
+	return sb.String()
+}
+
+func (r *Runner) renderResult(sb *strings.Builder, result VerificationResult, detail toonDetail) {
+	if result.Status == "pass" && detail >= toonCompactPass {
+		if detail == toonCompactPass {
+			fmt.Fprintf(sb, "[%s]\nstatus: pass\n\n", result.Gate)
+		}
+		return
+	}
+
+	reason := result.Reason
+	if detail == toonEssential && result.Status != "block" && len(reason) > toonClip {
+		reason = reason[:toonClip] + "..."
+	}
+	fmt.Fprintf(sb, "[%s]\n", result.Gate)
+	fmt.Fprintf(sb, "status: %s\n", result.Status)
+	fmt.Fprintf(sb, "reason: %s\n", reason)
+	if result.Source != "" && detail < toonEssential {
+		fmt.Fprintf(sb, "source: %s\n", result.Source)
+	}
+	if result.NextAction != "" && (detail < toonEssential || result.Status == "block") {
+		fmt.Fprintf(sb, "next_action: %s\n", result.NextAction)
+	}
+	if result.Gate == "AEGIS" && r.state.Aegis != nil && detail == toonFull {
+		fmt.Fprintf(sb, "provenance: %s\n", r.state.Aegis.MemoryProvenance)
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,221 @@
+// Package chain provides multi-agent verification chain for kavach.
+// topology.go: Static view of the pipeline RunFull executes for a runner's
+// options, rendered as Mermaid or Graphviz dot for documentation.
+package chain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check is an optional policy folded into a stage's result.
+type Check struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Stage is one gate of the pipeline in execution order.
+type Stage struct {
+	Gate    string  `json:"gate"` // As reported in results (INTENT, CEO, ...)
+	Enabled bool    `json:"enabled"`
+	Detail  string  `json:"detail"`
+	Checks  []Check `json:"checks,omitempty"`
+}
+
+// Topology is the configured pipeline. With FailFast a block at any stage
+// ends the run; without it every stage runs and a block is reported at the
+// end. Parallel runs AEGIS and RESEARCH concurrently. Enabled, Chain and
+// FailFast mirror the enforcer config; a runner alone reports the chain on
+// and fail-fast for every tool.
+type Topology struct {
+	Enabled  bool     `json:"enabled"`
+	Chain    []string `json:"chain,omitempty"` // Tool kinds the chain runs for (read, bash, write)
+	FailFast bool     `json:"fail_fast"`
+	Stages   []Stage  `json:"stages"`
+	Parallel bool     `json:"parallel"`
+	Risk     bool     `json:"risk_budget"` // Every result feeds the session risk budget
+}
+
+// Topology describes the pipeline this runner's options produce.
+func (r *Runner) Topology() Topology {
+	classifier := "built-in keywords"
+	switch r.classifier.(type) {
+	case nil:
+	case *KeywordClassifier:
+		classifier = "configured keywords"
+	default:
+		classifier = fmt.Sprintf("custom (%T)", r.classifier)
+	}
+	research := "classifier flag"
+	researchOn := true
+	if r.researchPolicy != nil {
+		research = fmt.Sprintf("%d policy rules", len(r.researchPolicy.Rules))
+		researchOn = len(r.researchPolicy.Rules) > 0
+	}
+	if len(r.researchTodoLevels) > 0 {
+		research += ", todo at " + strings.Join(r.researchTodoLevels, ",")
+	}
+	return Topology{
+		Enabled:  true,
+		FailFast: true,
+		Parallel: r.parallel,
+		Risk:     r.risk != nil,
+		Stages: []Stage{
+			{Gate: "INTENT", Enabled: true, Detail: classifier},
+			{Gate: "CEO", Enabled: true, Detail: "delegation strategy"},
+			{Gate: "AEGIS", Enabled: true, Detail: "dangerous commands, sensitive paths", Checks: []Check{
+				{"self_protection", len(r.selfPaths) > 0},
+				{"aegis_actions", len(r.aegisActions) > 0},
+				{"ask", r.ask != nil},
+				{"packages", r.packages != nil},
+				{"endpoints", r.endpoints != nil},
+				{"security_evasion", r.evasion != nil},
+				{"deploy_window", r.deployWindow != nil},
+				{"recommendations", r.recommend != nil},
+			}},
+			{Gate: "RESEARCH", Enabled: researchOn, Detail: research},
+			{Gate: "POLICY", Enabled: len(r.toolPolicy) > 0, Detail: fmt.Sprintf("%d tool floors", len(r.toolPolicy))},
+		},
+	}
+}
+
+// active reports whether stage s runs: it is enabled and so is the chain.
+func (t Topology) active(s Stage) bool {
+	return t.Enabled && s.Enabled
+}
+
+// start is the START node's text: the tool call, and which kinds of tool
+// the chain covers.
+func (t Topology) start() string {
+	if len(t.Chain) == 0 {
+		return "tool call"
+	}
+	return "tool call (" + strings.Join(t.Chain, ", ") + ")"
+}
+
+// exits are the block transitions: one per active stage with fail-fast,
+// else a single one after the last stage.
+func (t Topology) exits() []edge {
+	if !t.Enabled {
+		return nil
+	}
+	if !t.FailFast {
+		return []edge{{t.Stages[len(t.Stages)-1].Gate, "BLOCKED", "any block"}}
+	}
+	var out []edge
+	for _, s := range t.Stages {
+		if s.Enabled {
+			out = append(out, edge{s.Gate, "BLOCKED", "block"})
+		}
+	}
+	return out
+}
+
+// label is a node's text: gate, detail and its enabled checks.
+func (s Stage) label() string {
+	parts := []string{s.Gate, s.Detail}
+	var on []string
+	for _, c := range s.Checks {
+		if c.Enabled {
+			on = append(on, c.Name)
+		}
+	}
+	if len(on) > 0 {
+		parts = append(parts, "+ "+strings.Join(on, ", "))
+	}
+	if !s.Enabled {
+		parts = append(parts, "(disabled)")
+	}
+	return strings.Join(parts, "\n")
+}
+
+// edge is a pipeline transition; Label is empty for a plain pass.
+type edge struct {
+	From, To, Label string
+}
+
+// edges lists the pass transitions between stages. With Parallel, AEGIS
+// and RESEARCH both start after CEO and both lead to the next stage.
+// A disabled chain passes straight from START to DONE.
+func (t Topology) edges() []edge {
+	if !t.Enabled {
+		return []edge{{"START", "DONE", "chain disabled"}}
+	}
+	out := []edge{{"START", t.Stages[0].Gate, ""}}
+	for i := 1; i < len(t.Stages); i++ {
+		from, to := t.Stages[i-1].Gate, t.Stages[i].Gate
+		switch {
+		case t.Parallel && to == "RESEARCH":
+			out = append(out, edge{"CEO", to, "parallel"})
+		case t.Parallel && from == "RESEARCH":
+			out = append(out, edge{"AEGIS", to, ""}, edge{from, to, ""})
+		default:
+			out = append(out, edge{from, to, ""})
+		}
+	}
+	return append(out, edge{t.Stages[len(t.Stages)-1].Gate, "DONE", ""})
+}
+
+// Mermaid renders the topology as a Mermaid flowchart. Disabled stages are
+// greyed; dotted edges are the block exits.
+func (t Topology) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	fmt.Fprintf(&sb, "    START([%s])\n    DONE([approved / ask])\n    BLOCKED([blocked])\n", t.start())
+	for _, s := range t.Stages {
+		fmt.Fprintf(&sb, "    %s[\"%s\"]\n", s.Gate, strings.ReplaceAll(s.label(), "\n", "<br/>"))
+	}
+	for _, e := range t.edges() {
+		if e.Label != "" {
+			fmt.Fprintf(&sb, "    %s -- %s --> %s\n", e.From, e.Label, e.To)
+		} else {
+			fmt.Fprintf(&sb, "    %s --> %s\n", e.From, e.To)
+		}
+	}
+	for _, e := range t.exits() {
+		fmt.Fprintf(&sb, "    %s -. %s .-> %s\n", e.From, e.Label, e.To)
+	}
+	if t.Risk {
+		sb.WriteString("    RISK[(risk budget: escalates every result)]\n")
+	}
+	sb.WriteString("    classDef disabled fill:#eee,stroke:#aaa,color:#999\n")
+	for _, s := range t.Stages {
+		if !t.active(s) {
+			fmt.Fprintf(&sb, "    class %s disabled\n", s.Gate)
+		}
+	}
+	return sb.String()
+}
+
+// Dot renders the topology as a Graphviz digraph. Disabled stages are
+// greyed; dashed edges are the block exits.
+func (t Topology) Dot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph chain {\n")
+	sb.WriteString("    rankdir=TB;\n    node [shape=box];\n")
+	fmt.Fprintf(&sb, "    START [label=%q, shape=oval];\n", t.start())
+	sb.WriteString("    DONE [label=\"approved / ask\", shape=oval];\n")
+	sb.WriteString("    BLOCKED [label=\"blocked\", shape=oval, color=red];\n")
+	for _, s := range t.Stages {
+		style := ""
+		if !t.active(s) {
+			style = ", style=filled, fillcolor=\"#eeeeee\", color=\"#aaaaaa\", fontcolor=\"#999999\""
+		}
+		fmt.Fprintf(&sb, "    %s [label=%q%s];\n", s.Gate, s.label(), style)
+	}
+	for _, e := range t.edges() {
+		if e.Label != "" {
+			fmt.Fprintf(&sb, "    %s -> %s [label=%q];\n", e.From, e.To, e.Label)
+		} else {
+			fmt.Fprintf(&sb, "    %s -> %s;\n", e.From, e.To)
+		}
+	}
+	for _, e := range t.exits() {
+		fmt.Fprintf(&sb, "    %s -> %s [style=dashed, label=%q];\n", e.From, e.To, e.Label)
+	}
+	if t.Risk {
+		sb.WriteString("    RISK [label=\"risk budget: escalates every result\", shape=cylinder];\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
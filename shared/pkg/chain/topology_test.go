@@ -0,0 +1,59 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopology(t *testing.T) {
+	topo := NewRunner("sess_test", WithParallel(true), WithToolPolicy(map[string]string{"Bash": "ask"}),
+		WithResearchPolicy(ResearchPolicy{})).Topology()
+
+	gates := make(map[string]Stage)
+	for _, s := range topo.Stages {
+		gates[s.Gate] = s
+	}
+	if gates["RESEARCH"].Enabled || !gates["POLICY"].Enabled || !topo.Parallel {
+		t.Fatalf("topology = %+v", topo)
+	}
+
+	mermaid := topo.Mermaid()
+	for _, want := range []string{"CEO -- parallel --> RESEARCH", "AEGIS --> POLICY", "class RESEARCH disabled", "AEGIS -. block .-> BLOCKED"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid missing %q:\n%s", want, mermaid)
+		}
+	}
+	if strings.Contains(mermaid, "RESEARCH -. block") {
+		t.Error("disabled stage should have no block exit")
+	}
+
+	dot := topo.Dot()
+	for _, want := range []string{"digraph chain {", "CEO -> RESEARCH [label=\"parallel\"];", "POLICY -> DONE;", "fillcolor=\"#eeeeee\""} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Dot missing %q:\n%s", want, dot)
+		}
+	}
+
+	// Enforcer config: covered tools, one block exit without fail-fast,
+	// and a disabled chain bypassing every stage
+	topo.Chain, topo.FailFast = []string{"bash", "write"}, false
+	mermaid = topo.Mermaid()
+	for _, want := range []string{"START([tool call (bash, write)])", "POLICY -. any block .-> BLOCKED"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid missing %q:\n%s", want, mermaid)
+		}
+	}
+	if strings.Contains(mermaid, "AEGIS -. block") {
+		t.Errorf("per-stage exit without fail-fast:\n%s", mermaid)
+	}
+	topo.Enabled = false
+	dot = topo.Dot()
+	for _, want := range []string{"START -> DONE [label=\"chain disabled\"];", "INTENT [label=\"INTENT\\nbuilt-in keywords\", style=filled"} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Dot missing %q:\n%s", want, dot)
+		}
+	}
+	if strings.Contains(dot, "BLOCKED [style") || strings.Contains(dot, "-> BLOCKED") {
+		t.Errorf("disabled chain has block exits:\n%s", dot)
+	}
+}
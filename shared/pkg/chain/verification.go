@@ -4,30 +4,71 @@
 package chain
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/claude/shared/pkg/patterns"
+	"github.com/claude/shared/pkg/util"
 )
 
 // VerificationResult holds the result of a verification step.
 type VerificationResult struct {
 	Gate       string            `json:"gate"`
-	Status     string            `json:"status"` // "pass", "warn", "block"
+	Status     string            `json:"status"` // "pass", "warn", "ask", "block"
 	Reason     string            `json:"reason"`
 	Context    map[string]string `json:"context,omitempty"`
 	Timestamp  time.Time         `json:"timestamp"`
 	NextAction string            `json:"next_action,omitempty"` // Suggestion for next step
+	Source     string            `json:"source,omitempty"`      // Where a non-pass decision came from (Source* constants)
+}
+
+// Rule sources tell users which knob to turn when a gate fires.
+const (
+	SourceBuiltin = "builtin-detection" // Hardcoded detector (dangerous commands, sensitive paths)
+	SourceConfig  = "config-policy"     // gates config rule (blocked_paths, ask, risk budget)
+	SourceStrict  = "strict-mode"       // Strict enforcement without a bypass (research before high-risk work)
+)
+
+// Describe renders "GATE [source]: reason" for permission prompts.
+func (r VerificationResult) Describe() string {
+	if r.Source == "" {
+		return r.Gate + ": " + r.Reason
+	}
+	return r.Gate + " [" + r.Source + "]: " + r.Reason
 }
 
 // ChainState holds the accumulated state across verification gates.
 type ChainState struct {
+	SchemaVersion int `json:"schema_version"` // See StateSchemaVersion
+
 	SessionID   string                 `json:"session_id"`
+	Input       *ChainInput            `json:"input,omitempty"`
 	Intent      *IntentAnalysis        `json:"intent,omitempty"`
 	CEO         *CEODecision           `json:"ceo,omitempty"`
 	Aegis       *AegisVerification     `json:"aegis,omitempty"`
 	Research    *ResearchStatus        `json:"research,omitempty"`
 	Results     []VerificationResult   `json:"results"`
-	FinalStatus string                 `json:"final_status"` // "approved", "blocked", "pending"
+	Risk        *RiskStatus            `json:"risk,omitempty"`
+	FinalStatus string                 `json:"final_status"`                // "approved", "ask", "blocked", "pending"; RunPost: "context" or "blocked"
+	Deadline    string                 `json:"deadline_exceeded,omitempty"` // First gate skipped by an expired RunFullContext deadline
+	Remediation []string               `json:"remediation,omitempty"`       // Ordered unblock steps when blocked (RemediationPlan)
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	mu    sync.Mutex // Guards Results/FinalStatus for concurrent gates
+	clock util.Clock // Stamps result timestamps; nil means util.SystemClock
+}
+
+// ChainInput records what RunFull evaluated, so a saved run can be replayed.
+type ChainInput struct {
+	Prompt         string                 `json:"prompt,omitempty"`
+	ToolName       string                 `json:"tool_name"`
+	ToolInput      map[string]interface{} `json:"tool_input,omitempty"`
+	ResearchDone   bool                   `json:"research_done"`
+	TranscriptPath string                 `json:"transcript_path,omitempty"`
+	ToolResponse   map[string]interface{} `json:"tool_response,omitempty"` // Set by RunPost
+	Redacted       bool                   `json:"redacted,omitempty"`      // Scrubbed before persisting; replay sees placeholders
 }
 
 // IntentAnalysis holds the result of intent classification.
@@ -39,6 +80,9 @@ type IntentAnalysis struct {
 	RequiresResearch bool     `json:"requires_research"` // TABULA_RASA trigger
 	Complexity       string   `json:"complexity"`        // "simple", "moderate", "complex"
 	RiskLevel        string   `json:"risk_level"`        // "low", "medium", "high", "critical"
+
+	// Keyword hits per category ("debug:2") and tie-breaks ("tie:a,b->b")
+	MatchedSignals []string `json:"matched_signals,omitempty"`
 }
 
 // CEODecision holds the CEO gate's delegation decision.
@@ -53,12 +97,22 @@ type CEODecision struct {
 
 // AegisVerification holds security verification results.
 type AegisVerification struct {
-	Passed           bool     `json:"passed"`
-	SecurityScore    float64  `json:"security_score"`    // 0.0 - 1.0
-	ThreatLevel      string   `json:"threat_level"`      // "none", "low", "medium", "high"
-	ViolationsFound  []string `json:"violations_found"`  // Security violations
-	Recommendations  []string `json:"recommendations"`   // Security recommendations
-	MemoryProvenance string   `json:"memory_provenance"` // Source tracking
+	Passed           bool       `json:"passed"`
+	SecurityScore    float64    `json:"security_score"`    // 0.0 - 1.0
+	ThreatLevel      string     `json:"threat_level"`      // "none", "low", "medium", "high"
+	ViolationsFound  []string   `json:"violations_found"`  // Security violations
+	Recommendations  []string   `json:"recommendations"`   // Security recommendations
+	MemoryProvenance Provenance `json:"memory_provenance"` // What the decision was based on
+
+	// Category per recommendation, counted across the session
+	RecommendationCategories []string `json:"recommendation_categories,omitempty"`
+
+	// Category per violation (ViolationDangerousCommand, ...), for aegis actions
+	ViolationCategories []string `json:"violation_categories,omitempty"`
+
+	// Bash only: ScoreDestructiveness of the command and why
+	Destructiveness    float64  `json:"destructiveness,omitempty"`
+	DestructiveReasons []string `json:"destructive_reasons,omitempty"`
 }
 
 // ResearchStatus holds TABULA_RASA compliance status.
@@ -66,28 +120,44 @@ type ResearchStatus struct {
 	Done           bool     `json:"done"`
 	Sources        []string `json:"sources,omitempty"`
 	SuggestedQuery string   `json:"suggested_query,omitempty"`
-	Bypass         bool     `json:"bypass"`        // True for trivial changes
-	BypassReason   string   `json:"bypass_reason"` // Why bypassed
+	Todo           string   `json:"todo,omitempty"` // Research deferred as a TODO instead of blocking
+	Bypass         bool     `json:"bypass"`         // True for trivial changes
+	BypassReason   string   `json:"bypass_reason"`  // Why bypassed
 }
 
 // NewChainState creates a new verification chain state.
 func NewChainState(sessionID string) *ChainState {
 	return &ChainState{
-		SessionID:   sessionID,
-		Results:     make([]VerificationResult, 0),
-		FinalStatus: "pending",
-		Metadata:    make(map[string]interface{}),
+		SchemaVersion: StateSchemaVersion,
+		SessionID:     sessionID,
+		Results:       make([]VerificationResult, 0),
+		FinalStatus:   "pending",
+		Metadata:      make(map[string]interface{}),
+		clock:         util.SystemClock,
+	}
+}
+
+// now reads the state's clock, defaulting for states loaded from disk.
+func (c *ChainState) now() time.Time {
+	if c.clock == nil {
+		return util.SystemClock.Now()
 	}
+	return c.clock.Now()
 }
 
 // AddResult adds a verification result to the chain.
 func (c *ChainState) AddResult(result VerificationResult) {
-	result.Timestamp = time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result.Timestamp = c.now()
 	c.Results = append(c.Results, result)
 
 	// Update final status based on results
 	if result.Status == "block" {
 		c.FinalStatus = "blocked"
+	} else if result.Status == "ask" && c.FinalStatus != "blocked" {
+		c.FinalStatus = "ask"
 	}
 }
 
@@ -96,11 +166,32 @@ func (c *ChainState) IsBlocked() bool {
 	return c.FinalStatus == "blocked"
 }
 
+// IsDeadlineExceeded returns true if the run ended before every gate was
+// evaluated. FinalStatus is then "ask", or "blocked" by tool_policy.
+func (c *ChainState) IsDeadlineExceeded() bool {
+	return c.Deadline != ""
+}
+
+// IsAsk returns true if a gate requires user confirmation.
+func (c *ChainState) IsAsk() bool {
+	return c.FinalStatus == "ask"
+}
+
+// GetAskReason returns the reason for asking, if any.
+func (c *ChainState) GetAskReason() string {
+	for _, r := range c.Results {
+		if r.Status == "ask" {
+			return r.Describe()
+		}
+	}
+	return ""
+}
+
 // GetBlockReason returns the reason for blocking, if any.
 func (c *ChainState) GetBlockReason() string {
 	for _, r := range c.Results {
 		if r.Status == "block" {
-			return r.Gate + ": " + r.Reason
+			return r.Describe()
 		}
 	}
 	return ""
@@ -109,72 +200,9 @@ func (c *ChainState) GetBlockReason() string {
 // ===== Intent Analysis =====
 
 // AnalyzeIntent classifies user intent from prompt.
+// Convenience wrapper over the default compiled KeywordClassifier.
 func AnalyzeIntent(prompt string) *IntentAnalysis {
-	promptLower := strings.ToLower(prompt)
-	analysis := &IntentAnalysis{
-		Type:             "general",
-		Confidence:       0.5,
-		RequiredSkills:   []string{},
-		RequiredAgents:   []string{},
-		RequiresResearch: false,
-		Complexity:       "simple",
-		RiskLevel:        "low",
-	}
-
-	// Implementation intent
-	if containsAny(promptLower, []string{"implement", "create", "build", "add", "develop", "write"}) {
-		analysis.Type = "implement"
-		analysis.RequiresResearch = true
-		analysis.Complexity = "moderate"
-		analysis.Confidence = 0.8
-	}
-
-	// Debug intent
-	if containsAny(promptLower, []string{"fix", "bug", "error", "debug", "broken", "not working", "crash"}) {
-		analysis.Type = "debug"
-		analysis.RequiredSkills = append(analysis.RequiredSkills, "debug-like-expert")
-		analysis.Complexity = "moderate"
-		analysis.Confidence = 0.85
-	}
-
-	// Refactor intent
-	if containsAny(promptLower, []string{"refactor", "restructure", "clean up", "improve", "optimize"}) {
-		analysis.Type = "refactor"
-		analysis.RequiresResearch = true
-		analysis.Complexity = "complex"
-		analysis.RiskLevel = "medium"
-		analysis.Confidence = 0.8
-	}
-
-	// Deploy intent
-	if containsAny(promptLower, []string{"deploy", "release", "publish", "production", "go live"}) {
-		analysis.Type = "deploy"
-		analysis.RequiredSkills = append(analysis.RequiredSkills, "cloud-infrastructure-mastery")
-		analysis.RiskLevel = "high"
-		analysis.Complexity = "complex"
-		analysis.Confidence = 0.9
-		analysis.RequiresResearch = true // Deploy always needs verification
-	}
-
-	// Security intent
-	if containsAny(promptLower, []string{"security", "auth", "encrypt", "vulnerability", "password"}) {
-		analysis.Type = "security"
-		analysis.RequiredSkills = append(analysis.RequiredSkills, "security")
-		analysis.RiskLevel = "high"
-		analysis.RequiresResearch = true
-		analysis.Confidence = 0.85
-	}
-
-	// Deletion/removal intent - HIGH RISK
-	if containsAny(promptLower, []string{"delete", "remove", "drop", "destroy", "purge"}) {
-		analysis.RiskLevel = "critical"
-		analysis.Complexity = "complex"
-	}
-
-	// Extract required agents based on context
-	analysis.RequiredAgents = extractAgents(promptLower)
-
-	return analysis
+	return defaultClassifier().Classify(prompt)
 }
 
 // ===== CEO Gate =====
@@ -227,7 +255,14 @@ func CEOValidate(intent *IntentAnalysis, toolName, agentType string) *CEODecisio
 // ===== Aegis Gate =====
 
 // AegisVerify performs security verification.
+// No "curl | sh" installer is trusted; use a Runner with WithTrustedInstalls.
 func AegisVerify(intent *IntentAnalysis, toolName string, toolInput map[string]interface{}) *AegisVerification {
+	return aegisVerify(util.SystemClock, nil, nil, intent, toolName, toolInput)
+}
+
+// aegisVerify is AegisVerify with the provenance timestamp from clock and
+// the given trusted installers.
+func aegisVerify(clock util.Clock, trustedInstalls []string, sensitive []SensitivePath, intent *IntentAnalysis, toolName string, toolInput map[string]interface{}) *AegisVerification {
 	verification := &AegisVerification{
 		Passed:          true,
 		SecurityScore:   1.0,
@@ -239,12 +274,32 @@ func AegisVerify(intent *IntentAnalysis, toolName string, toolInput map[string]i
 	// Check for dangerous patterns in tool input
 	if toolName == "Bash" {
 		if cmd, ok := toolInput["command"].(string); ok {
+			score, reasons := ScoreDestructiveness(cmd)
+			verification.Destructiveness, verification.DestructiveReasons = score, reasons
+			violation := "Dangerous command pattern detected"
+			category := ""
 			if isDangerousCommand(cmd) {
+				category = ViolationDangerousCommand
+			} else if isUntrustedPipeInstall(cmd, trustedInstalls) {
+				category = ViolationPipeInstall
+			} else if exfil := detectEnvExfil(cmd); exfil != "" {
+				category = ViolationEnvExfil
+				violation = "Environment secret exfiltration: " + exfil
+			} else if score >= destructiveBlock {
+				category = ViolationDangerousCommand
+				violation = fmt.Sprintf("Destructive command (score %.2f): %s", score, strings.Join(reasons, "; "))
+			}
+			if category != "" {
 				verification.Passed = false
 				verification.ThreatLevel = "high"
 				verification.SecurityScore = 0.0
-				verification.ViolationsFound = append(verification.ViolationsFound,
-					"Dangerous command pattern detected")
+				verification.ViolationsFound = append(verification.ViolationsFound, violation)
+				verification.ViolationCategories = append(verification.ViolationCategories, category)
+			} else if score > 0 {
+				verification.SecurityScore = 1 - score
+				if score >= destructiveWarn {
+					verification.ThreatLevel = "medium"
+				}
 			}
 		}
 	}
@@ -252,12 +307,13 @@ func AegisVerify(intent *IntentAnalysis, toolName string, toolInput map[string]i
 	// Check file access patterns
 	if toolName == "Read" || toolName == "Write" || toolName == "Edit" {
 		if path, ok := toolInput["file_path"].(string); ok {
-			if isSensitivePath(path) {
+			if match := matchSensitivePath(path, sensitive); match != nil {
 				verification.Passed = false
 				verification.ThreatLevel = "high"
 				verification.SecurityScore = 0.0
 				verification.ViolationsFound = append(verification.ViolationsFound,
-					"Sensitive file access: "+path)
+					"Sensitive file access ("+match.Category+"): "+path)
+				verification.ViolationCategories = append(verification.ViolationCategories, ViolationSensitivePath)
 			}
 		}
 	}
@@ -273,11 +329,26 @@ func AegisVerify(intent *IntentAnalysis, toolName string, toolInput map[string]i
 			verification.SecurityScore = 0.3
 			verification.ViolationsFound = append(verification.ViolationsFound,
 				"Suspicious code removal pattern - verify intent")
+			verification.ViolationCategories = append(verification.ViolationCategories, ViolationCodeRemoval)
 		}
 	}
 
+	addRecommendations(verification, toolName, toolInput)
+	verification.dedupe()
+
 	// Add memory provenance
-	verification.MemoryProvenance = "chain_verification:" + time.Now().Format(time.RFC3339)
+	prov := newProvenance("chain_verification", clock.Now())
+	prov.AddInput("tool", toolName)
+	if intent != nil {
+		prov.AddInput("intent", intent.Type)
+		prov.AddInput("risk_level", intent.RiskLevel)
+	}
+	for _, key := range []string{"command", "file_path", "subagent_type"} {
+		if v, ok := toolInput[key].(string); ok {
+			prov.AddInput(key, v)
+		}
+	}
+	verification.MemoryProvenance = prov
 
 	return verification
 }
@@ -287,6 +358,11 @@ func AegisVerify(intent *IntentAnalysis, toolName string, toolInput map[string]i
 // ResearchCheck verifies TABULA_RASA compliance.
 // STRICT: For high-risk intents, always require fresh research verification.
 func ResearchCheck(intent *IntentAnalysis, researchDone bool, prompt string) *ResearchStatus {
+	return researchCheck(util.SystemClock, intent, researchDone, prompt)
+}
+
+// researchCheck is ResearchCheck with the query year taken from clock.
+func researchCheck(clock util.Clock, intent *IntentAnalysis, researchDone bool, prompt string) *ResearchStatus {
 	status := &ResearchStatus{
 		Done:   researchDone,
 		Bypass: false,
@@ -307,7 +383,7 @@ func ResearchCheck(intent *IntentAnalysis, researchDone bool, prompt string) *Re
 	if intent != nil && intent.RequiresResearch {
 		if !researchDone {
 			status.Done = false
-			status.SuggestedQuery = buildSearchQuery(intent.Type, prompt)
+			status.SuggestedQuery = buildSearchQuery(intent.Type, prompt, clock.Now())
 			return status
 		}
 		// Research was done — trust it, even for high-risk intents
@@ -336,71 +412,14 @@ func containsString(slice []string, s string) bool {
 	return false
 }
 
-func extractAgents(prompt string) []string {
-	agents := []string{}
-	agentKeywords := map[string]string{
-		"backend":  "backend-engineer",
-		"frontend": "frontend-engineer",
-		"database": "database-engineer",
-		"devops":   "devops-engineer",
-		"security": "security-engineer",
-		"test":     "qa-lead",
-		"explore":  "Explore",
-		"plan":     "Plan",
-	}
-	for keyword, agent := range agentKeywords {
-		if strings.Contains(prompt, keyword) {
-			agents = append(agents, agent)
-		}
-	}
-	return agents
-}
-
-func isDangerousCommand(cmd string) bool {
-	dangerous := []string{
-		"rm -rf /", "rm -rf /*", "> /dev/sda",
-		":(){ :|:& };:", "dd if=/dev/zero",
-		"chmod -R 777 /", "curl | bash", "wget | sh",
-	}
-	cmdLower := strings.ToLower(cmd)
-	for _, d := range dangerous {
-		if strings.Contains(cmdLower, d) {
-			return true
-		}
-	}
-	return false
-}
-
-func isSensitivePath(path string) bool {
-	sensitive := []string{
-		"/etc/shadow", "/etc/passwd", "/.ssh/",
-		"/.aws/credentials", "/.gnupg/", ".pem", ".key",
-	}
-	pathLower := strings.ToLower(path)
-	for _, s := range sensitive {
-		if strings.Contains(pathLower, s) {
-			return true
-		}
-	}
-	return false
-}
-
-func isProblematicEdit(old, new string) bool {
-	// Empty replacement of significant code
-	if strings.TrimSpace(new) == "" && len(old) > 100 {
-		return true
-	}
-	// Removing TODO/FIXME without expanding code
-	oldHasStub := containsAny(strings.ToLower(old), []string{"todo", "fixme", "stub", "placeholder"})
-	newHasStub := containsAny(strings.ToLower(new), []string{"todo", "fixme", "stub", "placeholder"})
-	if oldHasStub && !newHasStub && len(new) <= len(old) {
-		return true
-	}
-	return false
+// isUntrustedPipeInstall flags output piped into a shell unless it is a
+// single https download from a trusted installer.
+func isUntrustedPipeInstall(cmd string, trusted []string) bool {
+	return patterns.IsPipeToShell(cmd) && patterns.MatchTrustedPipeInstall(cmd, trusted) == ""
 }
 
-func buildSearchQuery(intentType, prompt string) string {
-	year := time.Now().Format("2006")
+func buildSearchQuery(intentType, prompt string, now time.Time) string {
+	year := now.Format("2006")
 	switch intentType {
 	case "implement":
 		return "implementation patterns " + year + " best practices"
@@ -0,0 +1,50 @@
+// Package config provides dynamic configuration loading.
+// derived.go: Config versioning and caches of state compiled from a config
+// (regexes, classifiers, matchers), rebuilt whenever the config reloads.
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// gatesConfigVersion counts gates config loads; each load gets a new version.
+var gatesConfigVersion atomic.Uint64
+
+// Version identifies this load of the gates config. It changes on every
+// reload (ReloadGatesConfig or TTL expiry). Configs read directly with
+// ReadGatesConfig or built in memory have version 0.
+func (c *GatesConfig) Version() uint64 {
+	return c.version
+}
+
+// Derived caches a value built from a gates config, such as compiled
+// patterns. The value is rebuilt when the config's Version changes;
+// unversioned configs are built fresh on every Get.
+type Derived[T any] struct {
+	build func(*GatesConfig) T
+
+	mu      sync.Mutex
+	version uint64
+	value   T
+}
+
+// NewDerived returns a cache of build(cfg) keyed to the config version.
+func NewDerived[T any](build func(*GatesConfig) T) *Derived[T] {
+	return &Derived[T]{build: build}
+}
+
+// Get returns the value for cfg, building it if cfg is a different load
+// than the cached one.
+func (d *Derived[T]) Get(cfg *GatesConfig) T {
+	if cfg.version == 0 {
+		return d.build(cfg)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.version != cfg.version {
+		d.value = d.build(cfg)
+		d.version = cfg.version
+	}
+	return d.value
+}
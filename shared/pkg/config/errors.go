@@ -0,0 +1,9 @@
+// Package config provides dynamic configuration loading.
+// errors.go: Sentinel errors so callers can branch with errors.Is.
+package config
+
+import "errors"
+
+// ErrInvalidConfig is returned when a config file exists but cannot be parsed.
+// Loaders fall back to defaults; CLI commands surface it to the user.
+var ErrInvalidConfig = errors.New("invalid config")
@@ -4,27 +4,64 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+
+	"github.com/claude/shared/pkg/patterns"
+	"github.com/claude/shared/pkg/types"
+	"github.com/claude/shared/pkg/validate"
 )
 
 // GatesConfig holds all gate configurations from config.json
 type GatesConfig struct {
-	Schema      string         `json:"$schema"`
-	Description string         `json:"description"`
-	Updated     string         `json:"updated"`
-	Read        ReadConfig     `json:"read"`
-	Bash        BashConfig     `json:"bash"`
-	Write       WriteConfig    `json:"write"`
-	Enforcer    EnforcerConfig `json:"enforcer"`
-	Intent      IntentConfig   `json:"intent"`
-	Research    ResearchConfig `json:"research"`
-	Context     ContextConfig  `json:"context"`
-	Quality     QualityConfig  `json:"quality"`
+	Schema      string           `json:"$schema"`
+	Description string           `json:"description"`
+	Updated     string           `json:"updated"`
+	Read        ReadConfig       `json:"read"`
+	Bash        BashConfig       `json:"bash"`
+	Write       WriteConfig      `json:"write"`
+	Enforcer    EnforcerConfig   `json:"enforcer"`
+	Intent      IntentConfig     `json:"intent"`
+	Research    ResearchConfig   `json:"research"`
+	Context     ContextConfig    `json:"context"`
+	Quality     QualityConfig    `json:"quality"`
+	Risk        RiskConfig       `json:"risk"`
+	Ask         AskConfig        `json:"ask"`
+	Recommend   RecommendConfig  `json:"recommendations"`
+	Packages    PackageConfig    `json:"packages"`
+	Git         GitConfig        `json:"git"`
+	Redaction   RedactionConfig  `json:"redaction"`
+	DAG         DAGConfig        `json:"dag"`
+	Onboarding  OnboardingConfig `json:"onboarding"`
+	Endpoints   EndpointConfig   `json:"endpoints"`
+	Evasion     EvasionConfig    `json:"security_evasion"`
+	Aegis       AegisConfig      `json:"aegis"`
+	Subagent    SubagentConfig   `json:"subagent"`
+	Advisories  AdvisoryConfig   `json:"advisories"`
+	MCP         MCPConfig        `json:"mcp"`
+	Failure     FailureConfig    `json:"failure"`
+	Deploy      DeployConfig     `json:"deploy_window"`
+	Learning    LearningConfig   `json:"learning"`
+	Tasks       TaskConfig       `json:"tasks"`
+
+	// ToolPolicy is a decision floor per tool or tool glob ({"Bash": "ask",
+	// "mcp__github__*": "warn"}): allow, warn, ask or block. Gate findings
+	// above the floor still escalate; nothing lowers a decision below it.
+	ToolPolicy map[string]string `json:"tool_policy,omitempty"`
+
+	version uint64 // Set per load by LoadGatesConfig; see Version
 }
 
 // ReadConfig defines file read gate rules
@@ -34,6 +71,25 @@ type ReadConfig struct {
 	BlockedExtensions []string `json:"blocked_extensions"`
 	WarnExtensions    []string `json:"warn_extensions"`
 	WarnPatterns      []string `json:"warn_patterns"`
+
+	// Bulk reads within a window (possible data harvesting)
+	Harvest HarvestConfig `json:"harvest"`
+}
+
+// HarvestConfig flags sessions reading many files in a short window.
+// Over max_reads warns; spread over max_dirs directories as well applies
+// action.
+type HarvestConfig struct {
+	Enabled       bool   `json:"enabled"`
+	WindowSeconds int    `json:"window_seconds"` // Sliding window for counting reads; 0 = 300
+	MaxReads      int    `json:"max_reads"`      // Reads in the window before warning; 0 = 100
+	MaxDirs       int    `json:"max_dirs"`       // Distinct directories that, with max_reads, apply action; 0 = 20
+	Action        string `json:"action"`         // "warn" or "ask" for reads spread across max_dirs
+}
+
+// Window is the read counting window.
+func (c HarvestConfig) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
 }
 
 // BashConfig defines bash command gate rules
@@ -42,14 +98,57 @@ type BashConfig struct {
 	BlockedCommands []string `json:"blocked_commands"`
 	BlockedPatterns []string `json:"blocked_patterns"`
 	WarnCommands    []string `json:"warn_commands"`
+
+	// Commits/pushes targeting these branches (globs allowed: release/*)
+	ProtectedBranches     []string `json:"protected_branches"`
+	ProtectedBranchAction string   `json:"protected_branch_action"` // "warn" or "ask"
+
+	// "curl https://host/... | sh" installers allowed past the pipe-to-shell block.
+	// Entries are "host" or "host/path-prefix"; URLs must be https.
+	TrustedInstallHosts []string `json:"trusted_install_hosts"`
+
+	// Routine sudo commands that skip the sudo warning ("systemctl status",
+	// "apt-get update"); matched word by word after sudo's options, never
+	// with option (-o, -c) or path arguments after them
+	SafeSudo []string `json:"safe_sudo"`
 }
 
 // WriteConfig defines file write gate rules
 type WriteConfig struct {
-	Enabled        bool     `json:"enabled"`
-	BlockedPaths   []string `json:"blocked_paths"`
-	ProtectedFiles []string `json:"protected_files"`
-	SecretPatterns []string `json:"secret_patterns"`
+	Enabled        bool            `json:"enabled"`
+	BlockedPaths   []string        `json:"blocked_paths"`
+	ProtectedFiles []ProtectedFile `json:"protected_files"` // Globs; edits ask or block per entry
+	SecretPatterns []string        `json:"secret_patterns"`
+
+	// Writes to a tracked file with uncommitted changes: "ask", "warn" or
+	// "off"; files this session already modified are exempt
+	Uncommitted string `json:"uncommitted"`
+
+	// Repeated edits to one file without a test run or commit (thrashing)
+	Churn ChurnConfig `json:"churn"`
+}
+
+// ChurnConfig flags a file edited many times since the last test run or
+// commit of it, suggesting the agent stop and verify.
+type ChurnConfig struct {
+	Threshold    int      `json:"threshold"`     // Edits to one file before action applies; 0 = 8, -1 disables
+	Action       string   `json:"action"`        // "warn" or "ask"
+	TestCommands []string `json:"test_commands"` // Bash command prefixes that count as a test run
+}
+
+// IsTestCommand reports whether a Bash command runs tests: any segment
+// of it starts with one of TestCommands.
+func (c ChurnConfig) IsTestCommand(command string) bool {
+	r := strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", "\n")
+	for _, segment := range strings.Split(r.Replace(command), "\n") {
+		segment = strings.Join(strings.Fields(segment), " ")
+		for _, prefix := range c.TestCommands {
+			if segment == prefix || strings.HasPrefix(segment, prefix+" ") {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // EnforcerConfig defines enforcer gate chain
@@ -57,6 +156,24 @@ type EnforcerConfig struct {
 	Enabled  bool     `json:"enabled"`
 	Chain    []string `json:"chain"`
 	FailFast bool     `json:"fail_fast"`
+	Parallel bool     `json:"parallel"` // Run independent chain gates (Aegis, Research) concurrently
+
+	// Scrubbed from inputs saved in ~/.claude/chain: "secrets", "paths"; [] saves raw inputs
+	RedactInputs []string `json:"redact_inputs"`
+
+	// Character budget for injected chain TOON (verbose pass details go first); -1 disables
+	ContextMaxChars int `json:"context_max_chars"`
+
+	// Deadline for one chain run; gates not reached are skipped and the call asks; -1 disables
+	TimeoutMs int `json:"timeout_ms"`
+}
+
+// Timeout is the chain deadline; 0 means unbounded.
+func (c EnforcerConfig) Timeout() time.Duration {
+	if c.TimeoutMs <= 0 {
+		return 0
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
 }
 
 // IntentConfig defines intent classification rules
@@ -64,6 +181,7 @@ type IntentConfig struct {
 	Enabled          bool                `json:"enabled"`
 	SkillTriggers    map[string][]string `json:"skill_triggers"`
 	ResearchTriggers []string            `json:"research_triggers"`
+	Keywords         map[string][]string `json:"keywords"` // Chain classifier overrides by category (implement, debug, refactor, deploy, security, deletion)
 }
 
 // ResearchConfig defines research enforcement rules
@@ -72,7 +190,24 @@ type ResearchConfig struct {
 	RequireBeforeCode bool     `json:"require_before_code"`
 	CodeTools         []string `json:"code_tools"`
 	ResearchTools     []string `json:"research_tools"`
+	ResearchWindow    int      `json:"research_window"` // Recent transcript tool uses to inspect (0 = all)
 	BypassPatterns    []string `json:"bypass_patterns"`
+	TodoRiskLevels    []string `json:"todo_risk_levels"` // Intent risk levels that get a research TODO instead of a block
+
+	// Distinct sites fetched in the transcript (searches do not count) required per intent type, e.g. {"deploy": 3}
+	MinSourcesByIntent map[string]int `json:"min_sources_by_intent"`
+
+	// Research is required when any rule matches the classified intent;
+	// require_before_code false turns every rule off
+	Policy []ResearchRule `json:"policy"`
+}
+
+// ResearchRule matches an intent type ("*" for any) at or above a risk
+// level and complexity; empty minimums match every level
+type ResearchRule struct {
+	Intent        string `json:"intent"`
+	MinRisk       string `json:"min_risk,omitempty"`       // low, medium, high, critical
+	MinComplexity string `json:"min_complexity,omitempty"` // simple, moderate, complex
 }
 
 // ContextConfig defines context tracking rules
@@ -92,6 +227,357 @@ type QualityConfig struct {
 	MaxFileSizeKB int    `json:"max_file_size_kb"`
 }
 
+// RiskConfig defines the session risk budget (cumulative escalation)
+type RiskConfig struct {
+	Enabled        bool `json:"enabled"`
+	WarnWeight     int  `json:"warn_weight"`
+	AskWeight      int  `json:"ask_weight"`
+	BlockWeight    int  `json:"block_weight"`
+	AskThreshold   int  `json:"ask_threshold"`   // Score at which warn → ask
+	BlockThreshold int  `json:"block_threshold"` // Score at which warn/ask → block
+}
+
+// AskConfig defines which high-risk but legitimate actions ask the user
+// instead of being allowed or denied. Block stays for the truly dangerous.
+type AskConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Commands   []string `json:"commands"`    // Bash substrings (force-push, prod deploys)
+	RiskLevels []string `json:"risk_levels"` // Intent risk levels that ask for mutating tools
+	Gates      []string `json:"gates"`       // Chain gates whose warn becomes ask (e.g. "CEO")
+}
+
+// RecommendConfig escalates Aegis recommendations that recur in a session
+type RecommendConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Threshold int    `json:"threshold"` // Occurrences per category before escalating
+	Action    string `json:"action"`    // "warn" or "ask"
+}
+
+// PackageConfig flags package-manager installs of untrusted packages
+type PackageConfig struct {
+	Enabled bool     `json:"enabled"`
+	Action  string   `json:"action"`  // "warn", "ask" or "block"
+	Trusted []string `json:"trusted"` // Names, "prefix*" or "manager:name"
+}
+
+// EndpointConfig flags hardcoded localhost/IPs and developer paths written
+// into deployment config files
+type EndpointConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Action   string   `json:"action"`   // "warn", "ask" or "block"
+	Files    []string `json:"files"`    // Base-name globs, or path substrings containing "/"
+	Patterns []string `json:"patterns"` // Regexps matched per line
+}
+
+// EvasionConfig flags Bash commands that disable logging, shell history,
+// error checking, linters or security tooling
+type EvasionConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Action     string   `json:"action"`      // "warn", "ask" or "block"
+	Patterns   []string `json:"patterns"`    // Regexps over the lowercased, quote-stripped command
+	MinSignals int      `json:"min_signals"` // Independent pattern matches needed in one command
+}
+
+// AegisConfig sets what an Aegis finding does per violation category
+// (dangerous_command, pipe_install, env_exfil, sensitive_path,
+// code_removal, ask_command; keys may be globs, the exact key or longest
+// glob wins): "deny", "ask", or "modify" to rewrite a Bash command to a
+// safer form (e.g. --force-with-lease, --dry-run) where one is known
+type AegisConfig struct {
+	Actions map[string]string `json:"actions"`
+
+	// Extra sensitive path substrings by category ("credential", "history",
+	// "config"), added to the built-in list
+	SensitivePaths map[string][]string `json:"sensitive_paths"`
+
+	// Violations and recommendations listed in a reason before the rest
+	// are summarized as "+N more"; the audit state keeps all of them
+	MaxFindings int `json:"max_findings"`
+}
+
+// coversAction reports whether an Actions key, exact or glob, sets the
+// action for category.
+func (c AegisConfig) coversAction(category string) bool {
+	for key := range c.Actions {
+		if types.MatchToolName(key, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// GitConfig is the commit compliance policy checked by the Bash gate
+type GitConfig struct {
+	AuthorPattern  string `json:"author_pattern"`  // Regexp over "Name <email>"; "" skips
+	RequireSigning bool   `json:"require_signing"` // Commits must be GPG/SSH signed
+	Action         string `json:"action"`          // "warn" or "block"
+
+	Conventional ConventionalCommitConfig `json:"conventional_commits"`
+}
+
+// ConventionalCommitConfig validates git commit -m messages as
+// type(scope): subject
+type ConventionalCommitConfig struct {
+	Enabled bool     `json:"enabled"`
+	Types   []string `json:"types"`  // Allowed types; empty = feat, fix, docs, ...
+	Action  string   `json:"action"` // "warn" or "block"
+}
+
+// RedactionConfig scrubs secrets from everything kavach writes to disk:
+// chain state, the audit log and kavach.log. On unless disabled.
+type RedactionConfig struct {
+	Disabled  bool `json:"disabled"`   // Persist and log raw values
+	HashPaths bool `json:"hash_paths"` // Replace path directories with a short hash
+}
+
+// DAGConfig tunes DAG dispatch directives
+type DAGConfig struct {
+	MaxParallel    int    `json:"max_parallel"`     // Tasks per dispatch sub-batch; 0 = unlimited
+	MaxNodes       int    `json:"max_nodes"`        // Nodes a scheduled DAG may hold; 0 = 1000
+	EventURL       string `json:"event_url"`        // POST node events here as JSON; empty = off
+	EventTimeoutMs int    `json:"event_timeout_ms"` // Per-event delivery timeout; 0 = 2s
+}
+
+// SubagentConfig bounds recursive delegation
+type SubagentConfig struct {
+	MaxDepth int `json:"max_depth"` // Deepest subagent nesting (spawner chain) allowed; 0 = default, -1 disables
+}
+
+// FailureConfig breaks retry loops of a failing tool call
+type FailureConfig struct {
+	// Consecutive failures of the same call before the failure gate stops
+	// suggesting fixes and the chain asks; 0 = 3, -1 disables
+	RetryLimit int `json:"retry_limit"`
+}
+
+// DeployConfig limits deploys to allowed days and hours outside freeze
+// windows. A Bash call is a deploy when its intent is deploy or the command
+// contains one of Commands.
+type DeployConfig struct {
+	Enabled  bool           `json:"enabled"`
+	Action   string         `json:"action"`   // "ask" or "block"
+	Timezone string         `json:"timezone"` // IANA name, e.g. "Asia/Kolkata"; "" = local
+	Days     []string       `json:"days"`     // "mon".."sun"; empty = every day
+	Hours    string         `json:"hours"`    // "09:00-17:00" in Timezone
+	Commands []string       `json:"commands"` // Command substrings, e.g. "terraform apply"
+	Freezes  []DeployFreeze `json:"freezes"`
+}
+
+// DeployFreeze forbids deploys between From and To: RFC 3339 times, or
+// dates in Timezone where To covers the whole day.
+type DeployFreeze struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AdvisoryConfig controls advisories that recur within a session
+type AdvisoryConfig struct {
+	Repeat bool     `json:"repeat"` // Emit every advisory each time, ignoring once
+	Once   []string `json:"once"`   // Categories shown once per session; all others every time
+	Always []string `json:"always"` // Categories never suppressed, even when listed in once (safety-critical)
+}
+
+// WarnOnce reports whether category is suppressed after its first
+// emission in a session: it is opted in through once and not in always.
+func (c AdvisoryConfig) WarnOnce(category string) bool {
+	if c.Repeat || !containsCategory(c.Once, category) {
+		return false
+	}
+	return !containsCategory(c.Always, category)
+}
+
+func containsCategory(list []string, category string) bool {
+	for _, c := range list {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// LearningConfig puts new rules in a learning period: until its end date a
+// matching block or ask rule is audited and shown as a warning but lets the
+// call through, then enforces on its own.
+type LearningConfig struct {
+	// End of learning per rule ("bash.blocked_commands:terraform destroy"),
+	// rule list ("bash.blocked_commands") or gate ("BASH"). A date learns
+	// through that day; an RFC 3339 time until that instant.
+	Rules map[string]string `json:"rules,omitempty"`
+}
+
+// Until returns when learning ends for rule of gate, or false when the rule
+// enforces at now. The most specific key wins: rule, then its list, then
+// the gate. Unparseable dates enforce.
+func (c LearningConfig) Until(gate, rule string, now time.Time) (time.Time, bool) {
+	list, _, _ := strings.Cut(rule, ":")
+	for _, key := range []string{rule, list, gate} {
+		value, ok := c.Rules[key]
+		if !ok || key == "" {
+			continue
+		}
+		end, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return time.Time{}, false
+			}
+			end = day.AddDate(0, 0, 1)
+		}
+		return end, now.Before(end)
+	}
+	return time.Time{}, false
+}
+
+// TaskConfig is the minimum quality of a TaskCreate subject and
+// description. Empty fields always block; these catch placeholders and
+// one-word tasks that give subagents nothing to work from.
+type TaskConfig struct {
+	Action              string   `json:"action"`                // "block", "warn" or "off" for a low-quality task
+	MinSubjectWords     int      `json:"min_subject_words"`     // 0 = 2
+	MinDescriptionWords int      `json:"min_description_words"` // 0 = 3
+	Placeholders        []string `json:"placeholders"`          // Words that say nothing ("todo", "task"); a field of only these and numbers is a placeholder
+}
+
+// Check returns the first quality issue of a task ("subject:placeholder",
+// "description:too_short"), or "" when both fields pass.
+func (c TaskConfig) Check(subject, description string) string {
+	fields := []struct {
+		name, text string
+		minWords   int
+	}{
+		{"subject", subject, c.MinSubjectWords},
+		{"description", description, c.MinDescriptionWords},
+	}
+	for _, f := range fields {
+		words := strings.Fields(strings.ToLower(f.text))
+		if c.isPlaceholder(words) {
+			return f.name + ":placeholder"
+		}
+		if len(words) < f.minWords {
+			return f.name + ":too_short"
+		}
+	}
+	return ""
+}
+
+// isPlaceholder reports whether every word is a placeholder or a number
+// once punctuation is trimmed ("TODO", "Task 1", "tbd...").
+func (c TaskConfig) isPlaceholder(words []string) bool {
+	for _, word := range words {
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if word == "" || strings.TrimFunc(word, unicode.IsDigit) == "" {
+			continue
+		}
+		placeholder := false
+		for _, p := range c.Placeholders {
+			if strings.EqualFold(p, word) {
+				placeholder = true
+				break
+			}
+		}
+		if !placeholder {
+			return false
+		}
+	}
+	return true
+}
+
+// MCPConfig validates MCP tool arguments before the call
+type MCPConfig struct {
+	Action string `json:"action"` // "block" or "warn" when arguments do not match the schema
+
+	// JSON Schema per tool name or glob ("mcp__github__create_issue",
+	// "mcp__github__*"); the exact name wins, else the longest glob
+	Schemas map[string]*validate.Schema `json:"schemas,omitempty"`
+
+	// Schemas that failed to parse or compile, by key, with the error;
+	// they are left out of Schemas instead of failing the whole config
+	Invalid map[string]string `json:"-"`
+}
+
+// UnmarshalJSON parses each schema on its own, so one malformed entry
+// lands in Invalid rather than rejecting the gates config. Like plain
+// struct decoding, it only overrides what data sets (config layers).
+func (c *MCPConfig) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Action  *string                    `json:"action"`
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Action != nil {
+		c.Action = *raw.Action
+	}
+	for key, msg := range raw.Schemas {
+		delete(c.Invalid, key)
+		delete(c.Schemas, key)
+		schema := &validate.Schema{}
+		err := json.Unmarshal(msg, schema)
+		if err == nil {
+			err = schema.Compile()
+		}
+		if err != nil {
+			if c.Invalid == nil {
+				c.Invalid = make(map[string]string)
+			}
+			c.Invalid[key] = err.Error()
+			continue
+		}
+		if c.Schemas == nil {
+			c.Schemas = make(map[string]*validate.Schema)
+		}
+		c.Schemas[key] = schema
+	}
+	return nil
+}
+
+// InvalidFor returns the first (sorted) invalid schema key matching
+// toolName and its error, or "".
+func (c MCPConfig) InvalidFor(toolName string) (string, string) {
+	keys := make([]string, 0, len(c.Invalid))
+	for key := range c.Invalid {
+		if types.MatchToolName(key, toolName) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return "", ""
+	}
+	sort.Strings(keys)
+	return keys[0], c.Invalid[keys[0]]
+}
+
+// SchemaFor returns the schema for toolName and the key it was found under.
+func (c MCPConfig) SchemaFor(toolName string) (*validate.Schema, string) {
+	var best *validate.Schema
+	key := ""
+	for pattern, schema := range c.Schemas {
+		if strings.EqualFold(pattern, toolName) {
+			return schema, pattern
+		}
+		if !types.MatchToolName(pattern, toolName) {
+			continue
+		}
+		if len(pattern) > len(key) || (len(pattern) == len(key) && pattern < key) {
+			best, key = schema, pattern
+		}
+	}
+	return best, key
+}
+
+// OnboardingConfig eases kavach in for new users
+type OnboardingConfig struct {
+	SoftMode bool `json:"soft_mode"` // Explain blocks and warnings with an example of how to proceed
+
+	// Every gate runs but nothing blocks or asks: decisions become allow
+	// with the reasoning as context. KAVACH_ADVISORY=1 turns it on too.
+	Advisory bool `json:"advisory"`
+}
+
 var (
 	gatesConfig     *GatesConfig
 	gatesConfigOnce sync.Once
@@ -106,6 +592,7 @@ func GatesConfigPath() string {
 }
 
 // LoadGatesConfig loads gates configuration from ~/.claude/gates/config.json
+// tightened by the project's .kavach/gates.json (GatesConfigLayers).
 // Uses sync.Once for first load, then TTL-based cache invalidation.
 func LoadGatesConfig() *GatesConfig {
 	gatesConfigMu.RLock()
@@ -124,45 +611,145 @@ func LoadGatesConfig() *GatesConfig {
 	}
 
 	cfg := loadGatesConfigFromFile()
+	cfg.version = gatesConfigVersion.Add(1)
 	gatesConfig = cfg
 	gatesConfigTime = time.Now()
 	return cfg
 }
 
 func loadGatesConfigFromFile() *GatesConfig {
-	cfg := &GatesConfig{}
-
-	data, err := os.ReadFile(GatesConfigPath())
+	layers := GatesConfigLayers()
+	cfg, err := ReadGatesConfigLayers(layers[0], layers[1:]...)
 	if err != nil {
-		// Return defaults if file not found
+		// Return defaults if file not found or parse error
 		return getDefaultGatesConfig()
 	}
+	return cfg
+}
 
-	if err := json.Unmarshal(data, cfg); err != nil {
-		// Return defaults if parse error
-		return getDefaultGatesConfig()
+// GatesConfigLayers returns the config files LoadGatesConfig merges, in
+// order: the user config, then the project's .kavach/gates.json when the
+// working directory is inside a project that has one.
+func GatesConfigLayers() []string {
+	layers := []string{GatesConfigPath()}
+	if dir, err := os.Getwd(); err == nil {
+		if project := FindProjectGatesConfig(dir); project != "" {
+			layers = append(layers, project)
+		}
 	}
+	return layers
+}
+
+// ReadGatesConfig reads and merges a gates config file. Errors wrap
+// fs.ErrNotExist when the file is missing and ErrInvalidConfig when it
+// cannot be parsed.
+func ReadGatesConfig(path string) (*GatesConfig, error) {
+	return ReadGatesConfigLayers(path)
+}
 
+// ReadGatesConfigLayers reads the user config at user, fills defaults,
+// then applies each project layer with tightenGatesConfig: a project
+// config ships with the repository, so it may add blocks and raise
+// actions but never turn a gate off or loosen the user's rules. Missing
+// files are skipped; fs.ErrNotExist is returned only when all are
+// missing, ErrInvalidConfig for any file that cannot be parsed.
+func ReadGatesConfigLayers(user string, projects ...string) (*GatesConfig, error) {
+	cfg := &GatesConfig{}
+	found, err := readGatesConfigFile(user, cfg)
+	if err != nil {
+		return nil, err
+	}
 	// Merge with defaults for any missing fields
 	mergeGatesDefaults(cfg)
-	return cfg
+
+	for _, path := range projects {
+		project := &GatesConfig{}
+		ok, err := readGatesConfigFile(path, project)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			tightenGatesConfig(cfg, project)
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("gates config: %w", fs.ErrNotExist)
+	}
+	return cfg, nil
+}
+
+// ReadUserGatesConfig reads the user config at path as written and as it
+// loads without project layers, for tools that edit the file. A missing
+// file reads as nil and the defaults.
+func ReadUserGatesConfig(path string) (map[string]interface{}, *GatesConfig, error) {
+	var raw map[string]interface{}
+	cfg, err := ReadGatesConfig(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, getDefaultGatesConfig(), nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	return raw, cfg, nil
+}
+
+// readGatesConfigFile decodes path into cfg; found is false when the file
+// does not exist.
+func readGatesConfigFile(path string, cfg *GatesConfig) (found bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return false, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	return true, nil
 }
 
 // getDefaultGatesConfig returns built-in security defaults
+// windowsWriteBlocked are Windows system directories, normalized.
+var windowsWriteBlocked = []string{"/windows/", "/program files/", "/program files (x86)/", "/programdata/"}
+
+// platformPaths returns base plus the Windows entries when goos is windows.
+// Unix entries stay on Windows too: matching is normalized, so "/.ssh/"
+// covers C:\Users\x\.ssh\.
+func platformPaths(goos string, base, windows []string) []string {
+	if goos != "windows" {
+		return base
+	}
+	return append(append([]string{}, base...), windows...)
+}
+
 func getDefaultGatesConfig() *GatesConfig {
 	return &GatesConfig{
 		Schema:      "kavach-gates/1.0",
 		Description: "Default kavach gates config",
 		Read: ReadConfig{
 			Enabled: true,
-			BlockedPaths: []string{
+			BlockedPaths: platformPaths(runtime.GOOS, []string{
 				"/etc/shadow", "/etc/passwd", "/.ssh/id_rsa",
 				"/.ssh/id_ed25519", "/.aws/credentials",
 				"/.gnupg/", "/.bitcoin/wallet.dat",
-			},
+			}, patterns.WindowsSensitivePaths),
 			BlockedExtensions: []string{".pem", ".key", ".p12", ".pfx"},
 			WarnExtensions:    []string{".env", ".secret"},
-			WarnPatterns:      []string{"credentials", "password", "token"},
+			Harvest: HarvestConfig{
+				Enabled:       true,
+				WindowSeconds: 300,
+				MaxReads:      100,
+				MaxDirs:       20,
+				Action:        "ask",
+			},
+			WarnPatterns: []string{"credentials", "password", "token"},
 		},
 		Bash: BashConfig{
 			Enabled: true,
@@ -171,18 +758,47 @@ func getDefaultGatesConfig() *GatesConfig {
 				":(){ :|:& };:", "curl | bash", "wget | sh",
 			},
 			WarnCommands: []string{"sudo", "rm -rf", "chmod 777"},
+			SafeSudo: []string{
+				"systemctl status", "journalctl", "apt-get update", "apt update",
+			},
+			ProtectedBranches: []string{
+				"main", "master", "production", "release/*",
+			},
+			ProtectedBranchAction: "ask",
+			TrustedInstallHosts: []string{
+				"sh.rustup.rs",
+				"raw.githubusercontent.com/nvm-sh/nvm/",
+			},
 		},
 		Write: WriteConfig{
 			Enabled: true,
-			BlockedPaths: []string{
+			BlockedPaths: platformPaths(runtime.GOOS, []string{
 				"/etc/", "/usr/", "/bin/", "/.ssh/", "/.aws/",
+			}, windowsWriteBlocked),
+			ProtectedFiles: []ProtectedFile{
+				{Pattern: ".gitignore", Reason: "controls what gets committed"},
+				{Pattern: ".env", Reason: "holds local secrets"},
+				{Pattern: "Cargo.lock", Reason: "lockfile; regenerate with cargo instead of editing"},
+			},
+			Uncommitted: "ask",
+			Churn: ChurnConfig{
+				Threshold: 8,
+				Action:    "warn",
+				TestCommands: []string{
+					"go test", "cargo test", "cargo nextest", "npm test", "npm run test",
+					"yarn test", "pnpm test", "bun test", "deno test", "npx jest", "npx vitest",
+					"pytest", "python -m pytest", "python3 -m pytest", "make test",
+					"mvn test", "gradle test", "./gradlew test", "dotnet test",
+				},
 			},
-			ProtectedFiles: []string{".gitignore", ".env", "Cargo.lock"},
 		},
 		Enforcer: EnforcerConfig{
-			Enabled:  true,
-			Chain:    []string{"read", "bash", "write"},
-			FailFast: true,
+			Enabled:         true,
+			Chain:           []string{"read", "bash", "write"},
+			FailFast:        true,
+			RedactInputs:    []string{"secrets"},
+			ContextMaxChars: 4000,
+			TimeoutMs:       5000,
 		},
 		Intent: IntentConfig{
 			Enabled: true,
@@ -197,6 +813,14 @@ func getDefaultGatesConfig() *GatesConfig {
 			RequireBeforeCode: true,
 			CodeTools:         []string{"Write", "Edit"},
 			ResearchTools:     []string{"WebSearch", "WebFetch"},
+			ResearchWindow:    50,
+			Policy: []ResearchRule{
+				{Intent: "deploy"},
+				{Intent: "security"},
+				{Intent: "refactor"},
+				{Intent: "implement", MinComplexity: "complex"},
+				{Intent: "*", MinRisk: "high"},
+			},
 		},
 		Context: ContextConfig{
 			Enabled:       true,
@@ -206,6 +830,139 @@ func getDefaultGatesConfig() *GatesConfig {
 		Quality: QualityConfig{
 			Enabled: false,
 		},
+		Risk: RiskConfig{
+			Enabled:        true,
+			WarnWeight:     1,
+			AskWeight:      3,
+			BlockWeight:    5,
+			AskThreshold:   10,
+			BlockThreshold: 25,
+		},
+		Ask: AskConfig{
+			Enabled: true,
+			Commands: []string{
+				"git push --force", "git push -f", "git push --force-with-lease",
+				"kubectl apply", "kubectl delete", "terraform apply", "terraform destroy",
+				"helm upgrade", "--prod",
+			},
+			RiskLevels: []string{"critical"},
+		},
+		Recommend: RecommendConfig{
+			Enabled:   true,
+			Threshold: 3,
+			Action:    "warn",
+		},
+		Git: GitConfig{
+			Action: "warn",
+			Conventional: ConventionalCommitConfig{
+				Types:  []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"},
+				Action: "warn",
+			},
+		},
+		Endpoints: EndpointConfig{
+			Enabled: true,
+			Action:  "warn",
+			Files: []string{
+				"docker-compose*.yml", "docker-compose*.yaml", "compose.yml", "compose.yaml",
+				"config.yml", "config.yaml", "config.json", "config.toml", "config.env", "*.config.json",
+				"settings.yml", "settings.yaml", "settings.json", "settings.toml", "settings.env",
+				".env.production", "*.tfvars",
+				"k8s/", "kubernetes/", "manifests/", "helm/", "deploy/",
+			},
+			Patterns: []string{
+				`\blocalhost\b`,
+				`\b127\.0\.0\.1\b`,
+				`\b(?:10\.\d{1,3}|192\.168|172\.(?:1[6-9]|2\d|3[01]))\.\d{1,3}\.\d{1,3}\b`,
+				`/(?:Users|home)/[A-Za-z0-9._-]+/`,
+				`(?i)\bC:\\Users\\`,
+			},
+		},
+		Evasion: EvasionConfig{
+			Enabled:    true,
+			Action:     "warn",
+			MinSignals: 2,
+			Patterns: []string{
+				// Error checking and shell history
+				`\bset \+[a-z]*e`,
+				`\bset \+o (?:errexit|history)\b`,
+				`\bunset histfile\b`,
+				`\bhistfile=/dev/null`,
+				`\bhist(?:file)?size=0\b`,
+				`\bhistory -c\b`,
+				`\b(?:rm|shred|truncate)\b.*\.(?:bash|zsh)_history\b`,
+				// Logs and audit
+				`>\s*/var/log/`,
+				`\btruncate -s ?0 /var/log/`,
+				`\bjournalctl --vacuum-`,
+				`\bauditctl -(?:e 0|d)\b`,
+				`\bsystemctl (?:stop|disable|mask) (?:auditd|rsyslog|syslog|apparmor|firewalld)\b`,
+				`\bservice (?:auditd|rsyslog|syslog|apparmor|firewalld) stop\b`,
+				// Security tooling
+				`\bsetenforce 0\b`,
+				`\bufw disable\b`,
+				`\biptables -f\b`,
+				// Hooks and linters
+				`--no-verify\b`,
+				`\bgit config (?:--global )?core\.hookspath\b`,
+				`\beslint-disable\b`,
+				`--no-eslintrc\b`,
+				// Permission bypass
+				`\bchmod (?:-r )?0?777\b`,
+				`\bchmod [ugoa]*-x\b.*\.git/hooks`,
+				`\bchattr -i\b`,
+			},
+		},
+		Deploy: DeployConfig{
+			Action: "ask",
+			Days:   []string{"mon", "tue", "wed", "thu", "fri"},
+			Hours:  "09:00-17:00",
+			Commands: []string{
+				"kubectl apply", "kubectl rollout", "helm install", "helm upgrade",
+				"terraform apply", "terraform destroy", "pulumi up",
+				"serverless deploy", "vercel --prod", "fly deploy", "cdk deploy",
+			},
+		},
+		Tasks: TaskConfig{
+			Action:              "warn",
+			MinSubjectWords:     2,
+			MinDescriptionWords: 3,
+			Placeholders: []string{
+				"todo", "tbd", "fixme", "xxx", "task", "tasks", "placeholder",
+				"stuff", "something", "misc", "test", "foo", "bar", "n/a", "na", "none", "same", "wip",
+			},
+		},
+		Aegis: AegisConfig{
+			MaxFindings: 5,
+			Actions: map[string]string{
+				"dangerous_command": "deny",
+				"pipe_install":      "deny",
+				"env_exfil":         "deny",
+				"sensitive_path":    "deny",
+				"code_removal":      "deny",
+				"ask_command":       "ask",
+			},
+		},
+		Subagent: SubagentConfig{
+			MaxDepth: 3,
+		},
+		Advisories: AdvisoryConfig{
+			Once:   []string{"large_file", "hardcoded_localhost"},
+			Always: []string{"sudo_detected", "may_contain_secrets", "protected_branch"},
+		},
+		MCP: MCPConfig{
+			Action: "block",
+		},
+		Failure: FailureConfig{
+			RetryLimit: 3,
+		},
+		Packages: PackageConfig{
+			Enabled: true,
+			Action:  "warn",
+			Trusted: []string{
+				"npm:typescript", "npm:@types/*", "pip:pytest", "pip:requests",
+				"go:golang.org/x/*", "go:github.com/spf13/cobra", "cargo:serde", "cargo:tokio",
+			},
+		},
 	}
 }
 
@@ -216,15 +973,188 @@ func mergeGatesDefaults(cfg *GatesConfig) {
 	if len(cfg.Read.BlockedPaths) == 0 {
 		cfg.Read.BlockedPaths = defaults.Read.BlockedPaths
 	}
+	if cfg.Read.Harvest.WindowSeconds == 0 {
+		cfg.Read.Harvest.WindowSeconds = defaults.Read.Harvest.WindowSeconds
+	}
+	if cfg.Read.Harvest.MaxReads == 0 {
+		cfg.Read.Harvest.MaxReads = defaults.Read.Harvest.MaxReads
+	}
+	if cfg.Read.Harvest.MaxDirs == 0 {
+		cfg.Read.Harvest.MaxDirs = defaults.Read.Harvest.MaxDirs
+	}
+	if cfg.Read.Harvest.Action == "" {
+		cfg.Read.Harvest.Action = defaults.Read.Harvest.Action
+	}
 	if len(cfg.Bash.BlockedCommands) == 0 {
 		cfg.Bash.BlockedCommands = defaults.Bash.BlockedCommands
 	}
+	if len(cfg.Bash.ProtectedBranches) == 0 {
+		cfg.Bash.ProtectedBranches = defaults.Bash.ProtectedBranches
+	}
+	if cfg.Bash.SafeSudo == nil {
+		cfg.Bash.SafeSudo = defaults.Bash.SafeSudo
+	}
+	if cfg.Bash.ProtectedBranchAction == "" {
+		cfg.Bash.ProtectedBranchAction = defaults.Bash.ProtectedBranchAction
+	}
+	if cfg.Bash.TrustedInstallHosts == nil {
+		cfg.Bash.TrustedInstallHosts = defaults.Bash.TrustedInstallHosts
+	}
 	if len(cfg.Write.BlockedPaths) == 0 {
 		cfg.Write.BlockedPaths = defaults.Write.BlockedPaths
 	}
+	if cfg.Write.ProtectedFiles == nil {
+		cfg.Write.ProtectedFiles = defaults.Write.ProtectedFiles
+	}
+	if cfg.Write.Uncommitted == "" {
+		cfg.Write.Uncommitted = defaults.Write.Uncommitted
+	}
+	if cfg.Write.Churn.Threshold == 0 {
+		cfg.Write.Churn.Threshold = defaults.Write.Churn.Threshold
+	}
+	if cfg.Write.Churn.Action == "" {
+		cfg.Write.Churn.Action = defaults.Write.Churn.Action
+	}
+	if cfg.Write.Churn.TestCommands == nil {
+		cfg.Write.Churn.TestCommands = defaults.Write.Churn.TestCommands
+	}
+	if cfg.Enforcer.ContextMaxChars == 0 {
+		cfg.Enforcer.ContextMaxChars = defaults.Enforcer.ContextMaxChars
+	}
+	if cfg.Enforcer.TimeoutMs == 0 {
+		cfg.Enforcer.TimeoutMs = defaults.Enforcer.TimeoutMs
+	}
+	if cfg.Enforcer.Chain == nil {
+		cfg.Enforcer.Chain = defaults.Enforcer.Chain
+	}
+	if cfg.Enforcer.RedactInputs == nil {
+		cfg.Enforcer.RedactInputs = defaults.Enforcer.RedactInputs
+	}
+	if len(cfg.Research.ResearchTools) == 0 {
+		cfg.Research.ResearchTools = defaults.Research.ResearchTools
+	}
+	if cfg.Research.Policy == nil {
+		cfg.Research.Policy = defaults.Research.Policy
+	}
+	if cfg.Risk.AskThreshold == 0 && cfg.Risk.BlockThreshold == 0 {
+		cfg.Risk.WarnWeight = defaults.Risk.WarnWeight
+		cfg.Risk.AskWeight = defaults.Risk.AskWeight
+		cfg.Risk.BlockWeight = defaults.Risk.BlockWeight
+		cfg.Risk.AskThreshold = defaults.Risk.AskThreshold
+		cfg.Risk.BlockThreshold = defaults.Risk.BlockThreshold
+	}
+	if len(cfg.Ask.Commands) == 0 {
+		cfg.Ask.Commands = defaults.Ask.Commands
+	}
+	if cfg.Recommend.Threshold == 0 {
+		cfg.Recommend.Threshold = defaults.Recommend.Threshold
+	}
+	if cfg.Recommend.Action == "" {
+		cfg.Recommend.Action = defaults.Recommend.Action
+	}
+	if cfg.Git.Action == "" {
+		cfg.Git.Action = defaults.Git.Action
+	}
+	if cfg.Git.Conventional.Types == nil {
+		cfg.Git.Conventional.Types = defaults.Git.Conventional.Types
+	}
+	if cfg.Git.Conventional.Action == "" {
+		cfg.Git.Conventional.Action = defaults.Git.Conventional.Action
+	}
+	if cfg.Endpoints.Action == "" {
+		cfg.Endpoints.Action = defaults.Endpoints.Action
+	}
+	if cfg.Endpoints.Files == nil {
+		cfg.Endpoints.Files = defaults.Endpoints.Files
+	}
+	if cfg.Endpoints.Patterns == nil {
+		cfg.Endpoints.Patterns = defaults.Endpoints.Patterns
+	}
+	if cfg.Evasion.Action == "" {
+		cfg.Evasion.Action = defaults.Evasion.Action
+	}
+	if cfg.Evasion.Patterns == nil {
+		cfg.Evasion.Patterns = defaults.Evasion.Patterns
+	}
+	if cfg.Evasion.MinSignals == 0 {
+		cfg.Evasion.MinSignals = defaults.Evasion.MinSignals
+	}
+	if cfg.Deploy.Action == "" {
+		cfg.Deploy.Action = defaults.Deploy.Action
+	}
+	if cfg.Deploy.Days == nil {
+		cfg.Deploy.Days = defaults.Deploy.Days
+	}
+	if cfg.Deploy.Hours == "" {
+		cfg.Deploy.Hours = defaults.Deploy.Hours
+	}
+	if cfg.Deploy.Commands == nil {
+		cfg.Deploy.Commands = defaults.Deploy.Commands
+	}
+	if cfg.Aegis.Actions == nil {
+		cfg.Aegis.Actions = make(map[string]string)
+	}
+	if cfg.Aegis.MaxFindings == 0 {
+		cfg.Aegis.MaxFindings = defaults.Aegis.MaxFindings
+	}
+	for category, action := range defaults.Aegis.Actions {
+		if !cfg.Aegis.coversAction(category) {
+			cfg.Aegis.Actions[category] = action
+		}
+	}
+	if cfg.Subagent.MaxDepth == 0 {
+		cfg.Subagent.MaxDepth = defaults.Subagent.MaxDepth
+	}
+	if cfg.Advisories.Once == nil {
+		cfg.Advisories.Once = defaults.Advisories.Once
+	}
+	if cfg.Advisories.Always == nil {
+		cfg.Advisories.Always = defaults.Advisories.Always
+	}
+	if cfg.Failure.RetryLimit == 0 {
+		cfg.Failure.RetryLimit = defaults.Failure.RetryLimit
+	}
+	if cfg.MCP.Action == "" {
+		cfg.MCP.Action = defaults.MCP.Action
+	}
+	if cfg.Packages.Action == "" {
+		cfg.Packages.Action = defaults.Packages.Action
+	}
+	if cfg.Tasks.Action == "" {
+		cfg.Tasks.Action = defaults.Tasks.Action
+	}
+	if cfg.Tasks.MinSubjectWords == 0 {
+		cfg.Tasks.MinSubjectWords = defaults.Tasks.MinSubjectWords
+	}
+	if cfg.Tasks.MinDescriptionWords == 0 {
+		cfg.Tasks.MinDescriptionWords = defaults.Tasks.MinDescriptionWords
+	}
+	if cfg.Tasks.Placeholders == nil {
+		cfg.Tasks.Placeholders = defaults.Tasks.Placeholders
+	}
+	if cfg.Packages.Trusted == nil {
+		cfg.Packages.Trusted = defaults.Packages.Trusted
+	}
+}
+
+// GatesConfigHash returns a short fingerprint of the effective gates config,
+// recorded in decision provenance to tie a verdict to a config version.
+func GatesConfigHash() string {
+	return configHash.Get(LoadGatesConfig())
 }
 
-// ReloadGatesConfig forces reload of gates config
+// configHash is the GatesConfigHash of each config load.
+var configHash = NewDerived(func(cfg *GatesConfig) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+})
+
+// ReloadGatesConfig forces reload of gates config. The new load gets a new
+// Version, so every Derived value is rebuilt on its next Get.
 func ReloadGatesConfig() *GatesConfig {
 	gatesConfigMu.Lock()
 	gatesConfig = nil
@@ -237,85 +1167,162 @@ func ReloadGatesConfig() *GatesConfig {
 
 // IsBlockedPath checks if path matches any blocked path pattern
 func IsBlockedPath(path string) bool {
+	return MatchBlockedPath(path) != ""
+}
+
+// MatchBlockedPath returns the matching rule ("read.blocked_paths:<entry>") or ""
+func MatchBlockedPath(path string) string {
 	cfg := LoadGatesConfig()
 	if !cfg.Read.Enabled {
-		return false
+		return ""
 	}
 
-	pathLower := strings.ToLower(path)
-	for _, blocked := range cfg.Read.BlockedPaths {
-		if strings.Contains(pathLower, strings.ToLower(blocked)) {
-			return true
-		}
+	if entry, ok := containsRule(compiledPaths.Get(cfg).readBlocked, patterns.NormalizePath(path)); ok {
+		return "read.blocked_paths:" + entry
 	}
-	return false
+	return ""
 }
 
 // IsBlockedExtension checks if path has a blocked extension
 func IsBlockedExtension(path string) bool {
+	return MatchBlockedExtension(path) != ""
+}
+
+// MatchBlockedExtension returns the matching rule ("read.blocked_extensions:<ext>") or ""
+func MatchBlockedExtension(path string) string {
 	cfg := LoadGatesConfig()
 	if !cfg.Read.Enabled {
-		return false
+		return ""
 	}
 
-	pathLower := strings.ToLower(path)
-	for _, ext := range cfg.Read.BlockedExtensions {
-		if strings.HasSuffix(pathLower, strings.ToLower(ext)) {
-			return true
-		}
+	if ext, ok := suffixRule(compiledPaths.Get(cfg).readExts, patterns.NormalizePath(path)); ok {
+		return "read.blocked_extensions:" + ext
 	}
-	return false
+	return ""
 }
 
 // IsWarnPath checks if path should trigger a warning
 func IsWarnPath(path string) bool {
-	cfg := LoadGatesConfig()
-	pathLower := strings.ToLower(path)
+	return MatchWarnPath(path) != ""
+}
 
-	for _, ext := range cfg.Read.WarnExtensions {
-		if strings.HasSuffix(pathLower, strings.ToLower(ext)) {
-			return true
-		}
-	}
+// MatchWarnPath returns the matching warn rule or ""
+func MatchWarnPath(path string) string {
+	rules := compiledPaths.Get(LoadGatesConfig())
+	pathLower := patterns.NormalizePath(path)
 
-	for _, pattern := range cfg.Read.WarnPatterns {
-		if strings.Contains(pathLower, strings.ToLower(pattern)) {
-			return true
-		}
+	if ext, ok := suffixRule(rules.warnExts, pathLower); ok {
+		return "read.warn_extensions:" + ext
 	}
-
-	return false
+	if pattern, ok := containsRule(rules.warnPatterns, pathLower); ok {
+		return "read.warn_patterns:" + pattern
+	}
+	return ""
 }
 
 // IsBlockedCommand checks if command matches any blocked pattern
 func IsBlockedCommand(cmd string) bool {
+	return MatchBlockedCommand(cmd) != ""
+}
+
+// MatchBlockedCommand returns the matching rule ("bash.blocked_commands:<entry>") or ""
+func MatchBlockedCommand(cmd string) string {
 	cfg := LoadGatesConfig()
 	if !cfg.Bash.Enabled {
-		return false
+		return ""
 	}
 
 	cmdLower := strings.ToLower(cmd)
 	for _, blocked := range cfg.Bash.BlockedCommands {
 		if strings.Contains(cmdLower, strings.ToLower(blocked)) {
-			return true
+			return "bash.blocked_commands:" + blocked
 		}
 	}
-	return false
+	return ""
+}
+
+// MatchTrustedInstall returns the matching rule
+// ("bash.trusted_install_hosts:<entry>") when cmd is a trusted "curl | sh"
+// installer, or "".
+func MatchTrustedInstall(cmd string) string {
+	cfg := LoadGatesConfig()
+	if entry := patterns.MatchTrustedPipeInstall(cmd, cfg.Bash.TrustedInstallHosts); entry != "" {
+		return "bash.trusted_install_hosts:" + entry
+	}
+	return ""
+}
+
+// MatchSafeSudo returns the bash.safe_sudo rule when every sudo in cmd
+// runs a listed routine command, or "".
+func MatchSafeSudo(cmd string) string {
+	cfg := LoadGatesConfig()
+	if entry := patterns.MatchSafeSudo(cmd, cfg.Bash.SafeSudo); entry != "" {
+		return "bash.safe_sudo:" + entry
+	}
+	return ""
+}
+
+// RedactString replaces secrets in s before it is persisted or logged,
+// unless redaction.disabled is set.
+func RedactString(s string) string {
+	if s == "" || LoadGatesConfig().Redaction.Disabled {
+		return s
+	}
+	return patterns.RedactSecrets(s)
+}
+
+// RedactPath is RedactString for a file path; with redaction.hash_paths the
+// directory is replaced by a short hash.
+func RedactPath(p string) string {
+	cfg := LoadGatesConfig().Redaction
+	if p == "" || cfg.Disabled {
+		return p
+	}
+	if cfg.HashPaths {
+		return patterns.HashPath(p)
+	}
+	return patterns.RedactSecrets(p)
+}
+
+// MatchWarnCommand returns the matching rule ("bash.warn_commands:<entry>") or ""
+func MatchWarnCommand(cmd string) string {
+	cfg := LoadGatesConfig()
+	cmdLower := strings.ToLower(cmd)
+	safeSudo := MatchSafeSudo(cmd) != ""
+	for _, warn := range cfg.Bash.WarnCommands {
+		if safeSudo && strings.EqualFold(warn, "sudo") {
+			continue
+		}
+		if strings.Contains(cmdLower, strings.ToLower(warn)) {
+			return "bash.warn_commands:" + warn
+		}
+	}
+	return ""
 }
 
-// IsBlockedWritePath checks if write path is blocked
+// IsBlockedWritePath checks if write path is blocked.
 func IsBlockedWritePath(path string) bool {
+	return MatchBlockedWritePath(path) != ""
+}
+
+// MatchBlockedWritePath returns the write.blocked_paths rule blocking
+// path, or "". Entries match as prefixes, except dot directories
+// ("/.ssh/") which live under a home directory and match anywhere. Paths
+// are compared normalized, so Windows paths match the same entries.
+func MatchBlockedWritePath(path string) string {
 	cfg := LoadGatesConfig()
 	if !cfg.Write.Enabled {
-		return false
+		return ""
 	}
 
-	for _, blocked := range cfg.Write.BlockedPaths {
-		if strings.HasPrefix(path, blocked) {
-			return true
+	normalized := patterns.NormalizePath(path)
+	for _, r := range compiledPaths.Get(cfg).writeBlocked {
+		if strings.HasPrefix(normalized, r.norm) ||
+			(strings.HasPrefix(r.norm, "/.") && strings.Contains(normalized, r.norm)) {
+			return "write.blocked_paths:" + r.entry
 		}
 	}
-	return false
+	return ""
 }
 
 // GetSkillsForIntent returns skills matching an intent keyword
@@ -337,6 +1344,18 @@ func GetSkillsForIntent(prompt string) []string {
 	return skills
 }
 
+// IsResearchTool checks if a tool counts as research (research.research_tools,
+// exact names or globs such as "mcp__context7__*")
+func IsResearchTool(toolName string) bool {
+	cfg := LoadGatesConfig()
+	for _, tool := range cfg.Research.ResearchTools {
+		if types.MatchToolName(tool, toolName) {
+			return true
+		}
+	}
+	return false
+}
+
 // RequiresResearch checks if prompt requires research before code
 func RequiresResearch(prompt string) bool {
 	cfg := LoadGatesConfig()
@@ -0,0 +1,122 @@
+// Package config provides dynamic configuration loading.
+// gates_diff.go: Effective gates config vs built-in defaults, with value sources.
+// DACE: Reuses loadGatesConfigFromFile so the diff matches real load behavior.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// Config value sources, lowest to highest precedence.
+const (
+	SourceDefault = "default"
+	SourceGlobal  = "global"
+	SourceProject = "project"
+	SourceUnset   = "unset" // Section present but field omitted and not merged
+)
+
+// ConfigField is one leaf value of the effective GatesConfig.
+type ConfigField struct {
+	Path    string      `json:"path"` // Dotted JSON path, e.g. "bash.warn_commands"
+	Value   interface{} `json:"value"`
+	Default interface{} `json:"default,omitempty"`
+	Source  string      `json:"source"`
+	Changed bool        `json:"changed"`
+}
+
+// DiffGatesConfig returns every effective config field, sorted by path,
+// marking fields that differ from defaults and the layer that set them.
+func DiffGatesConfig() []ConfigField {
+	effective := flattenConfig(loadGatesConfigFromFile())
+	defaults := flattenConfig(getDefaultGatesConfig())
+	global := readConfigLayer(GatesConfigPath())
+
+	// Project layers only tighten, so they set whatever they changed from
+	// the user config alone
+	userOnly := defaults
+	if user, err := ReadGatesConfig(GatesConfigPath()); err == nil {
+		userOnly = flattenConfig(user)
+	}
+
+	paths := make(map[string]bool, len(effective))
+	for path := range effective {
+		paths[path] = true
+	}
+	for path := range defaults {
+		paths[path] = true
+	}
+
+	fields := make([]ConfigField, 0, len(paths))
+	for path := range paths {
+		value := effective[path]
+		def := defaults[path]
+		field := ConfigField{
+			Path:    path,
+			Value:   value,
+			Default: def,
+			Source:  SourceDefault,
+			Changed: !reflect.DeepEqual(value, def),
+		}
+		if field.Changed {
+			field.Source = SourceUnset
+		}
+		if v, ok := global[path]; ok && reflect.DeepEqual(v, value) {
+			field.Source = SourceGlobal
+		}
+		if !reflect.DeepEqual(userOnly[path], value) {
+			field.Source = SourceProject
+		}
+		fields = append(fields, field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return fields
+}
+
+// readConfigLayer flattens a raw JSON config file. Returns nil if unreadable.
+func readConfigLayer(path string) map[string]interface{} {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var raw map[string]interface{}
+	if json.Unmarshal(data, &raw) != nil {
+		return nil
+	}
+	values := make(map[string]interface{})
+	flattenInto(values, "", raw)
+	return values
+}
+
+// flattenConfig converts a GatesConfig into dotted-path leaf values.
+func flattenConfig(cfg *GatesConfig) map[string]interface{} {
+	values := make(map[string]interface{})
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return values
+	}
+	var raw map[string]interface{}
+	if json.Unmarshal(data, &raw) != nil {
+		return values
+	}
+	flattenInto(values, "", raw)
+	return values
+}
+
+// flattenInto walks nested objects; arrays and scalars are leaves.
+func flattenInto(out map[string]interface{}, prefix string, node map[string]interface{}) {
+	for key, value := range node {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if child, ok := value.(map[string]interface{}); ok && len(child) > 0 {
+			flattenInto(out, path, child)
+			continue
+		}
+		out[path] = value
+	}
+}
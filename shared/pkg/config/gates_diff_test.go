@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffGatesConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".claude", "gates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `{"bash": {"enabled": true, "warn_commands": ["sudo"]}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[string]ConfigField)
+	for _, f := range DiffGatesConfig() {
+		fields[f.Path] = f
+	}
+
+	warn, ok := fields["bash.warn_commands"]
+	if !ok {
+		t.Fatal("missing bash.warn_commands")
+	}
+	if !warn.Changed || warn.Source != SourceGlobal {
+		t.Errorf("bash.warn_commands = %+v, want changed from global", warn)
+	}
+
+	// Merged back from defaults: unchanged, sourced from defaults
+	blocked := fields["bash.blocked_commands"]
+	if blocked.Changed || blocked.Source != SourceDefault {
+		t.Errorf("bash.blocked_commands = %+v, want unchanged default", blocked)
+	}
+
+	// Omitted from file and not merged: zero value overrides the default
+	readEnabled := fields["read.enabled"]
+	if !readEnabled.Changed || readEnabled.Source != SourceUnset {
+		t.Errorf("read.enabled = %+v, want changed and unset", readEnabled)
+	}
+
+	// Set in file to the default value: unchanged, but sourced from global
+	enabled := fields["bash.enabled"]
+	if enabled.Changed || enabled.Source != SourceGlobal {
+		t.Errorf("bash.enabled = %+v, want unchanged from global", enabled)
+	}
+
+	// A project layer over the user config is reported as project
+	root := t.TempDir()
+	project, _, err := InitProjectGatesConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(project, []byte(`{"bash": {"warn_commands": ["sudo", "npm publish"]}}`), 0644)
+	t.Chdir(root)
+	for _, f := range DiffGatesConfig() {
+		if f.Path == "bash.warn_commands" && f.Source != SourceProject {
+			t.Errorf("bash.warn_commands = %+v, want from project", f)
+		}
+		if f.Path == "bash.enabled" && f.Source != SourceGlobal {
+			t.Errorf("bash.enabled = %+v, want still from global", f)
+		}
+	}
+}
@@ -0,0 +1,554 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/claude/shared/pkg/patterns"
+)
+
+func TestReadGatesConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ReadGatesConfig(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: expected fs.ErrNotExist, got %v", err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"bash": [`), 0644)
+	if _, err := ReadGatesConfig(bad); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("bad json: expected ErrInvalidConfig, got %v", err)
+	}
+
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`{"bash": {"enabled": true}}`), 0644)
+	cfg, err := ReadGatesConfig(good)
+	if err != nil {
+		t.Fatalf("good json: %v", err)
+	}
+	if len(cfg.Bash.BlockedCommands) == 0 {
+		t.Error("expected defaults merged into missing fields")
+	}
+}
+
+func TestProtectedFiles(t *testing.T) {
+	var cfg WriteConfig
+	data := `{"protected_files": [".env", {"pattern": "*.lock", "action": "block", "reason": "generated"}, ".github/workflows/*"]}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.ProtectedFiles) != 3 || cfg.ProtectedFiles[0].Pattern != ".env" || !cfg.ProtectedFiles[1].Blocks() {
+		t.Fatalf("ProtectedFiles = %+v", cfg.ProtectedFiles)
+	}
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/repo/.env", ".env"},
+		{"/repo/.env.example", ""},
+		{"/repo/Cargo.lock", "*.lock"},
+		{"/repo/.github/workflows/ci.yml", ".github/workflows/*"},
+		{"/repo/.github/workflows/nested/ci.yml", ""},
+		{"workflows/ci.yml", ""},
+	}
+	for _, tc := range cases {
+		got := ""
+		for _, pf := range cfg.ProtectedFiles {
+			if pf.Matches(tc.path) {
+				got = pf.Pattern
+				break
+			}
+		}
+		if got != tc.want {
+			t.Errorf("%s matched %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestWindowsPaths(t *testing.T) {
+	cfg := getDefaultGatesConfig()
+	cfg.Read.BlockedPaths = platformPaths("windows", cfg.Read.BlockedPaths, patterns.WindowsSensitivePaths)
+	cfg.Write.BlockedPaths = platformPaths("windows", cfg.Write.BlockedPaths, windowsWriteBlocked)
+	gatesConfigMu.Lock()
+	gatesConfig, gatesConfigTime = cfg, time.Now()
+	gatesConfigMu.Unlock()
+	t.Cleanup(func() {
+		gatesConfigMu.Lock()
+		gatesConfig = nil
+		gatesConfigMu.Unlock()
+	})
+
+	reads := []struct {
+		path    string
+		blocked bool
+	}{
+		{`C:\Users\dev\.ssh\id_rsa`, true},
+		{`c:/users/dev/.aws/credentials`, true},
+		{`%USERPROFILE%\.ssh\id_ed25519`, true},
+		{`%APPDATA%\Microsoft\Credentials\DFBE70A7`, true},
+		{`C:\Users\dev\AppData\Local\Microsoft\Credentials\x`, true},
+		{`$env:APPDATA\Microsoft\Protect\S-1-5-21\key`, true},
+		{`C:\Windows\System32\config\SAM`, true},
+		{`C:\Users\dev\src\main.go`, false},
+	}
+	for _, tc := range reads {
+		if got := MatchBlockedPath(tc.path) != ""; got != tc.blocked {
+			t.Errorf("MatchBlockedPath(%s) = %v, want %v", tc.path, got, tc.blocked)
+		}
+	}
+	if MatchBlockedExtension(`D:\certs\server.PEM`) == "" {
+		t.Error("Windows .pem not blocked")
+	}
+
+	writes := []struct {
+		path    string
+		blocked bool
+	}{
+		{`C:\Windows\System32\drivers\etc\hosts`, true},
+		{`C:\Program Files\App\app.exe`, true},
+		{`C:\Users\dev\.ssh\authorized_keys`, true},
+		{`/home/dev/.aws/config`, true},
+		{`C:\Users\dev\project\windows\notes.txt`, false},
+	}
+	for _, tc := range writes {
+		if got := IsBlockedWritePath(tc.path); got != tc.blocked {
+			t.Errorf("IsBlockedWritePath(%s) = %v, want %v", tc.path, got, tc.blocked)
+		}
+	}
+
+	if !(ProtectedFile{Pattern: ".github/workflows/*"}).Matches(`C:\repo\.github\workflows\ci.yml`) {
+		t.Error("protected glob missed a Windows path")
+	}
+	if got := platformPaths("linux", []string{"/etc/"}, windowsWriteBlocked); len(got) != 1 {
+		t.Errorf("linux defaults include Windows entries: %v", got)
+	}
+}
+
+func TestInitProjectGatesConfig(t *testing.T) {
+	dir := t.TempDir()
+	path, created, err := InitProjectGatesConfig(dir)
+	if err != nil || !created || path != filepath.Join(dir, ".kavach", "gates.json") {
+		t.Fatalf("first init = %s, %t, %v; want created", path, created, err)
+	}
+	cfg, err := ReadGatesConfig(path)
+	if err != nil {
+		t.Fatalf("created config unreadable: %v", err)
+	}
+
+	// The template sets nothing, so user customizations stay in force
+	user := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(user, []byte(`{"bash": {"enabled": true, "blocked_commands": ["terraform destroy"]}}`), 0644)
+	alone, _ := ReadGatesConfig(user)
+	layered, err := ReadGatesConfigLayers(user, path)
+	if err != nil || !reflect.DeepEqual(layered, alone) {
+		t.Errorf("template changed the user config: %v\n%+v\nwant %+v", err, layered.Bash, alone.Bash)
+	}
+
+	os.WriteFile(path, []byte(`{"quality":{"enabled":true},"bash":{"enabled":false}}`), 0644)
+	if _, created, err := InitProjectGatesConfig(dir); err != nil || created {
+		t.Errorf("second init = %t, %v; want existing file kept", created, err)
+	}
+	cfg, _ = ReadGatesConfig(path)
+	states := make(map[string]bool)
+	for _, g := range ActiveGates(cfg) {
+		states[g.Name] = g.Active
+	}
+	if !states["quality"] || states["bash"] {
+		t.Errorf("ActiveGates = %v, want quality on and bash off", states)
+	}
+}
+
+func TestDerivedRebuiltOnReload(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := GatesConfigPath()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	write := func(pattern string) {
+		data, _ := json.Marshal(map[string]interface{}{
+			"security_evasion": map[string]interface{}{"enabled": true, "patterns": []string{pattern}},
+		})
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	builds := 0
+	compiled := NewDerived(func(cfg *GatesConfig) *regexp.Regexp {
+		builds++
+		return regexp.MustCompile(cfg.Evasion.Patterns[0])
+	})
+
+	write(`\bfoo\b`)
+	cfg := ReloadGatesConfig()
+	hash := GatesConfigHash()
+	if !compiled.Get(cfg).MatchString("run foo") || !compiled.Get(LoadGatesConfig()).MatchString("foo") {
+		t.Fatal("initial pattern not applied")
+	}
+	if builds != 1 {
+		t.Errorf("builds = %d, want 1 (cached for the same load)", builds)
+	}
+
+	write(`\bbar\b`)
+	reloaded := ReloadGatesConfig()
+	if reloaded.Version() == cfg.Version() {
+		t.Fatalf("version unchanged on reload: %d", cfg.Version())
+	}
+	re := compiled.Get(reloaded)
+	if builds != 2 || !re.MatchString("run bar") || re.MatchString("run foo") {
+		t.Errorf("after reload: builds=%d pattern=%s", builds, re)
+	}
+	if GatesConfigHash() == hash {
+		t.Error("config hash not recomputed after reload")
+	}
+
+	// Unversioned configs are never served from the cache
+	if compiled.Get(getDefaultGatesConfig()) == re {
+		t.Error("unversioned config returned the cached value")
+	}
+}
+
+func TestChurnTestCommand(t *testing.T) {
+	c := getDefaultGatesConfig().Write.Churn
+	for command, want := range map[string]bool{
+		"go test ./...":                     true,
+		"cd api &&  go   test -run X ./...": true,
+		"pytest":                            true,
+		"make build && make test":           true,
+		"go testdata/gen.go":                false,
+		"echo go test":                      false,
+		"go build ./...":                    false,
+	} {
+		if got := c.IsTestCommand(command); got != want {
+			t.Errorf("IsTestCommand(%q) = %v, want %v", command, got, want)
+		}
+	}
+}
+
+func TestAegisActionGlobs(t *testing.T) {
+	cfg := &GatesConfig{Aegis: AegisConfig{Actions: map[string]string{"*_command": "ask"}}}
+	mergeGatesDefaults(cfg)
+	if _, ok := cfg.Aegis.Actions["dangerous_command"]; ok {
+		t.Errorf("default filled over a glob: %v", cfg.Aegis.Actions)
+	}
+	if cfg.Aegis.Actions["env_exfil"] != "deny" {
+		t.Errorf("uncovered category lost its default: %v", cfg.Aegis.Actions)
+	}
+}
+
+func TestFieldDocs(t *testing.T) {
+	paths := make(map[string]bool)
+	for _, f := range GatesConfigFields() {
+		paths[f.Path] = true
+		if f.Doc == "" {
+			t.Errorf("%s has no entry in fieldDocs", f.Path)
+		}
+	}
+	for path := range fieldDocs {
+		if !paths[path] {
+			t.Errorf("fieldDocs has %s, which is not a config field", path)
+		}
+	}
+}
+
+func TestGatesConfigTemplate(t *testing.T) {
+	var sb strings.Builder
+	for _, line := range strings.Split(GatesConfigTemplate(), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			sb.WriteString(line + "\n")
+		}
+	}
+	var cfg GatesConfig
+	if err := json.Unmarshal([]byte(sb.String()), &cfg); err != nil {
+		t.Fatalf("template without comments is not valid JSON: %v", err)
+	}
+	// The template spells unset lists and maps as [] and {}
+	empty := strings.NewReplacer(":[]", ":null", ":{}", ":null")
+	got, _ := json.Marshal(&cfg)
+	want, _ := json.Marshal(getDefaultGatesConfig())
+	if empty.Replace(string(got)) != empty.Replace(string(want)) {
+		t.Errorf("template does not round-trip to defaults:\n got %s\nwant %s", got, want)
+	}
+}
+
+// naiveBlockedPath is MatchBlockedPath before rules were precompiled,
+// normalizing every entry on every call.
+func naiveBlockedPath(cfg *GatesConfig, path string) string {
+	normalized := patterns.NormalizePath(path)
+	for _, blocked := range cfg.Read.BlockedPaths {
+		if strings.Contains(normalized, patterns.NormalizePath(blocked)) {
+			return "read.blocked_paths:" + blocked
+		}
+	}
+	return ""
+}
+
+var benchPaths = []string{
+	"/home/dev/project/internal/server/handler.go",
+	`C:\Users\dev\.ssh\id_rsa`,
+	"/home/dev/.aws/credentials",
+	"/home/dev/project/node_modules/react/index.js",
+}
+
+// useVersionedConfig installs cfg as the loaded config with a fresh
+// version, so Derived values are cached as they are in a hook run.
+func useVersionedConfig(t testing.TB, cfg *GatesConfig) {
+	cfg.version = gatesConfigVersion.Add(1)
+	gatesConfigMu.Lock()
+	gatesConfig, gatesConfigTime = cfg, time.Now()
+	gatesConfigMu.Unlock()
+	t.Cleanup(func() {
+		gatesConfigMu.Lock()
+		gatesConfig = nil
+		gatesConfigMu.Unlock()
+	})
+}
+
+func TestCompiledPathRules(t *testing.T) {
+	cfg := getDefaultGatesConfig()
+	cfg.Read.BlockedPaths = append(platformPaths("windows", cfg.Read.BlockedPaths, patterns.WindowsSensitivePaths), "/Secrets/")
+	useVersionedConfig(t, cfg)
+
+	for _, path := range append(benchPaths, "/srv/SECRETS/db.yml", "/srv/app/main.go") {
+		if got, want := MatchBlockedPath(path), naiveBlockedPath(cfg, path); got != want {
+			t.Errorf("MatchBlockedPath(%s) = %q, want %q", path, got, want)
+		}
+	}
+	if compiledPaths.Get(cfg) != compiledPaths.Get(LoadGatesConfig()) {
+		t.Error("path rules rebuilt for the same config load")
+	}
+	if got := MatchBlockedExtension("/keys/server.PEM"); got != "read.blocked_extensions:.pem" {
+		t.Errorf("MatchBlockedExtension = %q", got)
+	}
+}
+
+func BenchmarkMatchBlockedPath(b *testing.B) {
+	cfg := getDefaultGatesConfig()
+	cfg.Read.BlockedPaths = platformPaths("windows", cfg.Read.BlockedPaths, patterns.WindowsSensitivePaths)
+	useVersionedConfig(b, cfg)
+
+	b.Run("naive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			naiveBlockedPath(cfg, benchPaths[i%len(benchPaths)])
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			MatchBlockedPath(benchPaths[i%len(benchPaths)])
+		}
+	})
+}
+
+func TestLearningUntil(t *testing.T) {
+	c := LearningConfig{Rules: map[string]string{
+		"bash.blocked_commands:terraform destroy": "2026-11-30",
+		"bash.blocked_commands":                   "2026-11-01",
+		"READ":                                    "2026-11-15T09:00:00Z",
+		"MCP":                                     "soon",
+	}}
+	now := time.Date(2026, 11, 15, 8, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		gate, rule string
+		learning   bool
+	}{
+		{"BASH", "bash.blocked_commands:terraform destroy", true}, // Rule beats its list
+		{"BASH", "bash.blocked_commands:rm -rf", false},           // List ended Nov 1
+		{"READ", "read.blocked_paths:/.ssh/", true},
+		{"MCP", "mcp.schemas:mcp__github__*", false}, // Bad date enforces
+		{"ENFORCER", "write.protected_files:go.mod", false},
+	} {
+		if _, got := c.Until(tc.gate, tc.rule, now); got != tc.learning {
+			t.Errorf("Until(%s, %s) learning = %v, want %v", tc.gate, tc.rule, got, tc.learning)
+		}
+	}
+	if _, learning := c.Until("READ", "read.blocked_paths:/.ssh/", now.Add(2*time.Hour)); learning {
+		t.Error("READ still learning after its end time")
+	}
+	end, _ := c.Until("BASH", "bash.blocked_commands:terraform destroy", now)
+	if want := time.Date(2026, 12, 1, 0, 0, 0, 0, time.Local); !end.Equal(want) {
+		t.Errorf("date-only end = %v, want the following midnight %v", end, want)
+	}
+}
+
+func TestTaskCheck(t *testing.T) {
+	c := getDefaultGatesConfig().Tasks
+	for _, tc := range []struct {
+		subject, description, want string
+	}{
+		{"Add retry to webhook sender", "Retry failed deliveries with backoff", ""},
+		{"TODO", "Retry failed deliveries with backoff", "subject:placeholder"},
+		{"Task 1", "Retry failed deliveries with backoff", "subject:placeholder"},
+		{"Refactor", "Retry failed deliveries with backoff", "subject:too_short"},
+		{"Add retry to webhook sender", "tbd...", "description:placeholder"},
+		{"Add retry to webhook sender", "add retry", "description:too_short"},
+		{"Fix TODO in parser", "Remove the TODO left in parse.go", ""},
+	} {
+		if got := c.Check(tc.subject, tc.description); got != tc.want {
+			t.Errorf("Check(%q, %q) = %q, want %q", tc.subject, tc.description, got, tc.want)
+		}
+	}
+}
+
+func TestAdvisoryWarnOnce(t *testing.T) {
+	c := getDefaultGatesConfig().Advisories
+	for category, want := range map[string]bool{
+		"large_file":          true,
+		"hardcoded_localhost": true,
+		"rm -rf_detected":     false, // Not opted in: shown every time
+		"git_policy":          false,
+		"read_harvest":        false,
+		"learning":            false,
+		"task_quality":        false,
+		"sudo_detected":       false,
+	} {
+		if got := c.WarnOnce(category); got != want {
+			t.Errorf("WarnOnce(%q) = %v, want %v", category, got, want)
+		}
+	}
+
+	c.Once = append(c.Once, "sudo_detected")
+	if c.WarnOnce("sudo_detected") {
+		t.Error("always lost to once for sudo_detected")
+	}
+	c.Repeat = true
+	if c.WarnOnce("large_file") {
+		t.Error("repeat still suppresses large_file")
+	}
+}
+
+func TestMCPSchemasLenient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gates.json")
+	os.WriteFile(path, []byte(`{"bash": {"enabled": true}, "mcp": {"action": "warn", "schemas": {
+		"mcp__github__create_issue": {"type": "object", "required": ["title"]},
+		"mcp__github__*": {"type": 7},
+		"mcp__slack__post": {"properties": {"channel": {"pattern": "("}}}
+	}}}`), 0644)
+	cfg, err := ReadGatesConfig(path)
+	if err != nil {
+		t.Fatalf("one bad schema rejected the config: %v", err)
+	}
+	if !cfg.Bash.Enabled || cfg.MCP.Action != "warn" {
+		t.Errorf("rest of config lost: bash=%v action=%q", cfg.Bash.Enabled, cfg.MCP.Action)
+	}
+	if schema, key := cfg.MCP.SchemaFor("mcp__github__create_issue"); schema == nil || key != "mcp__github__create_issue" {
+		t.Errorf("valid schema dropped: %v %q", schema, key)
+	}
+	if len(cfg.MCP.Schemas) != 1 || len(cfg.MCP.Invalid) != 2 {
+		t.Errorf("schemas = %d, invalid = %v", len(cfg.MCP.Schemas), cfg.MCP.Invalid)
+	}
+	if key, msg := cfg.MCP.InvalidFor("mcp__slack__post"); key != "mcp__slack__post" || !strings.Contains(msg, "invalid pattern") {
+		t.Errorf("InvalidFor = %q %q", key, msg)
+	}
+	if key, _ := cfg.MCP.InvalidFor("mcp__jira__get"); key != "" {
+		t.Errorf("InvalidFor matched unrelated tool: %q", key)
+	}
+}
+
+func TestGatesConfigLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	user := filepath.Join(home, "user.json")
+	os.WriteFile(user, []byte(`{"bash": {"enabled": true}, "read": {"enabled": true}, "tool_policy": {"Bash": "ask"},
+		"mcp": {"action": "block", "schemas": {"mcp__github__*": {"type": "object"}}}}`), 0644)
+
+	root := t.TempDir()
+	project, _, err := InitProjectGatesConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A project layer ships with the repository: it may tighten, never loosen
+	os.WriteFile(project, []byte(`{"read": {"enabled": false}, "bash": {"enabled": false,
+		"blocked_commands": ["terraform destroy"], "safe_sudo": ["rm -rf /"]},
+		"write": {"uncommitted": "off"}, "onboarding": {"advisory": true, "soft_mode": true},
+		"tool_policy": {"Write": "block", "bash": "allow", "Read": "deny"},
+		"mcp": {"action": "warn"}, "packages": {"action": "block"},
+		"aegis": {"actions": {"pipe_install": "modify", "env_exfil": "ask", "custom_*": "modify"}}}`), 0644)
+
+	cfg, err := ReadGatesConfigLayers(user, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Bash.Enabled || !cfg.Read.Enabled || cfg.Onboarding.Advisory || cfg.Onboarding.SoftMode {
+		t.Errorf("project layer loosened: bash=%v read=%v onboarding=%+v", cfg.Bash.Enabled, cfg.Read.Enabled, cfg.Onboarding)
+	}
+	if !slices.Contains(cfg.Bash.BlockedCommands, "terraform destroy") || !slices.Contains(cfg.Bash.BlockedCommands, "rm -rf /") {
+		t.Errorf("blocked_commands = %q, want defaults plus the project entry", cfg.Bash.BlockedCommands)
+	}
+	if slices.Contains(cfg.Bash.SafeSudo, "rm -rf /") {
+		t.Errorf("safe_sudo = %q, project allowlist applied", cfg.Bash.SafeSudo)
+	}
+	if defaults := getDefaultGatesConfig(); cfg.Write.Uncommitted != defaults.Write.Uncommitted {
+		t.Errorf("uncommitted = %q, project lowered it", cfg.Write.Uncommitted)
+	}
+	if want := map[string]string{"Bash": "ask", "Write": "block"}; !maps.Equal(cfg.ToolPolicy, want) {
+		t.Errorf("tool_policy = %v, want %v", cfg.ToolPolicy, want)
+	}
+	if schema, _ := cfg.MCP.SchemaFor("mcp__github__get"); cfg.MCP.Action != "block" || schema == nil {
+		t.Errorf("mcp action=%q schema=%v, want user action and schema", cfg.MCP.Action, schema)
+	}
+	if cfg.Packages.Action != "block" {
+		t.Errorf("packages action = %q, want raised to block", cfg.Packages.Action)
+	}
+	if a := cfg.Aegis.Actions; a["pipe_install"] != "deny" || a["env_exfil"] == "modify" || a["custom_*"] != "" {
+		t.Errorf("aegis actions = %v, want none lowered and no new modify key", a)
+	}
+
+	if _, err := ReadGatesConfigLayers(filepath.Join(home, "missing.json"), project); err != nil {
+		t.Errorf("missing user layer: %v", err)
+	}
+	if _, err := ReadGatesConfigLayers(filepath.Join(home, "a.json"), filepath.Join(home, "b.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("all layers missing: expected fs.ErrNotExist, got %v", err)
+	}
+
+	deeper := filepath.Join(root, "sub", "deeper")
+	os.MkdirAll(deeper, 0755)
+	if got := FindProjectGatesConfig(deeper); got != project {
+		t.Errorf("FindProjectGatesConfig = %q, want %q", got, project)
+	}
+	t.Chdir(deeper)
+	if layers := GatesConfigLayers(); len(layers) != 2 || layers[1] != project {
+		t.Errorf("GatesConfigLayers = %q, want user then project", layers)
+	}
+	// The home directory's .kavach is not a project config
+	InitProjectGatesConfig(home)
+	if got := FindProjectGatesConfig(home); got != "" {
+		t.Errorf("home .kavach used as project config: %q", got)
+	}
+}
+
+func TestReadUserGatesConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw, cfg, err := ReadUserGatesConfig(path)
+	if err != nil || raw != nil || len(cfg.Bash.BlockedCommands) == 0 {
+		t.Fatalf("missing file = %v, %d blocked, %v; want nil and defaults", raw, len(cfg.Bash.BlockedCommands), err)
+	}
+
+	os.WriteFile(path, []byte(`{"bash": {"warn_commands": ["sudo"]}}`), 0644)
+	raw, cfg, err = ReadUserGatesConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["bash"].(map[string]interface{})["blocked_commands"]; ok {
+		t.Errorf("raw = %v, defaults leaked into the file view", raw)
+	}
+	if len(cfg.Bash.BlockedCommands) == 0 || !reflect.DeepEqual(cfg.Bash.WarnCommands, []string{"sudo"}) {
+		t.Errorf("loaded bash = %+v, want file values over defaults", cfg.Bash)
+	}
+
+	os.WriteFile(path, []byte(`{`), 0644)
+	if _, _, err := ReadUserGatesConfig(path); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("invalid file: %v, want ErrInvalidConfig", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Package config provides dynamic configuration loading.
+// pathmatch.go: Read/write path rules normalized once per config load, so a
+// path check normalizes only the path being tested.
+package config
+
+import (
+	"strings"
+
+	"github.com/claude/shared/pkg/patterns"
+)
+
+// pathRule is a config entry and its normalized form.
+type pathRule struct {
+	entry string
+	norm  string
+}
+
+// pathMatchers holds the path rules of one config load.
+type pathMatchers struct {
+	readBlocked  []pathRule
+	readExts     []pathRule
+	warnExts     []pathRule
+	warnPatterns []pathRule
+	writeBlocked []pathRule
+}
+
+// compiledPaths caches the normalized path rules per config load.
+var compiledPaths = NewDerived(func(cfg *GatesConfig) *pathMatchers {
+	return &pathMatchers{
+		readBlocked:  compileRules(cfg.Read.BlockedPaths, patterns.NormalizePath),
+		readExts:     compileRules(cfg.Read.BlockedExtensions, strings.ToLower),
+		warnExts:     compileRules(cfg.Read.WarnExtensions, strings.ToLower),
+		warnPatterns: compileRules(cfg.Read.WarnPatterns, strings.ToLower),
+		writeBlocked: compileRules(cfg.Write.BlockedPaths, patterns.NormalizePath),
+	}
+})
+
+func compileRules(entries []string, norm func(string) string) []pathRule {
+	rules := make([]pathRule, len(entries))
+	for i, e := range entries {
+		rules[i] = pathRule{entry: e, norm: norm(e)}
+	}
+	return rules
+}
+
+// containsRule returns the entry of the first rule found in path.
+func containsRule(rules []pathRule, path string) (string, bool) {
+	for _, r := range rules {
+		if strings.Contains(path, r.norm) {
+			return r.entry, true
+		}
+	}
+	return "", false
+}
+
+// suffixRule returns the entry of the first rule path ends with.
+func suffixRule(rules []pathRule, path string) (string, bool) {
+	for _, r := range rules {
+		if strings.HasSuffix(path, r.norm) {
+			return r.entry, true
+		}
+	}
+	return "", false
+}
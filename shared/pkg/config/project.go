@@ -0,0 +1,100 @@
+// Package config provides dynamic configuration loading.
+// project.go: Project-level gates config (.kavach/gates.json), which
+// LoadGatesConfig applies over the user config as a tightening-only layer
+// (tighten.go): lookup, bootstrap and a summary of the gates a config
+// turns on.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ProjectGatesConfigPath returns the project gates config under dir.
+func ProjectGatesConfigPath(dir string) string {
+	return filepath.Join(dir, ".kavach", "gates.json")
+}
+
+// FindProjectGatesConfig returns the nearest .kavach/gates.json in dir or
+// a parent of it, or "". The home directory is not a project: its
+// .kavach is never used.
+func FindProjectGatesConfig(dir string) string {
+	home, _ := os.UserHomeDir()
+	for dir != "" && dir != home {
+		path := ProjectGatesConfigPath(dir)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// projectConfigTemplate is what InitProjectGatesConfig writes: no
+// effective values, so the user config stays in force until the project
+// adds its own blocks. The file is committed, so nothing platform specific.
+var projectConfigTemplate = map[string]string{
+	"$schema":     "kavach-gates/1.0",
+	"description": "Project kavach gates config. Applied over ~/.claude/gates/config.json and can only tighten it: add blocked entries, enable gates, raise actions.",
+}
+
+// InitProjectGatesConfig writes an empty project overlay to
+// dir/.kavach/gates.json unless a file is already there. created reports
+// whether this call wrote it; an existing file is never touched.
+func InitProjectGatesConfig(dir string) (path string, created bool, err error) {
+	path = ProjectGatesConfigPath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return path, false, fmt.Errorf("mkdir: %w", err)
+	}
+	data, err := json.MarshalIndent(projectConfigTemplate, "", "  ")
+	if err != nil {
+		return path, false, fmt.Errorf("marshal: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return path, false, nil
+	} else if err != nil {
+		return path, false, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return path, false, err
+	}
+	return path, true, nil
+}
+
+// GateState is one gate section and whether cfg enables it.
+type GateState struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// ActiveGates lists the gate sections of cfg in a fixed order with their
+// enabled state.
+func ActiveGates(cfg *GatesConfig) []GateState {
+	return []GateState{
+		{"read", cfg.Read.Enabled},
+		{"write", cfg.Write.Enabled},
+		{"bash", cfg.Bash.Enabled},
+		{"intent", cfg.Intent.Enabled},
+		{"research", cfg.Research.Enabled && cfg.Research.RequireBeforeCode},
+		{"context", cfg.Context.Enabled},
+		{"quality", cfg.Quality.Enabled},
+		{"risk", cfg.Risk.Enabled},
+		{"ask", cfg.Ask.Enabled},
+		{"recommendations", cfg.Recommend.Enabled},
+		{"packages", cfg.Packages.Enabled},
+		{"endpoints", cfg.Endpoints.Enabled},
+		{"security_evasion", cfg.Evasion.Enabled},
+		{"conventional_commits", cfg.Git.Conventional.Enabled},
+		{"redaction", !cfg.Redaction.Disabled},
+	}
+}
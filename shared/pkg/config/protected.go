@@ -0,0 +1,70 @@
+// Package config provides dynamic configuration loading.
+// protected.go: Protected-file globs with a per-entry ask/block policy.
+package config
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// ProtectedFile is one write.protected_files entry. The JSON form is either
+// a bare glob ("*.lock") or {"pattern", "action", "reason"}.
+type ProtectedFile struct {
+	Pattern string `json:"pattern"`
+	Action  string `json:"action,omitempty"` // "ask" (default) or "block"
+	Reason  string `json:"reason,omitempty"` // Why the file is protected
+}
+
+// UnmarshalJSON accepts the legacy bare-string form.
+func (p *ProtectedFile) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*p = ProtectedFile{Pattern: s}
+		return nil
+	}
+	type alias ProtectedFile
+	return json.Unmarshal(data, (*alias)(p))
+}
+
+// Blocks reports whether the entry denies the write instead of asking.
+func (p ProtectedFile) Blocks() bool {
+	return strings.EqualFold(p.Action, "block")
+}
+
+// Matches reports whether filePath matches the entry's glob. Patterns
+// without a slash match the base name; patterns with one match the
+// trailing path segments (".github/workflows/*").
+func (p ProtectedFile) Matches(filePath string) bool {
+	if p.Pattern == "" || filePath == "" {
+		return false
+	}
+	pattern := strings.TrimPrefix(strings.ReplaceAll(p.Pattern, `\`, "/"), "./")
+	full := path.Clean(strings.ReplaceAll(filePath, `\`, "/"))
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(full))
+		return ok
+	}
+	segments := strings.Split(full, "/")
+	depth := strings.Count(pattern, "/") + 1
+	if depth > len(segments) {
+		return false
+	}
+	ok, _ := path.Match(pattern, strings.Join(segments[len(segments)-depth:], "/"))
+	return ok
+}
+
+// MatchProtectedFile returns the first write.protected_files entry that
+// matches filePath, or nil.
+func MatchProtectedFile(filePath string) *ProtectedFile {
+	cfg := LoadGatesConfig()
+	if !cfg.Write.Enabled {
+		return nil
+	}
+	for i := range cfg.Write.ProtectedFiles {
+		if cfg.Write.ProtectedFiles[i].Matches(filePath) {
+			return &cfg.Write.ProtectedFiles[i]
+		}
+	}
+	return nil
+}
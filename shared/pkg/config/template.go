@@ -0,0 +1,330 @@
+// Package config provides dynamic configuration loading.
+// template.go: Annotated reference of the full gates config, generated by
+// walking GatesConfig's json tags with defaults filled in. Field docs live
+// in fieldDocs; TestFieldDocs keeps them in step with the struct.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TemplateField is one field of the gates config reference.
+type TemplateField struct {
+	Path    string      `json:"path"` // Dotted JSON path, e.g. "bash.warn_commands"
+	Type    string      `json:"type"`
+	Default interface{} `json:"default"`
+	Doc     string      `json:"doc"`
+	Section bool        `json:"section,omitempty"` // Object holding further fields
+}
+
+// fieldDocs documents every GatesConfig JSON path, sections included.
+var fieldDocs = map[string]string{
+	"$schema":     "Config format identifier",
+	"description": "Free-form description of this config",
+	"updated":     "Date the config was last edited (YYYY-MM-DD)",
+
+	"read":                        "File read gate (Read, Glob, Grep)",
+	"read.enabled":                "Turn the read gate on",
+	"read.blocked_paths":          "Path substrings that are never read (normalized, case-insensitive)",
+	"read.blocked_extensions":     "File suffixes that are never read",
+	"read.warn_extensions":        "File suffixes that are read with a warning",
+	"read.warn_patterns":          "Path substrings that are read with a warning",
+	"read.harvest":                "Bulk reads within a window (possible data harvesting)",
+	"read.harvest.enabled":        "Count Read/Glob/Grep calls per session and flag bursts",
+	"read.harvest.window_seconds": "Sliding window for counting reads; 0 = 300",
+	"read.harvest.max_reads":      "Reads in the window before warning; 0 = 100",
+	"read.harvest.max_dirs":       "Distinct directories that, with max_reads, apply action; 0 = 20",
+	"read.harvest.action":         `"warn" or "ask" for reads spread across max_dirs directories`,
+
+	"bash":                         "Bash command gate",
+	"bash.enabled":                 "Turn the bash gate on",
+	"bash.blocked_commands":        "Command substrings that are always blocked",
+	"bash.blocked_patterns":        "Additional blocked command patterns",
+	"bash.warn_commands":           "Command substrings that run with a warning",
+	"bash.protected_branches":      "Commits/pushes targeting these branches are checked (globs allowed: release/*)",
+	"bash.protected_branch_action": `"warn" or "ask" for protected branch commits/pushes`,
+	"bash.trusted_install_hosts":   `"curl https://host/... | sh" installers allowed past the pipe-to-shell block: "host" or "host/path-prefix"; one https URL, no proxy, insecure or config-file options`,
+	"bash.safe_sudo":               `Routine sudo commands that skip the sudo warning ("systemctl status"); [] warns on every sudo`,
+
+	"write":                     "File write gate (Write, Edit, MultiEdit)",
+	"write.enabled":             "Turn the write gate on",
+	"write.blocked_paths":       "Path substrings that are never written",
+	"write.protected_files":     `Globs whose edits ask or block: "*.lock" or {"pattern", "action": "ask"|"block", "reason"}`,
+	"write.secret_patterns":     "Content patterns treated as secrets in written files",
+	"write.churn":               "Repeated edits to one file without a test run or commit of it",
+	"write.churn.threshold":     "Edits to one file before action applies; 0 = 8, -1 disables",
+	"write.churn.action":        `"warn" or "ask"`,
+	"write.churn.test_commands": `Bash command prefixes that count as a test run and reset every file's count, e.g. "go test"`,
+	"write.uncommitted":         `Writes to a tracked file with uncommitted git changes: "ask", "warn" or "off"; files this session already modified are exempt`,
+
+	"enforcer":                   "Verification chain settings",
+	"enforcer.enabled":           "Turn the chain on",
+	"enforcer.chain":             "Gates run by the chain, in order",
+	"enforcer.fail_fast":         "Stop at the first blocking gate",
+	"enforcer.parallel":          "Run independent chain gates (Aegis, Research) concurrently",
+	"enforcer.redact_inputs":     `Scrubbed from inputs saved in ~/.claude/chain: "secrets", "paths"; [] saves raw inputs`,
+	"enforcer.context_max_chars": "Character budget for injected chain TOON; -1 disables the limit",
+	"enforcer.timeout_ms":        "Deadline in ms for one chain run; gates not reached are skipped and the call asks; -1 disables",
+
+	"intent":                   "Prompt intent classification",
+	"intent.enabled":           "Turn intent classification on",
+	"intent.skill_triggers":    "Skill name to prompt keywords that suggest it",
+	"intent.research_triggers": "Prompt keywords that call for research",
+	"intent.keywords":          "Chain classifier keyword overrides by category (implement, debug, refactor, deploy, security, deletion)",
+
+	"research":                       "Research-before-code enforcement",
+	"research.enabled":               "Turn research tracking on",
+	"research.require_before_code":   "Require research before code tools; false turns every policy rule off",
+	"research.code_tools":            "Tools that count as writing code",
+	"research.research_tools":        "Tools that count as research",
+	"research.research_window":       "Recent transcript tool uses to inspect for research (0 = all)",
+	"research.bypass_patterns":       "Prompt patterns that skip the research requirement",
+	"research.todo_risk_levels":      "Intent risk levels that get a research TODO instead of a block",
+	"research.min_sources_by_intent": `Distinct fetched sites (WebFetch hosts; searches do not count) required per intent type, e.g. {"deploy": 3}`,
+	"research.policy":                `Research is required when any rule matches: {"intent": type or "*", "min_risk": low..critical, "min_complexity": simple..complex}`,
+
+	"context":                 "Hot path context tracking",
+	"context.enabled":         "Turn context tracking on",
+	"context.track_hot_paths": "Record frequently touched files",
+	"context.max_hot_files":   "Hot files kept",
+	"context.persist_to_stm":  "Persist hot paths to short-term memory",
+
+	"quality":                  "Code quality gate",
+	"quality.enabled":          "Turn the quality gate on",
+	"quality.comment":          "Free-form note",
+	"quality.check_syntax":     "Check syntax of written files",
+	"quality.check_imports":    "Check imports of written files",
+	"quality.max_file_size_kb": "Largest file the quality gate inspects",
+
+	"risk":                 "Session risk budget: findings add up and escalate later ones",
+	"risk.enabled":         "Turn the risk budget on",
+	"risk.warn_weight":     "Points per warn finding",
+	"risk.ask_weight":      "Points per ask finding",
+	"risk.block_weight":    "Points per block finding",
+	"risk.ask_threshold":   "Score at which warn becomes ask",
+	"risk.block_threshold": "Score at which warn/ask becomes block",
+
+	"ask":             "High-risk but legitimate actions that ask the user instead of allow/deny",
+	"ask.enabled":     "Turn ask policy on",
+	"ask.commands":    "Bash substrings that ask (force-push, prod deploys)",
+	"ask.risk_levels": "Intent risk levels that ask for mutating tools",
+	"ask.gates":       `Chain gates whose warn becomes ask (e.g. "CEO")`,
+
+	"recommendations":           "Escalation of Aegis recommendations that recur in a session",
+	"recommendations.enabled":   "Turn recommendation escalation on",
+	"recommendations.threshold": "Occurrences per category before escalating",
+	"recommendations.action":    `"warn" or "ask" once the threshold is reached`,
+
+	"packages":         "Package-manager installs of untrusted packages",
+	"packages.enabled": "Turn the package check on",
+	"packages.action":  `"warn", "ask" or "block"`,
+	"packages.trusted": `Trusted packages: names, "prefix*" or "manager:name"`,
+
+	"git":                              "Commit compliance policy checked by the Bash gate",
+	"git.author_pattern":               `Regexp over "Name <email>"; "" skips`,
+	"git.require_signing":              "Commits must be GPG/SSH signed",
+	"git.action":                       `"warn" or "block" on author/signing violations`,
+	"git.conventional_commits":         "git commit -m messages must be type(scope): subject",
+	"git.conventional_commits.enabled": "Turn conventional commit checks on",
+	"git.conventional_commits.types":   "Allowed types; empty = feat, fix, docs, ...",
+	"git.conventional_commits.action":  `"warn" or "block"`,
+
+	"redaction":            "Secret scrubbing for chain state, the audit log and kavach.log",
+	"redaction.disabled":   "Persist and log raw values",
+	"redaction.hash_paths": "Replace path directories with a short hash",
+
+	"dag":                  "DAG dispatch directives",
+	"dag.max_parallel":     "Tasks per dispatch sub-batch; 0 = unlimited",
+	"dag.max_nodes":        "Nodes a scheduled DAG may hold; 0 = 1000",
+	"dag.event_url":        "POST node events here as JSON; empty = off",
+	"dag.event_timeout_ms": "Per-event delivery timeout; 0 = 2s",
+
+	"onboarding":           "Settings that ease kavach in for new users",
+	"onboarding.soft_mode": "Explain blocks and warnings with an example of how to proceed",
+	"onboarding.advisory":  "Run every gate but never block or ask: decisions become allow with their reasoning as context (also KAVACH_ADVISORY=1)",
+
+	"endpoints":          "Hardcoded localhost/IPs and developer paths in deployment config files",
+	"endpoints.enabled":  "Turn the endpoint check on",
+	"endpoints.action":   `"warn", "ask" or "block"`,
+	"endpoints.files":    `Base-name globs, or path substrings containing "/"`,
+	"endpoints.patterns": "Regexps matched per line",
+
+	"security_evasion":             "Bash commands that disable logging, history, error checking, linters or security tooling",
+	"security_evasion.enabled":     "Turn the evasion check on",
+	"security_evasion.action":      `"warn", "ask" or "block"`,
+	"security_evasion.patterns":    "Regexps over the lowercased, quote-stripped command",
+	"security_evasion.min_signals": "Distinct patterns that must match in one command before it is flagged (default 2)",
+
+	"deploy_window":          "Deploys outside allowed days and hours, or inside a freeze, ask or block with the next allowed time",
+	"deploy_window.enabled":  "Turn the deploy window check on",
+	"deploy_window.action":   `"ask" or "block"`,
+	"deploy_window.timezone": `IANA zone for days, hours and date-only freezes, e.g. "Asia/Kolkata"; "" = local`,
+	"deploy_window.days":     `Allowed days, "mon".."sun"; empty = every day`,
+	"deploy_window.hours":    `Allowed hours, "09:00-17:00"`,
+	"deploy_window.commands": "Command substrings treated as deploys; the intent does not matter",
+	"deploy_window.freezes":  `No-deploy windows: [{"from": "2026-12-24", "to": "2026-12-27", "reason": "holidays"}]; RFC 3339 times or whole dates`,
+
+	"aegis":                 "What an Aegis finding does",
+	"aegis.sensitive_paths": `Extra sensitive path substrings by category, added to the built-ins: {"credential": ["/.vault-token"], "history": [...], "config": [...]}`,
+	"aegis.max_findings":    `Violations and recommendations listed in a block reason before the rest become "+N more"; the saved chain state keeps all`,
+	"aegis.actions":         `Per category (dangerous_command, pipe_install, env_exfil, sensitive_path, code_removal, ask_command; globs such as "*" allowed, exact key then longest glob wins): "deny", "ask" or "modify" (rewrite to a safer command)`,
+
+	"subagent":           "Recursive delegation limits",
+	"subagent.max_depth": "Deepest subagent nesting allowed: a subagent's spawner chain, main agent = 0; 0 = default (3), -1 disables",
+
+	"advisories":          "Warn-once suppression of noisy advisories that recur within a session",
+	"advisories.repeat":   "Emit every advisory each time, ignoring advisories.once",
+	"advisories.once":     `Categories shown only the first time per session, e.g. "large_file", "hardcoded_localhost"; all others show every time`,
+	"advisories.always":   `Categories never suppressed even if listed in once, e.g. "sudo_detected", "protected_branch"`,
+	"failure":             "Retry loop breaking for tool calls that keep failing",
+	"failure.retry_limit": "Consecutive failures of the same call before the failure gate escalates and the chain asks; 0 = 3, -1 disables",
+	"mcp":                 "Argument validation for MCP tool calls",
+	"mcp.action":          `"block" or "warn" when arguments do not match the tool's schema`,
+	"mcp.schemas":         `JSON Schema per tool name or glob ("mcp__github__*"); the exact name wins, else the longest glob`,
+
+	"learning":       "Learning periods for new rules: matches are audited and warned, not enforced, until the end date",
+	"learning.rules": `End of learning per rule, rule list or gate, e.g. {"bash.blocked_commands:terraform destroy": "2026-11-30", "READ": "2026-11-15T09:00:00Z"}; dates learn through that day`,
+
+	"tasks":                       "Minimum quality of TaskCreate subjects and descriptions; empty fields always block",
+	"tasks.action":                `"block", "warn" or "off" for placeholder or too-short tasks`,
+	"tasks.min_subject_words":     "Words a subject needs; 0 = 2",
+	"tasks.min_description_words": "Words a description needs; 0 = 3",
+	"tasks.placeholders":          `Words that say nothing ("todo", "task", "tbd"); a field of only these and numbers is a placeholder`,
+
+	"tool_policy": `Decision floor per tool name or glob, e.g. {"Bash": "ask", "mcp__github__*": "warn"}: allow, warn, ask or block`,
+}
+
+// GatesConfigFields lists every field of GatesConfig in declaration order
+// with its built-in default and documentation.
+func GatesConfigFields() []TemplateField {
+	defaults := reflect.ValueOf(getDefaultGatesConfig()).Elem()
+	var fields []TemplateField
+	walkTemplate(defaults, "", func(f TemplateField) { fields = append(fields, f) })
+	return fields
+}
+
+// walkTemplate visits the exported JSON fields of struct v, recursing into
+// nested structs after visiting the section itself.
+func walkTemplate(v reflect.Value, prefix string, visit func(TemplateField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := jsonName(sf)
+		if name == "" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		fv := v.Field(i)
+		if sf.Type.Kind() == reflect.Struct {
+			visit(TemplateField{Path: path, Type: "object", Doc: fieldDocs[path], Section: true})
+			walkTemplate(fv, path, visit)
+			continue
+		}
+		visit(TemplateField{Path: path, Type: typeName(sf.Type), Default: templateValue(fv), Doc: fieldDocs[path]})
+	}
+}
+
+// jsonName is the field's JSON key, or "" for unexported and "-" fields.
+func jsonName(sf reflect.StructField) string {
+	if !sf.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// typeName renders a Go type in JSON terms.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int64, reflect.Uint64:
+		return "int"
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	case reflect.Struct:
+		return "object"
+	}
+	return t.String()
+}
+
+// templateValue is v as the template shows it; nil slices and maps become
+// empty so every field is present.
+func templateValue(v reflect.Value) interface{} {
+	switch {
+	case v.Kind() == reflect.Slice && v.IsNil():
+		return []interface{}{}
+	case v.Kind() == reflect.Map && v.IsNil():
+		return map[string]interface{}{}
+	}
+	return v.Interface()
+}
+
+// GatesConfigTemplate renders the full default gates config as JSONC:
+// every field present, each preceded by a // comment with its doc.
+func GatesConfigTemplate() string {
+	var sb strings.Builder
+	sb.WriteString("// kavach gates config reference: every field with its default.\n")
+	sb.WriteString("// Copy to " + GatesConfigPath() + " and strip the comments;\n")
+	sb.WriteString("// fields left out fall back to these defaults.\n")
+	writeTemplateObject(&sb, reflect.ValueOf(getDefaultGatesConfig()).Elem(), "", 0)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// writeTemplateObject writes struct v as a commented JSON object.
+func writeTemplateObject(sb *strings.Builder, v reflect.Value, prefix string, depth int) {
+	indent := strings.Repeat("  ", depth+1)
+	t := v.Type()
+	var names []string
+	var idx []int
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			names = append(names, name)
+			idx = append(idx, i)
+		}
+	}
+
+	sb.WriteString("{\n")
+	for n, name := range names {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if doc := fieldDocs[path]; doc != "" {
+			fmt.Fprintf(sb, "%s// %s\n", indent, doc)
+		}
+		fmt.Fprintf(sb, "%s%q: ", indent, name)
+		fv := v.Field(idx[n])
+		if fv.Kind() == reflect.Struct {
+			writeTemplateObject(sb, fv, path, depth+1)
+		} else {
+			data, err := json.MarshalIndent(templateValue(fv), indent, "  ")
+			if err != nil {
+				data = []byte("null")
+			}
+			sb.Write(data)
+		}
+		if n < len(names)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(strings.Repeat("  ", depth) + "}")
+}
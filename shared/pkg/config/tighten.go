@@ -0,0 +1,170 @@
+// Package config provides dynamic configuration loading.
+// tighten.go: Project gates config layer. A .kavach/gates.json comes with
+// the repository, so cloning a hostile one must not switch kavach off: it
+// may only add blocks, enable gates and raise actions over the user config.
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/claude/shared/pkg/validate"
+)
+
+// tightenGatesConfig applies project over cfg without loosening it:
+// enabled flags only turn on, lists gain entries, actions only rise.
+// Everything that could weaken enforcement (onboarding and advisory mode,
+// allowlists such as safe_sudo and trusted hosts, thresholds, redaction)
+// is taken from the user config alone.
+func tightenGatesConfig(cfg, project *GatesConfig) {
+	enable(&cfg.Enforcer.Enabled, project.Enforcer.Enabled)
+
+	enable(&cfg.Read.Enabled, project.Read.Enabled)
+	cfg.Read.BlockedPaths = appendNew(cfg.Read.BlockedPaths, project.Read.BlockedPaths)
+	cfg.Read.BlockedExtensions = appendNew(cfg.Read.BlockedExtensions, project.Read.BlockedExtensions)
+	cfg.Read.WarnExtensions = appendNew(cfg.Read.WarnExtensions, project.Read.WarnExtensions)
+	cfg.Read.WarnPatterns = appendNew(cfg.Read.WarnPatterns, project.Read.WarnPatterns)
+	enable(&cfg.Read.Harvest.Enabled, project.Read.Harvest.Enabled)
+	raise(&cfg.Read.Harvest.Action, project.Read.Harvest.Action, "warn", "ask")
+
+	enable(&cfg.Bash.Enabled, project.Bash.Enabled)
+	cfg.Bash.BlockedCommands = appendNew(cfg.Bash.BlockedCommands, project.Bash.BlockedCommands)
+	cfg.Bash.BlockedPatterns = appendNew(cfg.Bash.BlockedPatterns, project.Bash.BlockedPatterns)
+	cfg.Bash.WarnCommands = appendNew(cfg.Bash.WarnCommands, project.Bash.WarnCommands)
+	cfg.Bash.ProtectedBranches = appendNew(cfg.Bash.ProtectedBranches, project.Bash.ProtectedBranches)
+	raise(&cfg.Bash.ProtectedBranchAction, project.Bash.ProtectedBranchAction, "warn", "ask")
+
+	enable(&cfg.Write.Enabled, project.Write.Enabled)
+	cfg.Write.BlockedPaths = appendNew(cfg.Write.BlockedPaths, project.Write.BlockedPaths)
+	cfg.Write.SecretPatterns = appendNew(cfg.Write.SecretPatterns, project.Write.SecretPatterns)
+	for _, pf := range project.Write.ProtectedFiles {
+		if !hasProtectedPattern(cfg.Write.ProtectedFiles, pf.Pattern) {
+			cfg.Write.ProtectedFiles = append(cfg.Write.ProtectedFiles, pf)
+		}
+	}
+	raise(&cfg.Write.Uncommitted, project.Write.Uncommitted, "off", "warn", "ask")
+	raise(&cfg.Write.Churn.Action, project.Write.Churn.Action, "warn", "ask")
+
+	enable(&cfg.Intent.Enabled, project.Intent.Enabled)
+	enable(&cfg.Research.Enabled, project.Research.Enabled)
+	enable(&cfg.Research.RequireBeforeCode, project.Research.RequireBeforeCode)
+	enable(&cfg.Quality.Enabled, project.Quality.Enabled)
+	enable(&cfg.Risk.Enabled, project.Risk.Enabled)
+
+	enable(&cfg.Ask.Enabled, project.Ask.Enabled)
+	cfg.Ask.Commands = appendNew(cfg.Ask.Commands, project.Ask.Commands)
+	cfg.Ask.RiskLevels = appendNew(cfg.Ask.RiskLevels, project.Ask.RiskLevels)
+	cfg.Ask.Gates = appendNew(cfg.Ask.Gates, project.Ask.Gates)
+
+	enable(&cfg.Recommend.Enabled, project.Recommend.Enabled)
+	raise(&cfg.Recommend.Action, project.Recommend.Action, "warn", "ask")
+	enable(&cfg.Packages.Enabled, project.Packages.Enabled)
+	raise(&cfg.Packages.Action, project.Packages.Action, "warn", "ask", "block")
+
+	enable(&cfg.Endpoints.Enabled, project.Endpoints.Enabled)
+	raise(&cfg.Endpoints.Action, project.Endpoints.Action, "warn", "ask", "block")
+	cfg.Endpoints.Files = appendNew(cfg.Endpoints.Files, project.Endpoints.Files)
+	cfg.Endpoints.Patterns = appendNew(cfg.Endpoints.Patterns, project.Endpoints.Patterns)
+
+	enable(&cfg.Evasion.Enabled, project.Evasion.Enabled)
+	raise(&cfg.Evasion.Action, project.Evasion.Action, "warn", "ask", "block")
+	cfg.Evasion.Patterns = appendNew(cfg.Evasion.Patterns, project.Evasion.Patterns)
+
+	enable(&cfg.Git.RequireSigning, project.Git.RequireSigning)
+	raise(&cfg.Git.Action, project.Git.Action, "warn", "block")
+	enable(&cfg.Git.Conventional.Enabled, project.Git.Conventional.Enabled)
+	raise(&cfg.Git.Conventional.Action, project.Git.Conventional.Action, "warn", "block")
+
+	enable(&cfg.Deploy.Enabled, project.Deploy.Enabled)
+	raise(&cfg.Deploy.Action, project.Deploy.Action, "ask", "block")
+	cfg.Deploy.Commands = appendNew(cfg.Deploy.Commands, project.Deploy.Commands)
+	cfg.Deploy.Freezes = append(cfg.Deploy.Freezes, project.Deploy.Freezes...)
+
+	enable(&cfg.Redaction.HashPaths, project.Redaction.HashPaths)
+	raise(&cfg.MCP.Action, project.MCP.Action, "warn", "block")
+	for key, schema := range project.MCP.Schemas {
+		if _, ok := cfg.MCP.Schemas[key]; !ok {
+			if cfg.MCP.Schemas == nil {
+				cfg.MCP.Schemas = make(map[string]*validate.Schema)
+			}
+			cfg.MCP.Schemas[key] = schema
+		}
+	}
+	raise(&cfg.Tasks.Action, project.Tasks.Action, "off", "warn", "block")
+
+	for category, paths := range project.Aegis.SensitivePaths {
+		if cfg.Aegis.SensitivePaths == nil {
+			cfg.Aegis.SensitivePaths = make(map[string][]string)
+		}
+		cfg.Aegis.SensitivePaths[category] = appendNew(cfg.Aegis.SensitivePaths[category], paths)
+	}
+	for key, action := range project.Aegis.Actions {
+		// A new key can shadow a stricter glob, so only deny is added
+		if current, ok := cfg.Aegis.Actions[key]; ok {
+			raise(&current, action, aegisLevels...)
+			cfg.Aegis.Actions[key] = current
+		} else if strings.EqualFold(action, "deny") {
+			cfg.Aegis.Actions[key] = "deny"
+		}
+	}
+
+	// Tool policy floors only escalate, so any new floor tightens. Keys
+	// match case-insensitively, as the chain reads them.
+	for tool, floor := range project.ToolPolicy {
+		key, current := tool, "allow"
+		for k, v := range cfg.ToolPolicy {
+			if strings.EqualFold(k, tool) {
+				key, current = k, v
+			}
+		}
+		if raise(&current, floor, toolPolicyLevels...) {
+			if cfg.ToolPolicy == nil {
+				cfg.ToolPolicy = make(map[string]string)
+			}
+			cfg.ToolPolicy[key] = current
+		}
+	}
+}
+
+// Ordered levels, loosest first, for the actions keyed by name.
+var (
+	aegisLevels      = []string{"modify", "ask", "deny"}
+	toolPolicyLevels = []string{"allow", "warn", "ask", "block"}
+)
+
+// enable turns dst on when the project turns it on; never off.
+func enable(dst *bool, project bool) {
+	*dst = *dst || project
+}
+
+// raise sets dst to action when action is one of levels (ordered loosest
+// first) and stricter than dst, reporting whether it did. Values a field
+// does not accept are ignored: an unknown action could read as allow.
+func raise(dst *string, action string, levels ...string) bool {
+	action = strings.ToLower(action)
+	rank := slices.Index(levels, action)
+	if rank < 0 || rank <= slices.Index(levels, strings.ToLower(*dst)) {
+		return false
+	}
+	*dst = action
+	return true
+}
+
+// appendNew appends the entries of add not already in list.
+func appendNew(list, add []string) []string {
+	for _, entry := range add {
+		if !containsCategory(list, entry) {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+func hasProtectedPattern(files []ProtectedFile, pattern string) bool {
+	for _, pf := range files {
+		if pf.Pattern == pattern {
+			return true
+		}
+	}
+	return false
+}
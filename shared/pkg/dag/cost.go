@@ -0,0 +1,79 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// cost.go: Estimated vs actual token/cost accounting per node.
+package dag
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CostSummary totals node cost fields across a DAG.
+type CostSummary struct {
+	Estimated int `json:"tokens_estimated"`
+	Used      int `json:"tokens_used"`
+	CostCents int `json:"cost_cents"`
+	Reported  int `json:"nodes_reported"` // Nodes with usage recorded
+	Nodes     int `json:"nodes"`
+}
+
+// Cost sums estimates and reported usage over all nodes.
+func (s *DAGState) Cost() CostSummary {
+	c := CostSummary{Nodes: len(s.Nodes)}
+	for _, n := range s.Nodes {
+		c.Estimated += n.TokenEstimate
+		c.Used += n.TokensUsed
+		c.CostCents += n.CostCents
+		if n.TokensUsed > 0 || n.CostCents > 0 {
+			c.Reported++
+		}
+	}
+	return c
+}
+
+// Empty reports whether no node carries an estimate or usage.
+func (c CostSummary) Empty() bool {
+	return c.Estimated == 0 && c.Used == 0 && c.CostCents == 0
+}
+
+// String renders "estimated=N used=N (reported/nodes)" plus cost when known.
+func (c CostSummary) String() string {
+	out := fmt.Sprintf("estimated=%d used=%d reported=%d/%d", c.Estimated, c.Used, c.Reported, c.Nodes)
+	if c.CostCents > 0 {
+		out += fmt.Sprintf(" cost=$%d.%02d", c.CostCents/100, c.CostCents%100)
+	}
+	return out
+}
+
+// recordUsage copies token_estimate, tokens_used and cost_cents from task
+// metadata onto the node. Values may be JSON numbers or numeric strings.
+func recordUsage(n *Node, md map[string]interface{}) {
+	if v, ok := metadataInt(md, "token_estimate"); ok {
+		n.TokenEstimate = v
+	}
+	if v, ok := metadataInt(md, "tokens_used"); ok {
+		n.TokensUsed = v
+	}
+	if v, ok := metadataInt(md, "cost_cents"); ok {
+		n.CostCents = v
+	}
+}
+
+func metadataInt(md map[string]interface{}, key string) (int, bool) {
+	switch v := md[key].(type) {
+	case float64:
+		return int(v), v >= 0
+	case string:
+		i, err := strconv.Atoi(v)
+		return i, err == nil && i >= 0
+	}
+	return 0, false
+}
+
+// completionDirective is BuildCompletionDirective plus the token totals.
+func completionDirective(state *DAGState) string {
+	out := BuildCompletionDirective(state.ID)
+	if c := state.Cost(); !c.Empty() {
+		out += "tokens: " + c.String() + "\n"
+	}
+	return out
+}
@@ -1,8 +1,19 @@
 package dag
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/claude/shared/pkg/statestore"
+	"github.com/claude/shared/pkg/util"
 )
 
 // TestTopoLevels: 5 nodes, 4 edges → verify correct parallel groups.
@@ -44,14 +55,10 @@ func TestTopoLevels(t *testing.T) {
 	if len(levels) != 3 {
 		t.Fatalf("expected 3 levels, got %d", len(levels))
 	}
-	if len(levels[0].Nodes) != 2 {
-		t.Errorf("level 0: expected 2 nodes, got %d", len(levels[0].Nodes))
-	}
-	if len(levels[1].Nodes) != 2 {
-		t.Errorf("level 1: expected 2 nodes, got %d", len(levels[1].Nodes))
-	}
-	if len(levels[2].Nodes) != 1 {
-		t.Errorf("level 2: expected 1 node, got %d", len(levels[2].Nodes))
+	for i, want := range []string{"a,b", "c,d", "e"} {
+		if got := levelIDs(levels[i]); got != want {
+			t.Errorf("level %d = %s, want %s", i, got, want)
+		}
 	}
 	if state.MaxLevel != 2 {
 		t.Errorf("expected MaxLevel=2, got %d", state.MaxLevel)
@@ -70,6 +77,51 @@ func TestCycleDetection(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected cycle error, got nil")
 	}
+	if !errors.Is(err, ErrCycle) {
+		t.Errorf("expected ErrCycle, got %v", err)
+	}
+}
+
+func TestSelfLoop(t *testing.T) {
+	state := NewDAGState("test-self", "self loop")
+	state.AddNode(&Node{ID: "a", Subject: "A"})
+
+	for _, err := range []error{
+		state.AddEdge("a", "a"),
+		state.AddConditionalEdge("a", "a", EdgeCondition{}),
+	} {
+		if !errors.Is(err, ErrSelfLoop) || !errors.Is(err, ErrCycle) {
+			t.Errorf("self edge: got %v, want ErrSelfLoop wrapping ErrCycle", err)
+		}
+	}
+	if n := state.Nodes["a"]; len(n.DependsOn) != 0 || len(n.Blocks) != 0 || n.Conditions != nil {
+		t.Errorf("rejected edge left state behind: %+v", n)
+	}
+	if err := state.AddEdge("zz", "zz"); !errors.Is(err, ErrSelfLoop) {
+		t.Errorf("unknown self edge: got %v, want ErrSelfLoop", err)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	state := NewDAGState("test-errs", "sentinel test")
+	state.AddNode(&Node{ID: "a"})
+
+	if err := state.AddNode(&Node{ID: "a"}); !errors.Is(err, ErrDuplicateNode) {
+		t.Errorf("AddNode duplicate: expected ErrDuplicateNode, got %v", err)
+	}
+	if err := state.AddEdge("a", "missing"); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("AddEdge missing: expected ErrNodeNotFound, got %v", err)
+	}
+
+	// Force a cycle past AddEdge's inline check
+	state.AddNode(&Node{ID: "b"})
+	state.Nodes["a"].Blocks = []string{"b"}
+	state.Nodes["b"].DependsOn = []string{"a"}
+	state.Nodes["b"].Blocks = []string{"a"}
+	state.Nodes["a"].DependsOn = []string{"b"}
+	if _, err := TopoLevels(state); !errors.Is(err, ErrCycle) {
+		t.Errorf("TopoLevels: expected ErrCycle, got %v", err)
+	}
 }
 
 func TestNodeStatusPropagation(t *testing.T) {
@@ -96,6 +148,54 @@ func TestNodeStatusPropagation(t *testing.T) {
 	}
 }
 
+func TestNodeStatusTransitions(t *testing.T) {
+	clock := util.NewMockClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	state := NewDAGStateAt("sess", "lifecycle", clock)
+	state.AddNode(&Node{ID: "a", Subject: "A", Status: StatusReady})
+	state.AddNode(&Node{ID: "b", Subject: "B"})
+	_ = state.AddEdge("a", "b")
+
+	for _, next := range []NodeStatus{StatusDispatched, StatusRunning, StatusDone} {
+		clock.Advance(time.Minute)
+		if err := state.UpdateNodeStatus("a", next); err != nil {
+			t.Fatalf("a -> %s: %v", next, err)
+		}
+	}
+	a := state.Nodes["a"]
+	if got := a.EnteredAt[StatusRunning]; !got.Equal(time.Date(2026, 3, 1, 9, 2, 0, 0, time.UTC)) {
+		t.Errorf("entered running at %v", got)
+	}
+	var path []string
+	for _, tr := range a.History {
+		path = append(path, string(tr.From)+">"+string(tr.To))
+	}
+	if strings.Join(path, " ") != "ready>dispatched dispatched>running running>done" {
+		t.Errorf("history = %v", path)
+	}
+	if b := state.Nodes["b"]; b.Status != StatusReady || len(b.History) != 1 || !b.EnteredAt[StatusReady].Equal(clock.Now()) {
+		t.Errorf("b = %s %+v", b.Status, b.History)
+	}
+
+	// Illegal transitions are no-ops
+	for _, next := range []NodeStatus{StatusRunning, StatusReady, StatusFailed} {
+		if err := state.UpdateNodeStatus("a", next); !errors.Is(err, ErrIllegalTransition) {
+			t.Errorf("done -> %s: err = %v", next, err)
+		}
+	}
+	if err := state.UpdateNodeStatus("b", StatusPending); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("ready -> pending: err = %v", err)
+	}
+	if a.Status != StatusDone || len(a.History) != 3 || state.Nodes["b"].Status != StatusReady {
+		t.Errorf("illegal transition changed state: a=%s (%d transitions) b=%s", a.Status, len(a.History), state.Nodes["b"].Status)
+	}
+	if err := state.UpdateNodeStatus("a", StatusDone); err != nil || len(a.History) != 3 {
+		t.Errorf("same status: err = %v, %d transitions", err, len(a.History))
+	}
+	if err := state.UpdateNodeStatus("zz", StatusDone); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("unknown node: err = %v", err)
+	}
+}
+
 func TestScheduleResearchParallel(t *testing.T) {
 	breakdown := []string{
 		"Research webhook patterns",
@@ -112,18 +212,55 @@ func TestScheduleResearchParallel(t *testing.T) {
 	}
 
 	// Research nodes should be level 0 (parallel), impl level 1, tests level 2
+	levels, err := TopoLevels(state)
+	if err != nil {
+		t.Fatal(err)
+	}
 	researchCount := 0
-	for _, n := range state.Nodes {
-		if n.Level == 0 {
+	for i, n := range levels[0].Nodes {
+		if isResearch(n.Subject) {
 			researchCount++
 		}
+		if i > 0 && levels[0].Nodes[i-1].ID > n.ID {
+			t.Errorf("level 0 not in ID order: %s", levelIDs(levels[0]))
+		}
 	}
 	if researchCount < 2 {
 		t.Errorf("expected at least 2 research nodes at level 0, got %d", researchCount)
 	}
 }
 
+func TestScheduleWithEdges(t *testing.T) {
+	nodes := []*Node{{ID: "api"}, {ID: "ui"}, {ID: "docs"}, {ID: "e2e"}}
+	edges := [][2]string{{"api", "ui"}, {"api", "e2e"}, {"ui", "e2e"}}
+
+	state, err := ScheduleWithEdges("test-edges", "ship", nodes, edges)
+	if err != nil {
+		t.Fatalf("ScheduleWithEdges: %v", err)
+	}
+	want := map[string]int{"api": 0, "docs": 0, "ui": 1, "e2e": 2}
+	for id, level := range want {
+		if got := state.Nodes[id].Level; got != level {
+			t.Errorf("%s level = %d, want %d", id, got, level)
+		}
+	}
+	if state.Nodes["docs"].Status != StatusReady || state.Nodes["ui"].Status != StatusPending {
+		t.Error("only nodes without deps should start ready")
+	}
+
+	cyclic := []*Node{{ID: "a"}, {ID: "b"}}
+	if _, err := ScheduleWithEdges("s", "p", cyclic, [][2]string{{"a", "b"}, {"b", "a"}}); !errors.Is(err, ErrCycle) {
+		t.Errorf("cycle: got %v, want ErrCycle", err)
+	}
+	if _, err := ScheduleWithEdges("s", "p", []*Node{{ID: "a"}}, [][2]string{{"a", "zz"}}); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("unknown: got %v, want ErrNodeNotFound", err)
+	}
+}
+
 func TestStatePersistence(t *testing.T) {
+	mem := statestore.NewMemoryStore()
+	defer SetStore(mem)()
+
 	sid := "test-persist-roundtrip"
 	state := NewDAGState(sid, "persist test")
 	state.AddNode(&Node{ID: "p1", Subject: "Persist node", Agent: "test"})
@@ -131,7 +268,9 @@ func TestStatePersistence(t *testing.T) {
 	if err := Save(state); err != nil {
 		t.Fatalf("Save: %v", err)
 	}
-	defer Delete(sid)
+	if _, err := mem.Load(sid); err != nil {
+		t.Fatalf("state not in the injected store: %v", err)
+	}
 
 	loaded, err := Load(sid)
 	if err != nil {
@@ -148,6 +287,71 @@ func TestStatePersistence(t *testing.T) {
 	}
 }
 
+func TestStateMigration(t *testing.T) {
+	legacy := `{"id":"kv-old","session_id":"s","nodes":{"a":{"subject":"A"},"b":{"id":"b","status":"done"}}}`
+	state, err := decodeState([]byte(legacy))
+	if err != nil {
+		t.Fatalf("decode v0: %v", err)
+	}
+	if state.SchemaVersion != StateSchemaVersion || state.Status != DAGActive {
+		t.Errorf("migrated = v%d %s, want v%d active", state.SchemaVersion, state.Status, StateSchemaVersion)
+	}
+	if a := state.Nodes["a"]; a.ID != "a" || a.Status != StatusPending {
+		t.Errorf("node a = %+v, want id a pending", a)
+	}
+	if state.Nodes["b"].Status != StatusDone {
+		t.Errorf("node b status = %s, want done kept", state.Nodes["b"].Status)
+	}
+
+	if state, err := decodeState([]byte(`{"id":"kv-empty"}`)); err != nil || state.Nodes == nil {
+		t.Errorf("empty v0 = %+v, %v; want non-nil nodes", state, err)
+	}
+
+	future := fmt.Sprintf(`{"schema_version":%d,"id":"kv-new"}`, StateSchemaVersion+1)
+	if _, err := decodeState([]byte(future)); !errors.Is(err, ErrUnknownSchema) {
+		t.Errorf("future version: got %v, want ErrUnknownSchema", err)
+	}
+
+	data, _ := json.Marshal(NewDAGState("s", "p"))
+	if !strings.Contains(string(data), fmt.Sprintf(`"schema_version":%d`, StateSchemaVersion)) {
+		t.Errorf("new state not stamped: %s", data)
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	state := NewDAGState("s", "labels")
+	for _, id := range []string{"b", "a", "c"} {
+		state.AddNode(&Node{ID: id, Subject: id})
+	}
+	state.Nodes["a"].SetLabel("area", "payments")
+	state.Nodes["b"].SetLabel("area", "payments")
+	state.Nodes["c"].SetLabel("area", "search")
+
+	if v, ok := state.Nodes["a"].GetLabel("area"); !ok || v != "payments" {
+		t.Errorf("GetLabel = %q, %t", v, ok)
+	}
+	if _, ok := state.Nodes["a"].GetLabel("owner"); ok {
+		t.Error("unset label reported as set")
+	}
+
+	ids := func(nodes []*Node) string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.ID)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := ids(FindNodesByLabel(state, "area", "payments")); got != "a,b" {
+		t.Errorf("area=payments = %s, want a,b", got)
+	}
+	if got := ids(FindNodesByLabel(state, "area", "")); got != "a,b,c" {
+		t.Errorf("area (any) = %s, want a,b,c", got)
+	}
+	if got := FindNodesByLabel(state, "owner", "x"); got != nil {
+		t.Errorf("owner=x = %v, want none", got)
+	}
+}
+
 func TestBuildDirective(t *testing.T) {
 	state := NewDAGState("test-dir", "directive test")
 	state.AddNode(&Node{ID: "d1", Subject: "Task 1", Agent: "eng", Status: StatusReady, Level: 0})
@@ -164,6 +368,109 @@ func TestBuildDirective(t *testing.T) {
 	if !contains(directive, "count: 2") {
 		t.Error("directive missing count: 2")
 	}
+	// Stable order: golden-comparable across runs
+	if strings.Index(directive, "[TASK:d1]") > strings.Index(directive, "[TASK:d2]") {
+		t.Errorf("tasks out of ID order:\n%s", directive)
+	}
+	for i := 0; i < 20; i++ {
+		if again := BuildDirective(state); again != directive {
+			t.Fatalf("directive changed between runs:\n%s\n---\n%s", directive, again)
+		}
+	}
+}
+
+func TestListSince(t *testing.T) {
+	mem := statestore.NewMemoryStore()
+	defer SetStore(mem)()
+	for i, age := range []time.Duration{time.Hour, 48 * time.Hour} {
+		state := NewDAGState(fmt.Sprintf("sess-%d", i), "list test")
+		state.AddNode(&Node{ID: "n1", Subject: "Task", Status: StatusDone})
+		state.AddNode(&Node{ID: "n2", Subject: "Task 2", Status: StatusReady})
+		Save(state)
+		mem.SetModTime(state.SessionID, time.Now().Add(-age))
+	}
+
+	all, err := List(0)
+	if err != nil || len(all) != 2 || all[0].SessionID != "sess-0" {
+		t.Fatalf("List(all) = %+v, %v", all, err)
+	}
+	if all[0].Nodes != 2 || all[0].Done != 1 {
+		t.Errorf("summary = %+v, want 2 nodes 1 done", all[0])
+	}
+	if recent, _ := List(24 * time.Hour); len(recent) != 1 {
+		t.Errorf("List(24h) = %+v, want 1", recent)
+	}
+}
+
+type stubResolver map[string]string
+
+func (r stubResolver) ResolveAgent(name string) (string, []string, bool) {
+	model, ok := r[name]
+	return model, []string{"rust", "debug"}, ok
+}
+
+func TestDirectiveBuilderResolvesAgents(t *testing.T) {
+	state := NewDAGState("test-res", "resolver test")
+	state.AddNode(&Node{ID: "r1", Subject: "Task 1", Agent: "backend", Status: StatusReady, Level: 0})
+	state.AddNode(&Node{ID: "r2", Subject: "Task 2", Agent: "unknown", Status: StatusReady, Level: 0})
+
+	directive := NewDirectiveBuilder(stubResolver{"backend": "sonnet"}).Directive(state)
+	if !contains(directive, "model: sonnet\nskills: rust, debug\n") {
+		t.Errorf("directive missing resolved model/skills:\n%s", directive)
+	}
+	if n := strings.Count(directive, "model:"); n != 1 {
+		t.Errorf("expected model only for the resolved agent, got %d", n)
+	}
+
+	// nil resolver degrades to plain dispatch
+	if plain := NewDirectiveBuilder(nil).Directive(state); contains(plain, "model:") {
+		t.Error("nil resolver should not emit model hints")
+	}
+}
+
+func TestDecomposeWithSkills(t *testing.T) {
+	nodes, err := DecomposeWithSkills(
+		[]string{"Research the API", "Implement handler", "Write tests"},
+		[]string{"research-director", "backend", ""},
+		[]string{"", "rust", ""},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes[1].Agent != "backend" || nodes[1].Skill != "rust" {
+		t.Errorf("node 1 = %s/%s, want backend/rust", nodes[1].Agent, nodes[1].Skill)
+	}
+	if nodes[2].Agent != "backend" {
+		t.Errorf("empty agent should keep the pool assignment, got %q", nodes[2].Agent)
+	}
+
+	InferSkills(nodes, stubResolver{"research-director": "opus"})
+	if nodes[0].Skill != "rust" || nodes[1].Skill != "rust" || nodes[2].Skill != "" {
+		t.Errorf("skills = %q, %q, %q; want inferred only for the known agent",
+			nodes[0].Skill, nodes[1].Skill, nodes[2].Skill)
+	}
+
+	state, err := Schedule("test-skills", "skills", nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := BuildDirective(state); !contains(d, "skill: rust\n") {
+		t.Errorf("plain dispatch should carry node skills:\n%s", d)
+	}
+
+	_, err = DecomposeWithSkills([]string{"a", "b"}, []string{"x", "y"}, []string{"s"})
+	if !errors.Is(err, ErrLengthMismatch) || !strings.Contains(err.Error(), "2 steps, 2 agents, 1 skills") {
+		t.Errorf("mismatch error = %v", err)
+	}
+}
+
+// levelIDs joins a level's node IDs in order.
+func levelIDs(level ParallelLevel) string {
+	ids := make([]string, len(level.Nodes))
+	for i, n := range level.Nodes {
+		ids[i] = n.ID
+	}
+	return strings.Join(ids, ",")
 }
 
 func contains(s, substr string) bool {
@@ -182,3 +489,602 @@ func searchString(s, sub string) bool {
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
+
+func TestAddNodeMaxNodes(t *testing.T) {
+	state := NewDAGState("test-max", "max nodes")
+	state.MaxNodes = 2
+	state.AddNode(&Node{ID: "a"})
+	state.AddNode(&Node{ID: "b"})
+	err := state.AddNode(&Node{ID: "c"})
+	if err == nil {
+		t.Fatal("expected error when exceeding MaxNodes")
+	}
+	if !errors.Is(err, ErrTooLarge) || !contains(err.Error(), "2") {
+		t.Errorf("expected ErrTooLarge including the limit: %v", err)
+	}
+}
+
+func TestScheduleMaxNodes(t *testing.T) {
+	breakdown := make([]string, DefaultMaxNodes+1)
+	for i := range breakdown {
+		breakdown[i] = fmt.Sprintf("step %d", i)
+	}
+	_, err := Schedule("test-max", "huge", Decompose(breakdown, nil))
+	if err == nil {
+		t.Fatal("expected Schedule to reject oversized DAG")
+	}
+	if !errors.Is(err, ErrTooLarge) || !contains(err.Error(), fmt.Sprint(DefaultMaxNodes)) {
+		t.Errorf("error should include the limit: %v", err)
+	}
+}
+
+func TestScheduleWithMaxNodes(t *testing.T) {
+	nodes := Decompose([]string{"step 1", "step 2", "step 3"}, nil)
+	_, err := Schedule("test-max", "small cap", nodes, WithMaxNodes(2))
+	if !errors.Is(err, ErrTooLarge) || !contains(err.Error(), "limit 2") {
+		t.Errorf("3 nodes under max 2: err = %v, want ErrTooLarge with the limit", err)
+	}
+	state, err := ScheduleWithEdges("test-max", "small cap", nodes, nil, WithMaxNodes(3))
+	if err != nil {
+		t.Fatalf("3 nodes under max 3: %v", err)
+	}
+	if state.MaxNodes != 3 {
+		t.Errorf("MaxNodes = %d, want 3", state.MaxNodes)
+	}
+	if state, err := Schedule("test-max", "default", nodes, WithMaxNodes(0)); err != nil || state.MaxNodes != DefaultMaxNodes {
+		t.Errorf("WithMaxNodes(0): MaxNodes = %v, %v; want the default", state, err)
+	}
+}
+
+func TestHandleTaskResultRoundTrip(t *testing.T) {
+	newState := func() *DAGState {
+		state := NewDAGState("test-rt", "roundtrip")
+		state.AddNode(&Node{ID: "a", Subject: "Research A", Agent: "eng", Status: StatusDispatched})
+		state.AddNode(&Node{ID: "b", Subject: "Research B", Agent: "eng", Status: StatusDispatched})
+		return state
+	}
+	b := NewDirectiveBuilder(nil)
+
+	// TaskCreate result binds the returned ID to the node named in metadata
+	state := newState()
+	create := map[string]interface{}{"subject": "Research A", "metadata": map[string]interface{}{"dag_node_id": "a"}}
+	b.HandleTaskResult(state, "TaskCreate", create, map[string]interface{}{"task": map[string]interface{}{"id": "7"}})
+	if state.Nodes["a"].TaskID != "7" {
+		t.Fatalf("TaskID = %q, want 7", state.Nodes["a"].TaskID)
+	}
+	_, _, _, drift := b.HandleTaskResult(state, "TaskUpdate", map[string]interface{}{"taskId": "7", "status": "completed"}, nil)
+	if drift != nil || state.Nodes["a"].Status != StatusDone {
+		t.Errorf("bound completion: status=%s drift=%v", state.Nodes["a"].Status, drift)
+	}
+	// A late in_progress for the finished task is rejected, not applied
+	b.HandleTaskEvent(state, "TaskUpdate", map[string]interface{}{"taskId": "7", "status": "in_progress"})
+	if state.Nodes["a"].Status != StatusDone {
+		t.Errorf("stale event: status=%s, want done", state.Nodes["a"].Status)
+	}
+
+	// dag_node_id carried in the response metadata matches without a prior bind
+	state = newState()
+	resp := map[string]interface{}{"metadata": map[string]interface{}{"dag_node_id": "b"}}
+	_, _, _, drift = b.HandleTaskResult(state, "TaskUpdate", map[string]interface{}{"taskId": "9", "status": "completed"}, resp)
+	if drift != nil || state.Nodes["b"].TaskID != "9" || state.Nodes["b"].Status != StatusDone {
+		t.Errorf("response metadata: node=%+v drift=%v", state.Nodes["b"], drift)
+	}
+
+	// Completion without dag_node_id while nodes wait is drift, even when
+	// the subject fallback finds the node
+	state = newState()
+	_, _, _, drift = b.HandleTaskResult(state, "TaskUpdate", map[string]interface{}{"taskId": "3", "status": "completed", "subject": "Research A"}, nil)
+	if drift == nil || drift.Matched != "a" || strings.Join(drift.Waiting, ",") != "b" {
+		t.Fatalf("drift = %+v, want matched a, waiting b", drift)
+	}
+	if !strings.Contains(drift.String(), "without dag_node_id") {
+		t.Errorf("drift.String() = %q", drift.String())
+	}
+
+	// Tasks outside the DAG are not drift once nothing is waiting
+	state = newState()
+	state.Nodes["a"].TaskID, state.Nodes["b"].TaskID = "1", "2"
+	if _, _, _, drift = b.HandleTaskResult(state, "TaskUpdate", map[string]interface{}{"taskId": "99", "status": "completed"}, nil); drift != nil {
+		t.Errorf("unrelated task: drift = %+v, want nil", drift)
+	}
+}
+
+func TestNewDAGStateAtReproducible(t *testing.T) {
+	clock := util.NewMockClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	a := NewDAGStateAt("s", "build feature", clock)
+	b := NewDAGStateAt("s", "build feature", clock)
+	if a.ID != b.ID {
+		t.Errorf("frozen clock IDs differ: %s vs %s", a.ID, b.ID)
+	}
+	clock.Advance(time.Nanosecond)
+	if c := NewDAGStateAt("s", "build feature", clock); c.ID == a.ID {
+		t.Errorf("advanced clock reused ID %s", c.ID)
+	}
+}
+
+func TestDecomposeAtReproducible(t *testing.T) {
+	clock := util.NewMockClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	steps := []string{"Implement handler", "Implement handler", "Research API"}
+	a := DecomposeAt(steps, nil, clock)
+	b, err := DecomposeWithSkillsAt(steps, []string{"", "", ""}, []string{"", "", ""}, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			t.Errorf("step %d: frozen clock IDs differ: %s vs %s", i, a[i].ID, b[i].ID)
+		}
+		if seen[a[i].ID] {
+			t.Errorf("step %d: duplicate ID %s", i, a[i].ID)
+		}
+		seen[a[i].ID] = true
+	}
+	clock.Advance(time.Nanosecond)
+	if c := DecomposeAt(steps, nil, clock); c[0].ID == a[0].ID {
+		t.Errorf("advanced clock reused ID %s", c[0].ID)
+	}
+}
+
+func TestNodeCostAccounting(t *testing.T) {
+	nodes := []*Node{{ID: "a", Subject: "A", TokenEstimate: 1000}, {ID: "b", Subject: "B", TokenEstimate: 500}}
+	state, err := ScheduleWithEdges("test-cost", "cost", nodes, [][2]string{{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := BuildDirective(state); !strings.Contains(d, "token_estimate: 1000") || !strings.Contains(d, "tokens_used") {
+		t.Errorf("dispatch missing cost hints:\n%s", d)
+	}
+
+	b := NewDirectiveBuilder(nil)
+	done := func(taskID, nodeID string, md map[string]interface{}) string {
+		md["dag_node_id"] = nodeID
+		_, _, directive, _ := b.HandleTaskResult(state, "TaskUpdate",
+			map[string]interface{}{"taskId": taskID, "status": "completed", "metadata": md}, nil)
+		return directive
+	}
+	done("1", "a", map[string]interface{}{"tokens_used": float64(1200), "cost_cents": "35"})
+	directive := done("2", "b", map[string]interface{}{"tokens_used": "400"})
+
+	c := state.Cost()
+	if c.Estimated != 1500 || c.Used != 1600 || c.CostCents != 35 || c.Reported != 2 {
+		t.Errorf("Cost() = %+v", c)
+	}
+	if !strings.Contains(directive, "tokens: estimated=1500 used=1600 reported=2/2 cost=$0.35") {
+		t.Errorf("completion directive missing totals:\n%s", directive)
+	}
+}
+
+func TestParallelDispatchMaxParallel(t *testing.T) {
+	level := ParallelLevel{Level: 0}
+	for i := 0; i < 20; i++ {
+		level.Nodes = append(level.Nodes, &Node{ID: fmt.Sprintf("n%02d", i), Subject: "task", Agent: "eng"})
+	}
+
+	out := BuildParallelDispatch("dag-wide", level, 1, 5)
+	if strings.Count(out, "[SUB_BATCH:") != 4 || !contains(out, "[SUB_BATCH:4/4]\ncount: 5") {
+		t.Fatalf("want 4 sub-batches of 5:\n%s", out)
+	}
+	if got := strings.Count(out, "[AFTER_SUB_BATCH]"); got != 3 {
+		t.Errorf("next sub-batch notes = %d, want 3", got)
+	}
+	if strings.Count(out, "[TASK:") != 20 || strings.Index(out, "[TASK:n04]") > strings.Index(out, "[SUB_BATCH:2/4]") {
+		t.Errorf("tasks not chunked in ID order:\n%s", out)
+	}
+
+	// Unlimited keeps the single-message dispatch
+	if plain := BuildParallelDispatch("dag-wide", level, 1, 0); contains(plain, "SUB_BATCH") || !contains(plain, "count: 20") {
+		t.Errorf("unlimited dispatch split:\n%s", plain)
+	}
+
+	// The next sub-batch waits until the dispatched one completes
+	state := NewDAGState("test-cap", "wide")
+	for _, n := range level.Nodes {
+		state.AddNode(&Node{ID: n.ID, Subject: n.Subject, Agent: n.Agent, Status: StatusReady})
+	}
+	b := NewDirectiveBuilder(nil).WithMaxParallel(5)
+	for _, id := range []string{"n00", "n01", "n02", "n03", "n04"} {
+		state.Nodes[id].Status = StatusDispatched
+	}
+	state.Nodes["n00"].Status = StatusDone
+	if d := b.Directive(state); d != "" {
+		t.Errorf("directive while the sub-batch runs:\n%s", d)
+	}
+	for _, id := range []string{"n01", "n02", "n03", "n04"} {
+		state.Nodes[id].Status = StatusDone
+	}
+	if d := b.Directive(state); !contains(d, "[SUB_BATCH:1/3]") || contains(d, "[TASK:n04]") {
+		t.Errorf("directive after the sub-batch completes:\n%s", d)
+	}
+}
+
+func TestLinkAcrossSessions(t *testing.T) {
+	defer SetStore(statestore.NewMemoryStore())()
+
+	parent, err := ScheduleWithEdges("sess-day1", "build billing", []*Node{
+		{ID: "schema", Subject: "Schema"},
+		{ID: "api", Subject: "API"},
+		{ID: "ui", Subject: "UI"},
+		{ID: "docs", Subject: "Docs"},
+	}, [][2]string{{"schema", "api"}, {"api", "ui"}, {"schema", "docs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.ProjectID = "billing"
+	parent.UpdateNodeStatus("schema", StatusDone)
+	parent.Nodes["api"].Status, parent.Nodes["api"].TaskID = StatusRunning, "12"
+	if err := Save(parent); err != nil {
+		t.Fatal(err)
+	}
+	other := NewDAGState("sess-other", "other project")
+	other.ProjectID = "unrelated"
+	other.AddNode(&Node{ID: "x"})
+	Save(other)
+
+	found, err := LatestUnfinished("billing", "sess-day2")
+	if err != nil || found == nil || found.ID != parent.ID {
+		t.Fatalf("LatestUnfinished = %v, %v; want %s", found, err, parent.ID)
+	}
+	if found, _ := LatestUnfinished("billing", "sess-day1"); found != nil {
+		t.Errorf("own session DAG returned: %s", found.ID)
+	}
+
+	linked, err := Link(found, "sess-day2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.ParentDAG != parent.ID || linked.ProjectID != "billing" || linked.SessionID != "sess-day2" {
+		t.Errorf("link fields: %+v", linked)
+	}
+	if _, ok := linked.Nodes["schema"]; ok || len(linked.Nodes) != 3 {
+		t.Fatalf("carried nodes = %v, want api, ui, docs", sortedIDs(linked))
+	}
+	api := linked.Nodes["api"]
+	if api.TaskID != "" || api.Status != StatusReady || len(api.DependsOn) != 0 {
+		t.Errorf("api = %+v, want ready with no deps or task", api)
+	}
+	if ui := linked.Nodes["ui"]; ui.Status != StatusPending || strings.Join(ui.DependsOn, ",") != "api" {
+		t.Errorf("ui = %+v, want pending on api", ui)
+	}
+	if parent.Nodes["api"].TaskID != "12" {
+		t.Error("Link mutated the parent DAG's nodes")
+	}
+
+	// The parent is saved superseded and is not resumed again
+	saved, err := Load("sess-day1")
+	if err != nil || saved.Status != DAGSuperseded {
+		t.Fatalf("parent after Link = %v, %v; want superseded", saved, err)
+	}
+	if found, _ := LatestUnfinished("billing", "sess-day3"); found != nil {
+		t.Errorf("superseded DAG resumed again: %s", found.ID)
+	}
+}
+
+type recordSink struct{ events []NodeEvent }
+
+func (r *recordSink) EmitEvent(e NodeEvent) { r.events = append(r.events, e) }
+
+func TestEventSink(t *testing.T) {
+	rec := &recordSink{}
+	SetEventSink(rec)
+	defer SetEventSink(nil)
+
+	state, err := ScheduleWithEdges("sess", "ship", []*Node{
+		{ID: "a", Subject: "A"}, {ID: "b", Subject: "B"}, {ID: "c", Subject: "C"},
+	}, [][2]string{{"a", "b"}, {"b", "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.UpdateNodeStatus("a", StatusFailed)
+
+	var got []string
+	for _, e := range rec.events {
+		got = append(got, fmt.Sprintf("%s:%s:%s>%s", e.Type, e.NodeID, e.Previous, e.Status))
+	}
+	want := "node_added:a:>pending node_added:b:>pending node_added:c:>pending " +
+		"status_changed:a:pending>ready status_changed:a:ready>failed " +
+		"status_changed:b:pending>skipped status_changed:c:pending>skipped"
+	if strings.Join(got, " ") != want {
+		t.Errorf("events =\n%s\nwant\n%s", strings.Join(got, " "), want)
+	}
+	if last := rec.events[len(rec.events)-1]; last.DAGStatus != DAGFailed || last.DAGID != state.ID {
+		t.Errorf("last event = %+v, want dag %s failed", last, state.ID)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var mu sync.Mutex
+	var received []NodeEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e NodeEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err == nil {
+			mu.Lock()
+			received = append(received, e)
+			mu.Unlock()
+		}
+	}))
+	defer srv.Close()
+
+	SetEventSink(NewHTTPSink(srv.URL, time.Second))
+	defer SetEventSink(nil)
+	state := NewDAGState("sess", "ship")
+	state.AddNode(&Node{ID: "a", Subject: "A", Status: StatusReady})
+	state.UpdateNodeStatus("a", StatusDone)
+	FlushEvents()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 {
+		t.Fatalf("received %d events, want 2", len(received))
+	}
+
+	// An unreachable endpoint must not block past the timeout
+	SetEventSink(NewHTTPSink("http://127.0.0.1:1", 100*time.Millisecond))
+	start := time.Now()
+	state.UpdateNodeStatus("a", StatusDone)
+	FlushEvents()
+	if time.Since(start) > time.Second {
+		t.Error("HTTP sink blocked past its timeout")
+	}
+}
+
+func TestConditionalEdges(t *testing.T) {
+	build := func() *DAGState {
+		t.Helper()
+		state := NewDAGState("sess", "ci")
+		for _, id := range []string{"test", "fix", "report", "deploy"} {
+			state.AddNode(&Node{ID: id, Subject: id})
+		}
+		if err := state.AddConditionalEdge("test", "fix", EdgeCondition{Status: StatusFailed}); err != nil {
+			t.Fatal(err)
+		}
+		state.AddConditionalEdge("fix", "report", EdgeCondition{}) // optional
+		state.AddEdge("test", "deploy")
+		if _, err := finishSchedule(state); err != nil {
+			t.Fatal(err)
+		}
+		return state
+	}
+	statuses := func(s *DAGState) string {
+		var out []string
+		for _, id := range []string{"fix", "report", "deploy"} {
+			out = append(out, id+"="+string(s.Nodes[id].Status))
+		}
+		return strings.Join(out, " ")
+	}
+
+	failed := build()
+	failed.UpdateNodeStatus("test", StatusFailed)
+	if got := statuses(failed); got != "fix=ready report=pending deploy=skipped" {
+		t.Errorf("after test failed: %s", got)
+	}
+	failed.UpdateNodeStatus("fix", StatusDone)
+	if got := failed.Nodes["report"].Status; got != StatusReady {
+		t.Errorf("report = %s, want ready", got)
+	}
+
+	passed := build()
+	passed.UpdateNodeStatus("test", StatusDone)
+	if got := statuses(passed); got != "fix=skipped report=ready deploy=ready" {
+		t.Errorf("after test passed: %s", got)
+	}
+
+	// Result predicate reported through task metadata
+	byResult := build()
+	byResult.Nodes["fix"].Conditions["test"] = EdgeCondition{Result: "flaky"}
+	byResult.Nodes["test"].TaskID = "1"
+	HandleTaskEvent(byResult, "TaskUpdate", map[string]interface{}{
+		"taskId": "1", "status": "completed",
+		"metadata": map[string]interface{}{"dag_result": "flaky"},
+	})
+	if got := byResult.Nodes["fix"].Status; got != StatusReady {
+		t.Errorf("fix = %s, want ready on result flaky", got)
+	}
+
+	// A completed task reported failed fails its node
+	byTask := build()
+	byTask.Nodes["test"].TaskID = "1"
+	HandleTaskEvent(byTask, "TaskUpdate", map[string]interface{}{
+		"taskId": "1", "status": "completed",
+		"metadata": map[string]interface{}{"dag_status": "failed"},
+	})
+	if got := byTask.Nodes["test"].Status; got != StatusFailed {
+		t.Errorf("test = %s, want failed", got)
+	}
+	if got := statuses(byTask); got != "fix=ready report=pending deploy=skipped" {
+		t.Errorf("after failed TaskUpdate: %s", got)
+	}
+}
+
+func TestAnyOfEdges(t *testing.T) {
+	defer SetStore(statestore.NewMemoryStore())()
+	build := func() *DAGState {
+		t.Helper()
+		state := NewDAGState("sess", "draft")
+		for _, id := range []string{"docs", "web", "papers", "outline", "draft", "publish"} {
+			state.AddNode(&Node{ID: id, Subject: id})
+		}
+		for _, src := range []string{"docs", "web", "papers"} {
+			if err := state.AddAnyOfEdge(src, "draft", "research"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		state.AddEdge("outline", "draft")
+		state.AddEdge("draft", "publish")
+		if _, err := finishSchedule(state); err != nil {
+			t.Fatal(err)
+		}
+		return state
+	}
+
+	// One source done is enough once the AND-dep is done too
+	state := build()
+	state.UpdateNodeStatus("web", StatusDone)
+	if got := state.Nodes["draft"].Status; got != StatusPending {
+		t.Errorf("draft = %s before outline done, want pending", got)
+	}
+	state.UpdateNodeStatus("outline", StatusDone)
+	if got := state.Nodes["draft"].Status; got != StatusReady {
+		t.Fatalf("draft = %s, want ready", got)
+	}
+	state.UpdateNodeStatus("docs", StatusFailed)
+	if got := state.Nodes["draft"].Status; got != StatusReady {
+		t.Errorf("draft = %s after a second source failed, want still ready", got)
+	}
+
+	// Skip propagates only when every member of the group is out
+	state = build()
+	state.UpdateNodeStatus("outline", StatusDone)
+	state.UpdateNodeStatus("docs", StatusFailed)
+	state.UpdateNodeStatus("web", StatusSkipped)
+	if got := state.Nodes["draft"].Status; got != StatusPending {
+		t.Errorf("draft = %s with papers still open, want pending", got)
+	}
+	state.UpdateNodeStatus("papers", StatusRunning)
+	state.UpdateNodeStatus("papers", StatusFailed)
+	if got := state.Nodes["draft"].Status + "/" + state.Nodes["publish"].Status; got != "skipped/skipped" {
+		t.Errorf("draft/publish = %s, want skipped/skipped", got)
+	}
+
+	// A failed AND-dep still skips regardless of the group
+	state = build()
+	state.UpdateNodeStatus("docs", StatusDone)
+	state.UpdateNodeStatus("outline", StatusFailed)
+	if got := state.Nodes["draft"].Status; got != StatusSkipped {
+		t.Errorf("draft = %s after outline failed, want skipped", got)
+	}
+
+	if err := build().AddAnyOfEdge("outline", "publish", ""); err == nil {
+		t.Error("empty group accepted")
+	}
+
+	// Link drops satisfied groups and keeps open ones
+	parent := build()
+	parent.UpdateNodeStatus("docs", StatusDone)
+	child, err := Link(parent, "sess2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if draft := child.Nodes["draft"]; len(draft.AnyOf) != 0 || len(draft.DependsOn) != 1 {
+		t.Errorf("linked draft deps = %v any_of = %v, want outline only", draft.DependsOn, draft.AnyOf)
+	}
+	child, err = Link(build(), "sess3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(child.Nodes["draft"].AnyOf); got != 3 {
+		t.Errorf("linked open group has %d members, want 3", got)
+	}
+}
+
+func TestToAdjacency(t *testing.T) {
+	state, err := ScheduleWithEdges("sess", "ship", []*Node{
+		{ID: "c", Subject: "Deploy", Agent: "devops"},
+		{ID: "a", Subject: "Test", Agent: "qa"},
+		{ID: "b", Subject: "Build", Agent: "backend", Metadata: map[string]string{"dag_node_id": "b"}},
+		{ID: "fix", Subject: "Fix", Agent: "backend"},
+	}, [][2]string{{"b", "c"}, {"a", "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.AddConditionalEdge("a", "fix", EdgeCondition{Status: StatusFailed})
+
+	adj, err := ToAdjacency(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adj.SchemaVersion != AdjacencySchemaVersion || adj.ID != state.ID {
+		t.Errorf("header = %d %s", adj.SchemaVersion, adj.ID)
+	}
+	var edges []string
+	for _, e := range adj.Edges {
+		label := e.From + ">" + e.To
+		if e.Condition != nil {
+			label += "?" + string(e.Condition.Status)
+		}
+		edges = append(edges, label)
+	}
+	if got := strings.Join(edges, " "); got != "a>c a>fix?failed b>c" {
+		t.Errorf("edges = %s", got)
+	}
+	if got := fmt.Sprint(adj.Levels); got != "[[a b] [c fix]]" {
+		t.Errorf("levels = %s", got)
+	}
+	if adj.Nodes[0].ID != "a" || adj.Nodes[1].Metadata["dag_node_id"] != "b" {
+		t.Errorf("nodes = %+v", adj.Nodes)
+	}
+
+	first, _ := json.Marshal(adj)
+	again, _ := ToAdjacency(state)
+	second, _ := json.Marshal(again)
+	if string(first) != string(second) {
+		t.Error("export is not stable")
+	}
+	for _, key := range []string{`"schema_version":1`, `"nodes":[`, `"edges":[`, `"levels":[`, `"condition":{"status":"failed"}`} {
+		if !strings.Contains(string(first), key) {
+			t.Errorf("JSON missing %s", key)
+		}
+	}
+}
+
+func TestReplan(t *testing.T) {
+	state, err := ScheduleWithEdges("sess", "ship billing", []*Node{
+		{ID: "schema", Subject: "Schema", Agent: "backend-engineer"},
+		{ID: "api", Subject: "API", Description: "Build the API", Agent: "backend-engineer"},
+		{ID: "ui", Subject: "UI", Description: "Build the UI", Agent: "frontend-engineer"},
+		{ID: "docs", Subject: "Docs", Agent: "general-purpose"},
+		{ID: "notify", Subject: "Notify", Agent: "general-purpose"},
+	}, [][2]string{{"schema", "api"}, {"api", "ui"}, {"schema", "docs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.AddConditionalEdge("ui", "notify", EdgeCondition{}); err != nil {
+		t.Fatal(err)
+	}
+	state.UpdateNodeStatus("schema", StatusDone)
+	state.UpdateNodeStatus("docs", StatusDone)
+	state.UpdateNodeStatus("api", StatusFailed)
+	if state.Nodes["ui"].Status != StatusSkipped || state.Nodes["notify"].Status != StatusReady {
+		t.Fatalf("setup: ui=%s notify=%s", state.Nodes["ui"].Status, state.Nodes["notify"].Status)
+	}
+	// Hold notify back so the replan has a pending dependent to rewire
+	state.Nodes["notify"].Status = StatusPending
+
+	results, err := Replan(state, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || strings.Join(results[0].Removed, ",") != "api,ui" || len(results[0].Added) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+	first, second := results[0].Added[0], results[0].Added[1]
+	if _, ok := state.Nodes["api"]; ok || state.Nodes["ui"] != nil {
+		t.Error("failed subtree still present")
+	}
+	if first.Subject != "Build the API" || first.Status != StatusReady || strings.Join(first.DependsOn, ",") != "schema" {
+		t.Errorf("first = %+v", first)
+	}
+	if v, _ := first.GetLabel("replan_of"); v != "api" || first.Agent != "backend-engineer" {
+		t.Errorf("first label = %q agent = %q", v, first.Agent)
+	}
+	if second.Status != StatusPending || strings.Join(second.DependsOn, ",") != first.ID {
+		t.Errorf("second = %+v", second)
+	}
+	notify := state.Nodes["notify"]
+	if strings.Join(notify.DependsOn, ",") != second.ID {
+		t.Errorf("notify deps = %v", notify.DependsOn)
+	}
+	if _, ok := notify.Conditions[second.ID]; !ok {
+		t.Error("notify lost its conditional edge")
+	}
+	if state.Status != DAGActive || state.Nodes["schema"].Status != StatusDone || strings.Contains(strings.Join(state.Nodes["schema"].Blocks, ","), "api") {
+		t.Errorf("status = %s schema = %+v", state.Status, state.Nodes["schema"])
+	}
+	if _, err := TopoLevels(state); err != nil || second.Level != 2 {
+		t.Errorf("levels: %v, second at L%d", err, second.Level)
+	}
+
+	if _, err := Replan(state, nil); err == nil {
+		t.Error("replan without failures succeeded")
+	}
+}
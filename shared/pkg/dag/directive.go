@@ -2,27 +2,129 @@
 // directive.go: Builds TOON directives that instruct Claude to create tasks in parallel.
 package dag
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-// BuildParallelDispatch generates a TOON directive for one parallel level.
-func BuildParallelDispatch(dagID string, level ParallelLevel, maxLevel int) string {
+// AgentResolver looks up per-agent model and skill hints (e.g. the agentic
+// DynamicLoader). ok is false when the agent is unknown.
+type AgentResolver interface {
+	ResolveAgent(name string) (model string, skills []string, ok bool)
+}
+
+// DirectiveBuilder builds dispatch directives, enriching each task with the
+// resolved model and skills when a resolver is provided.
+type DirectiveBuilder struct {
+	resolver    AgentResolver
+	maxParallel int // Tasks per sub-batch; 0 = unlimited
+}
+
+// NewDirectiveBuilder creates a builder. resolver may be nil, in which case
+// directives carry only the node's own agent and skill.
+func NewDirectiveBuilder(resolver AgentResolver) *DirectiveBuilder {
+	return &DirectiveBuilder{resolver: resolver}
+}
+
+// WithMaxParallel caps how many tasks are dispatched at once; wider levels
+// are split into sequential sub-batches. n <= 0 is unlimited.
+func (b *DirectiveBuilder) WithMaxParallel(n int) *DirectiveBuilder {
+	b.maxParallel = n
+	return b
+}
+
+// BuildParallelDispatch generates a TOON directive for one parallel level,
+// in sub-batches of at most maxParallel tasks (0 = unlimited).
+func BuildParallelDispatch(dagID string, level ParallelLevel, maxLevel, maxParallel int) string {
+	return NewDirectiveBuilder(nil).WithMaxParallel(maxParallel).ParallelDispatch(dagID, level, maxLevel)
+}
+
+// ParallelDispatch generates a TOON directive for one parallel level.
+func (b *DirectiveBuilder) ParallelDispatch(dagID string, level ParallelLevel, maxLevel int) string {
 	out := fmt.Sprintf("[DAG_SCHEDULER]\ndag_id: %s\nstatus: active\nlevel: %d/%d\n\n", dagID, level.Level, maxLevel)
-	out += fmt.Sprintf("[PARALLEL_DISPATCH]\ninstruction: Create ALL tasks below in a SINGLE message using parallel TaskCreate calls\ncount: %d\n\n", len(level.Nodes))
 
-	for _, n := range level.Nodes {
-		out += fmt.Sprintf("[TASK:%s]\nsubject: %s\ndescription: %s\nagent: %s\n", n.ID, n.Subject, n.Description, n.Agent)
-		if n.Skill != "" {
-			out += fmt.Sprintf("skill: %s\n", n.Skill)
+	batches := subBatches(level.Nodes, b.maxParallel)
+	if len(batches) <= 1 {
+		out += fmt.Sprintf("[PARALLEL_DISPATCH]\ninstruction: Create ALL tasks below in a SINGLE message using parallel TaskCreate calls\ncount: %d\n\n", len(level.Nodes))
+		for _, n := range level.Nodes {
+			out += b.task(n)
+		}
+	} else {
+		out += fmt.Sprintf("[PARALLEL_DISPATCH]\ninstruction: Create each sub-batch in a SINGLE message using parallel TaskCreate calls, one sub-batch at a time\ncount: %d\nmax_parallel: %d\nsub_batches: %d\n\n",
+			len(level.Nodes), b.maxParallel, len(batches))
+		for i, batch := range batches {
+			out += fmt.Sprintf("[SUB_BATCH:%d/%d]\ncount: %d\n\n", i+1, len(batches), len(batch))
+			for _, n := range batch {
+				out += b.task(n)
+			}
+			if i < len(batches)-1 {
+				out += "[AFTER_SUB_BATCH]\nAfter these complete, dispatch the next sub-batch.\n\n"
+			}
 		}
-		out += fmt.Sprintf("metadata: {\"dag_node_id\": \"%s\"}\n\n", n.ID)
 	}
 
 	if level.Level < maxLevel {
 		out += "[AFTER_LEVEL]\nWhen all tasks above complete, next level will be dispatched automatically.\n"
 	}
+	if hasEstimate(level.Nodes) {
+		out += "[COST]\ninstruction: On completion, TaskUpdate metadata {\"tokens_used\": N} (optional \"cost_cents\")\n"
+	}
 	return out
 }
 
+// task renders one TaskCreate block.
+func (b *DirectiveBuilder) task(n *Node) string {
+	out := fmt.Sprintf("[TASK:%s]\nsubject: %s\ndescription: %s\nagent: %s\n", n.ID, n.Subject, n.Description, n.Agent)
+	if n.Skill != "" {
+		out += fmt.Sprintf("skill: %s\n", n.Skill)
+	}
+	if model, skills, ok := b.resolve(n.Agent); ok {
+		if model != "" {
+			out += fmt.Sprintf("model: %s\n", model)
+		}
+		if len(skills) > 0 {
+			out += fmt.Sprintf("skills: %s\n", strings.Join(skills, ", "))
+		}
+	}
+	if n.TokenEstimate > 0 {
+		out += fmt.Sprintf("token_estimate: %d\n", n.TokenEstimate)
+	}
+	return out + fmt.Sprintf("metadata: {\"dag_node_id\": \"%s\"}\n\n", n.ID)
+}
+
+// subBatches splits nodes, in order, into chunks of at most size.
+// size <= 0 or a level that fits returns the nodes as one batch.
+func subBatches(nodes []*Node, size int) [][]*Node {
+	if size <= 0 || len(nodes) <= size {
+		return [][]*Node{nodes}
+	}
+	sorted := append([]*Node(nil), nodes...)
+	sortNodes(sorted)
+	var batches [][]*Node
+	for len(sorted) > size {
+		batches = append(batches, sorted[:size])
+		sorted = sorted[size:]
+	}
+	return append(batches, sorted)
+}
+
+func hasEstimate(nodes []*Node) bool {
+	for _, n := range nodes {
+		if n.TokenEstimate > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve degrades gracefully when no resolver is configured.
+func (b *DirectiveBuilder) resolve(agent string) (string, []string, bool) {
+	if b == nil || b.resolver == nil || agent == "" {
+		return "", nil, false
+	}
+	return b.resolver.ResolveAgent(agent)
+}
+
 // BuildCompletionDirective generates the "all done, run Aegis" directive.
 func BuildCompletionDirective(dagID string) string {
 	return fmt.Sprintf("[DAG_COMPLETE]\ndag_id: %s\nstatus: complete\naction: Run kavach orch aegis for final verification\n", dagID)
@@ -0,0 +1,29 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// errors.go: Sentinel errors; graph errors wrap these so errors.Is works.
+package dag
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrCycle is returned when an edge or ordering would form a cycle.
+	ErrCycle = errors.New("cycle detected")
+	// ErrSelfLoop is returned for an edge from a node to itself. It wraps
+	// ErrCycle, so errors.Is(err, ErrCycle) still holds.
+	ErrSelfLoop = fmt.Errorf("self-loop: %w", ErrCycle)
+	// ErrNodeNotFound is returned when an edge references an unknown node.
+	ErrNodeNotFound = errors.New("node not found")
+	// ErrDuplicateNode is returned when a node ID is already present.
+	ErrDuplicateNode = errors.New("duplicate node")
+	// ErrTooLarge is returned when the DAG would exceed MaxNodes.
+	ErrTooLarge = errors.New("dag too large")
+	// ErrUnknownSchema is returned when persisted state is from a newer kavach.
+	ErrUnknownSchema = errors.New("unknown schema version")
+	// ErrLengthMismatch is returned when per-step arrays differ in length.
+	ErrLengthMismatch = errors.New("length mismatch")
+	// ErrIllegalTransition is returned for a node status change the
+	// lifecycle does not allow.
+	ErrIllegalTransition = errors.New("illegal status transition")
+)
@@ -0,0 +1,135 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// events.go: Optional event sink so dashboards can follow DAG state changes
+// without polling the state file.
+package dag
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Event types emitted to the sink.
+const (
+	EventNodeAdded     = "node_added"
+	EventStatusChanged = "status_changed"
+)
+
+// DefaultEventTimeout bounds a single HTTP event delivery.
+const DefaultEventTimeout = 2 * time.Second
+
+// NodeEvent describes one node change.
+type NodeEvent struct {
+	Type      string     `json:"type"`
+	DAGID     string     `json:"dag_id"`
+	SessionID string     `json:"session_id"`
+	NodeID    string     `json:"node_id"`
+	Subject   string     `json:"subject"`
+	Status    NodeStatus `json:"status"`
+	Previous  NodeStatus `json:"previous,omitempty"`
+	DAGStatus DAGStatus  `json:"dag_status"`
+	Time      time.Time  `json:"time"`
+}
+
+// EventSink receives node events. Implementations must not block.
+type EventSink interface {
+	EmitEvent(NodeEvent)
+}
+
+// NopSink discards all events.
+type NopSink struct{}
+
+// EmitEvent implements EventSink.
+func (NopSink) EmitEvent(NodeEvent) {}
+
+var (
+	sinkMu sync.RWMutex
+	sink   EventSink = NopSink{}
+)
+
+// SetEventSink installs the process-wide sink; nil restores NopSink.
+func SetEventSink(s EventSink) {
+	if s == nil {
+		s = NopSink{}
+	}
+	sinkMu.Lock()
+	sink = s
+	sinkMu.Unlock()
+}
+
+// flusher is implemented by sinks that deliver asynchronously.
+type flusher interface {
+	Flush()
+}
+
+// FlushEvents waits for in-flight deliveries of the current sink. Short-lived
+// processes call it once before exiting so deliveries are not dropped.
+func FlushEvents() {
+	sinkMu.RLock()
+	s := sink
+	sinkMu.RUnlock()
+	if f, ok := s.(flusher); ok {
+		f.Flush()
+	}
+}
+
+// emit sends a node event to the current sink.
+func (s *DAGState) emit(eventType string, n *Node, previous NodeStatus) {
+	sinkMu.RLock()
+	current := sink
+	sinkMu.RUnlock()
+	if _, nop := current.(NopSink); nop {
+		return
+	}
+	current.EmitEvent(NodeEvent{
+		Type:      eventType,
+		DAGID:     s.ID,
+		SessionID: s.SessionID,
+		NodeID:    n.ID,
+		Subject:   n.Subject,
+		Status:    n.Status,
+		Previous:  previous,
+		DAGStatus: s.Status,
+		Time:      s.now(),
+	})
+}
+
+// HTTPSink POSTs each event as JSON to URL. Delivery is fire-and-forget:
+// errors are dropped and each request is bounded by the client timeout.
+type HTTPSink struct {
+	URL    string
+	client *http.Client
+	wg     sync.WaitGroup
+}
+
+// NewHTTPSink creates a sink posting to url; timeout <= 0 uses DefaultEventTimeout.
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	if timeout <= 0 {
+		timeout = DefaultEventTimeout
+	}
+	return &HTTPSink{URL: url, client: &http.Client{Timeout: timeout}}
+}
+
+// EmitEvent implements EventSink.
+func (h *HTTPSink) EmitEvent(e NodeEvent) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		resp, err := h.client.Post(h.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		resp.Body.Close()
+	}()
+}
+
+// Flush waits for pending posts; each is bounded by the client timeout.
+func (h *HTTPSink) Flush() {
+	h.wg.Wait()
+}
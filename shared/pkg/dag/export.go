@@ -0,0 +1,108 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// export.go: Adjacency export, the machine contract for handing a DAG to
+// an external runner. Fields are only ever added; a breaking change bumps
+// AdjacencySchemaVersion.
+package dag
+
+import "sort"
+
+// AdjacencySchemaVersion identifies the Adjacency layout.
+const AdjacencySchemaVersion = 1
+
+// Adjacency is a DAG as ordered node, edge and level lists.
+// Nodes are ordered by level then ID, edges by From then To, and each
+// level lists node IDs in ID order, so equal DAGs export identically.
+type Adjacency struct {
+	SchemaVersion int             `json:"schema_version"`
+	ID            string          `json:"id"`
+	SessionID     string          `json:"session_id"`
+	RootPrompt    string          `json:"root_prompt"`
+	Status        DAGStatus       `json:"status"`
+	ProjectID     string          `json:"project_id,omitempty"`
+	ParentDAG     string          `json:"parent_dag,omitempty"`
+	Nodes         []AdjacencyNode `json:"nodes"`
+	Edges         []AdjacencyEdge `json:"edges"`
+	Levels        [][]string      `json:"levels"`
+}
+
+// AdjacencyNode is one task with its scheduling metadata.
+type AdjacencyNode struct {
+	ID            string            `json:"id"`
+	Subject       string            `json:"subject"`
+	Description   string            `json:"description,omitempty"`
+	Agent         string            `json:"agent"`
+	Skill         string            `json:"skill,omitempty"`
+	Status        NodeStatus        `json:"status"`
+	Level         int               `json:"level"`
+	TaskID        string            `json:"task_id,omitempty"`
+	Result        string            `json:"result,omitempty"`
+	TokenEstimate int               `json:"token_estimate,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// AdjacencyEdge says From must finish before To starts. Condition is set
+// for conditional edges (see AddConditionalEdge), AnyOf for members of an
+// OR-group (see AddAnyOfEdge).
+type AdjacencyEdge struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Condition *EdgeCondition `json:"condition,omitempty"`
+	AnyOf     string         `json:"any_of,omitempty"`
+}
+
+// ToAdjacency exports state. Levels are recomputed, so a cyclic state
+// returns ErrCycle.
+func ToAdjacency(state *DAGState) (*Adjacency, error) {
+	levels, err := TopoLevels(state)
+	if err != nil {
+		return nil, err
+	}
+	adj := &Adjacency{
+		SchemaVersion: AdjacencySchemaVersion,
+		ID:            state.ID,
+		SessionID:     state.SessionID,
+		RootPrompt:    state.RootPrompt,
+		Status:        state.Status,
+		ProjectID:     state.ProjectID,
+		ParentDAG:     state.ParentDAG,
+		Nodes:         []AdjacencyNode{},
+		Edges:         []AdjacencyEdge{},
+		Levels:        make([][]string, len(levels)),
+	}
+	for _, n := range state.SortedNodes() {
+		adj.Nodes = append(adj.Nodes, AdjacencyNode{
+			ID:            n.ID,
+			Subject:       n.Subject,
+			Description:   n.Description,
+			Agent:         n.Agent,
+			Skill:         n.Skill,
+			Status:        n.Status,
+			Level:         n.Level,
+			TaskID:        n.TaskID,
+			Result:        n.Result,
+			TokenEstimate: n.TokenEstimate,
+			Metadata:      n.Metadata,
+		})
+		for _, dep := range n.DependsOn {
+			edge := AdjacencyEdge{From: dep, To: n.ID, AnyOf: n.AnyOf[dep]}
+			if cond, ok := n.Conditions[dep]; ok {
+				edge.Condition = &cond
+			}
+			adj.Edges = append(adj.Edges, edge)
+		}
+	}
+	sort.Slice(adj.Edges, func(i, j int) bool {
+		if adj.Edges[i].From != adj.Edges[j].From {
+			return adj.Edges[i].From < adj.Edges[j].From
+		}
+		return adj.Edges[i].To < adj.Edges[j].To
+	})
+	for i, level := range levels {
+		ids := make([]string, len(level.Nodes))
+		for j, n := range level.Nodes {
+			ids[j] = n.ID
+		}
+		adj.Levels[i] = ids
+	}
+	return adj, nil
+}
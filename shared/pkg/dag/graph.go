@@ -7,53 +7,123 @@ import (
 	"encoding/hex"
 	"fmt"
 	"time"
+
+	"github.com/claude/shared/pkg/util"
 )
 
 // NewDAGState creates a new DAG for the given session and prompt.
 func NewDAGState(sessionID, prompt string) *DAGState {
-	hash := sha256.Sum256([]byte(fmt.Sprintf("%s-%d", prompt, time.Now().UnixNano())))
+	return NewDAGStateAt(sessionID, prompt, util.SystemClock)
+}
+
+// NewDAGStateAt is NewDAGState with the ID derived from clock, so a frozen
+// clock yields a reproducible DAG ID.
+func NewDAGStateAt(sessionID, prompt string, clock util.Clock) *DAGState {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s-%d", prompt, clock.Now().UnixNano())))
 	id := "kv-" + hex.EncodeToString(hash[:])[:6]
 	return &DAGState{
-		ID:         id,
-		SessionID:  sessionID,
-		RootPrompt: prompt,
-		Nodes:      make(map[string]*Node),
-		Status:     DAGActive,
+		SchemaVersion: StateSchemaVersion,
+		ID:            id,
+		SessionID:     sessionID,
+		RootPrompt:    prompt,
+		Nodes:         make(map[string]*Node),
+		MaxNodes:      DefaultMaxNodes,
+		Status:        DAGActive,
+		clock:         clock,
+	}
+}
+
+// now returns the time transitions are stamped with.
+func (s *DAGState) now() time.Time {
+	if s.clock == nil {
+		return util.SystemClock.Now()
+	}
+	return s.clock.Now()
+}
+
+// nodeLimit returns the effective MaxNodes.
+func (s *DAGState) nodeLimit() int {
+	if s.MaxNodes > 0 {
+		return s.MaxNodes
 	}
+	return DefaultMaxNodes
 }
 
-// AddNode adds a node, returning error on duplicate ID.
+// AddNode adds a node, returning error on duplicate ID or when MaxNodes is reached.
 func (s *DAGState) AddNode(n *Node) error {
 	if _, exists := s.Nodes[n.ID]; exists {
-		return fmt.Errorf("duplicate node: %s", n.ID)
+		return fmt.Errorf("%w: %s", ErrDuplicateNode, n.ID)
+	}
+	if limit := s.nodeLimit(); len(s.Nodes) >= limit {
+		return fmt.Errorf("%w: cannot add %s, max_nodes limit is %d", ErrTooLarge, n.ID, limit)
 	}
 	if n.Status == "" {
 		n.Status = StatusPending
 	}
+	if n.EnteredAt == nil {
+		n.EnteredAt = map[NodeStatus]time.Time{n.Status: s.now()}
+	}
 	s.Nodes[n.ID] = n
+	s.emit(EventNodeAdded, n, "")
 	return nil
 }
 
 // AddEdge creates a dependency: depID must complete before nodeID starts.
-// Includes inline cycle detection via DFS.
+// Includes inline cycle detection via DFS; a self-loop is rejected first.
 func (s *DAGState) AddEdge(depID, nodeID string) error {
+	if depID == nodeID {
+		return fmt.Errorf("%w: %s", ErrSelfLoop, nodeID)
+	}
 	dep, ok := s.Nodes[depID]
 	if !ok {
-		return fmt.Errorf("node not found: %s", depID)
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, depID)
 	}
 	node, ok := s.Nodes[nodeID]
 	if !ok {
-		return fmt.Errorf("node not found: %s", nodeID)
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
 	}
 	// Cycle check: would nodeID->...->depID form a path?
 	if s.hasPath(nodeID, depID, make(map[string]bool)) {
-		return fmt.Errorf("cycle detected: %s -> %s", depID, nodeID)
+		return fmt.Errorf("%w: %s -> %s", ErrCycle, depID, nodeID)
 	}
 	node.DependsOn = append(node.DependsOn, depID)
 	dep.Blocks = append(dep.Blocks, nodeID)
 	return nil
 }
 
+// AddConditionalEdge is AddEdge for an edge that does not poison nodeID when
+// depID fails or is skipped: once depID is terminal, nodeID becomes ready if
+// cond holds and is skipped otherwise.
+func (s *DAGState) AddConditionalEdge(depID, nodeID string, cond EdgeCondition) error {
+	if err := s.AddEdge(depID, nodeID); err != nil {
+		return err
+	}
+	node := s.Nodes[nodeID]
+	if node.Conditions == nil {
+		node.Conditions = make(map[string]EdgeCondition)
+	}
+	node.Conditions[depID] = cond
+	return nil
+}
+
+// AddAnyOfEdge is AddEdge for a member of nodeID's OR-group named group:
+// nodeID needs any one member of each group done, not all of them, and is
+// skipped only once every member of a group has ended without one done.
+func (s *DAGState) AddAnyOfEdge(depID, nodeID, group string) error {
+	if group == "" {
+		return fmt.Errorf("any-of edge %s -> %s: empty group name", depID, nodeID)
+	}
+	if err := s.AddEdge(depID, nodeID); err != nil {
+		return err
+	}
+	node := s.Nodes[nodeID]
+	if node.AnyOf == nil {
+		node.AnyOf = make(map[string]string)
+	}
+	node.AnyOf[depID] = group
+	return nil
+}
+
 func (s *DAGState) hasPath(from, to string, visited map[string]bool) bool {
 	if from == to {
 		return true
@@ -74,24 +144,28 @@ func (s *DAGState) hasPath(from, to string, visited map[string]bool) bool {
 	return false
 }
 
-// UpdateNodeStatus transitions a node and propagates ready/skipped.
-func (s *DAGState) UpdateNodeStatus(id string, status NodeStatus) {
+// UpdateNodeStatus transitions a node and resolves its dependents: ready
+// once every dependency is satisfied, skipped when one cannot be.
+// The node and every propagated change are emitted once DAG status settles.
+// Moving to the current status is a no-op; an illegal transition (e.g.
+// done back to running) leaves the node unchanged and returns
+// ErrIllegalTransition.
+func (s *DAGState) UpdateNodeStatus(id string, status NodeStatus) error {
 	node, ok := s.Nodes[id]
 	if !ok {
-		return
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, id)
 	}
-	node.Status = status
-
-	if status == StatusDone {
-		// Check if blocked nodes become ready
-		for _, blockedID := range node.Blocks {
-			s.checkReady(blockedID)
-		}
+	if node.Status == status {
+		return nil
+	}
+	var changes []statusChange
+	if !s.transition(node, status, &changes) {
+		return fmt.Errorf("%w: %s %s -> %s", ErrIllegalTransition, id, node.Status, status)
 	}
-	if status == StatusFailed {
-		// Propagate skipped to all dependents
+
+	if status.IsTerminal() {
 		for _, blockedID := range node.Blocks {
-			s.propagateSkip(blockedID)
+			s.checkReady(blockedID, &changes)
 		}
 	}
 	// Update overall DAG status
@@ -104,33 +178,96 @@ func (s *DAGState) UpdateNodeStatus(id string, status NodeStatus) {
 			}
 		}
 	}
+	for _, c := range changes {
+		s.emit(EventStatusChanged, c.node, c.previous)
+	}
+	return nil
+}
+
+// transition moves node to status if the lifecycle allows it, stamping
+// the entry time and appending to the node's history.
+func (s *DAGState) transition(node *Node, status NodeStatus, changes *[]statusChange) bool {
+	if !node.Status.CanTransition(status) {
+		return false
+	}
+	at := s.now()
+	*changes = append(*changes, statusChange{node, node.Status})
+	node.History = append(node.History, Transition{From: node.Status, To: status, At: at})
+	if node.EnteredAt == nil {
+		node.EnteredAt = make(map[NodeStatus]time.Time)
+	}
+	node.EnteredAt[status] = at
+	node.Status = status
+	return true
 }
 
-func (s *DAGState) checkReady(id string) {
+// statusChange records a transition for event emission.
+type statusChange struct {
+	node     *Node
+	previous NodeStatus
+}
+
+// checkReady marks a node ready when all dependencies are satisfied and skips
+// it (and, transitively, its dependents) as soon as one cannot be. A plain
+// dependency is satisfied only by done; a conditional one by its predicate;
+// an OR-group by any one member done, and it fails only when no member can
+// still finish.
+func (s *DAGState) checkReady(id string, changes *[]statusChange) {
 	node := s.Nodes[id]
 	if node == nil || node.Status.IsTerminal() {
 		return
 	}
+	waiting := false
+	var groupMet, groupOpen map[string]bool
 	for _, depID := range node.DependsOn {
 		dep := s.Nodes[depID]
-		if dep == nil || !dep.Status.IsTerminal() {
+		if dep == nil {
 			return
 		}
-		if dep.Status != StatusDone {
-			return // dep failed/skipped
+		if group, ok := node.AnyOf[depID]; ok {
+			if groupMet == nil {
+				groupMet, groupOpen = make(map[string]bool), make(map[string]bool)
+			}
+			groupMet[group] = groupMet[group] || dep.Status == StatusDone
+			groupOpen[group] = groupOpen[group] || !dep.Status.IsTerminal()
+			continue
+		}
+		if !dep.Status.IsTerminal() {
+			waiting = true
+			continue
+		}
+		cond, conditional := node.Conditions[depID]
+		if (conditional && !cond.Met(dep)) || (!conditional && dep.Status != StatusDone) {
+			s.propagateSkip(id, changes)
+			return
 		}
 	}
-	node.Status = StatusReady
+	for group, met := range groupMet {
+		switch {
+		case met:
+		case !groupOpen[group]:
+			s.propagateSkip(id, changes)
+			return
+		default:
+			waiting = true
+		}
+	}
+	if waiting || node.Status == StatusReady {
+		return
+	}
+	s.transition(node, StatusReady, changes)
 }
 
-func (s *DAGState) propagateSkip(id string) {
+// propagateSkip skips a node and re-checks its dependents, which are
+// skipped in turn unless the node was a conditional dependency or one
+// member of an OR-group that another member can still satisfy.
+func (s *DAGState) propagateSkip(id string, changes *[]statusChange) {
 	node := s.Nodes[id]
-	if node == nil || node.Status.IsTerminal() {
+	if !s.transition(node, StatusSkipped, changes) {
 		return
 	}
-	node.Status = StatusSkipped
 	for _, blockedID := range node.Blocks {
-		s.propagateSkip(blockedID)
+		s.checkReady(blockedID, changes)
 	}
 }
 
@@ -142,9 +279,20 @@ func (s *DAGState) ReadyNodes() []*Node {
 			ready = append(ready, n)
 		}
 	}
+	sortNodes(ready)
 	return ready
 }
 
+// SortedNodes returns all nodes ordered by level, then ID.
+func (s *DAGState) SortedNodes() []*Node {
+	nodes := make([]*Node, 0, len(s.Nodes))
+	for _, n := range s.Nodes {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
 // IsComplete returns true when all nodes are in a terminal state.
 func (s *DAGState) IsComplete() bool {
 	for _, n := range s.Nodes {
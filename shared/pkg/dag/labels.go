@@ -0,0 +1,31 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// labels.go: Arbitrary key=value labels on nodes, kept in Node.Metadata,
+// so large DAGs can be filtered ("area=payments") and re-dispatched
+// selectively.
+package dag
+
+// SetLabel sets label key to value on n.
+func (n *Node) SetLabel(key, value string) {
+	if n.Metadata == nil {
+		n.Metadata = make(map[string]string)
+	}
+	n.Metadata[key] = value
+}
+
+// GetLabel returns label key of n and whether it is set.
+func (n *Node) GetLabel(key string) (string, bool) {
+	v, ok := n.Metadata[key]
+	return v, ok
+}
+
+// FindNodesByLabel returns the nodes whose label key equals value, ordered
+// by level then ID. An empty value matches every node that has the label.
+func FindNodesByLabel(state *DAGState, key, value string) []*Node {
+	var out []*Node
+	for _, n := range state.SortedNodes() {
+		if v, ok := n.GetLabel(key); ok && (value == "" || v == value) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// link.go: Cross-session DAG linking for multi-session projects.
+// A new session resumes a project's latest unfinished DAG by carrying its
+// incomplete nodes into a fresh DAG that records the old one as ParentDAG.
+package dag
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Unfinished reports whether any node has not completed successfully.
+func (s *DAGState) Unfinished() bool {
+	if s.Status == DAGSuperseded {
+		return false
+	}
+	for _, n := range s.Nodes {
+		if n.Status != StatusDone {
+			return true
+		}
+	}
+	return false
+}
+
+// LatestUnfinished returns the most recently saved unfinished DAG for
+// projectID, ignoring excludeSession (the caller's own). Returns nil, nil
+// when there is none.
+func LatestUnfinished(projectID, excludeSession string) (*DAGState, error) {
+	if projectID == "" {
+		return nil, nil
+	}
+	records, err := currentStore().List()
+	if err != nil {
+		return nil, err
+	}
+
+	// Records are newest first: the first match is the latest
+	for _, rec := range records {
+		state, err := decodeState(rec.Data)
+		if err != nil {
+			continue
+		}
+		if state.ProjectID != projectID || state.SessionID == excludeSession || !state.Unfinished() {
+			continue
+		}
+		return state, nil
+	}
+	return nil, nil
+}
+
+// Link starts a DAG for sessionID that continues parent: every node not yet
+// done is carried forward with its task binding cleared, and dependencies on
+// done nodes are dropped as satisfied. Failed and skipped nodes are retried.
+// parent is saved as superseded, so no other session resumes it. The new
+// DAG keeps the parent's MaxNodes.
+func Link(parent *DAGState, sessionID string) (*DAGState, error) {
+	var carried []*Node
+	for _, id := range sortedIDs(parent) {
+		n := parent.Nodes[id]
+		if n.Status == StatusDone {
+			continue
+		}
+		c := *n
+		c.Status, c.TaskID, c.Level = "", "", 0
+		c.DependsOn, c.Blocks, c.Conditions, c.AnyOf, c.Result = nil, nil, nil, nil, ""
+		c.EnteredAt, c.History = nil, nil
+		carried = append(carried, &c)
+	}
+	if len(carried) == 0 {
+		return nil, fmt.Errorf("dag %s has no unfinished nodes", parent.ID)
+	}
+
+	state, err := newScheduledState(sessionID, parent.RootPrompt, carried, []ScheduleOption{WithMaxNodes(parent.MaxNodes)})
+	if err != nil {
+		return nil, err
+	}
+	state.ProjectID = parent.ProjectID
+	state.ParentDAG = parent.ID
+	for _, c := range carried {
+		orig := parent.Nodes[c.ID]
+		met := doneGroups(parent, orig)
+		for _, dep := range orig.DependsOn {
+			if _, ok := state.Nodes[dep]; !ok {
+				continue
+			}
+			group, anyOf := orig.AnyOf[dep]
+			if anyOf && met[group] {
+				continue
+			}
+			var err error
+			if cond, ok := orig.Conditions[dep]; ok {
+				err = state.AddConditionalEdge(dep, c.ID, cond)
+			} else if anyOf {
+				err = state.AddAnyOfEdge(dep, c.ID, group)
+			} else {
+				err = state.AddEdge(dep, c.ID)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("edge %s->%s: %w", dep, c.ID, err)
+			}
+		}
+	}
+	state, err = finishSchedule(state)
+	if err != nil {
+		return nil, err
+	}
+	parent.Status = DAGSuperseded
+	if err := Save(parent); err != nil {
+		return nil, fmt.Errorf("supersede %s: %w", parent.ID, err)
+	}
+	return state, nil
+}
+
+// doneGroups returns n's OR-groups already satisfied in s; their
+// unfinished members are not carried as dependencies.
+func doneGroups(s *DAGState, n *Node) map[string]bool {
+	met := make(map[string]bool)
+	for dep, group := range n.AnyOf {
+		if d := s.Nodes[dep]; d != nil && d.Status == StatusDone {
+			met[group] = true
+		}
+	}
+	return met
+}
+
+// sortedIDs returns the node IDs in lexical order.
+func sortedIDs(s *DAGState) []string {
+	ids := make([]string, 0, len(s.Nodes))
+	for id := range s.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,227 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// replan.go: Recovery from partial failure. Each failed node and the nodes
+// skipped because of it are replaced by a fresh sub-plan decomposed from
+// their prompts, wired into the rest of the DAG. Completed work is kept.
+package dag
+
+import "fmt"
+
+// ReplanResult reports one replanned subtree: the nodes it removed and
+// the nodes that replace them.
+type ReplanResult struct {
+	Removed []string
+	Added   []*Node
+}
+
+// Replan replaces every failed subtree of state: a failed node plus the
+// nodes skipped because of it. Replacement steps are decomposed from the
+// removed nodes' descriptions in level order; agents is the pool Decompose
+// assigns from (empty = the removed nodes' agents). The new roots inherit
+// the subtree's outside dependencies, and pending dependents outside the
+// subtree wait on the new leaves.
+func Replan(state *DAGState, agents []string) ([]ReplanResult, error) {
+	claimed := make(map[string]bool)
+	var subtrees [][]*Node
+	for _, n := range state.SortedNodes() {
+		if n.Status != StatusFailed || claimed[n.ID] {
+			continue
+		}
+		subtrees = append(subtrees, state.failedSubtree(n, claimed))
+	}
+	if len(subtrees) == 0 {
+		return nil, fmt.Errorf("dag %s has no failed nodes", state.ID)
+	}
+
+	var results []ReplanResult
+	var changes []statusChange
+	for _, subtree := range subtrees {
+		res, err := state.replanSubtree(subtree, claimed, agents, &changes)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	if _, err := TopoLevels(state); err != nil {
+		return results, err
+	}
+	if !state.IsComplete() {
+		state.Status = DAGActive
+	}
+	for _, c := range changes {
+		state.emit(EventStatusChanged, c.node, c.previous)
+	}
+	return results, nil
+}
+
+// failedSubtree returns root, its skipped descendants and any other failed
+// node they also wait on, in level order. Nodes are marked claimed so a
+// node shared by two failures is replanned once.
+func (s *DAGState) failedSubtree(root *Node, claimed map[string]bool) []*Node {
+	subtree := []*Node{root}
+	claimed[root.ID] = true
+	add := func(id string, status NodeStatus) {
+		if n := s.Nodes[id]; n != nil && !claimed[id] && n.Status == status {
+			claimed[id] = true
+			subtree = append(subtree, n)
+		}
+	}
+	for i := 0; i < len(subtree); i++ {
+		for _, id := range subtree[i].Blocks {
+			add(id, StatusSkipped)
+		}
+		for _, id := range subtree[i].DependsOn {
+			add(id, StatusFailed)
+		}
+	}
+	sortNodes(subtree)
+	return subtree
+}
+
+// outsideEdge is a dependency crossing the subtree boundary.
+type outsideEdge struct {
+	id    string
+	cond  *EdgeCondition
+	group string // OR-group of the edge, "" = none
+}
+
+func (s *DAGState) replanSubtree(subtree []*Node, claimed map[string]bool, agents []string, changes *[]statusChange) (ReplanResult, error) {
+	var res ReplanResult
+	var steps, pool []string
+	var deps, dependents []outsideEdge
+	seen := make(map[string]bool)
+	for _, n := range subtree {
+		res.Removed = append(res.Removed, n.ID)
+		step := n.Description
+		if step == "" {
+			step = n.Subject
+		}
+		steps = append(steps, step)
+		if n.Agent != "" {
+			pool = append(pool, n.Agent)
+		}
+		for _, dep := range n.DependsOn {
+			if !claimed[dep] && !seen["dep:"+dep] {
+				seen["dep:"+dep] = true
+				deps = append(deps, outsideEdge{dep, conditionOf(n, dep), n.AnyOf[dep]})
+			}
+		}
+		for _, id := range n.Blocks {
+			if !claimed[id] && !seen["blocks:"+id] {
+				seen["blocks:"+id] = true
+				dependents = append(dependents, outsideEdge{id, conditionOf(s.Nodes[id], n.ID), groupOf(s.Nodes[id], n.ID)})
+			}
+		}
+	}
+	for _, n := range subtree {
+		s.removeNode(n.ID)
+	}
+	if len(agents) > 0 {
+		pool = agents
+	}
+
+	res.Added = Decompose(steps, pool)
+	for i, n := range res.Added {
+		n.SetLabel("replan_of", res.Removed[i])
+		if err := s.AddNode(n); err != nil {
+			return res, err
+		}
+	}
+	for _, e := range sequentialEdges(res.Added) {
+		if err := s.AddEdge(e[0], e[1]); err != nil {
+			return res, fmt.Errorf("edge %s->%s: %w", e[0], e[1], err)
+		}
+	}
+	for _, n := range res.Added {
+		if len(n.DependsOn) > 0 {
+			continue
+		}
+		for _, dep := range deps {
+			if err := s.addEdgeWith(dep.id, n.ID, dep); err != nil {
+				return res, err
+			}
+		}
+	}
+	for _, n := range res.Added {
+		if len(n.Blocks) > 0 {
+			continue
+		}
+		for _, d := range dependents {
+			if s.Nodes[d.id] == nil || s.Nodes[d.id].Status != StatusPending {
+				continue
+			}
+			if err := s.addEdgeWith(n.ID, d.id, d); err != nil {
+				return res, err
+			}
+		}
+	}
+	for _, n := range res.Added {
+		s.checkReady(n.ID, changes)
+	}
+	return res, nil
+}
+
+// conditionOf returns n's condition on dep, or nil for a plain edge.
+func conditionOf(n *Node, dep string) *EdgeCondition {
+	if n == nil {
+		return nil
+	}
+	if cond, ok := n.Conditions[dep]; ok {
+		return &cond
+	}
+	return nil
+}
+
+// groupOf returns n's OR-group for dep, or "".
+func groupOf(n *Node, dep string) string {
+	if n == nil {
+		return ""
+	}
+	return n.AnyOf[dep]
+}
+
+// addEdgeWith adds depID -> nodeID with the condition or OR-group of e.
+func (s *DAGState) addEdgeWith(depID, nodeID string, e outsideEdge) error {
+	var err error
+	if e.cond != nil {
+		err = s.AddConditionalEdge(depID, nodeID, *e.cond)
+	} else if e.group != "" {
+		err = s.AddAnyOfEdge(depID, nodeID, e.group)
+	} else {
+		err = s.AddEdge(depID, nodeID)
+	}
+	if err != nil {
+		return fmt.Errorf("edge %s->%s: %w", depID, nodeID, err)
+	}
+	return nil
+}
+
+// removeNode deletes id and every edge that mentions it.
+func (s *DAGState) removeNode(id string) {
+	n := s.Nodes[id]
+	if n == nil {
+		return
+	}
+	for _, dep := range n.DependsOn {
+		if d := s.Nodes[dep]; d != nil {
+			d.Blocks = without(d.Blocks, id)
+		}
+	}
+	for _, b := range n.Blocks {
+		if d := s.Nodes[b]; d != nil {
+			d.DependsOn = without(d.DependsOn, id)
+			delete(d.Conditions, id)
+			delete(d.AnyOf, id)
+		}
+	}
+	delete(s.Nodes, id)
+}
+
+func without(ids []string, id string) []string {
+	out := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
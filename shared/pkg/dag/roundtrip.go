@@ -0,0 +1,153 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// roundtrip.go: PostToolUse matching of Task results back to DAG nodes
+// via the dag_node_id metadata embedded at dispatch.
+package dag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Drift is a completed task that did not carry its dag_node_id back while
+// dispatched nodes were still waiting on one.
+type Drift struct {
+	TaskID  string   // Claude task that completed
+	Subject string   // Its subject, if known
+	Matched string   // Node reached by subject fallback, or ""
+	Waiting []string // Dispatched/running nodes with no task bound
+}
+
+// String renders the drift for hook output.
+func (d *Drift) String() string {
+	s := fmt.Sprintf("task %s completed without dag_node_id", d.TaskID)
+	if d.Matched != "" {
+		s += fmt.Sprintf("; matched %s by subject", d.Matched)
+	}
+	if len(d.Waiting) > 0 {
+		s += fmt.Sprintf("; waiting: %s", strings.Join(d.Waiting, ","))
+	}
+	return s
+}
+
+// TaskNodeID returns the dag_node_id from tool_response metadata, falling
+// back to the tool input metadata.
+func TaskNodeID(toolInput, toolResponse map[string]interface{}) string {
+	for _, m := range []map[string]interface{}{taskObject(toolResponse), toolResponse, toolInput} {
+		md, _ := m["metadata"].(map[string]interface{})
+		if id, _ := md["dag_node_id"].(string); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// TaskResultID returns the Claude task ID from a Task tool response,
+// falling back to the taskId in the tool input.
+func TaskResultID(toolInput, toolResponse map[string]interface{}) string {
+	for _, m := range []map[string]interface{}{taskObject(toolResponse), toolResponse} {
+		for _, key := range []string{"taskId", "task_id", "id"} {
+			if id, ok := m[key].(string); ok && id != "" {
+				return id
+			}
+			if id, ok := m[key].(float64); ok {
+				return fmt.Sprint(int64(id))
+			}
+		}
+	}
+	id, _ := toolInput["taskId"].(string)
+	return id
+}
+
+// taskObject unwraps responses shaped {"task": {...}}.
+func taskObject(toolResponse map[string]interface{}) map[string]interface{} {
+	t, _ := toolResponse["task"].(map[string]interface{})
+	return t
+}
+
+// HandleTaskResult is the PostToolUse counterpart of HandleTaskEvent. A
+// TaskCreate result binds the returned task ID to its node; a TaskUpdate
+// completion is matched through the round-tripped dag_node_id, and a
+// completion that could not carry one back is reported as drift.
+func (b *DirectiveBuilder) HandleTaskResult(state *DAGState, toolName string, toolInput, toolResponse map[string]interface{}) (bool, bool, string, *Drift) {
+	nodeID := TaskNodeID(toolInput, toolResponse)
+	taskID := TaskResultID(toolInput, toolResponse)
+
+	switch toolName {
+	case "TaskCreate":
+		if n, ok := state.Nodes[nodeID]; ok && taskID != "" {
+			n.TaskID = taskID
+		}
+		complete, needsAegis, directive := b.HandleTaskEvent(state, toolName, toolInput)
+		return complete, needsAegis, directive, nil
+
+	case "TaskUpdate":
+		// Forward the response's dag_node_id to the matcher without
+		// mutating the caller's input
+		input := make(map[string]interface{}, len(toolInput)+1)
+		for k, v := range toolInput {
+			input[k] = v
+		}
+		if nodeID != "" {
+			md := map[string]interface{}{}
+			if orig, ok := toolInput["metadata"].(map[string]interface{}); ok {
+				for k, v := range orig {
+					md[k] = v
+				}
+			}
+			md["dag_node_id"] = nodeID
+			input["metadata"] = md
+		}
+		if taskID != "" {
+			input["taskId"] = taskID
+		}
+
+		var drift *Drift
+		status, _ := input["status"].(string)
+		if status == "completed" && nodeID != "" {
+			if _, ok := state.Nodes[nodeID]; !ok {
+				nodeID = "" // Unknown node: treat as missing
+			}
+		}
+		if status == "completed" && nodeID == "" && taskID != "" && nodeByTask(state, taskID) == nil {
+			drift = &Drift{TaskID: taskID, Waiting: unboundNodes(state)}
+			drift.Subject, _ = input["subject"].(string)
+		}
+
+		complete, needsAegis, directive := b.HandleTaskEvent(state, toolName, input)
+		if drift != nil {
+			if n := nodeByTask(state, taskID); n != nil {
+				drift.Matched = n.ID
+				drift.Waiting = unboundNodes(state)
+			} else if len(drift.Waiting) == 0 {
+				drift = nil // Task outside the DAG
+			}
+		}
+		return complete, needsAegis, directive, drift
+	}
+
+	complete, needsAegis, directive := b.HandleTaskEvent(state, toolName, toolInput)
+	return complete, needsAegis, directive, nil
+}
+
+// nodeByTask finds the node bound to a Claude task ID.
+func nodeByTask(state *DAGState, taskID string) *Node {
+	for _, n := range state.Nodes {
+		if n.TaskID == taskID {
+			return n
+		}
+	}
+	return nil
+}
+
+// unboundNodes lists dispatched/running nodes still waiting for a task ID.
+func unboundNodes(state *DAGState) []string {
+	var ids []string
+	for _, n := range state.Nodes {
+		if n.TaskID == "" && (n.Status == StatusDispatched || n.Status == StatusRunning) {
+			ids = append(ids, n.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
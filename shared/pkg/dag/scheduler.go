@@ -7,7 +7,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
-	"time"
+
+	"github.com/claude/shared/pkg/logger"
+	"github.com/claude/shared/pkg/util"
 )
 
 // researchKeywords detects steps that are parallelizable (no inter-deps).
@@ -17,6 +19,12 @@ var researchKeywords = []string{"research", "search", "explore", "investigate",
 // Steps containing research keywords are treated as parallel-safe (no inter-deps).
 // Agents are matched by content: research steps → research agents, others → non-research agents.
 func Decompose(breakdown []string, agents []string) []*Node {
+	return DecomposeAt(breakdown, agents, util.SystemClock)
+}
+
+// DecomposeAt is Decompose with node IDs derived from clock, so a frozen
+// clock yields reproducible node IDs.
+func DecomposeAt(breakdown []string, agents []string, clock util.Clock) []*Node {
 	// Separate agents into research vs implementation pools
 	var researchAgents, implAgents []string
 	for _, a := range agents {
@@ -44,7 +52,7 @@ func Decompose(breakdown []string, agents []string) []*Node {
 			agent = implAgents[iIdx%len(implAgents)]
 			iIdx++
 		}
-		id := nodeID(step)
+		id := nodeID(step, i, clock)
 		nodes[i] = &Node{
 			ID:          id,
 			Subject:     step,
@@ -57,8 +65,55 @@ func Decompose(breakdown []string, agents []string) []*Node {
 	return nodes
 }
 
-func nodeID(label string) string {
-	hash := sha256.Sum256([]byte(fmt.Sprintf("%s-%d", label, time.Now().UnixNano())))
+// DecomposeWithSkills is Decompose with an agent and skill per step.
+// The three arrays must have equal length; an empty agent keeps the
+// Decompose assignment and an empty skill is left for InferSkills.
+func DecomposeWithSkills(breakdown, agents, skills []string) ([]*Node, error) {
+	return DecomposeWithSkillsAt(breakdown, agents, skills, util.SystemClock)
+}
+
+// DecomposeWithSkillsAt is DecomposeWithSkills with node IDs from clock.
+func DecomposeWithSkillsAt(breakdown, agents, skills []string, clock util.Clock) ([]*Node, error) {
+	if len(agents) != len(breakdown) || len(skills) != len(breakdown) {
+		return nil, fmt.Errorf("%w: %d steps, %d agents, %d skills",
+			ErrLengthMismatch, len(breakdown), len(agents), len(skills))
+	}
+	var pool []string
+	for _, a := range agents {
+		if a != "" {
+			pool = append(pool, a)
+		}
+	}
+	nodes := DecomposeAt(breakdown, pool, clock)
+	for i, n := range nodes {
+		if agents[i] != "" {
+			n.Agent = agents[i]
+		}
+		n.Skill = skills[i]
+	}
+	return nodes, nil
+}
+
+// InferSkills sets each node without a skill to the first skill its agent
+// declares. Nodes whose agent r does not know are left unchanged.
+func InferSkills(nodes []*Node, r AgentResolver) {
+	if r == nil {
+		return
+	}
+	for _, n := range nodes {
+		if n.Skill != "" || n.Agent == "" {
+			continue
+		}
+		if _, skills, ok := r.ResolveAgent(n.Agent); ok && len(skills) > 0 {
+			n.Skill = skills[0]
+		}
+	}
+}
+
+// nodeID hashes the step with its position, so repeated steps stay
+// distinct when the clock does not move between them.
+func nodeID(label string, index int, clock util.Clock) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s-%d-%d", label, index, clock.Now().UnixNano())))
 	return "kv-" + hex.EncodeToString(hash[:])[:6]
 }
 
@@ -72,35 +127,92 @@ func isResearch(step string) bool {
 	return false
 }
 
+// ScheduleOption configures the state Schedule and ScheduleWithEdges build.
+type ScheduleOption func(*DAGState)
+
+// WithMaxNodes caps the nodes the DAG may hold; n <= 0 keeps DefaultMaxNodes.
+func WithMaxNodes(n int) ScheduleOption {
+	return func(s *DAGState) {
+		if n > 0 {
+			s.MaxNodes = n
+		}
+	}
+}
+
 // Schedule builds a DAGState from decomposed nodes, adding sequential deps
 // for non-research steps while keeping research steps parallel.
-func Schedule(sessionID, prompt string, nodes []*Node) (*DAGState, error) {
-	state := NewDAGState(sessionID, prompt)
-	for _, n := range nodes {
-		if err := state.AddNode(n); err != nil {
-			return nil, err
+func Schedule(sessionID, prompt string, nodes []*Node, opts ...ScheduleOption) (*DAGState, error) {
+	state, err := newScheduledState(sessionID, prompt, nodes, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range sequentialEdges(nodes) {
+		if err := state.AddEdge(e[0], e[1]); err != nil {
+			return nil, fmt.Errorf("edge %s->%s: %w", e[0], e[1], err)
 		}
 	}
-	// Add sequential edges: non-research step[i] depends on step[i-1]
+	return finishSchedule(state)
+}
+
+// sequentialEdges chains non-research steps in order: step[i] depends on
+// the previous non-research step. Research steps get no sequential deps.
+func sequentialEdges(nodes []*Node) [][2]string {
+	var edges [][2]string
 	var lastNonResearch string
 	for _, n := range nodes {
 		if isResearch(n.Subject) {
-			continue // research nodes have no sequential deps
+			continue
 		}
 		if lastNonResearch != "" {
-			if err := state.AddEdge(lastNonResearch, n.ID); err != nil {
-				return nil, fmt.Errorf("edge %s->%s: %w", lastNonResearch, n.ID, err)
-			}
+			edges = append(edges, [2]string{lastNonResearch, n.ID})
 		}
 		lastNonResearch = n.ID
 	}
-	// Mark initial ready nodes
-	for _, n := range state.Nodes {
+	return edges
+}
+
+// ScheduleWithEdges builds a DAGState from nodes and explicit edges, skipping
+// the heuristic layering. Each edge is {depID, nodeID}: depID must complete
+// before nodeID starts. Unknown IDs and cycles are rejected.
+func ScheduleWithEdges(sessionID, prompt string, nodes []*Node, edges [][2]string, opts ...ScheduleOption) (*DAGState, error) {
+	state, err := newScheduledState(sessionID, prompt, nodes, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		if err := state.AddEdge(e[0], e[1]); err != nil {
+			return nil, fmt.Errorf("edge %s->%s: %w", e[0], e[1], err)
+		}
+	}
+	return finishSchedule(state)
+}
+
+// newScheduledState creates a state holding nodes, enforcing max_nodes.
+func newScheduledState(sessionID, prompt string, nodes []*Node, opts []ScheduleOption) (*DAGState, error) {
+	state := NewDAGState(sessionID, prompt)
+	for _, opt := range opts {
+		opt(state)
+	}
+	if limit := state.nodeLimit(); len(nodes) > limit {
+		return nil, fmt.Errorf("%w: %d nodes exceeds max_nodes limit %d", ErrTooLarge, len(nodes), limit)
+	}
+	for _, n := range nodes {
+		if err := state.AddNode(n); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// finishSchedule marks root nodes ready and validates the levels.
+func finishSchedule(state *DAGState) (*DAGState, error) {
+	for _, n := range state.SortedNodes() {
 		if len(n.DependsOn) == 0 {
-			n.Status = StatusReady
+			if err := state.UpdateNodeStatus(n.ID, StatusReady); err != nil {
+				return nil, err
+			}
 		}
 	}
-	// Compute levels
 	if _, err := TopoLevels(state); err != nil {
 		return nil, err
 	}
@@ -109,20 +221,52 @@ func Schedule(sessionID, prompt string, nodes []*Node) (*DAGState, error) {
 
 // BuildDirective generates the TOON directive for the current frontier level.
 func BuildDirective(state *DAGState) string {
+	return NewDirectiveBuilder(nil).Directive(state)
+}
+
+// Directive generates the TOON directive for the current frontier level.
+func (b *DirectiveBuilder) Directive(state *DAGState) string {
 	ready := state.ReadyNodes()
 	if len(ready) == 0 {
 		if state.IsComplete() {
-			return BuildCompletionDirective(state.ID)
+			return completionDirective(state)
 		}
 		return ""
 	}
+	// With a parallel cap, hold the next sub-batch until the current one drains
+	if b.maxParallel > 0 && inFlight(state) > 0 {
+		return ""
+	}
 	level := ParallelLevel{Level: ready[0].Level, Nodes: ready}
-	return BuildParallelDispatch(state.ID, level, state.MaxLevel)
+	return b.ParallelDispatch(state.ID, level, state.MaxLevel)
+}
+
+// inFlight counts dispatched and running nodes.
+func inFlight(state *DAGState) int {
+	count := 0
+	for _, n := range state.Nodes {
+		if n.Status == StatusDispatched || n.Status == StatusRunning {
+			count++
+		}
+	}
+	return count
+}
+
+// taskFailed reports whether a completed task's metadata marks it failed
+// ({"dag_status": "failed"}): TaskUpdate has no failed status of its own.
+func taskFailed(md map[string]interface{}) bool {
+	status, _ := md["dag_status"].(string)
+	return NodeStatus(status) == StatusFailed
 }
 
 // HandleTaskEvent processes TaskCreate/TaskUpdate hooks and advances DAG state.
 // Returns: (complete, needsAegis, nextDirective).
 func HandleTaskEvent(state *DAGState, toolName string, toolInput map[string]interface{}) (bool, bool, string) {
+	return NewDirectiveBuilder(nil).HandleTaskEvent(state, toolName, toolInput)
+}
+
+// HandleTaskEvent advances DAG state; the next directive uses this builder.
+func (b *DirectiveBuilder) HandleTaskEvent(state *DAGState, toolName string, toolInput map[string]interface{}) (bool, bool, string) {
 	switch toolName {
 	case "TaskCreate":
 		// At PreToolUse, extract dag_node_id from metadata and mark dispatched.
@@ -134,7 +278,8 @@ func HandleTaskEvent(state *DAGState, toolName string, toolInput map[string]inte
 		}
 		if n, ok := state.Nodes[nodeID]; ok {
 			// Store subject for later matching since taskId isn't available yet
-			n.Status = StatusDispatched
+			setTaskStatus(state, n.ID, StatusDispatched, toolName)
+			recordUsage(n, md)
 		}
 
 	case "TaskUpdate":
@@ -163,11 +308,17 @@ func HandleTaskEvent(state *DAGState, toolName string, toolInput map[string]inte
 				}
 			}
 			if matched {
-				if status == "completed" {
-					state.UpdateNodeStatus(n.ID, StatusDone)
-				} else {
-					n.Status = StatusRunning
+				recordUsage(n, md)
+				if result, ok := md["dag_result"].(string); ok {
+					n.Result = result
 				}
+				next := StatusRunning
+				if status == "completed" && taskFailed(md) {
+					next = StatusFailed
+				} else if status == "completed" {
+					next = StatusDone
+				}
+				setTaskStatus(state, n.ID, next, toolName)
 				break
 			}
 		}
@@ -181,13 +332,23 @@ func HandleTaskEvent(state *DAGState, toolName string, toolInput map[string]inte
 				break
 			}
 		}
-		return true, allDone, BuildCompletionDirective(state.ID)
+		return true, allDone, completionDirective(state)
 	}
 
-	directive := BuildDirective(state)
+	directive := b.Directive(state)
 	return false, false, directive
 }
 
+// setTaskStatus applies a status reported by a task event. The hook must
+// not fail on a stale or out-of-order event, so a rejected change (e.g. a
+// completed task whose node was already skipped) is logged and ignored.
+func setTaskStatus(state *DAGState, id string, status NodeStatus, toolName string) {
+	if err := state.UpdateNodeStatus(id, status); err != nil {
+		logger.Warn("dag", "task event status change rejected",
+			"dag_id", state.ID, "node", id, "tool", toolName, "error", err.Error())
+	}
+}
+
 // countBySubject counts how many nodes share a given subject.
 func countBySubject(state *DAGState, subject string) int {
 	count := 0
@@ -0,0 +1,61 @@
+// Package dag provides a parallel DAG scheduler for Kavach orchestration.
+// schema.go: Versioned persistence. Save stamps StateSchemaVersion; Load
+// upgrades older files one version at a time and refuses newer ones.
+package dag
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StateSchemaVersion is the DAGState layout written by Save. Files written
+// before the field existed read as version 0.
+const StateSchemaVersion = 1
+
+// migrations[v] upgrades a state from version v to v+1.
+var migrations = []func(*DAGState){
+	migrateV0,
+}
+
+// migrateV0 fills fields that pre-versioned files could leave empty.
+func migrateV0(s *DAGState) {
+	if s.Nodes == nil {
+		s.Nodes = make(map[string]*Node)
+	}
+	if s.Status == "" {
+		s.Status = DAGActive
+	}
+	for id, n := range s.Nodes {
+		if n.ID == "" {
+			n.ID = id
+		}
+		if n.Status == "" {
+			n.Status = StatusPending
+		}
+	}
+}
+
+// Migrate upgrades state in place to StateSchemaVersion. A version newer
+// than this build understands returns ErrUnknownSchema.
+func Migrate(state *DAGState) error {
+	if state.SchemaVersion < 0 || state.SchemaVersion > StateSchemaVersion {
+		return fmt.Errorf("%w: dag state v%d, supported up to v%d", ErrUnknownSchema, state.SchemaVersion, StateSchemaVersion)
+	}
+	for v := state.SchemaVersion; v < StateSchemaVersion; v++ {
+		migrations[v](state)
+	}
+	state.SchemaVersion = StateSchemaVersion
+	return nil
+}
+
+// decodeState parses a persisted DAG state and migrates it.
+func decodeState(data []byte) (*DAGState, error) {
+	var state DAGState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if err := Migrate(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -1,5 +1,6 @@
 // Package dag provides a parallel DAG scheduler for Kavach orchestration.
-// state.go: JSON persistence for DAG state.
+// state.go: JSON persistence for DAG state, through a statestore.Store
+// (files under ~/.claude/dag by default).
 package dag
 
 import (
@@ -8,66 +9,131 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/claude/shared/pkg/statestore"
 )
 
+// StateDir returns the directory holding persisted DAG state files.
+func StateDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude", "dag")
+}
+
 // StatePath returns the file path for a session's DAG state.
 func StatePath(sessionID string) string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".claude", "dag", sessionID+".json")
+	return filepath.Join(StateDir(), sessionID+".json")
 }
 
-// Save persists DAG state to disk as JSON.
-func Save(state *DAGState) error {
-	path := StatePath(state.SessionID)
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("mkdir: %w", err)
+// store overrides the file store under StateDir; see SetStore.
+var store statestore.Store
+
+// SetStore routes Save, Load, Delete, List and LatestUnfinished through s
+// (e.g. a statestore.MemoryStore in tests) and returns a func restoring the
+// previous store. nil restores the default file store under StateDir.
+func SetStore(s statestore.Store) (restore func()) {
+	prev := store
+	store = s
+	return func() { store = prev }
+}
+
+// currentStore returns the store in effect; the file store resolves
+// StateDir on each call so it follows HOME.
+func currentStore() statestore.Store {
+	if store != nil {
+		return store
 	}
+	return statestore.FileStore{Dir: StateDir()}
+}
+
+// Save persists DAG state as JSON. Sink deliveries stay asynchronous; the
+// process flushes them once before it exits (FlushEvents).
+func Save(state *DAGState) error {
+	state.SchemaVersion = StateSchemaVersion
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal: %w", err)
 	}
-	return os.WriteFile(path, data, 0644)
+	return currentStore().Save(state.SessionID, data)
 }
 
-// Load reads DAG state from disk.
+// Load reads DAG state, migrating older schema versions.
 func Load(sessionID string) (*DAGState, error) {
-	data, err := os.ReadFile(StatePath(sessionID))
+	data, err := currentStore().Load(sessionID)
 	if err != nil {
 		return nil, err
 	}
-	var state DAGState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("unmarshal: %w", err)
-	}
-	return &state, nil
+	return decodeState(data)
 }
 
 // Delete removes DAG state for a session.
 func Delete(sessionID string) error {
-	return os.Remove(StatePath(sessionID))
+	return currentStore().Delete(sessionID)
 }
 
-// CleanupOld removes DAG state files older than maxAge.
+// CleanupOld removes DAG states older than maxAgeDays.
 // Called from session end to prevent accumulation.
 func CleanupOld(maxAgeDays int) error {
-	home, _ := os.UserHomeDir()
-	dagDir := filepath.Join(home, ".claude", "dag")
-	entries, err := os.ReadDir(dagDir)
+	st := currentStore()
+	records, err := st.List()
 	if err != nil {
 		return nil // dir may not exist
 	}
 	cutoff := time.Now().AddDate(0, 0, -maxAgeDays)
-	for _, e := range entries {
-		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+	for _, rec := range records {
+		if rec.ModTime.Before(cutoff) {
+			st.Delete(rec.Key)
+		}
+	}
+	return nil
+}
+
+// Summary is a one-line view of a persisted DAG state.
+type Summary struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Status    DAGStatus `json:"status"`
+	Nodes     int       `json:"nodes"`
+	Done      int       `json:"done"`
+	Levels    int       `json:"levels"`
+	Time      time.Time `json:"time"`
+}
+
+// List returns summaries of persisted DAG states, newest first.
+// since > 0 keeps only states saved within that window.
+func List(since time.Duration) ([]Summary, error) {
+	records, err := currentStore().List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Time{}
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+
+	var out []Summary
+	for _, rec := range records {
+		if rec.ModTime.Before(cutoff) {
 			continue
 		}
-		info, err := e.Info()
+		state, err := decodeState(rec.Data)
 		if err != nil {
 			continue
 		}
-		if info.ModTime().Before(cutoff) {
-			os.Remove(filepath.Join(dagDir, e.Name()))
+		sum := Summary{
+			ID:        state.ID,
+			SessionID: state.SessionID,
+			Status:    state.Status,
+			Nodes:     len(state.Nodes),
+			Levels:    state.MaxLevel + 1,
+			Time:      rec.ModTime,
+		}
+		for _, n := range state.Nodes {
+			if n.Status == StatusDone {
+				sum.Done++
+			}
 		}
+		out = append(out, sum)
 	}
-	return nil
+	return out, nil
 }
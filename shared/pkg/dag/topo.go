@@ -2,10 +2,14 @@
 // topo.go: Kahn's algorithm for topological level assignment.
 package dag
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // TopoLevels groups nodes into parallel execution waves using Kahn's algorithm.
 // Sets node.Level and state.MaxLevel. Returns error on cycle.
+// Nodes within a level are ordered by ID so output is reproducible.
 func TopoLevels(state *DAGState) ([]ParallelLevel, error) {
 	inDeg := make(map[string]int, len(state.Nodes))
 	for id, n := range state.Nodes {
@@ -41,14 +45,25 @@ func TopoLevels(state *DAGState) ([]ParallelLevel, error) {
 			}
 		}
 
+		sortNodes(level.Nodes)
 		levels = append(levels, level)
 		queue = nextQueue
 	}
 
 	if processed != len(state.Nodes) {
-		return nil, fmt.Errorf("cycle detected: processed %d of %d nodes", processed, len(state.Nodes))
+		return nil, fmt.Errorf("%w: processed %d of %d nodes", ErrCycle, processed, len(state.Nodes))
 	}
 
 	state.MaxLevel = len(levels) - 1
 	return levels, nil
 }
+
+// sortNodes orders nodes by level, then ID.
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Level != nodes[j].Level {
+			return nodes[i].Level < nodes[j].Level
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+}
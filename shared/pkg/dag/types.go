@@ -2,6 +2,12 @@
 // types.go: Core type definitions for DAG scheduler state.
 package dag
 
+import (
+	"time"
+
+	"github.com/claude/shared/pkg/util"
+)
+
 // NodeStatus represents the lifecycle state of a DAG node.
 type NodeStatus string
 
@@ -20,6 +26,25 @@ func (s NodeStatus) IsTerminal() bool {
 	return s == StatusDone || s == StatusFailed || s == StatusSkipped
 }
 
+// transitions lists the statuses reachable from each status. Lifecycle
+// moves forward only; terminal statuses have no way out.
+var transitions = map[NodeStatus][]NodeStatus{
+	StatusPending:    {StatusReady, StatusFailed, StatusSkipped},
+	StatusReady:      {StatusDispatched, StatusRunning, StatusDone, StatusFailed, StatusSkipped},
+	StatusDispatched: {StatusRunning, StatusDone, StatusFailed, StatusSkipped},
+	StatusRunning:    {StatusDone, StatusFailed},
+}
+
+// CanTransition reports whether a node may move from s to next.
+func (s NodeStatus) CanTransition(next NodeStatus) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // Node represents a single task in the DAG.
 type Node struct {
 	ID          string            `json:"id"`
@@ -33,25 +58,77 @@ type Node struct {
 	Level       int               `json:"level"`
 	TaskID      string            `json:"task_id,omitempty"` // Claude task ID once created
 	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// Conditional dependencies: keyed by dep ID, see AddConditionalEdge.
+	// Result is the outcome reported via dag_result task metadata.
+	Conditions map[string]EdgeCondition `json:"conditions,omitempty"`
+	Result     string                   `json:"result,omitempty"`
+
+	// OR-dependencies: dep ID -> group name, see AddAnyOfEdge. A group is
+	// satisfied once any one of its members is done.
+	AnyOf map[string]string `json:"any_of,omitempty"`
+
+	// Cost accounting: estimate set at scheduling, usage reported on completion
+	TokenEstimate int `json:"token_estimate,omitempty"`
+	TokensUsed    int `json:"tokens_used,omitempty"`
+	CostCents     int `json:"cost_cents,omitempty"`
+
+	// Lifecycle: when each status was entered and every transition taken,
+	// for debugging stuck orchestrations
+	EnteredAt map[NodeStatus]time.Time `json:"entered_at,omitempty"`
+	History   []Transition             `json:"history,omitempty"`
+}
+
+// Transition is one status change of a node.
+type Transition struct {
+	From NodeStatus `json:"from"`
+	To   NodeStatus `json:"to"`
+	At   time.Time  `json:"at"`
+}
+
+// EdgeCondition is the predicate on a conditional dependency. Once the
+// upstream node is terminal the dependent runs if the predicate holds and is
+// skipped otherwise. The zero value accepts any outcome (optional dependency).
+type EdgeCondition struct {
+	Status NodeStatus `json:"status,omitempty"` // Required upstream status; "" = any
+	Result string     `json:"result,omitempty"` // Required upstream Result; "" = any
+}
+
+// Met reports whether the terminal upstream node satisfies the condition.
+func (c EdgeCondition) Met(dep *Node) bool {
+	return (c.Status == "" || dep.Status == c.Status) && (c.Result == "" || dep.Result == c.Result)
 }
 
 // DAGStatus represents the overall state of the DAG.
 type DAGStatus string
 
 const (
-	DAGActive   DAGStatus = "active"
-	DAGComplete DAGStatus = "complete"
-	DAGFailed   DAGStatus = "failed"
+	DAGActive     DAGStatus = "active"
+	DAGComplete   DAGStatus = "complete"
+	DAGFailed     DAGStatus = "failed"
+	DAGSuperseded DAGStatus = "superseded" // Continued by a linked DAG in another session
 )
 
+// DefaultMaxNodes bounds DAG size so runaway decomposition fails fast.
+const DefaultMaxNodes = 1000
+
 // DAGState holds the full scheduler state for a session.
 type DAGState struct {
+	SchemaVersion int `json:"schema_version"` // See StateSchemaVersion
+
 	ID         string           `json:"id"`
 	SessionID  string           `json:"session_id"`
 	RootPrompt string           `json:"root_prompt"`
 	Nodes      map[string]*Node `json:"nodes"`
 	MaxLevel   int              `json:"max_level"`
+	MaxNodes   int              `json:"max_nodes,omitempty"` // 0 = DefaultMaxNodes
 	Status     DAGStatus        `json:"status"`
+
+	// Cross-session linking: DAGs of one project chain through ParentDAG
+	ProjectID string `json:"project_id,omitempty"`
+	ParentDAG string `json:"parent_dag,omitempty"`
+
+	clock util.Clock // Transition timestamps; nil = system clock
 }
 
 // ParallelLevel groups nodes that can execute concurrently.
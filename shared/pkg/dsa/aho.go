@@ -0,0 +1,92 @@
+package dsa
+
+// AhoCorasick is an immutable multi-pattern substring matcher.
+// Patterns compile to a DFA over a compressed byte alphabet, so matching is
+// one table lookup per input byte regardless of pattern count.
+// Safe for concurrent use after construction.
+type AhoCorasick struct {
+	class    [256]uint8 // byte -> alphabet class (0 = not in any pattern)
+	classes  int
+	delta    []int32 // state*classes + class -> next state
+	output   [][]int // state -> pattern indices ending here
+	patterns int
+}
+
+// NewAhoCorasick builds the automaton for patterns. Empty patterns are ignored.
+// Matching is byte-exact; lowercase patterns and input for case-insensitivity.
+func NewAhoCorasick(patterns []string) *AhoCorasick {
+	ac := &AhoCorasick{patterns: len(patterns), classes: 1}
+	for _, p := range patterns {
+		for i := 0; i < len(p); i++ {
+			if ac.class[p[i]] == 0 {
+				ac.class[p[i]] = uint8(ac.classes)
+				ac.classes++
+			}
+		}
+	}
+
+	// Trie over classes; -1 marks a missing edge until failure links fill it
+	newState := func() int {
+		for i := 0; i < ac.classes; i++ {
+			ac.delta = append(ac.delta, -1)
+		}
+		ac.output = append(ac.output, nil)
+		return len(ac.output) - 1
+	}
+	newState()
+	for idx, p := range patterns {
+		if p == "" {
+			continue
+		}
+		state := 0
+		for i := 0; i < len(p); i++ {
+			slot := state*ac.classes + int(ac.class[p[i]])
+			if ac.delta[slot] < 0 {
+				next := newState()
+				ac.delta[slot] = int32(next)
+			}
+			state = int(ac.delta[slot])
+		}
+		ac.output[state] = append(ac.output[state], idx)
+	}
+
+	// BFS: fold failure transitions into the table to get a full DFA
+	fail := make([]int, len(ac.output))
+	var queue []int
+	for c := 0; c < ac.classes; c++ {
+		if next := ac.delta[c]; next < 0 {
+			ac.delta[c] = 0
+		} else {
+			queue = append(queue, int(next))
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		ac.output[state] = append(ac.output[state], ac.output[fail[state]]...)
+		for c := 0; c < ac.classes; c++ {
+			slot := state*ac.classes + c
+			fallback := ac.delta[fail[state]*ac.classes+c]
+			if next := ac.delta[slot]; next < 0 {
+				ac.delta[slot] = fallback
+			} else {
+				fail[next] = int(fallback)
+				queue = append(queue, int(next))
+			}
+		}
+	}
+	return ac
+}
+
+// MatchSet returns which patterns occur in text, indexed like the input patterns.
+func (ac *AhoCorasick) MatchSet(text string) []bool {
+	found := make([]bool, ac.patterns)
+	state := 0
+	for i := 0; i < len(text); i++ {
+		state = int(ac.delta[state*ac.classes+int(ac.class[text[i]])])
+		for _, idx := range ac.output[state] {
+			found[idx] = true
+		}
+	}
+	return found
+}
@@ -3,6 +3,7 @@ package dsa
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -440,6 +441,26 @@ func TestPatternMatcher_Basic(t *testing.T) {
 	}
 }
 
+// ==================== AhoCorasick Tests ====================
+
+func TestAhoCorasickMatchSet(t *testing.T) {
+	patterns := []string{"he", "she", "his", "hers", "", "deploy", "not working"}
+	ac := NewAhoCorasick(patterns)
+
+	text := "ushers say the deploy is not working"
+	got := ac.MatchSet(text)
+	for i, p := range patterns {
+		want := p != "" && strings.Contains(text, p)
+		if got[i] != want {
+			t.Errorf("pattern %q: got %v, want %v", p, got[i], want)
+		}
+	}
+
+	if none := ac.MatchSet("xyz"); none[0] || none[5] {
+		t.Error("expected no matches for unrelated text")
+	}
+}
+
 // ==================== Benchmarks ====================
 
 func BenchmarkSet_Contains(b *testing.B) {
@@ -608,6 +629,30 @@ func TestLazyMap(t *testing.T) {
 	}
 }
 
+func TestLazyMapClear(t *testing.T) {
+	calls := 0
+	lm := NewLazyMap[string, int](func(key string) func() (int, error) {
+		return func() (int, error) {
+			calls++
+			return len(key), nil
+		}
+	})
+
+	lm.Get("a")
+	lm.Get("bb")
+	if dropped := lm.Clear(); dropped != 2 {
+		t.Errorf("Clear() = %d, want 2", dropped)
+	}
+	if lm.IsLoaded("a") || len(lm.Keys()) != 0 {
+		t.Error("expected empty map after Clear")
+	}
+
+	lm.Get("a")
+	if calls != 3 {
+		t.Errorf("expected reload after Clear, got %d calls", calls)
+	}
+}
+
 // ==================== FACT CACHE TESTS ====================
 
 func TestFactCache(t *testing.T) {
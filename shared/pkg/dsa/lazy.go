@@ -135,3 +135,19 @@ func (m *LazyMap[K, V]) LoadedKeys() []K {
 	}
 	return keys
 }
+
+// Clear drops every loader, returning how many values had been loaded.
+// Subsequent Get calls reload from the factory.
+func (m *LazyMap[K, V]) Clear() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dropped := 0
+	for _, loader := range m.cache {
+		if loader.IsLoaded() {
+			dropped++
+		}
+	}
+	m.cache = make(map[K]*LazyLoader[V])
+	return dropped
+}
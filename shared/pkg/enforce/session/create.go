@@ -17,7 +17,7 @@ func NewSessionState(workDir string) *SessionState {
 	id := generateSessionID(workDir)
 	return &SessionState{
 		ID:             id,
-		SessionID:      id, // Same as ID — used by DAG scheduler and task gate
+		SessionID:      id, // Mirrors ID; callers use ResolveID
 		Today:          time.Now().Format("2006-01-02"),
 		WorkDir:        workDir,
 		Project:        util.DetectProject(),
@@ -15,6 +15,16 @@ func StatePath() string {
 	return filepath.Join(util.STMPath(), "session-state.toon")
 }
 
+// ResolveID returns the canonical session identifier. Chain runs, DAG
+// state and task metadata are all keyed by it, so they line up for the
+// same session. State saved without an id falls back to session_id.
+func (s *SessionState) ResolveID() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.SessionID
+}
+
 // GetOrCreateSession returns existing or new session.
 // IMPORTANT: Always uses DetectProject() for current project since workdir changes.
 func GetOrCreateSession() *SessionState {
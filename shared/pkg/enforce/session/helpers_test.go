@@ -0,0 +1,37 @@
+package session
+
+import "testing"
+
+func TestResolveID(t *testing.T) {
+	for _, tc := range []struct{ id, sessionID, want string }{
+		{"sess_a", "sess_a", "sess_a"},
+		{"sess_a", "", "sess_a"},
+		{"", "sess_b", "sess_b"},
+		{"sess_a", "sess_b", "sess_a"}, // ID wins when they disagree
+		{"", "", ""},
+	} {
+		s := &SessionState{ID: tc.id, SessionID: tc.sessionID}
+		if got := s.ResolveID(); got != tc.want {
+			t.Errorf("ResolveID(id=%q, session_id=%q) = %q, want %q", tc.id, tc.sessionID, got, tc.want)
+		}
+	}
+}
+
+func TestLoadResolvesLegacyID(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// State saved before id was written carries only session_id
+	s := NewSessionState(t.TempDir())
+	s.ID, s.SessionID = "", "sess_legacy"
+	if err := s.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := LoadSessionState()
+	if err != nil || loaded == nil {
+		t.Fatalf("load: %v %v", loaded, err)
+	}
+	if loaded.ID != "sess_legacy" || loaded.SessionID != "sess_legacy" {
+		t.Errorf("loaded id=%q session_id=%q, want both sess_legacy", loaded.ID, loaded.SessionID)
+	}
+}
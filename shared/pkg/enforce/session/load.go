@@ -56,6 +56,8 @@ func LoadSessionState() (*SessionState, error) {
 	if !isValidToday(state.Today) {
 		return nil, nil
 	}
+	state.ID = state.ResolveID()
+	state.SessionID = state.ID // Older state files could disagree; ID wins
 
 	return state, scanner.Err()
 }
@@ -101,6 +103,22 @@ func parseField(state *SessionState, key, value string, inFiles *bool) {
 		state.TasksCompleted, _ = strconv.Atoi(value)
 	case "session_id":
 		state.SessionID = value
+	case "risk_score":
+		state.RiskScore, _ = strconv.Atoi(value)
+	case "advisories":
+		state.Advisories = splitCSV(value)
+	case "running_subagents":
+		state.Subagents = parseSubagents(value)
+	case "subagent_spawns":
+		state.SubagentSpawns = parseSpawns(value)
+	case "recommendations":
+		state.Recommendations = parseCounts(value)
+	case "retry_key":
+		state.RetryKey = value
+	case "retry_count":
+		state.RetryCount, _ = strconv.Atoi(value)
+	case "edits":
+		state.Edits = parseEdits(value)
 	case "task":
 		state.CurrentTask = value
 	case "task_status":
@@ -145,3 +163,54 @@ func splitCSV(s string) []string {
 	}
 	return result
 }
+
+// parseCounts parses "category=n" pairs written by joinCounts.
+func parseCounts(s string) map[string]int {
+	counts := make(map[string]int)
+	for _, pair := range splitCSV(s) {
+		k, v, ok := strings.Cut(pair, "=")
+		if n, err := strconv.Atoi(v); ok && err == nil {
+			counts[k] = n
+		}
+	}
+	return counts
+}
+
+// parseSubagents parses the pairs written by joinSubagents.
+func parseSubagents(s string) map[string]string {
+	agents := make(map[string]string)
+	for _, pair := range strings.Split(s, "|") {
+		if id, parent, ok := strings.Cut(pair, "="); ok && id != "" {
+			agents[id] = parent
+		}
+	}
+	return agents
+}
+
+// parseSpawns parses the spawns written by joinSpawns.
+func parseSpawns(s string) []Spawn {
+	var spawns []Spawn
+	for _, part := range strings.Split(s, "|") {
+		at, parent, ok := strings.Cut(part, " ")
+		if n, err := strconv.ParseInt(at, 10, 64); ok && err == nil {
+			spawns = append(spawns, Spawn{At: n, Parent: parent})
+		}
+	}
+	return spawns
+}
+
+// parseEdits parses the pairs written by joinEdits; the count follows the
+// last "=" so paths may contain one.
+func parseEdits(s string) map[string]int {
+	edits := make(map[string]int)
+	for _, pair := range strings.Split(s, "|") {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			continue
+		}
+		if n, err := strconv.Atoi(pair[i+1:]); err == nil {
+			edits[pair[:i]] = n
+		}
+	}
+	return edits
+}
@@ -100,3 +100,138 @@ func (s *SessionState) StoreIntent(intentType, domain string, subAgents, skills
 	s.IntentSkills = skills
 	s.Save()
 }
+
+// AddRisk adds chain findings to the session risk budget.
+// Called by: chain/pre-write gates after each verification run.
+func (s *SessionState) AddRisk(points int) {
+	if points <= 0 {
+		return
+	}
+	s.RiskScore += points
+	s.Save()
+}
+
+// ResetRisk clears the session risk budget.
+// Called by: session init on SessionStart (startup/clear).
+func (s *SessionState) ResetRisk() {
+	if s.RiskScore == 0 {
+		return
+	}
+	s.RiskScore = 0
+	s.Save()
+}
+
+// ResetRecommendations forgets the recommendation counts.
+// Called by: session init on SessionStart (startup/clear).
+func (s *SessionState) ResetRecommendations() {
+	if len(s.Recommendations) == 0 {
+		return
+	}
+	s.Recommendations = nil
+	s.Save()
+}
+
+// AddRecommendations counts Aegis recommendation categories for escalation.
+// Called by: chain/pre-write gates after each verification run.
+func (s *SessionState) AddRecommendations(categories []string) {
+	if len(categories) == 0 {
+		return
+	}
+	if s.Recommendations == nil {
+		s.Recommendations = make(map[string]int)
+	}
+	for _, c := range categories {
+		s.Recommendations[c]++
+	}
+	s.Save()
+}
+
+// MarkAdvisory records that an advisory category was emitted and reports
+// whether this is its first emission in the session.
+// Called by: gates before emitting a warn-once advisory.
+func (s *SessionState) MarkAdvisory(category string) bool {
+	for _, c := range s.Advisories {
+		if c == category {
+			return false
+		}
+	}
+	s.Advisories = append(s.Advisories, category)
+	s.Save()
+	return true
+}
+
+// ResetAdvisories forgets the advisories emitted by a previous session.
+// Called by: session init on SessionStart (startup/clear).
+func (s *SessionState) ResetAdvisories() {
+	if len(s.Advisories) == 0 {
+		return
+	}
+	s.Advisories = nil
+	s.Save()
+}
+
+// RecordFailure counts a failure of the call identified by key and returns
+// its consecutive failures; a different key starts over at 1.
+// Called by: failure gate on PostToolUseFailure.
+func (s *SessionState) RecordFailure(key string) int {
+	if s.RetryKey != key {
+		s.RetryKey = key
+		s.RetryCount = 0
+	}
+	s.RetryCount++
+	s.Save()
+	return s.RetryCount
+}
+
+// Failures returns the consecutive failures recorded for key.
+func (s *SessionState) Failures(key string) int {
+	if s.RetryKey != key {
+		return 0
+	}
+	return s.RetryCount
+}
+
+// ResetFailures forgets the tracked failures when key is empty or matches.
+// Called by: post-tool gates on success, session init on SessionStart.
+func (s *SessionState) ResetFailures(key string) {
+	if s.RetryKey == "" || (key != "" && s.RetryKey != key) {
+		return
+	}
+	s.RetryKey = ""
+	s.RetryCount = 0
+	s.Save()
+}
+
+// RecordEdit counts an edit to path and returns its edits since the last
+// test run or commit.
+// Called by: memory sync on PostToolUse Write/Edit.
+func (s *SessionState) RecordEdit(path string) int {
+	if s.Edits == nil {
+		s.Edits = make(map[string]int)
+	}
+	s.Edits[path]++
+	s.Save()
+	return s.Edits[path]
+}
+
+// EditCount returns the edits to path since its last test run or commit.
+func (s *SessionState) EditCount(path string) int {
+	return s.Edits[path]
+}
+
+// ResetEdits forgets the edit counts of paths, or of every file when none
+// are given.
+// Called by: memory sync after a test run or commit, session init on
+// SessionStart.
+func (s *SessionState) ResetEdits(paths ...string) {
+	if len(s.Edits) == 0 {
+		return
+	}
+	if len(paths) == 0 {
+		s.Edits = nil
+	}
+	for _, p := range paths {
+		delete(s.Edits, p)
+	}
+	s.Save()
+}
@@ -0,0 +1,160 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarkAdvisory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := NewSessionState(t.TempDir())
+
+	if !s.MarkAdvisory("large_file") {
+		t.Fatal("first large_file not reported as first")
+	}
+	if s.MarkAdvisory("large_file") {
+		t.Error("second large_file reported as first")
+	}
+	if !s.MarkAdvisory("hardcoded_localhost") {
+		t.Error("other category suppressed")
+	}
+
+	// Persisted across hook processes, forgotten on reset
+	loaded, err := LoadSessionState()
+	if err != nil || loaded == nil {
+		t.Fatalf("load: %v %v", loaded, err)
+	}
+	if loaded.MarkAdvisory("large_file") {
+		t.Error("large_file not persisted")
+	}
+	loaded.ResetAdvisories()
+	if !loaded.MarkAdvisory("large_file") {
+		t.Error("large_file still marked after reset")
+	}
+}
+
+func TestRecordEdit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := NewSessionState(t.TempDir())
+
+	for want := 1; want <= 3; want++ {
+		if got := s.RecordEdit("/src/a.go"); got != want {
+			t.Fatalf("RecordEdit #%d = %d", want, got)
+		}
+	}
+	s.RecordEdit("/src/b.go")
+
+	loaded, err := LoadSessionState()
+	if err != nil || loaded == nil {
+		t.Fatalf("load: %v %v", loaded, err)
+	}
+	if got := loaded.EditCount("/src/a.go"); got != 3 {
+		t.Errorf("persisted a.go edits = %d, want 3", got)
+	}
+
+	loaded.ResetEdits("/src/a.go")
+	if got := loaded.EditCount("/src/a.go"); got != 0 {
+		t.Errorf("a.go edits after reset = %d, want 0", got)
+	}
+	if got := loaded.EditCount("/src/b.go"); got != 1 {
+		t.Errorf("b.go edits after resetting a.go = %d, want 1", got)
+	}
+	loaded.ResetEdits()
+	if len(loaded.Edits) != 0 {
+		t.Errorf("edits after full reset = %v", loaded.Edits)
+	}
+}
+
+func TestResetRisk(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := NewSessionState(t.TempDir())
+	s.AddRisk(40)
+	s.AddRecommendations([]string{"privilege", "privilege"})
+
+	s.ResetRisk()
+	if s.RiskScore != 0 || s.Recommendations["privilege"] != 2 {
+		t.Errorf("after ResetRisk: risk %d, recommendations %v; want 0 and kept", s.RiskScore, s.Recommendations)
+	}
+	s.ResetRecommendations()
+	if len(s.Recommendations) != 0 {
+		t.Errorf("after ResetRecommendations: %v", s.Recommendations)
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := NewSessionState(t.TempDir())
+
+	for want := 1; want <= 3; want++ {
+		if got := s.RecordFailure("Bash:a"); got != want {
+			t.Fatalf("RecordFailure #%d = %d", want, got)
+		}
+	}
+	loaded, err := LoadSessionState()
+	if err != nil || loaded == nil {
+		t.Fatalf("load: %v %v", loaded, err)
+	}
+	if got := loaded.Failures("Bash:a"); got != 3 {
+		t.Errorf("persisted failures = %d, want 3", got)
+	}
+
+	// A different call starts over; the old one no longer counts
+	if got := loaded.RecordFailure("Bash:b"); got != 1 {
+		t.Errorf("new key = %d, want 1", got)
+	}
+	if got := loaded.Failures("Bash:a"); got != 0 {
+		t.Errorf("replaced key failures = %d, want 0", got)
+	}
+
+	loaded.ResetFailures("Bash:a")
+	if got := loaded.Failures("Bash:b"); got != 1 {
+		t.Errorf("reset of another key cleared failures: %d", got)
+	}
+	loaded.ResetFailures("Bash:b")
+	if loaded.RetryKey != "" || loaded.RetryCount != 0 {
+		t.Errorf("after reset: %q %d", loaded.RetryKey, loaded.RetryCount)
+	}
+}
+
+func TestRecordRead(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := NewSessionState(t.TempDir())
+	start := time.Unix(1_700_000_000, 0)
+
+	s.RecordRead("/src/a", start, time.Minute)
+	s.RecordRead("/src/b", start.Add(10*time.Second), time.Minute)
+	count, dirs := s.RecordRead("/src/a", start.Add(20*time.Second), time.Minute)
+	if count != 3 || !reflect.DeepEqual(dirs, []string{"/src/a", "/src/b"}) {
+		t.Fatalf("in window: %d %v", count, dirs)
+	}
+
+	// Another hook process sees the same log; old reads leave the window
+	other := NewSessionState(t.TempDir())
+	count, dirs = other.RecordRead("/src/c", start.Add(75*time.Second), time.Minute)
+	if count != 2 || !reflect.DeepEqual(dirs, []string{"/src/a", "/src/c"}) {
+		t.Errorf("after window slid: %d %v", count, dirs)
+	}
+
+	s.ResetReads()
+	if count, _ := s.RecordRead("/src/d", start.Add(80*time.Second), time.Minute); count != 1 {
+		t.Errorf("after reset: %d reads", count)
+	}
+}
+
+func TestRecordReadBounded(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := NewSessionState(t.TempDir())
+	now := time.Unix(1_700_000_000, 0)
+
+	var count int
+	for i := 0; i <= 2*maxReadEvents; i++ {
+		count, _ = s.RecordRead("/src", now, time.Hour)
+	}
+	if count != maxReadEvents {
+		t.Errorf("count = %d, want cap %d", count, maxReadEvents)
+	}
+	if n := len(readEvents(ReadLogPath())); n != maxReadEvents {
+		t.Errorf("log holds %d events after compaction, want %d", n, maxReadEvents)
+	}
+}
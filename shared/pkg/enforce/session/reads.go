@@ -0,0 +1,116 @@
+// Package session provides session state management.
+// reads.go: Read log for harvest detection. Reads are appended one line
+// per event to their own file under a lock, so concurrent Read hooks never
+// lose events and the state file is not rewritten on every read.
+package session
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/claude/shared/lock"
+	"github.com/claude/shared/pkg/util"
+)
+
+// maxReadEvents caps the reads counted however long the harvest window
+// is; the log is compacted to them once it holds twice as many lines.
+const maxReadEvents = 1000
+
+// ReadLogPath returns the read log next to the session state file.
+func ReadLogPath() string {
+	return filepath.Join(filepath.Dir(StatePath()), "reads.log")
+}
+
+// RecordRead logs a read in dir at now and returns the reads within
+// window before now and their distinct directories.
+// Called by: read gate on Read/Glob/Grep.
+func (s *SessionState) RecordRead(dir string, now time.Time, window time.Duration) (int, []string) {
+	path := ReadLogPath()
+	if err := util.EnsureParentDir(path); err != nil {
+		return 0, nil
+	}
+	lm := lock.GetLockManager()
+	if err := lm.Acquire(path); err == nil {
+		defer lm.Release(path)
+	}
+
+	events := readEvents(path)
+	event := ReadEvent{At: now.Unix(), Dir: dir}
+	events = append(events, event)
+
+	cutoff := now.Add(-window).Unix()
+	kept := make([]ReadEvent, 0, len(events))
+	for _, r := range events {
+		if r.At >= cutoff {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) > maxReadEvents {
+		kept = kept[len(kept)-maxReadEvents:]
+	}
+
+	if len(events) > 2*maxReadEvents {
+		writeEvents(path, kept)
+	} else if f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err == nil {
+		fmt.Fprintf(f, "%d %s\n", event.At, event.Dir)
+		f.Close()
+	}
+
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, r := range kept {
+		if !seen[r.Dir] {
+			seen[r.Dir] = true
+			dirs = append(dirs, r.Dir)
+		}
+	}
+	sort.Strings(dirs)
+	return len(kept), dirs
+}
+
+// ResetReads forgets the reads logged by a previous session.
+// Called by: session init on SessionStart (startup/clear).
+func (s *SessionState) ResetReads() {
+	os.Remove(ReadLogPath())
+}
+
+// readEvents parses the "unix dir" lines of the read log.
+func readEvents(path string) []ReadEvent {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var events []ReadEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		at, dir, ok := strings.Cut(scanner.Text(), " ")
+		if n, err := strconv.ParseInt(at, 10, 64); ok && err == nil {
+			events = append(events, ReadEvent{At: n, Dir: dir})
+		}
+	}
+	return events
+}
+
+// writeEvents replaces the read log with events.
+func writeEvents(path string, events []ReadEvent) {
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return
+	}
+	w := bufio.NewWriter(f)
+	for _, r := range events {
+		fmt.Fprintf(w, "%d %s\n", r.At, r.Dir)
+	}
+	w.Flush()
+	f.Close()
+	os.Rename(tmpPath, path)
+}
@@ -6,6 +6,8 @@ package session
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/claude/shared/lock"
 	"github.com/claude/shared/pkg/util"
@@ -74,6 +76,26 @@ func writeStateBlock(f *os.File, s *SessionState) {
 	fmt.Fprintf(f, "tasks_created: %d\n", s.TasksCreated)
 	fmt.Fprintf(f, "tasks_completed: %d\n", s.TasksCompleted)
 	fmt.Fprintf(f, "session_id: %s\n", s.SessionID)
+	fmt.Fprintf(f, "risk_score: %d\n", s.RiskScore)
+	if len(s.Advisories) > 0 {
+		fmt.Fprintf(f, "advisories: %s\n", joinCSV(s.Advisories))
+	}
+	if s.RetryKey != "" {
+		fmt.Fprintf(f, "retry_key: %s\n", s.RetryKey)
+		fmt.Fprintf(f, "retry_count: %d\n", s.RetryCount)
+	}
+	if len(s.Subagents) > 0 {
+		fmt.Fprintf(f, "running_subagents: %s\n", joinSubagents(s.Subagents))
+	}
+	if len(s.SubagentSpawns) > 0 {
+		fmt.Fprintf(f, "subagent_spawns: %s\n", joinSpawns(s.SubagentSpawns))
+	}
+	if len(s.Edits) > 0 {
+		fmt.Fprintf(f, "edits: %s\n", joinEdits(s.Edits))
+	}
+	if len(s.Recommendations) > 0 {
+		fmt.Fprintf(f, "recommendations: %s\n", joinCounts(s.Recommendations))
+	}
 	fmt.Fprintln(f)
 }
 
@@ -120,6 +142,58 @@ func joinCSV(items []string) string {
 	return result
 }
 
+// joinSubagents renders "id=parent" pairs in sorted order separated by
+// "|"; the main agent's children have an empty parent.
+func joinSubagents(agents map[string]string) string {
+	ids := make([]string, 0, len(agents))
+	for id := range agents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		ids[i] = id + "=" + agents[id]
+	}
+	return strings.Join(ids, "|")
+}
+
+// joinSpawns renders "unix parent" spawns separated by "|".
+func joinSpawns(spawns []Spawn) string {
+	parts := make([]string, len(spawns))
+	for i, sp := range spawns {
+		parts[i] = fmt.Sprintf("%d %s", sp.At, sp.Parent)
+	}
+	return strings.Join(parts, "|")
+}
+
+// joinEdits renders "path=n" pairs in sorted order separated by "|",
+// since paths may contain commas.
+func joinEdits(edits map[string]int) string {
+	paths := make([]string, 0, len(edits))
+	for p := range edits {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	pairs := make([]string, len(paths))
+	for i, p := range paths {
+		pairs[i] = fmt.Sprintf("%s=%d", p, edits[p])
+	}
+	return strings.Join(pairs, "|")
+}
+
+// joinCounts renders "category=n" pairs in sorted order.
+func joinCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%d", k, counts[k])
+	}
+	return joinCSV(pairs)
+}
+
 func writeFilesArray(f *os.File, files []string) {
 	if len(files) == 0 {
 		fmt.Fprintln(f, "files[]:")
@@ -0,0 +1,88 @@
+// Package session provides session state management.
+// subagents.go: Subagent nesting. Each running subagent is kept with the
+// agent that spawned it, so depth is the length of its spawner chain.
+package session
+
+import "time"
+
+// spawnTTL drops Task spawns whose subagent never started (the call was
+// denied or failed) so they are not matched to a later SubagentStart.
+const spawnTTL = 10 * time.Minute
+
+// SubagentDepth returns how deep agent id is nested: 0 for the main agent
+// (""), 1 for a subagent it spawned, 2 for one spawned by that, and so on.
+func (s *SessionState) SubagentDepth(id string) int {
+	depth := 0
+	for seen := map[string]bool{}; id != "" && !seen[id]; depth++ {
+		seen[id] = true
+		parent, ok := s.Subagents[id]
+		if !ok {
+			// Not tracked (spawned before kavach saw it): count it alone
+			return depth + 1
+		}
+		id = parent
+	}
+	return depth
+}
+
+// MaxSubagentDepth returns the depth of the most nested running subagent.
+func (s *SessionState) MaxSubagentDepth() int {
+	max := 0
+	for id := range s.Subagents {
+		if d := s.SubagentDepth(id); d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// QueueSpawn records a Task call by parent whose subagent is about to start.
+// Called by: CEO gate on PreToolUse:Task once the spawn is allowed.
+func (s *SessionState) QueueSpawn(parent string, now time.Time) {
+	s.SubagentSpawns = append(s.SubagentSpawns, Spawn{At: now.Unix(), Parent: parent})
+	s.Save()
+}
+
+// EnterSubagent records a subagent start, attributing it to the oldest
+// pending spawn (else the main agent), and returns its depth.
+// Called by: subagent gate on SubagentStart.
+func (s *SessionState) EnterSubagent(id string, now time.Time) int {
+	parent := ""
+	cutoff := now.Add(-spawnTTL).Unix()
+	for len(s.SubagentSpawns) > 0 {
+		sp := s.SubagentSpawns[0]
+		s.SubagentSpawns = s.SubagentSpawns[1:]
+		if sp.At >= cutoff {
+			parent = sp.Parent
+			break
+		}
+	}
+	if s.Subagents == nil {
+		s.Subagents = make(map[string]string)
+	}
+	s.Subagents[id] = parent
+	s.Save()
+	return s.SubagentDepth(id)
+}
+
+// ExitSubagent records a subagent stop and returns the depth it ran at.
+// Called by: subagent gate on SubagentStop.
+func (s *SessionState) ExitSubagent(id string) int {
+	depth := s.SubagentDepth(id)
+	if _, ok := s.Subagents[id]; ok {
+		delete(s.Subagents, id)
+		s.Save()
+	}
+	return depth
+}
+
+// ResetSubagents forgets subagents and spawns left by an earlier run.
+// Called by: session init on every SessionStart.
+func (s *SessionState) ResetSubagents() {
+	if len(s.Subagents) == 0 && len(s.SubagentSpawns) == 0 {
+		return
+	}
+	s.Subagents = nil
+	s.SubagentSpawns = nil
+	s.Save()
+}
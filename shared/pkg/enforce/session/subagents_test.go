@@ -0,0 +1,52 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubagentDepth(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := NewSessionState(t.TempDir())
+	now := time.Unix(1_700_000_000, 0)
+
+	// Main agent spawns two siblings; one of them spawns a grandchild
+	s.QueueSpawn("", now)
+	s.QueueSpawn("", now)
+	if d := s.EnterSubagent("a", now); d != 1 {
+		t.Errorf("a depth = %d, want 1", d)
+	}
+	if d := s.EnterSubagent("b", now); d != 1 {
+		t.Errorf("sibling b depth = %d, want 1", d)
+	}
+	s.QueueSpawn("a", now)
+	if d := s.EnterSubagent("c", now); d != 2 {
+		t.Errorf("grandchild c depth = %d, want 2", d)
+	}
+	if got := s.SubagentDepth("c") + 1; got != 3 {
+		t.Errorf("a spawn by c would nest at %d, want 3", got)
+	}
+
+	// Persisted across hook processes
+	loaded, err := LoadSessionState()
+	if err != nil || loaded == nil {
+		t.Fatalf("load: %v %v", loaded, err)
+	}
+	if d := loaded.MaxSubagentDepth(); d != 2 {
+		t.Errorf("persisted max depth = %d, want 2", d)
+	}
+	if d := loaded.ExitSubagent("c"); d != 2 || loaded.MaxSubagentDepth() != 1 {
+		t.Errorf("exit c: depth %d, max now %d", d, loaded.MaxSubagentDepth())
+	}
+
+	// A stale spawn (denied Task) is not matched to a later start
+	loaded.QueueSpawn("a", now)
+	if d := loaded.EnterSubagent("d", now.Add(time.Hour)); d != 1 {
+		t.Errorf("stale spawn attributed: depth %d, want 1", d)
+	}
+
+	loaded.ResetSubagents()
+	if len(loaded.Subagents) != 0 || len(loaded.SubagentSpawns) != 0 || loaded.MaxSubagentDepth() != 0 {
+		t.Errorf("after reset: %v %v", loaded.Subagents, loaded.SubagentSpawns)
+	}
+}
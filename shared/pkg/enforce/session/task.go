@@ -24,6 +24,17 @@ func (s *SessionState) AddFileModified(filePath string) {
 	s.Save()
 }
 
+// HasModified reports whether filePath was written in this session.
+// Called by: pre-write gate to exempt the session's own uncommitted edits.
+func (s *SessionState) HasModified(filePath string) bool {
+	for _, f := range s.FilesModified {
+		if f == filePath {
+			return true
+		}
+	}
+	return false
+}
+
 // ClearTask clears the current task state.
 // Called by: task gate on task completion/deletion.
 func (s *SessionState) ClearTask() {
@@ -20,6 +20,31 @@ type SessionState struct {
 	AegisVerified  bool
 	TrainingCutoff string
 
+	// Risk budget: cumulative chain findings (reset on SessionStart)
+	RiskScore int
+
+	// Aegis recommendation categories seen this session (reset on SessionStart)
+	Recommendations map[string]int
+
+	// Advisory categories already emitted this session, for warn-once
+	// suppression (reset on SessionStart)
+	Advisories []string
+
+	// Consecutive failures of one tool call, keyed by its signature; a
+	// different call or a success starts over (reset on SessionStart)
+	RetryKey   string
+	RetryCount int
+
+	// Edits per file since its last test run or commit (reset on
+	// SessionStart)
+	Edits map[string]int
+
+	// Running subagents by id, each with the id of the agent that spawned
+	// it ("" = main agent), and the spawners of Task calls whose subagent
+	// has not started yet (reset on SessionStart)
+	Subagents      map[string]string
+	SubagentSpawns []Spawn
+
 	// Compact tracking
 	PostCompact  bool
 	CompactedAt  string
@@ -36,7 +61,7 @@ type SessionState struct {
 	FilesModified []string
 
 	// Task management (Claude Code 2.1.19+)
-	SessionID      string // Session identifier for multi-session coordination
+	SessionID      string // Mirrors ID; read it through ResolveID
 	TasksCreated   int    // Count of tasks created this session
 	TasksCompleted int    // Count of tasks completed this session
 	TaskListID     string // CLAUDE_CODE_TASK_LIST_ID for shared task lists
@@ -47,3 +72,17 @@ type SessionState struct {
 	IntentSubAgents []string // e.g., ["research-director", "backend-engineer"]
 	IntentSkills    []string // e.g., ["/security", "/rust"]
 }
+
+// Spawn is a Task call let through by the CEO gate: its Unix time and
+// the id of the agent that made it ("" = main agent).
+type Spawn struct {
+	At     int64
+	Parent string
+}
+
+// ReadEvent is one file read: its Unix time and parent directory. Reads
+// are appended to their own log (see RecordRead), not the state file.
+type ReadEvent struct {
+	At  int64
+	Dir string
+}
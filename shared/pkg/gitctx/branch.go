@@ -0,0 +1,148 @@
+// Package gitctx provides git context detection for gates.
+// branch.go: Protected branch detection for git commit/push commands.
+package gitctx
+
+import (
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Target is a git operation aimed at a branch. Branch is empty when the
+// command does not name one (the current branch is implied).
+type Target struct {
+	Op     string // "commit" or "push"
+	Branch string
+}
+
+// ParseTargets extracts commit/push targets from a shell command.
+// Handles chained commands (&&, ||, ;, |) and push refspecs (src:dst, +dst,
+// refs/heads/dst). Pushes naming only a remote target the current branch.
+func ParseTargets(command string) []Target {
+	var targets []Target
+	for _, segment := range splitSegments(command) {
+		args := gitArgs(strings.Fields(segment))
+		if len(args) == 0 {
+			continue
+		}
+		switch args[0] {
+		case "commit":
+			targets = append(targets, Target{Op: "commit"})
+		case "push":
+			var positional []string
+			for _, a := range args[1:] {
+				if !strings.HasPrefix(a, "-") {
+					positional = append(positional, a)
+				}
+			}
+			if len(positional) < 2 {
+				targets = append(targets, Target{Op: "push"})
+				continue
+			}
+			for _, refspec := range positional[1:] {
+				if branch := refspecBranch(refspec); branch != "" {
+					targets = append(targets, Target{Op: "push", Branch: branch})
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// CurrentBranch returns the checked-out branch in dir via git symbolic-ref,
+// or "" when unavailable (not a repo, detached HEAD, git missing).
+func CurrentBranch(dir string) string {
+	cmd := exec.Command("git", "symbolic-ref", "--short", "-q", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// MatchProtected returns the protected pattern matching branch, or "".
+// Patterns support path globs (release/*).
+func MatchProtected(branch string, protected []string) string {
+	if branch == "" {
+		return ""
+	}
+	for _, p := range protected {
+		if p == branch {
+			return p
+		}
+		if ok, _ := path.Match(p, branch); ok {
+			return p
+		}
+	}
+	return ""
+}
+
+// Violation describes a commit/push that targets a protected branch.
+type Violation struct {
+	Op      string
+	Branch  string
+	Pattern string
+}
+
+// DetectProtected checks command against protected branches. Targets without
+// an explicit branch resolve to the current branch in dir.
+func DetectProtected(command, dir string, protected []string) *Violation {
+	if len(protected) == 0 || !strings.Contains(command, "git") {
+		return nil
+	}
+	current := ""
+	resolved := false
+	for _, t := range ParseTargets(command) {
+		branch := t.Branch
+		if branch == "" {
+			if !resolved {
+				current, resolved = CurrentBranch(dir), true
+			}
+			branch = current
+		}
+		if pattern := MatchProtected(branch, protected); pattern != "" {
+			return &Violation{Op: t.Op, Branch: branch, Pattern: pattern}
+		}
+	}
+	return nil
+}
+
+// splitSegments splits a command line on shell control operators.
+func splitSegments(command string) []string {
+	r := strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", "\n")
+	return strings.Split(r.Replace(command), "\n")
+}
+
+// gitArgs returns the git subcommand and its args, skipping global options
+// such as -C <dir> and -c key=val. Returns nil for non-git segments.
+func gitArgs(fields []string) []string {
+	if len(fields) == 0 || path.Base(fields[0]) != "git" {
+		return nil
+	}
+	args := fields[1:]
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-C" || args[0] == "-c" {
+			if len(args) < 2 {
+				return nil
+			}
+			args = args[2:]
+			continue
+		}
+		args = args[1:]
+	}
+	return args
+}
+
+// refspecBranch returns the destination branch of a push refspec.
+func refspecBranch(refspec string) string {
+	refspec = strings.TrimPrefix(refspec, "+")
+	if i := strings.LastIndex(refspec, ":"); i >= 0 {
+		refspec = refspec[i+1:]
+	}
+	refspec = strings.TrimPrefix(refspec, "refs/heads/")
+	if refspec == "HEAD" {
+		return ""
+	}
+	return refspec
+}
@@ -0,0 +1,131 @@
+package gitctx
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+var protected = []string{"main", "master", "production", "release/*"}
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []Target
+	}{
+		{"git push origin main", []Target{{"push", "main"}}},
+		{"git add . && git commit -m 'x' && git push -u origin feature/x", []Target{{"commit", ""}, {"push", "feature/x"}}},
+		{"git push origin HEAD:refs/heads/release/1.2", []Target{{"push", "release/1.2"}}},
+		{"git push --force origin +master", []Target{{"push", "master"}}},
+		{"git -C /repo push", []Target{{"push", ""}}},
+		{"git status; ls", nil},
+	}
+	for _, tt := range tests {
+		got := ParseTargets(tt.command)
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseTargets(%q) = %v, want %v", tt.command, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseTargets(%q)[%d] = %v, want %v", tt.command, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestMatchProtected(t *testing.T) {
+	for branch, want := range map[string]string{
+		"main":        "main",
+		"release/1.2": "release/*",
+		"feature/x":   "",
+		"":            "",
+	} {
+		if got := MatchProtected(branch, protected); got != want {
+			t.Errorf("MatchProtected(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestDetectProtectedCurrentBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if err := exec.Command("git", "-C", dir, "init", "-q", "-b", "production").Run(); err != nil {
+		t.Skipf("git init: %v", err)
+	}
+
+	v := DetectProtected("git commit -m 'wip'", dir, protected)
+	if v == nil || v.Branch != "production" || v.Op != "commit" {
+		t.Errorf("DetectProtected(commit on production) = %+v", v)
+	}
+	if v := DetectProtected("git push origin feature/x", dir, protected); v != nil {
+		t.Errorf("explicit feature push should pass, got %+v", v)
+	}
+	if v := DetectProtected("go test ./...", dir, protected); v != nil {
+		t.Errorf("non-git command flagged: %+v", v)
+	}
+}
+
+func TestCheckCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if err := exec.Command("git", "-C", dir, "init", "-q").Run(); err != nil {
+		t.Skipf("git init: %v", err)
+	}
+	exec.Command("git", "-C", dir, "config", "user.name", "Agent").Run()
+	exec.Command("git", "-C", dir, "config", "user.email", "agent@example.com").Run()
+	t.Setenv("GIT_AUTHOR_NAME", "")
+	t.Setenv("GIT_AUTHOR_EMAIL", "")
+
+	policy := CommitPolicy{AuthorPattern: `@corp\.example>$`, RequireSigning: true}
+	rules := func(command string) string {
+		issues, err := CheckCommits(command, dir, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, i := range issues {
+			out = append(out, i.Rule)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := map[string]string{
+		"git commit -m 'wip'": "author,signing",
+		`git commit -S --author="Dev <dev@corp.example>" -m "x"`:                  "",
+		"git -c user.email=dev@corp.example -c commit.gpgsign=true commit":        "",
+		"GIT_AUTHOR_EMAIL=dev@corp.example git commit --gpg-sign -m x":            "",
+		"git add . && git commit --no-gpg-sign --author 'Dev <dev@corp.example>'": "signing",
+		"git status": "",
+	}
+	for command, want := range tests {
+		if got := rules(command); got != want {
+			t.Errorf("%s: issues = %q, want %q", command, got, want)
+		}
+	}
+
+	exec.Command("git", "-C", dir, "config", "commit.gpgsign", "true").Run()
+	if got := rules("git commit --author='Dev <dev@corp.example>' -m x"); got != "" {
+		t.Errorf("gpgsign from git config: issues = %q, want none", got)
+	}
+
+	// -C runs git in another repo: its config decides
+	other := t.TempDir()
+	exec.Command("git", "-C", other, "init", "-q").Run()
+	exec.Command("git", "-C", other, "config", "user.email", "dev@corp.example").Run()
+	exec.Command("git", "-C", other, "config", "commit.gpgsign", "true").Run()
+	if got := rules("git -C " + other + " commit -m x"); got != "" {
+		t.Errorf("-C repo config: issues = %q, want none", got)
+	}
+	if got := rules("git -C " + other + " -C " + dir + " commit --no-gpg-sign -m x"); got != "author,signing" {
+		t.Errorf("second -C: issues = %q, want author,signing", got)
+	}
+
+	if _, err := CheckCommits("git commit", dir, CommitPolicy{AuthorPattern: "("}); err == nil {
+		t.Error("expected error for invalid author_pattern")
+	}
+}
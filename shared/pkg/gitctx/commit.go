@@ -0,0 +1,223 @@
+// Package gitctx provides git context detection for gates.
+// commit.go: Author and signing policy for git commit commands.
+package gitctx
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CommitPolicy is the compliance requirement for commits.
+type CommitPolicy struct {
+	AuthorPattern  string // Regexp matched against "Name <email>"; "" skips
+	RequireSigning bool
+}
+
+// Enabled reports whether the policy checks anything.
+func (p CommitPolicy) Enabled() bool {
+	return p.AuthorPattern != "" || p.RequireSigning
+}
+
+// CommitIssue is one unmet requirement and the flags that fix it.
+type CommitIssue struct {
+	Rule   string // "author" or "signing"
+	Detail string
+	Fix    string
+}
+
+// Commit is a git commit command with the identity it resolves to.
+type Commit struct {
+	Author string // "Name <email>"; empty when unknown
+	Signed bool
+}
+
+// ParseCommits resolves each git commit in command: --author, -c
+// user.name/user.email, inline and process GIT_AUTHOR_* env, then git config
+// in dir (or its -C directory); -S/--gpg-sign, --no-gpg-sign, -c
+// commit.gpgsign, then git config.
+func ParseCommits(command, dir string) []Commit {
+	var commits []Commit
+	for _, segment := range splitSegments(command) {
+		fields := shellFields(segment)
+		env := map[string]string{}
+		for len(fields) > 0 && isAssignment(fields[0]) {
+			k, v, _ := strings.Cut(fields[0], "=")
+			env[k] = v
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || path.Base(fields[0]) != "git" {
+			continue
+		}
+		overrides, repo, args := gitOptions(fields[1:], dir)
+		if len(args) == 0 || args[0] != "commit" {
+			continue
+		}
+		commits = append(commits, resolveCommit(args[1:], overrides, env, repo))
+	}
+	return commits
+}
+
+// CheckCommits returns the policy issues for commits in command.
+func CheckCommits(command, dir string, p CommitPolicy) ([]CommitIssue, error) {
+	if !p.Enabled() || !strings.Contains(command, "commit") {
+		return nil, nil
+	}
+	var re *regexp.Regexp
+	if p.AuthorPattern != "" {
+		var err error
+		if re, err = regexp.Compile(p.AuthorPattern); err != nil {
+			return nil, fmt.Errorf("author_pattern: %w", err)
+		}
+	}
+	var issues []CommitIssue
+	for _, c := range ParseCommits(command, dir) {
+		if re != nil && !re.MatchString(c.Author) {
+			author := c.Author
+			if author == "" {
+				author = "unknown"
+			}
+			issues = append(issues, CommitIssue{
+				Rule:   "author",
+				Detail: fmt.Sprintf("author %s does not match %s", author, p.AuthorPattern),
+				Fix:    `git commit --author="Name <email>" (matching ` + p.AuthorPattern + `) or git config user.email`,
+			})
+		}
+		if p.RequireSigning && !c.Signed {
+			issues = append(issues, CommitIssue{
+				Rule:   "signing",
+				Detail: "commit is not signed",
+				Fix:    "git commit -S (or git config commit.gpgsign true)",
+			})
+		}
+	}
+	return issues, nil
+}
+
+func resolveCommit(args []string, overrides, env map[string]string, dir string) Commit {
+	var c Commit
+	signing := ""
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--author" && i+1 < len(args):
+			i++
+			c.Author = args[i]
+		case strings.HasPrefix(a, "--author="):
+			c.Author = strings.TrimPrefix(a, "--author=")
+		case strings.HasPrefix(a, "-S"), a == "--gpg-sign", strings.HasPrefix(a, "--gpg-sign="):
+			signing = "true"
+		case a == "--no-gpg-sign":
+			signing = "false"
+		}
+	}
+
+	if c.Author == "" {
+		name := firstSet(overrides["user.name"], env["GIT_AUTHOR_NAME"], os.Getenv("GIT_AUTHOR_NAME"))
+		email := firstSet(overrides["user.email"], env["GIT_AUTHOR_EMAIL"], os.Getenv("GIT_AUTHOR_EMAIL"))
+		if name == "" {
+			name = gitConfig(dir, "user.name")
+		}
+		if email == "" {
+			email = gitConfig(dir, "user.email")
+		}
+		if name != "" || email != "" {
+			c.Author = strings.TrimSpace(name + " <" + email + ">")
+		}
+	}
+
+	if signing == "" {
+		signing = strings.ToLower(overrides["commit.gpgsign"])
+	}
+	if signing == "" {
+		signing = gitConfig(dir, "commit.gpgsign")
+	}
+	c.Signed = signing == "true" || signing == "yes" || signing == "on" || signing == "1"
+	return c
+}
+
+// gitOptions splits global options (-C dir, -c key=val) from the
+// subcommand. dir is where git runs: each -C changes it, relative to the
+// previous one as git does.
+func gitOptions(fields []string, dir string) (map[string]string, string, []string) {
+	overrides := map[string]string{}
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+		if (fields[0] == "-C" || fields[0] == "-c") && len(fields) > 1 {
+			if fields[0] == "-c" {
+				k, v, _ := strings.Cut(fields[1], "=")
+				overrides[strings.ToLower(k)] = v
+			} else if filepath.IsAbs(fields[1]) || dir == "" {
+				dir = fields[1]
+			} else {
+				dir = filepath.Join(dir, fields[1])
+			}
+			fields = fields[2:]
+			continue
+		}
+		fields = fields[1:]
+	}
+	return overrides, dir, fields
+}
+
+// gitConfig reads a config value in dir, or "" when unset.
+func gitConfig(dir, key string) string {
+	cmd := exec.Command("git", "config", "--get", key)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// shellFields splits on whitespace, keeping single- and double-quoted runs
+// together and dropping the quotes.
+func shellFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	inField := false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inField = r, true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, cur.String())
+				cur.Reset()
+				inField = false
+			}
+		default:
+			cur.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+func isAssignment(field string) bool {
+	k, _, ok := strings.Cut(field, "=")
+	return ok && k != "" && !strings.ContainsAny(k, "-/.")
+}
+
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
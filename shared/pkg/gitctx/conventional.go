@@ -0,0 +1,310 @@
+// Package gitctx provides git context detection for gates.
+// conventional.go: Commit message extraction (-m, --message, heredocs) and
+// conventional-commit validation with a suggested rewrite.
+package gitctx
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCommitTypes are the conventional-commit types allowed by default.
+var DefaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// heredocPlaceholder marks where a heredoc body was lifted out of a command.
+const heredocPlaceholder = "\x00heredoc:"
+
+var (
+	heredocStart = regexp.MustCompile(`<<(-?)\s*['"]?([A-Za-z_][A-Za-z0-9_]*)['"]?`)
+	conventional = regexp.MustCompile(`^([a-z]+)(\([^()\s][^()]*\))?(!)?: \S`)
+	loosePrefix  = regexp.MustCompile(`^([A-Za-z]+)(\([^()]*\))?(!)?\s*[:\-]\s*`)
+)
+
+// CommitMessages returns the message of each git commit in command that sets
+// one via -m/--message (repeated flags join as paragraphs) or -F - with a
+// heredoc, including the -m "$(cat <<'EOF' ... EOF)" form. Commits that
+// open an editor or read a file are skipped.
+func CommitMessages(command string) []string {
+	command, bodies := liftHeredocs(command)
+	var messages []string
+	for _, segment := range splitQuoted(command) {
+		fields := shellFields(segment)
+		for len(fields) > 0 && isAssignment(fields[0]) {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || path.Base(fields[0]) != "git" {
+			continue
+		}
+		_, _, args := gitOptions(fields[1:], "")
+		if len(args) == 0 || args[0] != "commit" {
+			continue
+		}
+		if msg, ok := commitMessage(args[1:], bodies); ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// commitMessage collects -m/--message values and -F - heredocs.
+func commitMessage(args []string, bodies []string) (string, bool) {
+	var parts []string
+	fromStdin := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		value, flag := "", ""
+		switch {
+		case a == "--message" || a == "--file":
+			flag = a
+			if i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+		case strings.HasPrefix(a, "--message="), strings.HasPrefix(a, "--file="):
+			flag, value, _ = strings.Cut(a, "=")
+		case len(a) > 1 && a[0] == '-' && a[1] != '-':
+			// Short cluster such as -am "msg" or -m"msg"
+			for j := 1; j < len(a); j++ {
+				if a[j] != 'm' && a[j] != 'F' {
+					continue
+				}
+				flag, value = "-"+string(a[j]), a[j+1:]
+				if value == "" && i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+				break
+			}
+		}
+		switch flag {
+		case "-m", "--message":
+			parts = append(parts, expandHeredoc(value, bodies))
+		case "-F", "--file":
+			fromStdin = value == "-"
+		}
+	}
+	if len(parts) == 0 && fromStdin {
+		for _, a := range args {
+			if strings.HasPrefix(a, heredocPlaceholder) {
+				parts = append(parts, expandHeredoc(a, bodies))
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, "\n\n"), true
+}
+
+// expandHeredoc resolves a "$(cat <<EOF ...)" value to the heredoc body.
+func expandHeredoc(value string, bodies []string) string {
+	i := strings.Index(value, heredocPlaceholder)
+	if i < 0 {
+		return value
+	}
+	rest := value[i+len(heredocPlaceholder):]
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(rest)
+	}
+	n, err := strconv.Atoi(rest[:end])
+	if err != nil || n >= len(bodies) {
+		return value
+	}
+	return bodies[n]
+}
+
+// liftHeredocs replaces each heredoc (operator through terminator line) with
+// a numbered placeholder and returns the bodies.
+func liftHeredocs(command string) (string, []string) {
+	var bodies []string
+	var out strings.Builder
+	for {
+		loc := heredocStart.FindStringSubmatchIndex(command)
+		if loc == nil {
+			break
+		}
+		nl := strings.IndexByte(command[loc[1]:], '\n')
+		if nl < 0 {
+			break
+		}
+		stripTabs := loc[3] > loc[2]
+		delim := command[loc[4]:loc[5]]
+		// The rest of the operator line stays; the body follows it
+		lineRest := command[loc[1] : loc[1]+nl]
+		body := command[loc[1]+nl+1:]
+		var lines []string
+		consumed := len(body)
+		offset := 0
+		for _, line := range strings.SplitAfter(body, "\n") {
+			trimmed := strings.TrimRight(line, "\n")
+			if stripTabs {
+				trimmed = strings.TrimLeft(trimmed, "\t")
+			}
+			if strings.TrimSpace(trimmed) == delim {
+				consumed = offset + len(line)
+				break
+			}
+			lines = append(lines, trimmed)
+			offset += len(line)
+		}
+		out.WriteString(command[:loc[0]])
+		fmt.Fprintf(&out, "%s%d", heredocPlaceholder, len(bodies))
+		out.WriteString(lineRest)
+		bodies = append(bodies, strings.Join(lines, "\n"))
+		out.WriteString("\n")
+		command = body[consumed:]
+	}
+	out.WriteString(command)
+	return out.String(), bodies
+}
+
+// splitQuoted splits a command on ; & | and newlines outside quotes.
+func splitQuoted(command string) []string {
+	var segments []string
+	var cur strings.Builder
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';' || r == '&' || r == '|' || r == '\n':
+			segments = append(segments, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	return append(segments, cur.String())
+}
+
+// ConventionalIssue is a commit subject that fails the convention.
+type ConventionalIssue struct {
+	Subject    string
+	Detail     string
+	Suggestion string
+}
+
+// CheckConventional validates the subject line of each message against
+// type(scope)!: subject with type in types (DefaultCommitTypes when empty).
+func CheckConventional(messages []string, types []string) []ConventionalIssue {
+	if len(types) == 0 {
+		types = DefaultCommitTypes
+	}
+	var issues []ConventionalIssue
+	for _, msg := range messages {
+		subject := subjectLine(msg)
+		if subject == "" || strings.HasPrefix(subject, "Merge ") || strings.HasPrefix(subject, "Revert \"") {
+			continue
+		}
+		m := conventional.FindStringSubmatch(subject)
+		switch {
+		case m == nil:
+			issues = append(issues, ConventionalIssue{
+				Subject:    subject,
+				Detail:     "subject is not type(scope): description",
+				Suggestion: suggestConventional(subject, types),
+			})
+		case !containsString(types, m[1]):
+			issues = append(issues, ConventionalIssue{
+				Subject:    subject,
+				Detail:     fmt.Sprintf("type %q not in %s", m[1], strings.Join(types, ",")),
+				Suggestion: suggestConventional(subject, types),
+			})
+		}
+	}
+	return issues
+}
+
+// suggestConventional rewrites subject as "type(scope): description",
+// keeping a recognizable type prefix and inferring one otherwise.
+func suggestConventional(subject string, types []string) string {
+	typ, scope, bang := "", "", ""
+	desc := subject
+	if m := loosePrefix.FindStringSubmatchIndex(subject); m != nil {
+		candidate := strings.ToLower(subject[m[2]:m[3]])
+		_, alias := typeAliases[candidate]
+		if t := matchType(candidate, types); t != "" || alias || containsString(DefaultCommitTypes, candidate) {
+			// A known but disallowed type is dropped and re-inferred below
+			typ = t
+			if m[4] >= 0 {
+				scope = strings.ToLower(subject[m[4]:m[5]])
+			}
+			if m[6] >= 0 {
+				bang = "!"
+			}
+			desc = subject[m[1]:]
+		}
+	}
+	if typ == "" {
+		typ = inferType(desc, types)
+	}
+	desc = strings.TrimRight(strings.TrimSpace(desc), ".")
+	if desc != "" {
+		desc = strings.ToLower(desc[:1]) + desc[1:]
+	}
+	return typ + scope + bang + ": " + desc
+}
+
+// typeAliases maps common prefixes and leading verbs to commit types.
+var typeAliases = map[string]string{
+	"feature": "feat", "add": "feat", "adds": "feat", "added": "feat", "implement": "feat", "introduce": "feat",
+	"bugfix": "fix", "hotfix": "fix", "fixes": "fix", "fixed": "fix", "resolve": "fix",
+	"doc": "docs", "documentation": "docs", "readme": "docs",
+	"tests": "test", "testing": "test",
+	"refactoring": "refactor", "cleanup": "refactor", "restructure": "refactor", "rename": "refactor",
+	"performance": "perf", "optimize": "perf", "speed": "perf",
+	"deps": "build", "bump": "build",
+	"format": "style", "lint": "style",
+	"update": "chore", "remove": "chore",
+}
+
+// matchType resolves word to an allowed type, directly or via typeAliases.
+func matchType(word string, types []string) string {
+	if containsString(types, word) {
+		return word
+	}
+	if alias, ok := typeAliases[word]; ok && containsString(types, alias) {
+		return alias
+	}
+	return ""
+}
+
+// inferType picks a type from the first word, falling back to chore (or the
+// first allowed type when chore is not allowed).
+func inferType(desc string, types []string) string {
+	if fields := strings.Fields(desc); len(fields) > 0 {
+		if t := matchType(strings.ToLower(strings.Trim(fields[0], ":,.")), types); t != "" {
+			return t
+		}
+	}
+	if containsString(types, "chore") {
+		return "chore"
+	}
+	return types[0]
+}
+
+func subjectLine(msg string) string {
+	for _, line := range strings.Split(msg, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
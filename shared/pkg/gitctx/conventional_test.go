@@ -0,0 +1,59 @@
+package gitctx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommitMessages(t *testing.T) {
+	cases := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"single -m", `git commit -m "fix: handle nil"`, []string{"fix: handle nil"}},
+		{"cluster and paragraphs", `git add . && git commit -am 'feat: x' -m "body; more"`, []string{"feat: x\n\nbody; more"}},
+		{"attached and long", `git commit --message="docs: readme" && git commit -m'chore: y'`, []string{"docs: readme", "chore: y"}},
+		{"cat heredoc", "git commit -m \"$(cat <<'EOF'\nAdd parser\n\nLonger body.\nEOF\n)\" && git push", []string{"Add parser\n\nLonger body."}},
+		{"stdin heredoc", "git commit -F - <<-EOF\n\tfix(api): retry\n\tEOF\ngit log -1", []string{"fix(api): retry"}},
+		{"editor skipped", "git commit", nil},
+		{"file skipped", "git commit -F msg.txt", nil},
+		{"not a commit", `git log -m "x"`, nil},
+		{"global options", `git -C repo -c user.name=x commit -m "test: y"`, []string{"test: y"}},
+	}
+	for _, tc := range cases {
+		got := CommitMessages(tc.command)
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") || len(got) != len(tc.want) {
+			t.Errorf("%s: CommitMessages = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCheckConventional(t *testing.T) {
+	cases := []struct {
+		subject    string
+		types      []string
+		suggestion string // "" = valid
+	}{
+		{"feat(parser): support heredocs", nil, ""},
+		{"fix!: drop legacy flag", nil, ""},
+		{"Merge branch 'main'", nil, ""},
+		{"Add heredoc parsing.", nil, "feat: add heredoc parsing"},
+		{"Fix: Handle nil config", nil, "fix: handle nil config"},
+		{"Bugfix(API) - retry on 503", nil, "fix(api): retry on 503"},
+		{"wip stuff", nil, "chore: wip stuff"},
+		{"feat: new thing", []string{"fix", "chore"}, "chore: new thing"},
+		{"feat:missing space", nil, "feat: missing space"},
+	}
+	for _, tc := range cases {
+		issues := CheckConventional([]string{tc.subject + "\n\nbody"}, tc.types)
+		switch {
+		case tc.suggestion == "" && len(issues) > 0:
+			t.Errorf("%q flagged: %+v", tc.subject, issues[0])
+		case tc.suggestion != "" && len(issues) != 1:
+			t.Errorf("%q not flagged", tc.subject)
+		case tc.suggestion != "" && issues[0].Suggestion != tc.suggestion:
+			t.Errorf("%q suggestion = %q, want %q", tc.subject, issues[0].Suggestion, tc.suggestion)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package gitctx provides git context detection for gates.
+// status.go: Uncommitted change detection for files about to be written.
+package gitctx
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// statusTimeout bounds git status so a huge repo cannot stall a hook.
+const statusTimeout = 2 * time.Second
+
+// Uncommitted returns the git status --porcelain code of a tracked file
+// with uncommitted changes ("M", "MM", "A", ...), or "" when the file is
+// clean or untracked, or git cannot tell (not a repo, git missing, timeout).
+func Uncommitted(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "--", filepath.Base(abs))
+	cmd.Dir = filepath.Dir(abs)
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) < 3 || strings.HasPrefix(line, "??") || strings.HasPrefix(line, "!!") {
+			continue
+		}
+		return strings.TrimSpace(line[:2])
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package gitctx
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestUncommitted(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Agent", "-c", "user.email=agent@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	tracked := filepath.Join(dir, "main.go")
+	os.WriteFile(tracked, []byte("package main\n"), 0o644)
+	git("add", "main.go")
+	git("commit", "-q", "-m", "init")
+
+	if got := Uncommitted(tracked); got != "" {
+		t.Errorf("clean file: %q", got)
+	}
+	os.WriteFile(tracked, []byte("package main\n\nfunc main() {}\n"), 0o644)
+	if got := Uncommitted(tracked); got != "M" {
+		t.Errorf("modified file: %q, want M", got)
+	}
+	untracked := filepath.Join(dir, "new.go")
+	os.WriteFile(untracked, []byte("package main\n"), 0o644)
+	if got := Uncommitted(untracked); got != "" {
+		t.Errorf("untracked file: %q", got)
+	}
+	if got := Uncommitted(filepath.Join(dir, "missing", "x.go")); got != "" {
+		t.Errorf("missing dir: %q", got)
+	}
+	if got := Uncommitted(filepath.Join(t.TempDir(), "x.go")); got != "" {
+		t.Errorf("outside a repo: %q", got)
+	}
+}
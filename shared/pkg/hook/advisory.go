@@ -0,0 +1,74 @@
+package hook
+
+import "github.com/claude/shared/pkg/types"
+
+var (
+	advisoryMode   bool
+	advisoryBanner string // Shown once on the next response, then cleared
+	advisoryShown  func() // Called when a response carries the banner
+)
+
+// SetAdvisoryMode turns global advisory mode on or off. In advisory mode
+// every gate still runs, but block, deny and ask responses become allow
+// with the full reasoning as context, and input rewrites are dropped.
+func SetAdvisoryMode(on bool) {
+	advisoryMode = on
+}
+
+// AdvisoryMode reports whether global advisory mode is on.
+func AdvisoryMode() bool {
+	return advisoryMode
+}
+
+// AnnounceAdvisoryMode shows banner as the system message of the next
+// response that can carry one, and calls shown (if set) once it has.
+// UserPromptSubmit output has no system message and leaves it pending.
+func AnnounceAdvisoryMode(banner string, shown func()) {
+	advisoryBanner, advisoryShown = banner, shown
+}
+
+// advise rewrites resp for advisory mode: what the gates decided is kept
+// as an [ADVISORY] block, and the call is allowed. Converted responses
+// exit ExitCodeWarn under --strict-exit.
+func advise(resp *types.HookResponse) {
+	if !advisoryMode {
+		return
+	}
+	if advisoryBanner != "" && resp.SystemMessage == "" {
+		resp.SystemMessage = advisoryBanner
+		advisoryBanner = ""
+		if advisoryShown != nil {
+			advisoryShown()
+			advisoryShown = nil
+		}
+	}
+
+	if resp.Decision == "block" {
+		Note(ExitCodeWarn)
+		resp.AdditionalContext = advisoryBlock("block", resp.Reason) + resp.AdditionalContext
+		resp.Decision = "approve"
+		resp.Reason = "advisory: " + resp.Reason
+	}
+	out := resp.HookSpecificOutput
+	if out == nil {
+		return
+	}
+	switch {
+	case out.PermissionDecision == "deny" || out.PermissionDecision == "ask":
+		Note(ExitCodeWarn)
+		out.AdditionalContext = advisoryBlock(out.PermissionDecision, out.PermissionDecisionReason) + out.AdditionalContext
+		out.PermissionDecision = "allow"
+		out.PermissionDecisionReason = "advisory: " + out.PermissionDecisionReason
+		out.UpdatedInput = nil
+	case out.UpdatedInput != nil:
+		Note(ExitCodeWarn)
+		out.AdditionalContext = advisoryBlock("rewrite", out.PermissionDecisionReason) + out.AdditionalContext
+		out.UpdatedInput = nil
+	}
+}
+
+// advisoryBlock is the TOON note of a decision advisory mode did not
+// enforce.
+func advisoryBlock(would, reason string) string {
+	return "[ADVISORY]\nwould: " + would + "\nreason: " + reason + "\nenforced: no\n\n"
+}
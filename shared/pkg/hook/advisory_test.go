@@ -0,0 +1,62 @@
+package hook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/claude/shared/pkg/types"
+)
+
+func TestAdvise(t *testing.T) {
+	defer func() { advisoryMode, advisoryBanner, advisoryShown, strictExit, outcome = false, "", nil, false, 0 }()
+
+	deny := types.NewPreToolUseDeny("BASH:blocked:rm -rf /")
+	advise(deny)
+	if got := deny.HookSpecificOutput.PermissionDecision; got != "deny" {
+		t.Fatalf("advisory off: decision = %q, want deny", got)
+	}
+
+	SetAdvisoryMode(true)
+	shown := 0
+	AnnounceAdvisoryMode("advisory on", func() { shown++ })
+	strictExit = true
+	for _, resp := range []*types.HookResponse{
+		types.NewPreToolUseDeny("BASH:blocked:rm -rf /"),
+		types.NewPreToolUseAsk("confirm push"),
+		types.NewBlock("blocked"),
+	} {
+		outcome = 0
+		advise(resp)
+		noteResponse(resp)
+		decision, ctx := resp.Decision, resp.AdditionalContext
+		if out := resp.HookSpecificOutput; out != nil {
+			decision, ctx = out.PermissionDecision, out.AdditionalContext
+		}
+		if decision != "allow" && decision != "approve" {
+			t.Errorf("%+v: decision = %q, want allowed", resp, decision)
+		}
+		if !strings.HasPrefix(ctx, "[ADVISORY]\nwould: ") {
+			t.Errorf("%+v: context missing advisory block:\n%s", resp, ctx)
+		}
+		if got := ExitCode(); got != ExitCodeWarn {
+			t.Errorf("%+v: exit %d, want %d", resp, got, ExitCodeWarn)
+		}
+	}
+
+	rewrite := &types.HookResponse{HookSpecificOutput: &types.HookSpecificOutput{
+		HookEventName:      "PreToolUse",
+		PermissionDecision: "allow",
+		UpdatedInput:       map[string]interface{}{"command": "rm -ri build"},
+	}}
+	advise(rewrite)
+	if rewrite.HookSpecificOutput.UpdatedInput != nil {
+		t.Error("advisory mode kept an input rewrite")
+	}
+
+	// The banner is shown once, and reported shown once
+	first := types.NewApprove("ok")
+	advise(first)
+	if first.SystemMessage != "" || shown != 1 {
+		t.Errorf("banner repeated: %q, shown %d times", first.SystemMessage, shown)
+	}
+}
@@ -0,0 +1,86 @@
+package hook
+
+import (
+	"os"
+
+	"github.com/claude/shared/pkg/types"
+)
+
+// Exit codes under --strict-exit, for scripts and CI that branch on a gate
+// outcome. Hook mode always exits 0 and reports the decision in JSON.
+const (
+	ExitCodePass  = 0
+	ExitCodeWarn  = 1
+	ExitCodeBlock = 2 // Also ask: the call is not approved without a user
+	ExitCodeError = 3
+)
+
+var (
+	strictExit bool
+	outcome    int      // Strictest exit code noted so far
+	exitHooks  []func() // Run before the process exits, in order
+)
+
+// OnExit registers fn to run when the gate exits through Exit, such as
+// flushing asynchronous deliveries.
+func OnExit(fn func()) {
+	exitHooks = append(exitHooks, fn)
+}
+
+// runExitHooks runs the registered exit hooks once.
+func runExitHooks() {
+	hooks := exitHooks
+	exitHooks = nil
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+// SetStrictExit switches gate exits from always 0 to the outcome codes.
+func SetStrictExit(on bool) {
+	strictExit = on
+}
+
+// Note records a gate outcome; the strictest one noted is the exit code.
+func Note(code int) {
+	if code > outcome {
+		outcome = code
+	}
+}
+
+// ExitCode returns the code a gate exits with: 0 in hook mode, otherwise
+// the strictest outcome noted.
+func ExitCode() int {
+	if !strictExit {
+		return 0
+	}
+	return outcome
+}
+
+// Exit ends the gate with ExitCode. Gates that write their own response
+// with Output call it instead of os.Exit(0).
+func Exit() {
+	runExitHooks()
+	os.Exit(ExitCode())
+}
+
+// exitError ends the gate after an error: 1 in hook mode, ExitCodeError
+// under --strict-exit.
+func exitError() {
+	runExitHooks()
+	if strictExit {
+		os.Exit(ExitCodeError)
+	}
+	os.Exit(1)
+}
+
+// noteResponse records the outcome carried by a hook response: a block,
+// deny or ask decision.
+func noteResponse(resp *types.HookResponse) {
+	if resp.Decision == "block" {
+		Note(ExitCodeBlock)
+	}
+	if out := resp.HookSpecificOutput; out != nil && (out.PermissionDecision == "deny" || out.PermissionDecision == "ask") {
+		Note(ExitCodeBlock)
+	}
+}
@@ -0,0 +1,57 @@
+package hook
+
+import (
+	"testing"
+
+	"github.com/claude/shared/pkg/types"
+)
+
+func TestExitCode(t *testing.T) {
+	defer func() { strictExit, outcome = false, 0 }()
+
+	cases := []struct {
+		resp *types.HookResponse
+		want int
+	}{
+		{types.NewApprove("ok"), ExitCodePass},
+		{types.NewModify("BASH", "[BASH]\n"), ExitCodePass},
+		{types.NewPreToolUseAllow("ok"), ExitCodePass},
+		{types.NewBlock("blocked"), ExitCodeBlock},
+		{types.NewPreToolUseDeny("no"), ExitCodeBlock},
+		{types.NewPreToolUseAsk("confirm"), ExitCodeBlock},
+		{types.NewPostToolUseBlock("bad", ""), ExitCodeBlock},
+	}
+	for _, tc := range cases {
+		strictExit, outcome = true, 0
+		noteResponse(tc.resp)
+		if got := ExitCode(); got != tc.want {
+			t.Errorf("%+v: exit %d, want %d", tc.resp, got, tc.want)
+		}
+	}
+
+	// The strictest outcome wins; hook mode always exits 0
+	outcome = 0
+	Note(ExitCodeWarn)
+	Note(ExitCodeError)
+	Note(ExitCodeBlock)
+	if got := ExitCode(); got != ExitCodeError {
+		t.Errorf("strictest = %d, want %d", got, ExitCodeError)
+	}
+	SetStrictExit(false)
+	if got := ExitCode(); got != 0 {
+		t.Errorf("hook mode exit = %d, want 0", got)
+	}
+}
+
+func TestExitHooks(t *testing.T) {
+	defer func() { exitHooks = nil }()
+	var ran []int
+	OnExit(func() { ran = append(ran, 1) })
+	OnExit(func() { ran = append(ran, 2) })
+
+	runExitHooks()
+	runExitHooks()
+	if len(ran) != 2 || ran[0] != 1 || ran[1] != 2 {
+		t.Errorf("exit hooks ran %v, want [1 2] once", ran)
+	}
+}
@@ -0,0 +1,39 @@
+package hook
+
+import "github.com/claude/shared/pkg/types"
+
+// Explainer expands a terse block or warn reason into a short explanation
+// with an example of how to proceed (onboarding soft mode). blocked is
+// false for a warning the action went ahead with. input is the hook input
+// being evaluated, nil when unknown. "" keeps the output terse.
+type Explainer func(gate, reason string, blocked bool, input *types.HookInput) string
+
+var (
+	explainer    Explainer
+	currentInput *types.HookInput // Last input read by ReadHookInput
+)
+
+// SetExplainer installs the soft-mode explainer; nil disables it.
+func SetExplainer(e Explainer) {
+	explainer = e
+}
+
+// explain returns the explanation block to append to AdditionalContext.
+func explain(gate, reason string, blocked bool) string {
+	if explainer == nil || reason == "" {
+		return ""
+	}
+	text := explainer(gate, reason, blocked, currentInput)
+	if text == "" {
+		return ""
+	}
+	return "\n[EXPLAIN]\n" + text
+}
+
+// warnReason returns the warning carried by a modify TOON block, if any.
+func warnReason(kvs map[string]string) string {
+	if w := kvs["warn"]; w != "" {
+		return w
+	}
+	return kvs["warning"]
+}
@@ -0,0 +1,38 @@
+package hook
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/claude/shared/pkg/types"
+)
+
+func TestExplain(t *testing.T) {
+	defer SetExplainer(nil)
+
+	if got := explain("READ", "sensitive_file", true); got != "" {
+		t.Errorf("no explainer: %q", got)
+	}
+
+	currentInput = &types.HookInput{ToolInput: map[string]interface{}{"file_path": "/home/dev/.aws/credentials"}}
+	defer func() { currentInput = nil }()
+	SetExplainer(func(gate, reason string, blocked bool, input *types.HookInput) string {
+		if reason == "terse" {
+			return ""
+		}
+		return fmt.Sprintf("why: %s %s %v %s\n", gate, reason, blocked, input.GetString("file_path"))
+	})
+	if got := explain("READ", "sensitive_file", true); !strings.HasPrefix(got, "\n[EXPLAIN]\nwhy: READ sensitive_file true /home/dev/.aws/credentials") {
+		t.Errorf("explain = %q", got)
+	}
+	if got := explain("READ", "large_file", false); !strings.Contains(got, "large_file false") {
+		t.Errorf("warning explained as a block: %q", got)
+	}
+	if got := explain("READ", "terse", true); got != "" {
+		t.Errorf("empty explanation should add nothing: %q", got)
+	}
+	if warnReason(map[string]string{"warning": "drift"}) != "drift" || warnReason(map[string]string{"info": "x"}) != "" {
+		t.Error("warnReason")
+	}
+}
@@ -13,9 +13,39 @@ import (
 // Input is an alias to types.HookInput for convenience.
 type Input = types.HookInput
 
-// ReadHookInput reads and parses JSON hook input from stdin.
+// inputFile, when set, replaces stdin as the hook input source.
+// Set via SetInputFile from the --input flag on gate commands.
+var inputFile string
+
+// SetInputFile makes ReadHookInput read from path instead of stdin.
+// An empty path restores stdin.
+func SetInputFile(path string) {
+	inputFile = path
+}
+
+// ReadHookInput reads and parses JSON hook input from stdin,
+// or from the file set via SetInputFile.
 func ReadHookInput() (*types.HookInput, error) {
-	return ReadHookInputFrom(os.Stdin)
+	var input *types.HookInput
+	var err error
+	if inputFile != "" {
+		input, err = ReadHookInputFile(inputFile)
+	} else {
+		input, err = ReadHookInputFrom(os.Stdin)
+	}
+	currentInput = input
+	return input, err
+}
+
+// ReadHookInputFile reads and parses JSON hook input from a file.
+// Used to replay saved production inputs through a gate.
+func ReadHookInputFile(path string) (*types.HookInput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadHookInputFrom(f)
 }
 
 // ReadHookInputFrom reads and parses JSON hook input from a reader.
@@ -34,12 +64,35 @@ func ReadHookInputFrom(r io.Reader) (*types.HookInput, error) {
 	return &input, nil
 }
 
+// ReadHookInputs reads a JSON array of hook inputs from stdin, or from the
+// file set via SetInputFile. Used by batch evaluation of planned tool calls.
+func ReadHookInputs() ([]*types.HookInput, error) {
+	if inputFile != "" {
+		f, err := os.Open(inputFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadHookInputsFrom(f)
+	}
+	return ReadHookInputsFrom(os.Stdin)
+}
+
+// ReadHookInputsFrom reads a JSON array of hook inputs from a reader.
+func ReadHookInputsFrom(r io.Reader) ([]*types.HookInput, error) {
+	var inputs []*types.HookInput
+	if err := json.NewDecoder(r).Decode(&inputs); err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
 // MustReadHookInput reads hook input or exits with error JSON.
 func MustReadHookInput() *types.HookInput {
 	input, err := ReadHookInput()
 	if err != nil {
 		OutputError("failed to read hook input: " + err.Error())
-		os.Exit(1)
+		exitError()
 	}
 	return input
 }
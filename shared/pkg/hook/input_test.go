@@ -3,6 +3,8 @@
 package hook
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -64,6 +66,43 @@ func TestReadHookInputFrom(t *testing.T) {
 	}
 }
 
+func TestReadHookInputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.json")
+	data := `{"tool_name":"Bash","tool_input":{"command":"ls"}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	SetInputFile(path)
+	defer SetInputFile("")
+
+	input, err := ReadHookInput()
+	if err != nil {
+		t.Fatalf("ReadHookInput() error = %v", err)
+	}
+	if input.ToolName != "Bash" {
+		t.Errorf("ToolName = %v, want Bash", input.ToolName)
+	}
+
+	if _, err := ReadHookInputFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("ReadHookInputFile(missing) error = nil, want error")
+	}
+}
+
+func TestReadHookInputsFrom(t *testing.T) {
+	data := `[{"tool_name":"Bash","tool_input":{"command":"ls"}},{"tool_name":"Write","tool_response":"ok"}]`
+	inputs, err := ReadHookInputsFrom(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadHookInputsFrom() error = %v", err)
+	}
+	if len(inputs) != 2 || inputs[0].GetString("command") != "ls" || inputs[1].ToolName != "Write" {
+		t.Errorf("inputs = %+v", inputs)
+	}
+	if _, err := ReadHookInputsFrom(strings.NewReader(`{"tool_name":"Bash"}`)); err == nil {
+		t.Error("ReadHookInputsFrom(object) error = nil, want error")
+	}
+}
+
 func TestGetStringFromInput(t *testing.T) {
 	input := &types.HookInput{
 		ToolName: "Read",
@@ -3,14 +3,16 @@ package hook
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/claude/shared/pkg/types"
 )
 
-// Output writes a hook response as JSON to stdout.
+// Output writes a hook response as JSON to stdout and notes its outcome
+// for the --strict-exit code. Advisory mode converts it first.
 func Output(resp *types.HookResponse) {
+	advise(resp)
+	noteResponse(resp)
 	data, err := json.Marshal(resp)
 	if err != nil {
 		OutputError("failed to marshal response: " + err.Error())
@@ -36,6 +38,7 @@ func Modify(reason, context string) {
 
 // OutputError outputs a block decision for errors.
 func OutputError(message string) {
+	Note(ExitCodeError)
 	Output(&types.HookResponse{
 		Decision: "block",
 		Reason:   "error: " + message,
@@ -52,22 +55,22 @@ func OutputJSON(v interface{}) error {
 	return nil
 }
 
-// ExitApprove outputs approve and exits with code 0.
+// ExitApprove outputs approve and exits.
 func ExitApprove(reason string) {
 	Approve(reason)
-	os.Exit(0)
+	Exit()
 }
 
-// ExitBlock outputs block and exits with code 0.
+// ExitBlock outputs block and exits.
 func ExitBlock(reason string) {
 	Block(reason)
-	os.Exit(0)
+	Exit()
 }
 
-// ExitModify outputs modify and exits with code 0.
+// ExitModify outputs modify and exits.
 func ExitModify(reason, context string) {
 	Modify(reason, context)
-	os.Exit(0)
+	Exit()
 }
 
 // TOON-aware functions for SP/1.0 compliance
@@ -102,34 +105,51 @@ func ExitApproveTOON(gate string) {
 			AdditionalContext:        ctx,
 		},
 	})
-	os.Exit(0)
+	Exit()
 }
 
 // ExitBlockTOON outputs block with TOON context.
 // Uses hookSpecificOutput format (Claude Code 2026).
 func ExitBlockTOON(gate, reason string) {
-	ctx := TOONBlock("BLOCK", map[string]string{
+	ExitBlockTOONFrom(gate, reason, "")
+}
+
+// ExitBlockTOONFrom is ExitBlockTOON tagged with where the rule came from
+// (builtin-detection, config-policy, ...), so users know what to adjust.
+// An empty source leaves the block untagged.
+func ExitBlockTOONFrom(gate, reason, source string) {
+	fields := map[string]string{
 		"gate":   gate,
 		"reason": reason,
 		"date":   Today(),
-	})
+	}
+	tagged := reason
+	if source != "" {
+		fields["source"] = source
+		tagged += " [" + source + "]"
+	}
 	Output(&types.HookResponse{
 		HookSpecificOutput: &types.HookSpecificOutput{
 			HookEventName:            "PreToolUse",
 			PermissionDecision:       "deny",
-			PermissionDecisionReason: reason,
-			AdditionalContext:        ctx,
+			PermissionDecisionReason: tagged,
+			AdditionalContext:        TOONBlock("BLOCK", fields) + explain(gate, reason, true),
 		},
 	})
-	os.Exit(0)
+	Exit()
 }
 
 // ExitModifyTOON outputs modify with TOON context.
+// Warnings get the soft-mode explanation and exit ExitCodeWarn under
+// --strict-exit.
 func ExitModifyTOON(gate string, kvs map[string]string) {
+	if warnReason(kvs) != "" {
+		Note(ExitCodeWarn)
+	}
 	kvs["date"] = Today()
-	ctx := TOONBlock(gate, kvs)
+	ctx := TOONBlock(gate, kvs) + explain(gate, warnReason(kvs), false)
 	Modify(gate, ctx)
-	os.Exit(0)
+	Exit()
 }
 
 // UserPromptSubmit output format for Claude Code hooks
@@ -146,7 +166,7 @@ func ExitUserPromptSubmit(context string) {
 	}
 	data, _ := json.Marshal(resp)
 	fmt.Println(string(data))
-	os.Exit(0)
+	Exit()
 }
 
 // ExitUserPromptSubmitTOON outputs UserPromptSubmit with TOON context.
@@ -165,15 +185,15 @@ func ExitModifyTOONWithModule(gate string, kvs map[string]string, moduleContent
 		ctx += "\n[MODULE:LAZY_LOADED]\n" + moduleContent
 	}
 	Modify(gate, ctx)
-	os.Exit(0)
+	Exit()
 }
 
-// === SessionEnd / SubagentStart / SubagentStop output helpers ===
+// === SessionEnd / SubagentStart / SubagentStop / Setup output helpers ===
 
 // ExitSessionEnd outputs SessionEnd context and exits.
 func ExitSessionEnd(context string) {
 	Output(types.NewSessionEndContext(context))
-	os.Exit(0)
+	Exit()
 }
 
 // ExitSessionEndTOON outputs SessionEnd with TOON context.
@@ -186,25 +206,31 @@ func ExitSessionEndTOON(kvs map[string]string) {
 // ExitSubagentStart outputs SubagentStart context and exits.
 func ExitSubagentStart(context string) {
 	Output(types.NewSubagentStartContext(context))
-	os.Exit(0)
+	Exit()
 }
 
 // ExitSubagentStop outputs SubagentStop context and exits.
 func ExitSubagentStop(context string) {
 	Output(types.NewSubagentStopContext(context))
-	os.Exit(0)
+	Exit()
+}
+
+// ExitSetup outputs Setup context and exits.
+func ExitSetup(context string) {
+	Output(types.NewSetupContext(context))
+	Exit()
 }
 
 // ExitPermissionAllow auto-approves a permission request.
 func ExitPermissionAllow(reason string) {
 	Output(types.NewPermissionAllow(reason))
-	os.Exit(0)
+	Exit()
 }
 
 // ExitPermissionDeny auto-denies a permission request.
 func ExitPermissionDeny(reason string) {
 	Output(types.NewPermissionDeny(reason, false))
-	os.Exit(0)
+	Exit()
 }
 
 // DACE: Zero-context functions for silent passes
@@ -213,7 +239,7 @@ func ExitPermissionDeny(reason string) {
 // Use this when hook should pass without adding to context.
 func ExitSilent() {
 	Approve("ok")
-	os.Exit(0)
+	Exit()
 }
 
 // ExitUserPromptSubmitSilent outputs minimal UserPromptSubmit.
@@ -225,7 +251,7 @@ func ExitUserPromptSubmitSilent() {
 	}
 	data, _ := json.Marshal(resp)
 	fmt.Println(string(data))
-	os.Exit(0)
+	Exit()
 }
 
 // ExitUserPromptSubmitWithContext outputs UserPromptSubmit with context string.
@@ -237,5 +263,5 @@ func ExitUserPromptSubmitWithContext(context string) {
 	}
 	data, _ := json.Marshal(resp)
 	fmt.Println(string(data))
-	os.Exit(0)
+	Exit()
 }
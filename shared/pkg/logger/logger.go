@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"github.com/claude/shared/pkg/config"
 )
 
 var (
@@ -32,15 +34,26 @@ func initLogger() *slog.Logger {
 	if err != nil {
 		// Fallback to stderr if can't open log file
 		return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-			Level: slog.LevelInfo,
+			Level:       slog.LevelInfo,
+			ReplaceAttr: redactAttr,
 		}))
 	}
 
 	return slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level:       slog.LevelInfo,
+		ReplaceAttr: redactAttr,
 	}))
 }
 
+// redactAttr scrubs secrets from the message and string values before they
+// reach kavach.log (config redaction.disabled turns this off).
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindString {
+		a.Value = slog.StringValue(config.RedactString(a.Value.String()))
+	}
+	return a
+}
+
 // Debug logs a debug message.
 func Debug(component, msg string, args ...any) {
 	Get().Debug(msg, append([]any{"component", component}, args...)...)
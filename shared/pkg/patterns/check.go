@@ -66,8 +66,8 @@ func ValidateIdentifier(name string) error {
 // IsSensitive checks if path matches any sensitive pattern.
 func IsSensitive(path string) bool {
 	cfg := Load()
-	pathLower := strings.ToLower(path)
-	for _, p := range cfg.Sensitive {
+	pathLower := NormalizePath(path)
+	for _, p := range cfg.sensitive {
 		if strings.Contains(pathLower, p) {
 			return true
 		}
@@ -16,7 +16,12 @@ func Load() *Config {
 	if cached != nil {
 		return cached
 	}
-	cached = loadFromTOON()
+	cfg := loadFromTOON()
+	cfg.sensitive = make([]string, len(cfg.Sensitive))
+	for i, p := range cfg.Sensitive {
+		cfg.sensitive[i] = NormalizePath(p)
+	}
+	cached = cfg
 	return cached
 }
 
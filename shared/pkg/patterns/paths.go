@@ -0,0 +1,44 @@
+// Package patterns provides dynamic pattern loading from TOON config.
+// paths.go: OS-independent path normalization so Unix-style rules such as
+// "/.ssh/" also match Windows paths (C:\Users\x\.ssh\id_rsa, %APPDATA%\...).
+package patterns
+
+import "strings"
+
+// windowsVars maps profile variables (cmd and PowerShell forms) to where
+// they usually point, relative to the normalized profile "~".
+var windowsVars = strings.NewReplacer(
+	"%userprofile%", "~", "$env:userprofile", "~", "%homepath%", "~",
+	"%appdata%", "~/appdata/roaming", "$env:appdata", "~/appdata/roaming",
+	"%localappdata%", "~/appdata/local", "$env:localappdata", "~/appdata/local",
+	"%programdata%", "/programdata", "$env:programdata", "/programdata",
+	"%systemroot%", "/windows", "$env:systemroot", "/windows",
+	"%windir%", "/windows", "$env:windir", "/windows",
+)
+
+// WindowsSensitivePaths are credential stores under a Windows profile or
+// system root, in normalized form.
+var WindowsSensitivePaths = []string{
+	"/appdata/roaming/microsoft/credentials/",
+	"/appdata/local/microsoft/credentials/",
+	"/appdata/roaming/microsoft/protect/",
+	"/appdata/roaming/gnupg/",
+	"/windows/system32/config/sam",
+	"/windows/system32/config/security",
+	"/windows/system32/config/system",
+}
+
+// NormalizePath lowercases path, converts backslashes to slashes, drops a
+// drive letter ("C:\Windows" -> "/windows") and expands Windows profile
+// variables, so one rule list matches paths from any OS.
+func NormalizePath(path string) string {
+	p := strings.ReplaceAll(strings.ToLower(path), `\`, "/")
+	p = windowsVars.Replace(p)
+	if len(p) >= 2 && p[1] == ':' && p[0] >= 'a' && p[0] <= 'z' {
+		p = p[2:]
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+	}
+	return p
+}
@@ -0,0 +1,20 @@
+package patterns
+
+import "testing"
+
+func TestNormalizePath(t *testing.T) {
+	cases := map[string]string{
+		`C:\Users\Dev\.ssh\id_rsa`:     "/users/dev/.ssh/id_rsa",
+		`%APPDATA%\Microsoft\Protect`:  "~/appdata/roaming/microsoft/protect",
+		`$env:LOCALAPPDATA\Temp`:       "~/appdata/local/temp",
+		`%SystemRoot%\System32\config`: "/windows/system32/config",
+		`D:relative\file`:              "/relative/file",
+		"/home/dev/.aws/credentials":   "/home/dev/.aws/credentials",
+		`\\server\share\secrets.txt`:   "//server/share/secrets.txt",
+	}
+	for in, want := range cases {
+		if got := NormalizePath(in); got != want {
+			t.Errorf("NormalizePath(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
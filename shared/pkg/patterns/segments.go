@@ -0,0 +1,41 @@
+// Package patterns provides dynamic pattern loading from TOON config.
+// segments.go: Quote-aware split of a shell command into simple commands.
+package patterns
+
+import "strings"
+
+// SplitCommand splits cmd at ;, &&, ||, |, & and newlines into simple
+// commands, leaving operators inside single or double quotes alone.
+// Empty segments are dropped.
+func SplitCommand(cmd string) []string {
+	var segments []string
+	var cur strings.Builder
+	var quote rune
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			segments = append(segments, s)
+		}
+		cur.Reset()
+	}
+	escaped := false
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ';' || r == '&' || r == '|' || r == '\n':
+			flush()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	flush()
+	return segments
+}
@@ -0,0 +1,125 @@
+// Package patterns provides dynamic pattern loading from TOON config.
+// sudo.go: Routine sudo commands exempt from the sudo warning.
+// DACE: Pure functions; callers supply the safe list from config.
+package patterns
+
+import (
+	"path"
+	"strings"
+)
+
+// sudoArgOptions are sudo options that consume the next argument.
+var sudoArgOptions = map[string]bool{
+	"-u": true, "-g": true, "-C": true, "-D": true, "-h": true,
+	"-p": true, "-r": true, "-t": true, "-U": true,
+}
+
+// MatchSafeSudo returns the safe entry when cmd runs sudo and every sudo
+// invocation in it runs a command from safe. Entries are a program and
+// leading arguments ("systemctl status", "apt-get update") matched word
+// by word, so "systemctl status" does not cover "systemctl stop".
+// Redirection, command substitution, and option or path arguments after
+// the entry (see unsafeSudoArg) never match.
+func MatchSafeSudo(cmd string, safe []string) string {
+	if len(safe) == 0 || strings.ContainsAny(cmd, "<>`") || strings.Contains(cmd, "$(") {
+		return ""
+	}
+	matched := ""
+	for _, seg := range SplitCommand(cmd) {
+		fields := strings.Fields(seg)
+		if len(fields) == 0 || fields[0] != "sudo" {
+			continue
+		}
+		entry := matchSafeEntry(sudoCommand(fields[1:]), safe)
+		if entry == "" {
+			return ""
+		}
+		if matched == "" {
+			matched = entry
+		}
+	}
+	return matched
+}
+
+// sudoCommand strips sudo's options and environment assignments,
+// leaving the command it runs.
+func sudoCommand(args []string) []string {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			return args[i+1:]
+		case sudoArgOptions[a]:
+			i++
+		case strings.HasPrefix(a, "-"):
+		case strings.Contains(a, "="):
+		default:
+			return args[i:]
+		}
+	}
+	return nil
+}
+
+// sudoBinDirs are the directories a safe program may be named from by path.
+var sudoBinDirs = []string{"/usr/bin", "/bin", "/usr/sbin", "/sbin"}
+
+// sudoProgram returns the name of program when it is a bare name or a path
+// into sudoBinDirs, so "./journalctl" or "/tmp/x/systemctl" never names a
+// safe program.
+func sudoProgram(program string) string {
+	if !strings.Contains(program, "/") {
+		return strings.ToLower(program)
+	}
+	clean := path.Clean(program)
+	for _, dir := range sudoBinDirs {
+		if path.Dir(clean) == dir {
+			return strings.ToLower(path.Base(clean))
+		}
+	}
+	return ""
+}
+
+// matchSafeEntry returns the entry whose words prefix command, naming the
+// program bare or from a system bin dir, when no argument after them is
+// unsafe.
+func matchSafeEntry(command []string, safe []string) string {
+	if len(command) == 0 {
+		return ""
+	}
+	program := sudoProgram(command[0])
+	if program == "" {
+		return ""
+	}
+	for _, entry := range safe {
+		words := strings.Fields(strings.ToLower(entry))
+		if len(words) == 0 || len(words) > len(command) {
+			continue
+		}
+		ok := program == words[0]
+		for i := 1; ok && i < len(words); i++ {
+			ok = strings.ToLower(command[i]) == words[i]
+		}
+		for _, a := range command[len(words):] {
+			ok = ok && !unsafeSudoArg(a)
+		}
+		if ok {
+			return entry
+		}
+	}
+	return ""
+}
+
+// sudoInjectOptions make a routine command read config or run commands
+// given on the command line ("apt-get update -o APT::Update::Pre-Invoke=...").
+var sudoInjectOptions = []string{"-o", "--option", "-c", "--config", "--config-file"}
+
+// unsafeSudoArg reports whether a is an option that injects config or
+// commands, or a local path such as a package file or script.
+func unsafeSudoArg(a string) bool {
+	for _, opt := range sudoInjectOptions {
+		if a == opt || strings.HasPrefix(a, opt+"=") || (len(opt) == 2 && strings.HasPrefix(a, opt)) {
+			return true
+		}
+	}
+	return strings.HasPrefix(a, ".") || strings.HasPrefix(a, "~") || strings.Contains(a, "/")
+}
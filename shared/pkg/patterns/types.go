@@ -25,4 +25,6 @@ type Config struct {
 	ValidAgents map[string][]string
 	IntentWords map[string][]string
 	LoadedFrom  string
+
+	sensitive []string // Sensitive, normalized once at load
 }
@@ -0,0 +1,171 @@
+// Package statestore provides pluggable persistence for DAG and chain state.
+// store.go: Store interface with a file-backed default and an in-memory
+// implementation for tests.
+package statestore
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is one stored state document.
+type Record struct {
+	Key     string
+	Data    []byte
+	ModTime time.Time
+}
+
+// Store persists state documents by key. Load of a missing key returns an
+// error wrapping fs.ErrNotExist; List returns records newest first.
+type Store interface {
+	Save(key string, data []byte) error
+	Load(key string) ([]byte, error)
+	Delete(key string) error
+	List() ([]Record, error)
+}
+
+// FileStore keeps each record as Dir/<key>.json.
+type FileStore struct {
+	Dir string
+}
+
+// Path returns the file holding key.
+func (s FileStore) Path(key string) string {
+	return filepath.Join(s.Dir, key+".json")
+}
+
+// Save writes data for key, creating Dir if needed.
+func (s FileStore) Save(key string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	return os.WriteFile(s.Path(key), data, 0644)
+}
+
+// Load reads the data for key.
+func (s FileStore) Load(key string) ([]byte, error) {
+	return os.ReadFile(s.Path(key))
+}
+
+// Delete removes key.
+func (s FileStore) Delete(key string) error {
+	return os.Remove(s.Path(key))
+}
+
+// List reads every .json record in Dir. A missing Dir has no records.
+func (s FileStore) List() ([]Record, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []Record
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.Dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		out = append(out, Record{Key: strings.TrimSuffix(e.Name(), ".json"), Data: data, ModTime: info.ModTime()})
+	}
+	sortNewest(out)
+	return out, nil
+}
+
+// MemoryStore keeps records in memory. The zero value is ready to use.
+type MemoryStore struct {
+	// Now stamps saved records; defaults to time.Now
+	Now func() time.Time
+
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Save stores a copy of data under key.
+func (s *MemoryStore) Save(key string, data []byte) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records == nil {
+		s.records = make(map[string]Record)
+	}
+	s.records[key] = Record{Key: key, Data: append([]byte(nil), data...), ModTime: now()}
+	return nil
+}
+
+// Load returns a copy of the data stored under key.
+func (s *MemoryStore) Load(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+	}
+	return append([]byte(nil), rec.Data...), nil
+}
+
+// Delete removes key.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; !ok {
+		return fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+	}
+	delete(s.records, key)
+	return nil
+}
+
+// List returns every record, newest first.
+func (s *MemoryStore) List() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Record, 0, len(s.records))
+	for _, rec := range s.records {
+		rec.Data = append([]byte(nil), rec.Data...)
+		out = append(out, rec)
+	}
+	sortNewest(out)
+	return out, nil
+}
+
+// SetModTime backdates key, for tests of age-based listing and cleanup.
+func (s *MemoryStore) SetModTime(key string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok {
+		rec.ModTime = t
+		s.records[key] = rec
+	}
+}
+
+// sortNewest orders records by ModTime descending, then key.
+func sortNewest(recs []Record) {
+	sort.Slice(recs, func(i, j int) bool {
+		if !recs[i].ModTime.Equal(recs[j].ModTime) {
+			return recs[i].ModTime.After(recs[j].ModTime)
+		}
+		return recs[i].Key < recs[j].Key
+	})
+}
@@ -0,0 +1,49 @@
+package statestore
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestStores(t *testing.T) {
+	for name, s := range map[string]Store{
+		"file":   FileStore{Dir: t.TempDir()},
+		"memory": NewMemoryStore(),
+	} {
+		if _, err := s.Load("missing"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: Load(missing) = %v, want fs.ErrNotExist", name, err)
+		}
+		if recs, err := s.List(); err != nil || len(recs) != 0 {
+			t.Errorf("%s: empty List = %v, %v", name, recs, err)
+		}
+		if err := s.Save("a", []byte(`{"n":1}`)); err != nil {
+			t.Fatalf("%s: Save: %v", name, err)
+		}
+		s.Save("b", []byte(`{"n":2}`))
+		if data, err := s.Load("a"); err != nil || string(data) != `{"n":1}` {
+			t.Errorf("%s: Load(a) = %s, %v", name, data, err)
+		}
+		if recs, _ := s.List(); len(recs) != 2 {
+			t.Errorf("%s: List = %d records, want 2", name, len(recs))
+		}
+		if err := s.Delete("a"); err != nil {
+			t.Errorf("%s: Delete: %v", name, err)
+		}
+		if recs, _ := s.List(); len(recs) != 1 || recs[0].Key != "b" {
+			t.Errorf("%s: List after delete = %+v", name, recs)
+		}
+	}
+}
+
+func TestMemoryStoreOrder(t *testing.T) {
+	s := NewMemoryStore()
+	s.Save("old", nil)
+	s.Save("new", nil)
+	s.SetModTime("old", time.Now().Add(-time.Hour))
+	recs, _ := s.List()
+	if len(recs) != 2 || recs[0].Key != "new" {
+		t.Errorf("List = %+v, want new first", recs)
+	}
+}
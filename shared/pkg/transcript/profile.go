@@ -0,0 +1,72 @@
+// Package transcript provides parsing of Claude Code JSONL transcripts.
+// profile.go: Session-wide research coverage: which code edits had research
+// before them (TABULA_RASA audited after the fact).
+package transcript
+
+import "os"
+
+// CodeTools are the tool names that write files.
+var CodeTools = []string{"Write", "Edit", "MultiEdit", "NotebookEdit"}
+
+// Edit is a code-writing tool use and the file it targeted.
+type Edit struct {
+	Tool      string `json:"tool"`
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// ResearchProfile summarizes research vs code edits across a transcript.
+type ResearchProfile struct {
+	ToolUses     int    `json:"tool_uses"`
+	Research     int    `json:"research"`
+	Edits        int    `json:"code_edits"`
+	Unresearched []Edit `json:"unresearched"`
+}
+
+// Coverage is the percentage of code edits preceded by research; 100 when
+// there were no edits.
+func (p *ResearchProfile) Coverage() int {
+	if p.Edits == 0 {
+		return 100
+	}
+	return (p.Edits - len(p.Unresearched)) * 100 / p.Edits
+}
+
+// ProfileResearch walks the transcript in order and flags each edit to a
+// file isCode accepts that had no research tool use among the preceding
+// window tool uses (window <= 0: anywhere earlier in the session).
+// Unlike the gate lookups, a missing transcript is an error.
+func ProfileResearch(path string, researchTools []string, window int, isCode func(string) bool) (*ResearchProfile, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	uses, err := ReadToolUses(path)
+	if err != nil && len(uses) == 0 {
+		return nil, err
+	}
+	profile := &ResearchProfile{ToolUses: len(uses), Unresearched: []Edit{}}
+	lastResearch := -1
+	for i, u := range uses {
+		if containsTool(researchTools, u.Name) {
+			profile.Research++
+			lastResearch = i
+			continue
+		}
+		if !containsTool(CodeTools, u.Name) {
+			continue
+		}
+		file, _ := u.Input["file_path"].(string)
+		if file == "" {
+			file, _ = u.Input["notebook_path"].(string)
+		}
+		if isCode != nil && !isCode(file) {
+			continue
+		}
+		profile.Edits++
+		if lastResearch < 0 || (window > 0 && i-lastResearch > window) {
+			profile.Unresearched = append(profile.Unresearched, Edit{Tool: u.Name, Path: file, Line: u.Line, Timestamp: u.Timestamp})
+		}
+	}
+	return profile, nil
+}
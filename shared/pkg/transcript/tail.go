@@ -0,0 +1,96 @@
+// Package transcript provides parsing of Claude Code JSONL transcripts.
+// tail.go: Bounded transcript reads. Long sessions produce multi-megabyte
+// transcripts; gates only need the recent end and must stay fast.
+package transcript
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// DefaultTailBytes is how much of the transcript end gate lookups read.
+const DefaultTailBytes = 2 << 20
+
+// parseBudget caps the time spent decoding one tail read. Lines are
+// decoded newest first, so an exhausted budget drops the oldest ones.
+var parseBudget = 250 * time.Millisecond
+
+// ReadTranscriptTail returns the lines in the last maxBytes of the
+// transcript at path, oldest first; maxBytes <= 0 reads the whole file.
+// A line cut by the tail start is dropped and a truncated last line is
+// returned as is (it fails to decode and is skipped by the parsers). When
+// the newest complete line is longer than maxBytes the read grows back to
+// its start, so one large entry never hides the end of the transcript.
+// A missing file has no lines; other open or read errors are returned.
+func ReadTranscriptTail(path string, maxBytes int) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var size int64
+	if maxBytes > 0 {
+		if info, err := f.Stat(); err == nil {
+			size = info.Size()
+		}
+	}
+	for window := int64(maxBytes); ; window *= 2 {
+		partial := maxBytes > 0 && size > window
+		offset, whence := -window, io.SeekEnd
+		if !partial {
+			offset, whence = 0, io.SeekStart
+		}
+		if _, err := f.Seek(offset, whence); err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		if partial {
+			// The cut line ends at the first newline; with none before the
+			// final byte, no complete line fits yet
+			idx := bytes.IndexByte(bytes.TrimSuffix(data, []byte("\n")), '\n')
+			if idx < 0 {
+				continue
+			}
+			data = data[idx+1:]
+		}
+		return splitLines(data), nil
+	}
+}
+
+// splitLines splits newline-terminated data into lines.
+func splitLines(data []byte) []string {
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	if len(data) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, l := range bytes.Split(data, []byte("\n")) {
+		lines = append(lines, string(l))
+	}
+	return lines
+}
+
+// eachLineNewest calls fn on lines from newest to oldest with their 1-based
+// index, until fn returns false or budget runs out (budget <= 0: no limit).
+func eachLineNewest(lines []string, budget time.Duration, fn func(line int, text string) bool) {
+	deadline := time.Now().Add(budget)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if budget > 0 && i%256 == 0 && time.Now().After(deadline) {
+			return
+		}
+		if !fn(i+1, lines[i]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package transcript provides parsing of Claude Code JSONL transcripts.
+// transcript.go: Tool-use and prompt extraction for transcript-based gate decisions.
+// Gate lookups read only the transcript tail (tail.go).
+package transcript
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/claude/shared/pkg/types"
+)
+
+// ToolUse is a single tool invocation recorded in the transcript.
+type ToolUse struct {
+	Name      string                 `json:"name"`
+	Input     map[string]interface{} `json:"input,omitempty"`
+	Timestamp string                 `json:"timestamp,omitempty"`
+	Line      int                    `json:"line"` // 1-based transcript line
+}
+
+// entry is the subset of a transcript line kavach cares about.
+type entry struct {
+	Type        string `json:"type"`
+	Timestamp   string `json:"timestamp"`
+	IsMeta      bool   `json:"isMeta"`      // Injected by Claude Code, not typed by the user
+	IsSidechain bool   `json:"isSidechain"` // Subagent conversation
+	Message     struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// contentBlock is one element of a message content array.
+type contentBlock struct {
+	Type  string                 `json:"type"`
+	Name  string                 `json:"name,omitempty"`
+	Text  string                 `json:"text,omitempty"`
+	Input map[string]interface{} `json:"input,omitempty"`
+}
+
+// ReadToolUses returns all tool_use blocks in transcript order.
+// Malformed lines are skipped; a missing file has no tool uses.
+func ReadToolUses(path string) ([]ToolUse, error) {
+	return ReadToolUsesTail(path, 0)
+}
+
+// ReadToolUsesTail is ReadToolUses over the last maxBytes of the
+// transcript (see ReadTranscriptTail), decoded within the parse budget.
+// Line numbers count from the start of what was read. maxBytes <= 0 reads
+// and decodes everything.
+func ReadToolUsesTail(path string, maxBytes int) ([]ToolUse, error) {
+	return readToolUsesTail(context.Background(), path, maxBytes)
+}
+
+// readToolUsesTail is ReadToolUsesTail that stops decoding once ctx is
+// done, returning ctx's error.
+func readToolUsesTail(ctx context.Context, path string, maxBytes int) ([]ToolUse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	lines, err := ReadTranscriptTail(path, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := parseBudget
+	if maxBytes <= 0 {
+		budget = 0
+	}
+	var uses []ToolUse
+	eachLineNewest(lines, budget, func(line int, text string) bool {
+		if line%256 == 0 && ctx.Err() != nil {
+			return false
+		}
+		var e entry
+		if json.Unmarshal([]byte(text), &e) != nil || e.Type != "assistant" {
+			return true
+		}
+		blocks := contentBlocks(e.Message.Content)
+		for i := len(blocks) - 1; i >= 0; i-- {
+			if b := blocks[i]; b.Type == "tool_use" && b.Name != "" {
+				uses = append(uses, ToolUse{Name: b.Name, Input: b.Input, Timestamp: e.Timestamp, Line: line})
+			}
+		}
+		return true
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(uses)-1; i < j; i, j = i+1, j-1 {
+		uses[i], uses[j] = uses[j], uses[i]
+	}
+	return uses, nil
+}
+
+// HasRecentToolUse reports whether any of tools appears among the last
+// window tool uses of the transcript. window <= 0 inspects all tool uses.
+// Returns false when the transcript is missing or unreadable.
+func HasRecentToolUse(path string, tools []string, window int) bool {
+	return HasRecentToolUseContext(context.Background(), path, tools, window)
+}
+
+// HasRecentToolUseContext is HasRecentToolUse that gives up, returning
+// false, once ctx is done.
+func HasRecentToolUseContext(ctx context.Context, path string, tools []string, window int) bool {
+	if path == "" || len(tools) == 0 {
+		return false
+	}
+	uses, err := readToolUsesTail(ctx, path, windowBytes(window))
+	if err != nil {
+		return false
+	}
+	if window > 0 && len(uses) > window {
+		uses = uses[len(uses)-window:]
+	}
+	for _, u := range uses {
+		if containsTool(tools, u.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResearchSources returns the distinct sources fetched by the research
+// tools among the last window tool uses, keyed by sourceKey. Only fetched
+// URLs count: a WebSearch query is not a source on its own. window <= 0
+// inspects all tool uses.
+func ResearchSources(path string, tools []string, window int) []string {
+	return ResearchSourcesContext(context.Background(), path, tools, window)
+}
+
+// ResearchSourcesContext is ResearchSources that gives up, returning nil,
+// once ctx is done.
+func ResearchSourcesContext(ctx context.Context, path string, tools []string, window int) []string {
+	if path == "" || len(tools) == 0 {
+		return nil
+	}
+	uses, _ := readToolUsesTail(ctx, path, windowBytes(window))
+	if window > 0 && len(uses) > window {
+		uses = uses[len(uses)-window:]
+	}
+	var sources []string
+	seen := make(map[string]bool)
+	for _, u := range uses {
+		if !containsTool(tools, u.Name) {
+			continue
+		}
+		source := researchSource(u)
+		if source != "" && !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// researchSource keys a research tool use by the URL it fetched, or ""
+// when it fetched none.
+func researchSource(u ToolUse) string {
+	if u.Name == "WebSearch" {
+		return ""
+	}
+	raw, _ := u.Input["url"].(string)
+	return sourceKey(raw)
+}
+
+// sourceKey normalizes a fetched URL to the site it came from: the
+// lower-cased host without "www." or port. Pages of one site are one source.
+func sourceKey(raw string) string {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// windowBytes is how much of the transcript a lookup over the last window
+// tool uses reads: the default tail, or the whole file when window <= 0
+// asks for every tool use.
+func windowBytes(window int) int {
+	if window <= 0 {
+		return 0
+	}
+	return DefaultTailBytes
+}
+
+// containsTool reports whether name matches any entry of tools, exact or
+// glob ("mcp__context7__*").
+func containsTool(tools []string, name string) bool {
+	for _, t := range tools {
+		if types.MatchToolName(t, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LastUserPrompt returns the text of the latest user turn in the transcript
+// tail, or "" when none. Entries that are not the user's own turn are
+// skipped: tool_result-only messages, Claude Code meta messages and
+// subagent conversations.
+func LastUserPrompt(path string) (string, error) {
+	lines, err := ReadTranscriptTail(path, DefaultTailBytes)
+	if err != nil {
+		return "", err
+	}
+
+	last := ""
+	eachLineNewest(lines, parseBudget, func(_ int, text string) bool {
+		var e entry
+		if json.Unmarshal([]byte(text), &e) != nil || e.Type != "user" || e.IsMeta || e.IsSidechain {
+			return true
+		}
+		var texts []string
+		for _, b := range contentBlocks(e.Message.Content) {
+			if b.Type == "text" && strings.TrimSpace(b.Text) != "" {
+				texts = append(texts, b.Text)
+			}
+		}
+		if len(texts) == 0 {
+			return true
+		}
+		last = strings.Join(texts, "\n")
+		return false
+	})
+	return last, nil
+}
+
+// contentBlocks decodes message content, which is either a string or an array.
+func contentBlocks(raw json.RawMessage) []contentBlock {
+	if len(raw) == 0 {
+		return nil
+	}
+	var blocks []contentBlock
+	if json.Unmarshal(raw, &blocks) == nil {
+		return blocks
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return []contentBlock{{Type: "text", Text: text}}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package transcript
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTranscript writes JSONL lines to a temp transcript file.
+func writeTranscript(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func toolLine(name string) string {
+	return `{"type":"assistant","timestamp":"2026-01-01T00:00:00Z","message":{"role":"assistant","content":[{"type":"tool_use","name":"` + name + `","input":{}}]}}`
+}
+
+func TestReadToolUses(t *testing.T) {
+	path := writeTranscript(t,
+		`{"type":"user","message":{"role":"user","content":"add a handler"}}`,
+		toolLine("WebSearch"),
+		`not json`,
+		toolLine("Write"),
+	)
+
+	uses, err := ReadToolUses(path)
+	if err != nil {
+		t.Fatalf("ReadToolUses: %v", err)
+	}
+	if len(uses) != 2 {
+		t.Fatalf("expected 2 tool uses, got %d", len(uses))
+	}
+	if uses[0].Name != "WebSearch" || uses[0].Line != 2 {
+		t.Errorf("uses[0] = %+v, want WebSearch at line 2", uses[0])
+	}
+	if uses[1].Name != "Write" || uses[1].Line != 4 {
+		t.Errorf("uses[1] = %+v, want Write at line 4", uses[1])
+	}
+}
+
+func TestHasRecentToolUse(t *testing.T) {
+	path := writeTranscript(t,
+		toolLine("mcp__docs__search"),
+		toolLine("Read"),
+		toolLine("Write"),
+	)
+
+	tests := []struct {
+		name   string
+		tools  []string
+		window int
+		want   bool
+	}{
+		{"custom tool in full history", []string{"mcp__docs__search"}, 0, true},
+		{"custom tool outside window", []string{"mcp__docs__search"}, 2, false},
+		{"default tools absent", []string{"WebSearch", "WebFetch"}, 0, false},
+		{"no tools configured", nil, 0, false},
+	}
+	for _, tt := range tests {
+		if got := HasRecentToolUse(path, tt.tools, tt.window); got != tt.want {
+			t.Errorf("%s: HasRecentToolUse = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if HasRecentToolUse(filepath.Join(t.TempDir(), "missing.jsonl"), []string{"WebSearch"}, 0) {
+		t.Error("missing transcript should report false")
+	}
+
+	// Window 0 reaches research older than the default tail
+	filler := make([]string, DefaultTailBytes/len(toolLine("Read"))+1)
+	for i := range filler {
+		filler[i] = toolLine("Read")
+	}
+	long := writeTranscript(t, append([]string{toolLine("WebSearch")}, filler...)...)
+	if !HasRecentToolUse(long, []string{"WebSearch"}, 0) {
+		t.Error("window 0 should read the whole transcript")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if HasRecentToolUseContext(ctx, path, []string{"mcp__docs__search"}, 0) {
+		t.Error("ended context should report false")
+	}
+}
+
+func TestLastUserPrompt(t *testing.T) {
+	path := writeTranscript(t,
+		`{"type":"user","message":{"role":"user","content":"add a handler"}}`,
+		toolLine("Write"),
+		`{"type":"user","message":{"role":"user","content":[{"type":"text","text":"now deploy to production"}]}}`,
+		toolLine("Bash"),
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","content":"ok"}]}}`,
+		`{"type":"user","isMeta":true,"message":{"role":"user","content":"Caveat: local command output"}}`,
+		`{"type":"user","isSidechain":true,"message":{"role":"user","content":"subagent task prompt"}}`,
+	)
+	got, err := LastUserPrompt(path)
+	if err != nil || got != "now deploy to production" {
+		t.Errorf("LastUserPrompt = %q, %v; want the last text prompt", got, err)
+	}
+
+	if got, _ := LastUserPrompt(writeTranscript(t, toolLine("Read"))); got != "" {
+		t.Errorf("no user text: got %q", got)
+	}
+	if got, err := LastUserPrompt(filepath.Join(t.TempDir(), "missing.jsonl")); got != "" || err != nil {
+		t.Errorf("missing transcript: %q, %v; want no prompt", got, err)
+	}
+}
+
+func TestResearchSources(t *testing.T) {
+	use := func(name, input string) string {
+		return `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","name":"` + name + `","input":` + input + `}]}}`
+	}
+	path := writeTranscript(t,
+		use("WebSearch", `{"query":"Kubernetes rollout strategy 2026"}`),
+		use("WebFetch", `{"url":"https://www.kubernetes.io/docs/concepts/workloads/"}`),
+		use("WebFetch", `{"url":"https://kubernetes.io/docs/tasks/run-application/"}`),
+		use("WebSearch", `{"query":"kubernetes rollout strategy 2026 "}`),
+		use("Write", `{"file_path":"deploy.yaml"}`),
+		use("mcp__context7__get-library-docs", `{"libraryName":"Helm"}`),
+		use("mcp__fetch__fetch", `{"url":"HTTPS://Helm.sh:443/docs/"}`),
+	)
+	tools := []string{"WebSearch", "WebFetch", "mcp__context7__get-library-docs", "mcp__fetch__fetch"}
+
+	// Searches and URL-less lookups are not sources; pages of one site are one
+	got := strings.Join(ResearchSources(path, tools, 0), ",")
+	want := "kubernetes.io,helm.sh"
+	if got != want {
+		t.Errorf("sources = %s, want %s", got, want)
+	}
+	if got := ResearchSources(path, tools, 2); len(got) != 1 || got[0] != "helm.sh" {
+		t.Errorf("window 2 = %v, want only helm.sh", got)
+	}
+	if ResearchSources(filepath.Join(t.TempDir(), "missing"), tools, 0) != nil {
+		t.Error("missing transcript should have no sources")
+	}
+}
+
+func TestProfileResearch(t *testing.T) {
+	use := func(name, input string) string {
+		return `{"type":"assistant","timestamp":"2026-01-01T00:00:00Z","message":{"role":"assistant","content":[{"type":"tool_use","name":"` + name + `","input":` + input + `}]}}`
+	}
+	path := writeTranscript(t,
+		use("Edit", `{"file_path":"/src/early.go"}`),
+		`{"type":"user","message":{"role":"user","content":"next"}}`,
+		use("WebSearch", `{"query":"go errors"}`),
+		use("Write", `{"file_path":"/src/ok.go"}`),
+		use("Write", `{"file_path":"/notes/README.md"}`),
+		use("Read", `{"file_path":"/src/a.go"}`),
+		use("Edit", `{"file_path":"/src/late.go"}`),
+	)
+	isCode := func(p string) bool { return strings.HasSuffix(p, ".go") }
+
+	p, err := ProfileResearch(path, []string{"WebSearch"}, 0, isCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ToolUses != 6 || p.Research != 1 || p.Edits != 3 || len(p.Unresearched) != 1 {
+		t.Fatalf("profile = %+v", p)
+	}
+	if e := p.Unresearched[0]; e.Path != "/src/early.go" || e.Line != 1 || e.Timestamp == "" {
+		t.Errorf("unresearched = %+v, want early.go at line 1", e)
+	}
+	if p.Coverage() != 66 {
+		t.Errorf("coverage = %d, want 66", p.Coverage())
+	}
+
+	// With a window of 2, research is too far back for late.go
+	p, _ = ProfileResearch(path, []string{"WebSearch"}, 2, isCode)
+	if len(p.Unresearched) != 2 || p.Unresearched[1].Path != "/src/late.go" || p.Unresearched[1].Line != 7 {
+		t.Errorf("window 2 unresearched = %+v", p.Unresearched)
+	}
+}
+
+func TestReadTranscriptTail(t *testing.T) {
+	path := writeTranscript(t, toolLine("WebSearch"), toolLine("Read"), toolLine("Write"))
+	all, err := ReadTranscriptTail(path, 0)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("ReadTranscriptTail(0) = %d lines, %v; want 3", len(all), err)
+	}
+
+	// A tail shorter than the file drops the line it cuts into
+	tail, err := ReadTranscriptTail(path, len(toolLine("Write"))+10)
+	if err != nil || len(tail) != 1 || tail[0] != toolLine("Write") {
+		t.Errorf("ReadTranscriptTail(short) = %q, %v; want only the last line", tail, err)
+	}
+	uses, err := ReadToolUsesTail(path, len(toolLine("Write"))*3/2)
+	if err != nil || len(uses) != 1 || uses[0].Name != "Write" {
+		t.Errorf("ReadToolUsesTail = %+v, %v; want Write", uses, err)
+	}
+
+	// A line still being written is skipped by the parsers
+	truncated := filepath.Join(t.TempDir(), "truncated.jsonl")
+	os.WriteFile(truncated, []byte(toolLine("WebSearch")+"\n"+toolLine("Write")[:40]), 0644)
+	if uses, err := ReadToolUsesTail(truncated, DefaultTailBytes); err != nil || len(uses) != 1 || uses[0].Name != "WebSearch" {
+		t.Errorf("truncated: %+v, %v; want WebSearch only", uses, err)
+	}
+
+	// The newest line longer than the tail is still read whole
+	long := toolLine("Write") + strings.Repeat(" ", 64)
+	path = writeTranscript(t, toolLine("WebSearch"), long)
+	if tail, err := ReadTranscriptTail(path, 16); err != nil || len(tail) != 1 || tail[0] != long {
+		t.Errorf("ReadTranscriptTail(long line) = %q, %v; want the long line", tail, err)
+	}
+
+	if lines, err := ReadTranscriptTail(filepath.Join(t.TempDir(), "missing.jsonl"), DefaultTailBytes); lines != nil || err != nil {
+		t.Errorf("missing transcript: %q, %v; want no lines and no error", lines, err)
+	}
+}
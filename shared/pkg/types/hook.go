@@ -2,6 +2,12 @@
 // These types eliminate duplication across 10+ servers.
 package types
 
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
 // HookInput represents JSON input passed to any hook.
 // Reference: https://code.claude.com/docs/en/hooks
 type HookInput struct {
@@ -17,8 +23,9 @@ type HookInput struct {
 	ToolInput map[string]interface{} `json:"tool_input,omitempty"`
 	ToolUseID string                 `json:"tool_use_id,omitempty"`
 
-	// PostToolUse
-	ToolResponse map[string]interface{} `json:"tool_response,omitempty"`
+	// PostToolUse (object responses; string/array shapes via GetToolResponseText)
+	ToolResponse     map[string]interface{} `json:"tool_response,omitempty"`
+	toolResponseText string
 
 	// UserPromptSubmit
 	Prompt string `json:"prompt,omitempty"`
@@ -47,6 +54,59 @@ type HookInput struct {
 	NotificationType string `json:"notification_type,omitempty"` // "permission_prompt","idle_prompt","auth_success","elicitation_dialog"
 }
 
+// UnmarshalJSON decodes hook input, tolerating tool_response shapes
+// other than an object (some tools return a plain string or array).
+func (h *HookInput) UnmarshalJSON(data []byte) error {
+	type alias HookInput
+	aux := struct {
+		*alias
+		ToolResponse json.RawMessage `json:"tool_response,omitempty"`
+	}{alias: (*alias)(h)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	h.ToolResponse, h.toolResponseText = decodeToolResponse(aux.ToolResponse)
+	return nil
+}
+
+// decodeToolResponse splits a raw tool_response into map or text form.
+func decodeToolResponse(raw json.RawMessage) (map[string]interface{}, string) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ""
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(raw, &m) == nil {
+		return m, ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return nil, s
+	}
+	var items []interface{}
+	if json.Unmarshal(raw, &items) == nil {
+		var parts []string
+		for _, item := range items {
+			switch v := item.(type) {
+			case string:
+				parts = append(parts, v)
+			case map[string]interface{}:
+				// Content blocks: [{"type":"text","text":"..."}]
+				if text, ok := v["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return nil, strings.Join(parts, "\n")
+	}
+	return nil, string(raw)
+}
+
+// GetToolResponseText returns tool_response when it was a string or array
+// (array elements joined by newlines). Empty for object responses.
+func (h *HookInput) GetToolResponseText() string {
+	return h.toolResponseText
+}
+
 // GetToolName returns the tool name.
 func (h *HookInput) GetToolName() string {
 	return h.ToolName
@@ -79,6 +139,23 @@ func (h *HookInput) GetString(key string) string {
 	return ""
 }
 
+// ToolMatches reports whether the tool name matches pattern (see
+// MatchToolName), e.g. "mcp__github__*" for every GitHub MCP tool.
+func (h *HookInput) ToolMatches(pattern string) bool {
+	return MatchToolName(pattern, h.ToolName)
+}
+
+// MatchToolName matches a tool name against an exact name or a glob with
+// * and ? ("mcp__github__*"), ignoring case. Malformed globs never match.
+func MatchToolName(pattern, name string) bool {
+	pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern == name
+	}
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
 // IsEvent checks if this hook input is for a specific event.
 func (h *HookInput) IsEvent(event string) bool {
 	return h.HookEventName == event
@@ -231,6 +308,16 @@ func NewPostToolUseBlock(reason, context string) *HookResponse {
 	}
 }
 
+// NewPostToolUseContext adds context after a tool ran without blocking.
+func NewPostToolUseContext(context string) *HookResponse {
+	return &HookResponse{
+		HookSpecificOutput: &HookSpecificOutput{
+			HookEventName:     "PostToolUse",
+			AdditionalContext: context,
+		},
+	}
+}
+
 // NewUserPromptSubmitContext creates a UserPromptSubmit response with context.
 func NewUserPromptSubmitContext(context string) *HookResponse {
 	return &HookResponse{
@@ -2,7 +2,10 @@
 // hook_test.go: Tests for hook types.
 package types
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 func TestHookInput_GetString(t *testing.T) {
 	tests := []struct {
@@ -111,3 +114,95 @@ func TestNewModify(t *testing.T) {
 		t.Errorf("AdditionalContext = %v, want additional context", resp.AdditionalContext)
 	}
 }
+
+func TestHookInput_ToolResponseShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		wantText string
+		wantMap  bool
+	}{
+		{"string", `{"tool_name":"Bash","tool_response":"bash: foo: command not found"}`, "bash: foo: command not found", false},
+		{"array of strings", `{"tool_name":"mcp__x","tool_response":["line one","line two"]}`, "line one\nline two", false},
+		{"array of content blocks", `{"tool_name":"mcp__x","tool_response":[{"type":"text","text":"no such file"}]}`, "no such file", false},
+		{"map", `{"tool_name":"Bash","tool_response":{"stderr":"permission denied"}}`, "", true},
+		{"absent", `{"tool_name":"Bash"}`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var input HookInput
+			if err := json.Unmarshal([]byte(tt.json), &input); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if input.ToolName == "" {
+				t.Error("other fields should still decode")
+			}
+			if got := input.GetToolResponseText(); got != tt.wantText {
+				t.Errorf("GetToolResponseText() = %q, want %q", got, tt.wantText)
+			}
+			if (input.ToolResponse != nil) != tt.wantMap {
+				t.Errorf("ToolResponse = %v, wantMap %v", input.ToolResponse, tt.wantMap)
+			}
+		})
+	}
+}
+
+func TestHookInput_ToolMatches(t *testing.T) {
+	input := &HookInput{ToolName: "mcp__github__create_pull_request"}
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"mcp__github__*", true},
+		{"MCP__GitHub__*", true},
+		{"mcp__*__create_pull_request", true},
+		{"mcp__github__create_pull_request", true},
+		{"mcp__gitlab__*", false},
+		{"mcp__github", false},
+		{"mcp__github__[", false},
+	}
+	for _, tt := range tests {
+		if got := input.ToolMatches(tt.pattern); got != tt.want {
+			t.Errorf("ToolMatches(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestToolInputBuilders(t *testing.T) {
+	bash := NewBashInput("ls -la").WithSession("s1", "/tmp")
+	if bash.ToolName != "Bash" || bash.HookEventName != "PreToolUse" || bash.GetString("command") != "ls -la" {
+		t.Errorf("NewBashInput = %+v", bash)
+	}
+	if bash.SessionID != "s1" || bash.Cwd != "/tmp" {
+		t.Errorf("WithSession = %q, %q", bash.SessionID, bash.Cwd)
+	}
+	edit := NewEditInput("a.go", "x", "y")
+	if edit.GetString("file_path") != "a.go" || edit.GetString("old_string") != "x" || edit.GetString("new_string") != "y" {
+		t.Errorf("NewEditInput = %+v", edit.ToolInput)
+	}
+	write := NewWriteInput("b.go", "package b")
+	if write.ToolName != "Write" || write.GetString("content") != "package b" {
+		t.Errorf("NewWriteInput = %+v", write.ToolInput)
+	}
+
+	// A built input must look exactly like the same input decoded from JSON.
+	built := NewMultiEditInput("c.go", EditOp{Old: "a", New: "b"}, EditOp{Old: "c", New: "d", ReplaceAll: true})
+	data, err := json.Marshal(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded HookInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	edits, ok := built.ToolInput["edits"].([]interface{})
+	if !ok || len(edits) != 2 {
+		t.Fatalf("edits = %#v", built.ToolInput["edits"])
+	}
+	if _, ok := edits[1].(map[string]interface{}); !ok {
+		t.Errorf("edit op = %T, want map[string]interface{}", edits[1])
+	}
+	if len(decoded.ToolInput["edits"].([]interface{})) != 2 || decoded.ToolName != "MultiEdit" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
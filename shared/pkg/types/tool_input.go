@@ -0,0 +1,79 @@
+// Package types provides consolidated type definitions for the umbrella CLI.
+// tool_input.go: Typed builders for PreToolUse hook inputs. ToolInput gets
+// the keys and value shapes a decoded hook payload has ([]interface{} and
+// map[string]interface{}, not typed slices), so gate type assertions hold.
+package types
+
+// EditOp is one replacement of a MultiEdit.
+type EditOp struct {
+	Old        string
+	New        string
+	ReplaceAll bool
+}
+
+// NewToolInput returns a PreToolUse input for toolName with toolInput.
+func NewToolInput(toolName string, toolInput map[string]interface{}) *HookInput {
+	return &HookInput{
+		HookEventName: "PreToolUse",
+		ToolName:      toolName,
+		ToolInput:     toolInput,
+	}
+}
+
+// NewBashInput returns a Bash input running command.
+func NewBashInput(command string) *HookInput {
+	return NewToolInput("Bash", map[string]interface{}{"command": command})
+}
+
+// NewReadInput returns a Read input for path.
+func NewReadInput(path string) *HookInput {
+	return NewToolInput("Read", map[string]interface{}{"file_path": path})
+}
+
+// NewWriteInput returns a Write input creating path with content.
+func NewWriteInput(path, content string) *HookInput {
+	return NewToolInput("Write", map[string]interface{}{"file_path": path, "content": content})
+}
+
+// NewEditInput returns an Edit input replacing old with new in path.
+func NewEditInput(path, old, new string) *HookInput {
+	return NewToolInput("Edit", map[string]interface{}{
+		"file_path":  path,
+		"old_string": old,
+		"new_string": new,
+	})
+}
+
+// NewMultiEditInput returns a MultiEdit input applying edits to path in order.
+func NewMultiEditInput(path string, edits ...EditOp) *HookInput {
+	list := make([]interface{}, 0, len(edits))
+	for _, e := range edits {
+		op := map[string]interface{}{"old_string": e.Old, "new_string": e.New}
+		if e.ReplaceAll {
+			op["replace_all"] = true
+		}
+		list = append(list, op)
+	}
+	return NewToolInput("MultiEdit", map[string]interface{}{"file_path": path, "edits": list})
+}
+
+// NewWebFetchInput returns a WebFetch input for url with prompt.
+func NewWebFetchInput(url, prompt string) *HookInput {
+	return NewToolInput("WebFetch", map[string]interface{}{"url": url, "prompt": prompt})
+}
+
+// NewTaskInput returns a Task input delegating prompt to subagentType.
+func NewTaskInput(subagentType, description, prompt string) *HookInput {
+	return NewToolInput("Task", map[string]interface{}{
+		"subagent_type": subagentType,
+		"description":   description,
+		"prompt":        prompt,
+	})
+}
+
+// WithSession sets the session and working directory and returns h.
+func (h *HookInput) WithSession(sessionID, cwd string) *HookInput {
+	h.SessionID = sessionID
+	h.Cwd = cwd
+	return h
+}
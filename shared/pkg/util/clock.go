@@ -0,0 +1,53 @@
+// Package util provides shared utility functions.
+// clock.go: Injectable clock so time-dependent code can be tested.
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock reads the wall clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the default Clock, backed by time.Now.
+var SystemClock Clock = systemClock{}
+
+// MockClock is a Clock frozen at a set time until moved.
+type MockClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewMockClock returns a clock frozen at t.
+func NewMockClock(t time.Time) *MockClock {
+	return &MockClock{t: t}
+}
+
+// Now returns the frozen time.
+func (c *MockClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set moves the clock to t.
+func (c *MockClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.t = t
+	c.mu.Unlock()
+}
+
+// Advance moves the clock forward by d.
+func (c *MockClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
@@ -0,0 +1,104 @@
+// Package util provides shared utility functions.
+// diff.go: Compact line-diff summaries of Edit/MultiEdit tool inputs, kept
+// in the audit log and chain state instead of the raw old/new strings.
+package util
+
+import "fmt"
+
+// maxDiffLines bounds the LCS table; larger edits are counted line by line.
+const maxDiffLines = 500
+
+// EditSummary is the line-level effect of one or more string replacements.
+type EditSummary struct {
+	Edits   int `json:"edits"`
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// Net is lines added minus lines removed.
+func (s EditSummary) Net() int {
+	return s.Added - s.Removed
+}
+
+// String renders the summary unified-diff style: "+3 -1 (net +2) in 1 edit".
+func (s EditSummary) String() string {
+	noun := "edits"
+	if s.Edits == 1 {
+		noun = "edit"
+	}
+	return fmt.Sprintf("+%d -%d (net %+d) in %d %s", s.Added, s.Removed, s.Net(), s.Edits, noun)
+}
+
+// SummarizeEdit returns the line diff summary of an Edit or MultiEdit tool
+// input, or nil for other tools or inputs without replacements.
+func SummarizeEdit(toolName string, toolInput map[string]interface{}) *EditSummary {
+	var pairs [][2]string
+	switch toolName {
+	case "Edit":
+		oldStr, _ := toolInput["old_string"].(string)
+		newStr, _ := toolInput["new_string"].(string)
+		pairs = append(pairs, [2]string{oldStr, newStr})
+	case "MultiEdit":
+		edits, _ := toolInput["edits"].([]interface{})
+		for _, e := range edits {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			oldStr, _ := m["old_string"].(string)
+			newStr, _ := m["new_string"].(string)
+			pairs = append(pairs, [2]string{oldStr, newStr})
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	summary := &EditSummary{Edits: len(pairs)}
+	for _, p := range pairs {
+		added, removed := LineDiff(p[0], p[1])
+		summary.Added += added
+		summary.Removed += removed
+	}
+	return summary
+}
+
+// LineDiff counts the lines added and removed going from oldStr to newStr,
+// using the longest common subsequence of lines.
+func LineDiff(oldStr, newStr string) (added, removed int) {
+	a, b := splitLines(oldStr), splitLines(newStr)
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		common := commonLines(a, b)
+		return len(b) - common, len(a) - common
+	}
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = Max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return len(b) - lcs[0][0], len(a) - lcs[0][0]
+}
+
+// commonLines counts lines of b matched by a line of a, as a multiset.
+func commonLines(a, b []string) int {
+	seen := make(map[string]int, len(a))
+	for _, line := range a {
+		seen[line]++
+	}
+	common := 0
+	for _, line := range b {
+		if seen[line] > 0 {
+			seen[line]--
+			common++
+		}
+	}
+	return common
+}
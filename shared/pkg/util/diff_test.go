@@ -0,0 +1,43 @@
+// Package util provides utility functions.
+// diff_test.go: Tests for edit diff summaries.
+package util
+
+import "testing"
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		old, new       string
+		added, removed int
+	}{
+		{"a\nb\nc", "a\nb\nc", 0, 0},
+		{"a\nb\nc", "a\nx\ny\nc", 2, 1},
+		{"", "a\nb\n", 2, 0},
+		{"a\nb", "", 0, 2},
+		{"a\nb\nc", "c\nb\na", 2, 2},
+	}
+	for _, tt := range tests {
+		added, removed := LineDiff(tt.old, tt.new)
+		if added != tt.added || removed != tt.removed {
+			t.Errorf("LineDiff(%q, %q) = +%d -%d, want +%d -%d", tt.old, tt.new, added, removed, tt.added, tt.removed)
+		}
+	}
+}
+
+func TestSummarizeEdit(t *testing.T) {
+	s := SummarizeEdit("Edit", map[string]interface{}{"old_string": "a\nb", "new_string": "a\nc\nd"})
+	if s == nil || s.String() != "+2 -1 (net +1) in 1 edit" {
+		t.Errorf("Edit = %v", s)
+	}
+
+	s = SummarizeEdit("MultiEdit", map[string]interface{}{"edits": []interface{}{
+		map[string]interface{}{"old_string": "x", "new_string": "y"},
+		map[string]interface{}{"old_string": "p\nq\nr", "new_string": ""},
+	}})
+	if s == nil || s.String() != "+1 -4 (net -3) in 2 edits" {
+		t.Errorf("MultiEdit = %v", s)
+	}
+
+	if s := SummarizeEdit("Bash", map[string]interface{}{"command": "ls"}); s != nil {
+		t.Errorf("Bash = %v", s)
+	}
+}
@@ -0,0 +1,273 @@
+// Package validate provides code validation utilities.
+// schema.go: JSON Schema subset for validating decoded tool arguments.
+// DACE: Reusable validation functions.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema applied to tool arguments: type,
+// enum, required, properties, additionalProperties, items and the string,
+// number and array bounds. Other keywords are accepted and ignored.
+type Schema struct {
+	Type                 SchemaType         `json:"type,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Additional        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	re *regexp.Regexp // Pattern, set by Compile
+}
+
+// Compile checks every pattern in s and caches the compiled expressions,
+// so validation does not recompile them per call.
+func (s *Schema) Compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", s.Pattern, err)
+		}
+		s.re = re
+	}
+	subs := []*Schema{s.Items}
+	if s.AdditionalProperties != nil {
+		subs = append(subs, s.AdditionalProperties.Schema)
+	}
+	for _, sub := range s.Properties {
+		subs = append(subs, sub)
+	}
+	for _, sub := range subs {
+		if err := sub.Compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchemaType is a "type" keyword: one type name or a list of them.
+type SchemaType []string
+
+// UnmarshalJSON accepts "string" and ["string", "null"].
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = SchemaType{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("type must be a string or list of strings")
+	}
+	*t = many
+	return nil
+}
+
+// MarshalJSON writes a single type as a plain string.
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Additional is an "additionalProperties" keyword: false forbids
+// undeclared properties, a schema validates them.
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalJSON accepts a boolean or a schema.
+func (a *Additional) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*a = Additional{Allowed: b}
+		return nil
+	}
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*a = Additional{Allowed: true, Schema: &s}
+	return nil
+}
+
+// MarshalJSON writes the boolean form unless a schema is set.
+func (a Additional) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(a.Allowed)
+}
+
+// ValidateSchema checks v, a value decoded from JSON, against s. The
+// error names the first failing location as a JSON path ("$.repo").
+func ValidateSchema(s *Schema, v interface{}) error {
+	if s == nil {
+		return nil
+	}
+	return s.validate("$", v)
+}
+
+func (s *Schema) validate(at string, v interface{}) error {
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		return fmt.Errorf("%s: expected %s, got %s", at, strings.Join(s.Type, " or "), jsonType(v))
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		return fmt.Errorf("%s: %v is not one of %v", at, v, s.Enum)
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return s.validateObject(at, val)
+	case []interface{}:
+		return s.validateArray(at, val)
+	case string:
+		return s.validateString(at, val)
+	case float64:
+		return s.validateNumber(at, val)
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(at string, obj map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", at, name)
+		}
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sub, declared := s.Properties[name]
+		if !declared && s.AdditionalProperties != nil {
+			if !s.AdditionalProperties.Allowed {
+				return fmt.Errorf("%s: unexpected property %q", at, name)
+			}
+			sub = s.AdditionalProperties.Schema
+		}
+		if sub == nil {
+			continue
+		}
+		if err := sub.validate(at+"."+name, obj[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(at string, arr []interface{}) error {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		return fmt.Errorf("%s: %d items, minimum %d", at, len(arr), *s.MinItems)
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		return fmt.Errorf("%s: %d items, maximum %d", at, len(arr), *s.MaxItems)
+	}
+	if s.Items == nil {
+		return nil
+	}
+	for i, item := range arr {
+		if err := s.Items.validate(fmt.Sprintf("%s[%d]", at, i), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(at, str string) error {
+	n := len([]rune(str))
+	if s.MinLength != nil && n < *s.MinLength {
+		return fmt.Errorf("%s: length %d, minimum %d", at, n, *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return fmt.Errorf("%s: length %d, maximum %d", at, n, *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re := s.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(s.Pattern); err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %v", at, s.Pattern, err)
+			}
+		}
+		if !re.MatchString(str) {
+			return fmt.Errorf("%s: %q does not match %q", at, str, s.Pattern)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateNumber(at string, n float64) error {
+	if s.Minimum != nil && n < *s.Minimum {
+		return fmt.Errorf("%s: %v is below minimum %v", at, n, *s.Minimum)
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		return fmt.Errorf("%s: %v is above maximum %v", at, n, *s.Maximum)
+	}
+	return nil
+}
+
+// matches reports whether v has one of the types; integer accepts
+// whole numbers and number accepts both.
+func (t SchemaType) matches(v interface{}) bool {
+	got := jsonType(v)
+	for _, want := range t {
+		switch {
+		case want == got:
+			return true
+		case want == "number" && got == "integer":
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) && !math.IsInf(val, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) && jsonType(e) == jsonType(v) {
+			return true
+		}
+	}
+	return false
+}
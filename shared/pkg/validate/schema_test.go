@@ -0,0 +1,83 @@
+package validate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	var s Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["repo", "title"],
+		"additionalProperties": false,
+		"properties": {
+			"repo":   {"type": "string", "pattern": "^[\\w.-]+/[\\w.-]+$"},
+			"title":  {"type": "string", "minLength": 1, "maxLength": 10},
+			"draft":  {"type": "boolean"},
+			"number": {"type": "integer", "minimum": 1},
+			"state":  {"enum": ["open", "closed"]},
+			"labels": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+			"body":   {"type": ["string", "null"]}
+		}
+	}`), &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"valid", `{"repo": "a/b", "title": "Fix", "number": 3, "labels": ["bug"], "body": null}`, ""},
+		{"missing required", `{"repo": "a/b"}`, `$: missing required property "title"`},
+		{"wrong type", `{"repo": 7, "title": "x"}`, "$.repo: expected string, got integer"},
+		{"pattern", `{"repo": "nope", "title": "x"}`, `$.repo: "nope" does not match`},
+		{"too long", `{"repo": "a/b", "title": "much too long"}`, "$.title: length 13, maximum 10"},
+		{"not integer", `{"repo": "a/b", "title": "x", "number": 1.5}`, "$.number: expected integer, got number"},
+		{"below minimum", `{"repo": "a/b", "title": "x", "number": 0}`, "$.number: 0 is below minimum 1"},
+		{"enum", `{"repo": "a/b", "title": "x", "state": "merged"}`, "$.state: merged is not one of"},
+		{"item type", `{"repo": "a/b", "title": "x", "labels": ["a", 2]}`, "$.labels[1]: expected string"},
+		{"max items", `{"repo": "a/b", "title": "x", "labels": ["a", "b", "c"]}`, "$.labels: 3 items, maximum 2"},
+		{"additional", `{"repo": "a/b", "title": "x", "force": true}`, `$: unexpected property "force"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := json.Unmarshal([]byte(tt.input), &v); err != nil {
+				t.Fatal(err)
+			}
+			err := ValidateSchema(&s, v)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaCompile(t *testing.T) {
+	var s Schema
+	if err := json.Unmarshal([]byte(`{"properties": {"tags": {"items": {"pattern": "^[a-z]+$"}}}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Properties["tags"].Items.re == nil {
+		t.Error("nested pattern not compiled")
+	}
+	err := ValidateSchema(&s, map[string]interface{}{"tags": []interface{}{"ok", "Nope"}})
+	if err == nil || !strings.Contains(err.Error(), "$.tags[1]") {
+		t.Errorf("compiled pattern not applied: %v", err)
+	}
+
+	bad := Schema{AdditionalProperties: &Additional{Allowed: true, Schema: &Schema{Pattern: "("}}}
+	if err := bad.Compile(); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("Compile(bad) = %v", err)
+	}
+}